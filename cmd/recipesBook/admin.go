@@ -0,0 +1,354 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/gmaschi/go-recipes-book/internal/services/datastore/postgresql/connection"
+	"github.com/gmaschi/go-recipes-book/internal/services/datastore/postgresql/recipes/search"
+	db "github.com/gmaschi/go-recipes-book/internal/services/datastore/postgresql/recipes/sqlc"
+	"github.com/gmaschi/go-recipes-book/pkg/config/env"
+	"github.com/gmaschi/go-recipes-book/pkg/tools/password"
+	"github.com/gmaschi/go-recipes-book/pkg/tools/validators"
+	"io"
+	"os"
+	"os/signal"
+	"regexp"
+	"syscall"
+	"text/tabwriter"
+	"time"
+)
+
+const adminUsage = `usage: recipesBook admin <command> [flags] [arguments]
+
+commands:
+  create-author               create an author (-username, -email, -password, -role)
+  disable-author USERNAME     prevent an author from logging in or using the issued tokens
+  enable-author USERNAME      allow a disabled author to log in again
+  reset-password USERNAME     set a new password, generated unless -password is given
+  set-role USERNAME ROLE      change the role of an author: author or admin
+  reassign-recipes FROM TO    move every recipe of an author, but the deleted ones, to another one
+  purge                       delete the recipes soft-deleted before -older-than
+  stats                       print database statistics
+
+The commands talk to the database directly and bypass the HTTP authentication.
+`
+
+// generatedPasswordBytes is the entropy of a generated password, encoded in 16 characters
+const generatedPasswordBytes = 12
+
+var usernameRegex = regexp.MustCompile(`^[a-zA-Z0-9]+$`)
+
+// adminRun runs an admin command with its positional arguments
+type adminRun func(ctx context.Context, store db.Store, args []string, w io.Writer) error
+
+// adminCommands maps the admin commands to a function registering their flags and returning
+// the function running them
+var adminCommands = map[string]func(fs *flag.FlagSet) adminRun{
+	"create-author":    createAuthorCommand,
+	"disable-author":   disableAuthorCommand,
+	"enable-author":    enableAuthorCommand,
+	"reset-password":   resetPasswordCommand,
+	"set-role":         setRoleCommand,
+	"reassign-recipes": reassignRecipesCommand,
+	"purge":            purgeCommand,
+	"stats":            statsCommand,
+}
+
+// adminCommand runs an admin subcommand and returns the process exit code
+func adminCommand(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, adminUsage)
+		return 2
+	}
+	setup, ok := adminCommands[args[0]]
+	if !ok {
+		fmt.Fprint(os.Stderr, adminUsage)
+		return 2
+	}
+
+	fs := flag.NewFlagSet("admin "+args[0], flag.ContinueOnError)
+	run := setup(fs)
+	config, err := env.LoadFlagSet(fs, args[1:])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	conn, err := connection.Open(ctx, config.Database)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer conn.Close()
+
+	if err := run(ctx, db.NewStore(conn, search.New(conn)), fs.Args(), os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+func createAuthorCommand(fs *flag.FlagSet) adminRun {
+	username := fs.String("username", "", "username of the author")
+	email := fs.String("email", "", "email of the author")
+	pass := fs.String("password", "", "password of the author (generated and printed when empty)")
+	role := fs.String("role", db.RoleAuthor, "role of the author: author or admin")
+
+	return func(ctx context.Context, store db.Store, args []string, w io.Writer) error {
+		if err := expectArgs(args, 0); err != nil {
+			return err
+		}
+		if !usernameRegex.MatchString(*username) {
+			return fmt.Errorf("invalid username %q: letters and digits only", *username)
+		}
+		if !validators.Email(*email) {
+			return fmt.Errorf("invalid email %q", *email)
+		}
+		if err := checkRole(*role); err != nil {
+			return err
+		}
+
+		plain, generated, err := newPassword(*pass)
+		if err != nil {
+			return err
+		}
+		hashedPassword, err := password.HashPassword(plain)
+		if err != nil {
+			return err
+		}
+
+		author, err := store.CreateAuthor(ctx, db.CreateAuthorParams{
+			Username:       *username,
+			HashedPassword: hashedPassword,
+			Email:          *email,
+		})
+		if err != nil {
+			return err
+		}
+		if *role != author.Role {
+			author, err = store.UpdateAuthorRole(ctx, db.UpdateAuthorRoleParams{
+				Username:  author.Username,
+				Role:      *role,
+				UpdatedAt: time.Now(),
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		fmt.Fprintf(w, "created %s %s\n", author.Role, author.Username)
+		if generated {
+			fmt.Fprintf(w, "password: %s\n", plain)
+		}
+		return nil
+	}
+}
+
+func disableAuthorCommand(*flag.FlagSet) adminRun {
+	return func(ctx context.Context, store db.Store, args []string, w io.Writer) error {
+		if err := expectArgs(args, 1); err != nil {
+			return err
+		}
+		author, err := store.DisableAuthor(ctx, args[0])
+		if err != nil {
+			return authorError(args[0], err)
+		}
+		fmt.Fprintf(w, "disabled author %s\n", author.Username)
+		return nil
+	}
+}
+
+func enableAuthorCommand(*flag.FlagSet) adminRun {
+	return func(ctx context.Context, store db.Store, args []string, w io.Writer) error {
+		if err := expectArgs(args, 1); err != nil {
+			return err
+		}
+		author, err := store.EnableAuthor(ctx, args[0])
+		if err != nil {
+			return authorError(args[0], err)
+		}
+		fmt.Fprintf(w, "enabled author %s\n", author.Username)
+		return nil
+	}
+}
+
+func resetPasswordCommand(fs *flag.FlagSet) adminRun {
+	pass := fs.String("password", "", "new password (generated and printed when empty)")
+
+	return func(ctx context.Context, store db.Store, args []string, w io.Writer) error {
+		if err := expectArgs(args, 1); err != nil {
+			return err
+		}
+		author, err := store.GetAuthor(ctx, args[0])
+		if err != nil {
+			return authorError(args[0], err)
+		}
+
+		plain, generated, err := newPassword(*pass)
+		if err != nil {
+			return err
+		}
+		hashedPassword, err := password.HashPassword(plain)
+		if err != nil {
+			return err
+		}
+
+		_, err = store.UpdateAuthor(ctx, db.UpdateAuthorParams{
+			Username:       author.Username,
+			Email:          author.Email,
+			HashedPassword: hashedPassword,
+			UpdatedAt:      time.Now(),
+			Version:        author.Version,
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("author %q was updated or deleted meanwhile, retry", author.Username)
+		}
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(w, "reset the password of %s\n", author.Username)
+		if generated {
+			fmt.Fprintf(w, "password: %s\n", plain)
+		}
+		return nil
+	}
+}
+
+func setRoleCommand(*flag.FlagSet) adminRun {
+	return func(ctx context.Context, store db.Store, args []string, w io.Writer) error {
+		if err := expectArgs(args, 2); err != nil {
+			return err
+		}
+		if err := checkRole(args[1]); err != nil {
+			return err
+		}
+
+		author, err := store.UpdateAuthorRole(ctx, db.UpdateAuthorRoleParams{
+			Username:  args[0],
+			Role:      args[1],
+			UpdatedAt: time.Now(),
+		})
+		if err != nil {
+			return authorError(args[0], err)
+		}
+		fmt.Fprintf(w, "%s is now %s\n", author.Username, author.Role)
+		return nil
+	}
+}
+
+func reassignRecipesCommand(*flag.FlagSet) adminRun {
+	return func(ctx context.Context, store db.Store, args []string, w io.Writer) error {
+		if err := expectArgs(args, 2); err != nil {
+			return err
+		}
+		for _, username := range args {
+			if _, err := store.GetAuthor(ctx, username); err != nil {
+				return authorError(username, err)
+			}
+		}
+
+		n, err := store.ReassignRecipes(ctx, db.ReassignRecipesParams{
+			FromAuthor: args[0],
+			ToAuthor:   args[1],
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "reassigned %d recipe(s) from %s to %s\n", n, args[0], args[1])
+		return nil
+	}
+}
+
+func purgeCommand(fs *flag.FlagSet) adminRun {
+	olderThan := fs.Duration("older-than", 30*24*time.Hour, "minimum time since the soft deletion, 0 purges every deleted recipe")
+
+	return func(ctx context.Context, store db.Store, args []string, w io.Writer) error {
+		if err := expectArgs(args, 0); err != nil {
+			return err
+		}
+		if *olderThan < 0 {
+			return fmt.Errorf("invalid -older-than %s", *olderThan)
+		}
+
+		n, err := store.PurgeDeletedRecipes(ctx, time.Now().Add(-*olderThan))
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "purged %d deleted recipe(s)\n", n)
+		return nil
+	}
+}
+
+func statsCommand(*flag.FlagSet) adminRun {
+	return func(ctx context.Context, store db.Store, args []string, w io.Writer) error {
+		if err := expectArgs(args, 0); err != nil {
+			return err
+		}
+		stats, err := store.GetStats(ctx)
+		if err != nil {
+			return err
+		}
+		version, dirty, err := store.MigrationVersion(ctx)
+		if err != nil {
+			return err
+		}
+
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(tw, "authors:\t%d\n", stats.Authors)
+		fmt.Fprintf(tw, "disabled authors:\t%d\n", stats.DisabledAuthors)
+		fmt.Fprintf(tw, "admins:\t%d\n", stats.Admins)
+		fmt.Fprintf(tw, "recipes:\t%d\n", stats.Recipes)
+		fmt.Fprintf(tw, "deleted recipes:\t%d\n", stats.DeletedRecipes)
+		fmt.Fprintf(tw, "migration version:\t%d\n", version)
+		fmt.Fprintf(tw, "migration dirty:\t%t\n", dirty)
+		return tw.Flush()
+	}
+}
+
+// expectArgs checks the number of positional arguments of a command
+func expectArgs(args []string, n int) error {
+	if len(args) != n {
+		return fmt.Errorf("expected %d argument(s), got %d\n%s", n, len(args), adminUsage)
+	}
+	return nil
+}
+
+func checkRole(role string) error {
+	if role != db.RoleAuthor && role != db.RoleAdmin {
+		return fmt.Errorf("invalid role %q, use %s or %s", role, db.RoleAuthor, db.RoleAdmin)
+	}
+	return nil
+}
+
+// authorError replaces the sql.ErrNoRows of the queries on an author with a readable error
+func authorError(username string, err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("author %q not found", username)
+	}
+	return err
+}
+
+// newPassword checks the given password, or generates one when it is empty
+func newPassword(given string) (string, bool, error) {
+	if given != "" {
+		if !validators.Password(given) {
+			return "", false, errors.New("invalid password: between 6 and 24 characters")
+		}
+		return given, false, nil
+	}
+
+	b := make([]byte, generatedPasswordBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", false, fmt.Errorf("cannot generate password: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), true, nil
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	memoryStore "github.com/gmaschi/go-recipes-book/internal/mocks/datastore/memory"
+	db "github.com/gmaschi/go-recipes-book/internal/services/datastore/postgresql/recipes/sqlc"
+	"github.com/gmaschi/go-recipes-book/pkg/tools/password"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+)
+
+// runAdmin runs an admin command against store and returns its output
+func runAdmin(t *testing.T, store db.Store, args ...string) (string, error) {
+	fs := flag.NewFlagSet("admin "+args[0], flag.ContinueOnError)
+	run := adminCommands[args[0]](fs)
+	require.NoError(t, fs.Parse(args[1:]))
+
+	var out bytes.Buffer
+	err := run(context.Background(), store, fs.Args(), &out)
+	return out.String(), err
+}
+
+func TestAdminCommands(t *testing.T) {
+	ctx := context.Background()
+	store := memoryStore.New()
+
+	out, err := runAdmin(t, store, "create-author", "-username", "alice", "-email", "alice@example.com", "-role", "admin")
+	require.NoError(t, err)
+	require.Contains(t, out, "created admin alice")
+	generated := strings.TrimPrefix(strings.TrimSpace(out[strings.Index(out, "password: "):]), "password: ")
+	alice, err := store.GetAuthor(ctx, "alice")
+	require.NoError(t, err)
+	require.NoError(t, password.CheckPassword(generated, alice.HashedPassword))
+
+	_, err = runAdmin(t, store, "create-author", "-username", "bob", "-email", "bob@example.com", "-password", "secret")
+	require.NoError(t, err)
+
+	t.Run("Invalid author", func(t *testing.T) {
+		_, err := runAdmin(t, store, "create-author", "-username", "b-o-b", "-email", "bob@example.com")
+		require.Error(t, err)
+		_, err = runAdmin(t, store, "create-author", "-username", "carol", "-email", "carol@example.com", "-role", "owner")
+		require.Error(t, err)
+	})
+
+	t.Run("Disable and enable", func(t *testing.T) {
+		_, err := runAdmin(t, store, "disable-author", "bob")
+		require.NoError(t, err)
+		bob, err := store.GetAuthor(ctx, "bob")
+		require.NoError(t, err)
+		require.True(t, bob.DisabledAt.Valid)
+
+		_, err = runAdmin(t, store, "enable-author", "bob")
+		require.NoError(t, err)
+		bob, err = store.GetAuthor(ctx, "bob")
+		require.NoError(t, err)
+		require.False(t, bob.DisabledAt.Valid)
+
+		_, err = runAdmin(t, store, "disable-author", "unknown")
+		require.EqualError(t, err, `author "unknown" not found`)
+	})
+
+	t.Run("Reset password", func(t *testing.T) {
+		_, err := runAdmin(t, store, "reset-password", "-password", "newsecret", "bob")
+		require.NoError(t, err)
+		bob, err := store.GetAuthor(ctx, "bob")
+		require.NoError(t, err)
+		require.NoError(t, password.CheckPassword("newsecret", bob.HashedPassword))
+		require.Equal(t, "bob@example.com", bob.Email)
+	})
+
+	t.Run("Set role", func(t *testing.T) {
+		out, err := runAdmin(t, store, "set-role", "bob", "admin")
+		require.NoError(t, err)
+		require.Equal(t, "bob is now admin\n", out)
+
+		_, err = runAdmin(t, store, "set-role", "bob", "owner")
+		require.Error(t, err)
+	})
+
+	t.Run("Reassign, purge and stats", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			_, err := store.CreateRecipe(ctx, db.CreateRecipeParams{Author: "bob", Ingredients: []string{"a"}, Steps: []string{"b"}})
+			require.NoError(t, err)
+		}
+		recipes, err := store.ListRecipes(ctx, db.ListRecipesParams{Author: "bob", Limit: 10})
+		require.NoError(t, err)
+		require.NoError(t, store.DeleteRecipe(ctx, recipes[0].ID))
+
+		// the deleted recipe is left to bob, to be purged
+		out, err := runAdmin(t, store, "reassign-recipes", "bob", "alice")
+		require.NoError(t, err)
+		require.Equal(t, "reassigned 2 recipe(s) from bob to alice\n", out)
+		_, err = runAdmin(t, store, "reassign-recipes", "bob", "unknown")
+		require.Error(t, err)
+
+		out, err = runAdmin(t, store, "purge")
+		require.NoError(t, err)
+		require.Equal(t, "purged 0 deleted recipe(s)\n", out)
+		out, err = runAdmin(t, store, "purge", "-older-than", "0")
+		require.NoError(t, err)
+		require.Equal(t, "purged 1 deleted recipe(s)\n", out)
+
+		out, err = runAdmin(t, store, "stats")
+		require.NoError(t, err)
+		require.Contains(t, out, "authors:            2\n")
+		require.Contains(t, out, "admins:             2\n")
+		require.Contains(t, out, "recipes:            2\n")
+		require.Contains(t, out, "deleted recipes:    0\n")
+	})
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/gmaschi/go-recipes-book/pkg/config/env"
+	"os"
+)
+
+const configUsage = `usage: recipesBook config <command> [flags]
+
+commands:
+  validate   load the configuration and report any missing or invalid value
+  print      print the resolved configuration as YAML (secrets redacted by default)
+`
+
+// configCommand runs a config subcommand and returns the process exit code
+func configCommand(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, configUsage)
+		return 2
+	}
+
+	fs := flag.NewFlagSet("config "+args[0], flag.ContinueOnError)
+
+	switch args[0] {
+	case "validate":
+		if _, err := env.LoadFlagSet(fs, args[1:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		fmt.Println("configuration is valid")
+		return 0
+
+	case "print":
+		redacted := fs.Bool("redacted", true, "mask secret values")
+		config, err := env.LoadFlagSet(fs, args[1:])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		if *redacted {
+			config = config.Redacted()
+		}
+
+		out, err := config.YAML()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		fmt.Print(string(out))
+		return 0
+
+	default:
+		fmt.Fprint(os.Stderr, configUsage)
+		return 2
+	}
+}
@@ -1,33 +1,110 @@
 package main
 
 import (
-	"database/sql"
+	"context"
 	"github.com/gmaschi/go-recipes-book/internal/factories/book-recipe-factory"
+	"github.com/gmaschi/go-recipes-book/internal/services/datastore/postgresql/connection"
+	"github.com/gmaschi/go-recipes-book/internal/services/datastore/postgresql/recipes/migration"
+	"github.com/gmaschi/go-recipes-book/internal/services/datastore/postgresql/recipes/search"
 	db "github.com/gmaschi/go-recipes-book/internal/services/datastore/postgresql/recipes/sqlc"
+	"github.com/gmaschi/go-recipes-book/internal/services/tracing"
 	"github.com/gmaschi/go-recipes-book/pkg/config/env"
+	"github.com/gmaschi/go-recipes-book/pkg/logger"
 	_ "github.com/lib/pq"
 	"log"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
 )
 
 func main() {
-	config, err := env.NewConfig()
+	args := os.Args[1:]
+	if len(args) > 0 {
+		switch args[0] {
+		case "admin":
+			os.Exit(adminCommand(args[1:]))
+		case "config":
+			os.Exit(configCommand(args[1:]))
+		case "migrate":
+			os.Exit(migrateCommand(args[1:]))
+		case "serve":
+			args = args[1:]
+		}
+	}
+
+	serve(args)
+}
+
+func serve(args []string) {
+	config, err := env.Load(args)
+	if err != nil {
+		log.Fatalln("cannot load config:", err)
+	}
+
+	defaultLogger, err := logger.New(config.Logging, os.Stdout)
+	if err != nil {
+		log.Fatalln("cannot create logger:", err)
+	}
+	slog.SetDefault(defaultLogger)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	// the spans are written to stderr so that they do not mix with the JSON logs of stdout
+	tracerProvider, err := tracing.New(ctx, config.Tracing, os.Stderr)
 	if err != nil {
-		log.Fatalln("cannot load env variables")
+		fatal("cannot create tracer provider", err)
 	}
+	tracing.SetGlobal(tracerProvider)
 
-	conn, err := sql.Open(config.DbDriver, config.DbSource)
+	conn, err := connection.Open(ctx, config.Database)
 	if err != nil {
-		log.Fatalln("could not connect to database:", err)
+		fatal("could not connect to database", err)
 	}
 
-	store := db.NewStore(conn)
+	if err := prepareSchema(config); err != nil {
+		fatal("cannot prepare database schema", err)
+	}
+
+	store := db.NewStore(conn, search.New(conn))
 	server, err := bookRecipeFactory.New(config, store)
 	if err != nil {
-		log.Fatalln("could not start server:", err)
+		fatal("could not start server", err)
+	}
+	server.OnShutdown(func() error {
+		// flush the pending spans, the server context is already cancelled at this point
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), config.HTTP.ShutdownTimeout)
+		defer cancel()
+		return tracerProvider.Shutdown(shutdownCtx)
+	})
+	server.OnShutdown(conn.Close)
+
+	err = server.Run(ctx)
+	if err != nil {
+		fatal("server stopped with error", err)
 	}
+	slog.Info("server stopped")
+}
+
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
+}
 
-	err = server.Start(config.ServerAddress)
+// prepareSchema applies the pending migrations when AUTO_MIGRATE is set and refuses to
+// start against a schema migrated by a newer binary or left dirty by a failed migration
+func prepareSchema(config env.Config) error {
+	migrator, err := migration.New(config.Database.Source)
 	if err != nil {
-		log.Fatalln("cannot start server", err)
+		return err
+	}
+	defer migrator.Close()
+
+	if config.Database.AutoMigrate {
+		if err := migrator.Up(0); err != nil {
+			return err
+		}
 	}
+	return migrator.CheckCompatible()
 }
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/gmaschi/go-recipes-book/internal/services/datastore/postgresql/recipes/migration"
+	"github.com/gmaschi/go-recipes-book/pkg/config/env"
+	"os"
+	"strconv"
+)
+
+const migrateUsage = `usage: recipesBook migrate <command> [flags] [argument]
+
+commands:
+  up [N]            apply all pending migrations, or only the next N
+  down [N]          roll back the last N migrations (default 1)
+  status            print the current and latest migration versions
+  force VERSION     set the migration version and clear the dirty flag
+`
+
+// migrateCommand runs a migrate subcommand and returns the process exit code
+func migrateCommand(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, migrateUsage)
+		return 2
+	}
+
+	fs := flag.NewFlagSet("migrate "+args[0], flag.ContinueOnError)
+	config, err := env.LoadFlagSet(fs, args[1:])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	migrator, err := migration.New(config.Database.Source)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer migrator.Close()
+
+	if err := runMigrate(migrator, args[0], fs.Args()); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+func runMigrate(migrator *migration.Migrator, command string, args []string) error {
+	switch command {
+	case "up":
+		steps, err := intArg(args, 0)
+		if err != nil {
+			return err
+		}
+		if err := migrator.Up(steps); err != nil {
+			return err
+		}
+		return printStatus(migrator)
+
+	case "down":
+		steps, err := intArg(args, 1)
+		if err != nil {
+			return err
+		}
+		if err := migrator.Down(steps); err != nil {
+			return err
+		}
+		return printStatus(migrator)
+
+	case "status":
+		return printStatus(migrator)
+
+	case "force":
+		if len(args) != 1 {
+			return fmt.Errorf("force requires a version")
+		}
+		version, err := strconv.Atoi(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid version %q", args[0])
+		}
+		if err := migrator.Force(version); err != nil {
+			return err
+		}
+		return printStatus(migrator)
+
+	default:
+		return fmt.Errorf("unknown migrate command %q\n%s", command, migrateUsage)
+	}
+}
+
+// intArg parses the optional numeric argument of a migrate command
+func intArg(args []string, def int) (int, error) {
+	if len(args) == 0 {
+		return def, nil
+	}
+	n, err := strconv.Atoi(args[0])
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid number of steps %q", args[0])
+	}
+	return n, nil
+}
+
+func printStatus(migrator *migration.Migrator) error {
+	status, err := migrator.Status()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("version: %d\ndirty: %t\nlatest: %d\n", status.Version, status.Dirty, status.Latest)
+	return nil
+}
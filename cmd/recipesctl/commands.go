@@ -0,0 +1,377 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gmaschi/go-recipes-book/pkg/client"
+	"gopkg.in/yaml.v3"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	exportJSON     = "json"
+	exportYAML     = "yaml"
+	exportMarkdown = "markdown"
+)
+
+func loginCommand(ctx context.Context, cmd *command) error {
+	username := cmd.fs.String("username", "", "author username (prompted when empty)")
+	if _, err := cmd.parse(0); err != nil {
+		return err
+	}
+
+	var err error
+	if *username == "" {
+		if *username, err = cmd.app.readLine("Username: "); err != nil {
+			return err
+		}
+	}
+	// the password is never a flag, which would leave it in the shell history and the
+	// process list
+	password, err := cmd.app.readPassword("Password: ")
+	if err != nil {
+		return err
+	}
+
+	c, err := cmd.client()
+	if err != nil {
+		return err
+	}
+	if _, err := c.Login(ctx, *username, password); err != nil {
+		return err
+	}
+
+	s, err := cmd.settings.read()
+	if err != nil {
+		return err
+	}
+	s.Server = c.BaseURL()
+	if err := cmd.settings.write(s); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmd.app.stdout, "logged in as %s on %s\n", *username, s.Server)
+	return nil
+}
+
+func logoutCommand(_ context.Context, cmd *command) error {
+	if _, err := cmd.parse(0); err != nil {
+		return err
+	}
+	return cmd.settings.Clear()
+}
+
+func listCommand(ctx context.Context, cmd *command) error {
+	page := cmd.fs.Int("page", 0, "page to list, starting at 1 (all pages when 0)")
+	pageSize := cmd.fs.Int("page-size", client.MaxPageSize, "number of recipes per page")
+	cmd.outputFlag()
+	if _, err := cmd.parse(0); err != nil {
+		return err
+	}
+
+	c, err := cmd.client()
+	if err != nil {
+		return err
+	}
+
+	var recipes []client.Recipe
+	if *page > 0 {
+		recipes, err = c.ListRecipes(ctx, client.ListOptions{PageID: int32(*page), PageSize: int32(*pageSize)})
+	} else {
+		recipes, err = collectRecipes(ctx, c.Recipes(int32(*pageSize)))
+	}
+	if err != nil {
+		return err
+	}
+	return printRecipes(cmd.app.stdout, cmd.output, recipes)
+}
+
+func getCommand(ctx context.Context, cmd *command) error {
+	cmd.outputFlag()
+	args, err := cmd.parse(1)
+	if err != nil {
+		return err
+	}
+	id, err := parseID(args[0])
+	if err != nil {
+		return err
+	}
+
+	c, err := cmd.client()
+	if err != nil {
+		return err
+	}
+	recipe, err := c.GetRecipe(ctx, id)
+	if err != nil {
+		return err
+	}
+	return printRecipe(cmd.app.stdout, cmd.output, recipe)
+}
+
+func createCommand(ctx context.Context, cmd *command) error {
+	file := cmd.fs.String("file", "", "YAML (.yaml, .yml) or Markdown (.md) recipe file, - for a Markdown standard input (opens $EDITOR when empty)")
+	cmd.outputFlag()
+	if _, err := cmd.parse(0); err != nil {
+		return err
+	}
+
+	var recipe recipeFile
+	var err error
+	switch *file {
+	case "":
+		recipe, err = cmd.app.editRecipe("recipe-*.md", []byte(markdownTemplate))
+	case "-":
+		var content []byte
+		if content, err = io.ReadAll(cmd.app.stdin); err == nil {
+			recipe, err = parseRecipeFile("stdin.md", content)
+		}
+	default:
+		var content []byte
+		if content, err = os.ReadFile(*file); err == nil {
+			recipe, err = parseRecipeFile(*file, content)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	c, err := cmd.client()
+	if err != nil {
+		return err
+	}
+	created, err := c.CreateRecipe(ctx, client.CreateRecipeRequest{
+		Ingredients: recipe.Ingredients,
+		Steps:       recipe.Steps,
+	})
+	if err != nil {
+		return err
+	}
+	return printRecipe(cmd.app.stdout, cmd.output, created)
+}
+
+func editCommand(ctx context.Context, cmd *command) error {
+	format := cmd.fs.String("format", exportMarkdown, "format of the edited file: markdown or yaml")
+	cmd.outputFlag()
+	args, err := cmd.parse(1)
+	if err != nil {
+		return err
+	}
+	id, err := parseID(args[0])
+	if err != nil {
+		return err
+	}
+
+	c, err := cmd.client()
+	if err != nil {
+		return err
+	}
+	current, err := c.GetRecipe(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	var pattern string
+	var content []byte
+	switch *format {
+	case exportMarkdown:
+		pattern, content = "recipe-*.md", []byte(formatMarkdown(current))
+	case exportYAML:
+		pattern = "recipe-*.yaml"
+		if content, err = yaml.Marshal(recipeFile{Ingredients: current.Ingredients, Steps: current.Steps}); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("invalid edit format %q, use markdown or yaml", *format)
+	}
+
+	recipe, err := cmd.app.editRecipe(pattern, content)
+	if err != nil {
+		return err
+	}
+
+	updated, err := c.UpdateRecipe(ctx, client.UpdateRecipeRequest{
+		ID:          id,
+		Version:     current.Version,
+		Ingredients: recipe.Ingredients,
+		Steps:       recipe.Steps,
+		ETag:        current.ETag,
+	})
+	if err != nil {
+		return err
+	}
+	return printRecipe(cmd.app.stdout, cmd.output, updated)
+}
+
+func deleteCommand(ctx context.Context, cmd *command) error {
+	args, err := cmd.parse(-1)
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		cmd.fs.Usage()
+		return errors.New("expected at least one recipe ID")
+	}
+
+	ids := make([]int64, len(args))
+	for i, arg := range args {
+		if ids[i], err = parseID(arg); err != nil {
+			return err
+		}
+	}
+
+	c, err := cmd.client()
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if err := c.DeleteRecipe(ctx, id); err != nil {
+			return fmt.Errorf("recipe %d: %w", id, err)
+		}
+		fmt.Fprintf(cmd.app.stdout, "deleted recipe %d\n", id)
+	}
+	return nil
+}
+
+func searchCommand(ctx context.Context, cmd *command) error {
+	cmd.outputFlag()
+	args, err := cmd.parse(-1)
+	if err != nil {
+		return err
+	}
+	ingredient := strings.Join(args, " ")
+	if ingredient == "" {
+		cmd.fs.Usage()
+		return errors.New("expected an ingredient")
+	}
+
+	c, err := cmd.client()
+	if err != nil {
+		return err
+	}
+	recipes, err := collectRecipes(ctx, c.RecipesWithIngredient(ingredient, client.MaxPageSize))
+	if err != nil {
+		return err
+	}
+	return printRecipes(cmd.app.stdout, cmd.output, recipes)
+}
+
+func exportCommand(ctx context.Context, cmd *command) error {
+	format := cmd.fs.String("format", exportJSON, "export format: json, yaml or markdown")
+	out := cmd.fs.String("o", "", "file to write (standard output when empty)")
+	if _, err := cmd.parse(0); err != nil {
+		return err
+	}
+	if *format != exportJSON && *format != exportYAML && *format != exportMarkdown {
+		return fmt.Errorf("invalid export format %q, use json, yaml or markdown", *format)
+	}
+
+	c, err := cmd.client()
+	if err != nil {
+		return err
+	}
+	recipes, err := collectRecipes(ctx, c.Recipes(client.MaxPageSize))
+	if err != nil {
+		return err
+	}
+
+	if *out == "" {
+		return writeExport(cmd.app.stdout, *format, recipes)
+	}
+	f, err := os.Create(*out)
+	if err != nil {
+		return err
+	}
+	if err := writeExport(f, *format, recipes); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func writeExport(w io.Writer, format string, recipes []client.Recipe) error {
+	switch format {
+	case exportYAML:
+		return yaml.NewEncoder(w).Encode(exportedRecipes(recipes))
+	case exportMarkdown:
+		for i, recipe := range recipes {
+			if i > 0 {
+				fmt.Fprintln(w)
+			}
+			fmt.Fprint(w, formatMarkdown(recipe))
+		}
+		return nil
+	default:
+		return printRecipes(w, outputJSON, recipes)
+	}
+}
+
+// collectRecipes lists every recipe of it
+func collectRecipes(ctx context.Context, it *client.Iterator[client.Recipe]) ([]client.Recipe, error) {
+	var recipes []client.Recipe
+	for it.Next(ctx) {
+		recipes = append(recipes, it.Value())
+	}
+	return recipes, it.Err()
+}
+
+// exportedRecipe is the YAML form of an exported recipe
+type exportedRecipe struct {
+	ID          int64    `yaml:"id"`
+	Author      string   `yaml:"author"`
+	Ingredients []string `yaml:"ingredients"`
+	Steps       []string `yaml:"steps"`
+}
+
+func exportedRecipes(recipes []client.Recipe) []exportedRecipe {
+	out := make([]exportedRecipe, len(recipes))
+	for i, recipe := range recipes {
+		out[i] = exportedRecipe{
+			ID:          recipe.ID,
+			Author:      recipe.Author,
+			Ingredients: recipe.Ingredients,
+			Steps:       recipe.Steps,
+		}
+	}
+	return out
+}
+
+// editRecipe writes content to a temporary file named after pattern, opens it in the
+// editor and parses the result
+func (app *cli) editRecipe(pattern string, content []byte) (recipeFile, error) {
+	f, err := os.CreateTemp("", pattern)
+	if err != nil {
+		return recipeFile{}, err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(content); err != nil {
+		f.Close()
+		return recipeFile{}, err
+	}
+	if err := f.Close(); err != nil {
+		return recipeFile{}, err
+	}
+
+	if err := app.edit(f.Name()); err != nil {
+		return recipeFile{}, err
+	}
+
+	edited, err := os.ReadFile(f.Name())
+	if err != nil {
+		return recipeFile{}, err
+	}
+	return parseRecipeFile(filepath.Base(f.Name()), edited)
+}
+
+func parseID(arg string) (int64, error) {
+	id, err := strconv.ParseInt(arg, 10, 64)
+	if err != nil || id < 1 {
+		return 0, fmt.Errorf("invalid recipe ID %q", arg)
+	}
+	return id, nil
+}
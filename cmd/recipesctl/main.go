@@ -0,0 +1,218 @@
+// Command recipesctl manages recipes through the REST API of the recipes book.
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/gmaschi/go-recipes-book/pkg/client"
+	"golang.org/x/term"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"syscall"
+)
+
+const usage = `usage: recipesctl <command> [flags] [arguments]
+
+commands:
+  login             log in and store the access token in the config file
+  logout            forget the stored access token
+  list              list recipes
+  get ID            print a recipe
+  create            create a recipe from -file or in $EDITOR
+  edit ID           edit a recipe in $EDITOR
+  delete ID...      delete recipes
+  search INGREDIENT list the recipes with the ingredient INGREDIENT
+  export            write every recipe as JSON, YAML or Markdown
+
+Run recipesctl <command> -h for the flags of a command.
+`
+
+// cli holds the environment the commands run in, replaced by tests
+type cli struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+	getenv func(string) string
+
+	// edit opens path in the user editor and returns when it is closed
+	edit func(path string) error
+}
+
+// command is a recipesctl subcommand. Commands register their own flags on fs and then
+// call parse.
+type command struct {
+	fs   *flag.FlagSet
+	app  *cli
+	args []string
+
+	configPath string
+	server     string
+	output     string
+	settings   settingsFile
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	app := &cli{
+		stdin:  os.Stdin,
+		stdout: os.Stdout,
+		stderr: os.Stderr,
+		getenv: os.Getenv,
+	}
+	app.edit = app.runEditor
+
+	os.Exit(app.run(ctx, os.Args[1:]))
+}
+
+// run runs the command named by args[0] and returns the process exit code
+func (app *cli) run(ctx context.Context, args []string) int {
+	if len(args) == 0 {
+		fmt.Fprint(app.stderr, usage)
+		return 2
+	}
+
+	commands := map[string]func(context.Context, *command) error{
+		"login":  loginCommand,
+		"logout": logoutCommand,
+		"list":   listCommand,
+		"get":    getCommand,
+		"create": createCommand,
+		"edit":   editCommand,
+		"delete": deleteCommand,
+		"search": searchCommand,
+		"export": exportCommand,
+	}
+
+	name := args[0]
+	if name == "help" || name == "-h" || name == "--help" {
+		fmt.Fprint(app.stdout, usage)
+		return 0
+	}
+	runCommand, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(app.stderr, "unknown command %q\n\n%s", name, usage)
+		return 2
+	}
+
+	cmd := &command{
+		fs:   flag.NewFlagSet("recipesctl "+name, flag.ContinueOnError),
+		app:  app,
+		args: args[1:],
+	}
+	cmd.fs.SetOutput(app.stderr)
+	cmd.fs.StringVar(&cmd.configPath, "config", defaultSettingsPath(app.getenv), "path to the config file (defaults to $"+settingsVar+")")
+	cmd.fs.StringVar(&cmd.server, "server", "", "base URL of the API (defaults to $"+serverVar+" or the server of the last login)")
+
+	if err := runCommand(ctx, cmd); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		fmt.Fprintln(app.stderr, "error:", err)
+		if errors.Is(err, client.ErrNoToken) {
+			fmt.Fprintln(app.stderr, "run recipesctl login first")
+		}
+		return 1
+	}
+	return 0
+}
+
+// outputFlag registers the -output flag on the commands printing recipes
+func (cmd *command) outputFlag() {
+	cmd.fs.StringVar(&cmd.output, "output", outputTable, "output format: table or json")
+}
+
+// parse parses the command line and checks the shared flags. It returns the positional
+// arguments, which must be exactly nargs unless nargs is negative.
+func (cmd *command) parse(nargs int) ([]string, error) {
+	if err := cmd.fs.Parse(cmd.args); err != nil {
+		return nil, err
+	}
+	cmd.settings = settingsFile{path: cmd.configPath}
+
+	if cmd.output != "" && cmd.output != outputTable && cmd.output != outputJSON {
+		return nil, fmt.Errorf("invalid output format %q, use table or json", cmd.output)
+	}
+
+	args := cmd.fs.Args()
+	if nargs >= 0 && len(args) != nargs {
+		cmd.fs.Usage()
+		return nil, fmt.Errorf("expected %d argument(s), got %d", nargs, len(args))
+	}
+	return args, nil
+}
+
+// client returns an API client using the server from the flags, the environment or the
+// config file, and the token stored in the config file
+func (cmd *command) client() (*client.Client, error) {
+	server := cmd.server
+	if server == "" {
+		server = cmd.app.getenv(serverVar)
+	}
+	if server == "" {
+		s, err := cmd.settings.read()
+		if err != nil {
+			return nil, err
+		}
+		server = s.Server
+	}
+	if server == "" {
+		server = defaultServer
+	}
+	return client.New(server, client.WithTokenStore(cmd.settings))
+}
+
+// readLine reads a line of the standard input, printing prompt first
+func (app *cli) readLine(prompt string) (string, error) {
+	fmt.Fprint(app.stderr, prompt)
+	line, err := bufio.NewReader(app.stdin).ReadString('\n')
+	if err != nil && (!errors.Is(err, io.EOF) || line == "") {
+		return "", fmt.Errorf("cannot read %s: %w", strings.TrimSuffix(strings.ToLower(prompt), ": "), err)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// readPassword reads a password without echoing it when the standard input is a terminal,
+// and reads a line of it otherwise, e.g. when the password is piped by a script
+func (app *cli) readPassword(prompt string) (string, error) {
+	f, ok := app.stdin.(*os.File)
+	if !ok || !term.IsTerminal(int(f.Fd())) {
+		return app.readLine(prompt)
+	}
+
+	fmt.Fprint(app.stderr, prompt)
+	password, err := term.ReadPassword(int(f.Fd()))
+	fmt.Fprintln(app.stderr)
+	if err != nil {
+		return "", fmt.Errorf("cannot read password: %w", err)
+	}
+	return string(password), nil
+}
+
+// runEditor opens path with $VISUAL, $EDITOR or vi
+func (app *cli) runEditor(path string) error {
+	editor := app.getenv("VISUAL")
+	if editor == "" {
+		editor = app.getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+
+	fields := strings.Fields(editor)
+	cmd := exec.Command(fields[0], append(fields[1:], path)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("editor %s: %w", editor, err)
+	}
+	return nil
+}
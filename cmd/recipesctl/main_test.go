@@ -0,0 +1,228 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	bookRecipeFactory "github.com/gmaschi/go-recipes-book/internal/factories/book-recipe-factory"
+	memoryStore "github.com/gmaschi/go-recipes-book/internal/mocks/datastore/memory"
+	"github.com/gmaschi/go-recipes-book/pkg/client"
+	"github.com/gmaschi/go-recipes-book/pkg/config/env"
+	"github.com/gmaschi/go-recipes-book/pkg/tools/random"
+	"github.com/stretchr/testify/require"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testMarkdown = `# Pancakes
+
+Some text that is ignored.
+
+## Ingredients
+
+- flour
+* milk
+- eggs
+
+## Steps
+
+1. mix everything
+2) cook in a pan
+`
+
+func TestParseRecipeFile(t *testing.T) {
+	t.Run("Markdown", func(t *testing.T) {
+		recipe, err := parseRecipeFile("pancakes.md", []byte(testMarkdown))
+		require.NoError(t, err)
+		require.Equal(t, []string{"flour", "milk", "eggs"}, recipe.Ingredients)
+		require.Equal(t, []string{"mix everything", "cook in a pan"}, recipe.Steps)
+	})
+
+	t.Run("YAML", func(t *testing.T) {
+		recipe, err := parseRecipeFile("pancakes.yaml", []byte("ingredients: [flour, milk]\nsteps:\n  - mix\n"))
+		require.NoError(t, err)
+		require.Equal(t, []string{"flour", "milk"}, recipe.Ingredients)
+		require.Equal(t, []string{"mix"}, recipe.Steps)
+	})
+
+	t.Run("Round trip", func(t *testing.T) {
+		want := client.Recipe{ID: 3, Ingredients: []string{"a", "b"}, Steps: []string{"c"}}
+		recipe, err := parseRecipeFile("recipe.md", []byte(formatMarkdown(want)))
+		require.NoError(t, err)
+		require.Equal(t, want.Ingredients, recipe.Ingredients)
+		require.Equal(t, want.Steps, recipe.Steps)
+	})
+
+	t.Run("Empty template", func(t *testing.T) {
+		_, err := parseRecipeFile("recipe.md", []byte(markdownTemplate))
+		require.Error(t, err)
+	})
+
+	t.Run("Unsupported extension", func(t *testing.T) {
+		_, err := parseRecipeFile("recipe.txt", []byte(testMarkdown))
+		require.Error(t, err)
+	})
+}
+
+type testCLI struct {
+	t          *testing.T
+	configPath string
+	stdin      string
+	edit       func(path string) error
+}
+
+// run runs recipesctl with args and returns its exit code and standard output
+func (tc *testCLI) run(args ...string) (int, string) {
+	var stdout, stderr bytes.Buffer
+	app := &cli{
+		stdin:  strings.NewReader(tc.stdin),
+		stdout: &stdout,
+		stderr: &stderr,
+		getenv: func(name string) string {
+			if name == settingsVar {
+				return tc.configPath
+			}
+			return ""
+		},
+		edit: tc.edit,
+	}
+	code := app.run(context.Background(), args)
+	if code != 0 {
+		tc.t.Log(stderr.String())
+	}
+	return code, stdout.String()
+}
+
+func TestCommands(t *testing.T) {
+	config := env.Config{
+		Auth: env.AuthConfig{
+			TokenSymmetricKey: random.String(32),
+			TokenDuration:     time.Minute,
+		},
+	}
+	server, err := bookRecipeFactory.New(config, memoryStore.New())
+	require.NoError(t, err)
+	httpServer := httptest.NewServer(server.Router)
+	t.Cleanup(httpServer.Close)
+
+	ctx := context.Background()
+	c, err := client.New(httpServer.URL)
+	require.NoError(t, err)
+	author := client.CreateAuthorRequest{Username: random.String(10), Password: random.String(8), Email: random.Email()}
+	_, err = c.CreateAuthor(ctx, author)
+	require.NoError(t, err)
+	_, err = c.Login(ctx, author.Username, author.Password)
+	require.NoError(t, err)
+
+	tc := &testCLI{t: t, configPath: filepath.Join(t.TempDir(), "recipesctl", "config.json")}
+
+	code, _ := tc.run("list")
+	require.Equal(t, 1, code)
+
+	tc.stdin = author.Password + "\n"
+	code, out := tc.run("login", "-server", httpServer.URL, "-username", author.Username)
+	require.Equal(t, 0, code)
+	require.Contains(t, out, author.Username)
+
+	info, err := os.Stat(tc.configPath)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	recipePath := filepath.Join(t.TempDir(), "pancakes.md")
+	require.NoError(t, os.WriteFile(recipePath, []byte(testMarkdown), 0o600))
+	code, out = tc.run("create", "-file", recipePath, "-output", "json")
+	require.Equal(t, 0, code)
+	var created client.Recipe
+	require.NoError(t, json.Unmarshal([]byte(out), &created))
+	require.Equal(t, author.Username, created.Author)
+	require.Equal(t, []string{"flour", "milk", "eggs"}, created.Ingredients)
+	id := strconv.FormatInt(created.ID, 10)
+
+	tc.edit = func(path string) error {
+		return os.WriteFile(path, []byte("## Ingredients\n- rice\n## Steps\n- boil\n"), 0o600)
+	}
+	code, _ = tc.run("create")
+	require.Equal(t, 0, code)
+
+	t.Run("List", func(t *testing.T) {
+		code, out := tc.run("list")
+		require.Equal(t, 0, code)
+		require.Contains(t, out, "INGREDIENTS")
+		require.Contains(t, out, "flour, milk, eggs")
+		require.Contains(t, out, "rice")
+
+		code, out = tc.run("list", "-output", "json")
+		require.Equal(t, 0, code)
+		var recipes []client.Recipe
+		require.NoError(t, json.Unmarshal([]byte(out), &recipes))
+		require.Len(t, recipes, 2)
+	})
+
+	t.Run("Get", func(t *testing.T) {
+		code, out := tc.run("get", id)
+		require.Equal(t, 0, code)
+		require.Contains(t, out, "- flour")
+		require.Contains(t, out, "2. cook in a pan")
+
+		code, _ = tc.run("get", "0")
+		require.Equal(t, 1, code)
+	})
+
+	t.Run("Search", func(t *testing.T) {
+		code, out := tc.run("search", "-output", "json", "flour")
+		require.Equal(t, 0, code)
+		var recipes []client.Recipe
+		require.NoError(t, json.Unmarshal([]byte(out), &recipes))
+		require.Len(t, recipes, 1)
+		require.Equal(t, created.ID, recipes[0].ID)
+	})
+
+	t.Run("Edit", func(t *testing.T) {
+		tc.edit = func(path string) error {
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			return os.WriteFile(path, []byte(strings.Replace(string(content), "- milk", "- water", 1)), 0o600)
+		}
+		code, _ := tc.run("edit", id)
+		require.Equal(t, 0, code)
+
+		recipe, err := c.GetRecipe(ctx, created.ID)
+		require.NoError(t, err)
+		require.Equal(t, []string{"flour", "water", "eggs"}, recipe.Ingredients)
+	})
+
+	t.Run("Export", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "recipes.yaml")
+		code, _ := tc.run("export", "-format", "yaml", "-o", path)
+		require.Equal(t, 0, code)
+		content, err := os.ReadFile(path)
+		require.NoError(t, err)
+		require.Contains(t, string(content), "- water")
+		require.Contains(t, string(content), "- rice")
+
+		code, out := tc.run("export", "-format", "markdown")
+		require.Equal(t, 0, code)
+		require.Equal(t, 2, strings.Count(out, "## Ingredients"))
+	})
+
+	t.Run("Delete and logout", func(t *testing.T) {
+		code, out := tc.run("delete", id)
+		require.Equal(t, 0, code)
+		require.Contains(t, out, "deleted recipe "+id)
+
+		_, err := c.GetRecipe(ctx, created.ID)
+		require.True(t, client.IsNotFound(err))
+
+		code, _ = tc.run("logout")
+		require.Equal(t, 0, code)
+		code, _ = tc.run("list")
+		require.Equal(t, 1, code)
+	})
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/gmaschi/go-recipes-book/pkg/client"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+
+	// maxCellWidth is the width past which the table truncates the ingredients column
+	maxCellWidth = 48
+)
+
+// printRecipes writes recipes as a table or a JSON array
+func printRecipes(w io.Writer, format string, recipes []client.Recipe) error {
+	if format == outputJSON {
+		if recipes == nil {
+			recipes = []client.Recipe{}
+		}
+		return printJSON(w, recipes)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tAUTHOR\tINGREDIENTS\tSTEPS\tUPDATED")
+	for _, recipe := range recipes {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%d\t%s\n",
+			recipe.ID,
+			recipe.Author,
+			truncate(strings.Join(recipe.Ingredients, ", "), maxCellWidth),
+			len(recipe.Steps),
+			formatTime(updatedAt(recipe)),
+		)
+	}
+	return tw.Flush()
+}
+
+// printRecipe writes a single recipe as Markdown or as a JSON object
+func printRecipe(w io.Writer, format string, recipe client.Recipe) error {
+	if format == outputJSON {
+		return printJSON(w, recipe)
+	}
+
+	fmt.Fprint(w, formatMarkdown(recipe))
+	fmt.Fprintf(w, "\nAuthor:  %s\nCreated: %s\nUpdated: %s\n",
+		recipe.Author, formatTime(recipe.CreatedAt), formatTime(updatedAt(recipe)))
+	return nil
+}
+
+func printJSON(w io.Writer, v interface{}) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+func updatedAt(recipe client.Recipe) time.Time {
+	if recipe.UpdatedAt.IsZero() {
+		return recipe.CreatedAt
+	}
+	return recipe.UpdatedAt
+}
+
+func formatTime(t time.Time) string {
+	return t.Local().Format("2006-01-02 15:04")
+}
+
+func truncate(s string, width int) string {
+	runes := []rune(s)
+	if len(runes) <= width {
+		return s
+	}
+	return string(runes[:width-3]) + "..."
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/gmaschi/go-recipes-book/pkg/client"
+	"gopkg.in/yaml.v3"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// recipeFile is the editable part of a recipe
+type recipeFile struct {
+	Ingredients []string `yaml:"ingredients"`
+	Steps       []string `yaml:"steps"`
+}
+
+// markdownTemplate is opened in the editor to write a new recipe
+const markdownTemplate = `# Recipe
+
+## Ingredients
+
+- 
+
+## Steps
+
+1. 
+`
+
+var (
+	markdownHeading  = regexp.MustCompile(`^#{1,6}\s+(.*)$`)
+	markdownListItem = regexp.MustCompile(`^(?:[-*+]|\d+[.)])\s+(.*)$`)
+)
+
+// parseRecipeFile decodes a recipe written in YAML or Markdown, as told by the extension
+// of name
+func parseRecipeFile(name string, content []byte) (recipeFile, error) {
+	var recipe recipeFile
+	var err error
+
+	switch ext := strings.ToLower(filepath.Ext(name)); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, &recipe)
+	case ".md", ".markdown":
+		recipe, err = parseMarkdown(string(content))
+	default:
+		return recipeFile{}, fmt.Errorf("unsupported recipe file extension %q, use .yaml or .md", ext)
+	}
+	if err != nil {
+		return recipeFile{}, fmt.Errorf("%s: %w", name, err)
+	}
+
+	recipe.Ingredients = compact(recipe.Ingredients)
+	recipe.Steps = compact(recipe.Steps)
+	if len(recipe.Ingredients) == 0 || len(recipe.Steps) == 0 {
+		return recipeFile{}, fmt.Errorf("%s: a recipe needs at least one ingredient and one step", name)
+	}
+	return recipe, nil
+}
+
+// parseMarkdown reads the list items under the Ingredients and Steps headings. Other
+// headings and text are ignored.
+func parseMarkdown(content string) (recipeFile, error) {
+	var recipe recipeFile
+	var section *[]string
+
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		if m := markdownHeading.FindStringSubmatch(line); m != nil {
+			switch strings.ToLower(strings.TrimSpace(m[1])) {
+			case "ingredients":
+				section = &recipe.Ingredients
+			case "steps":
+				section = &recipe.Steps
+			default:
+				section = nil
+			}
+			continue
+		}
+
+		if section == nil {
+			continue
+		}
+		if m := markdownListItem.FindStringSubmatch(line); m != nil {
+			*section = append(*section, strings.TrimSpace(m[1]))
+		}
+	}
+	return recipe, scanner.Err()
+}
+
+// formatMarkdown writes a recipe in the Markdown format read by parseMarkdown
+func formatMarkdown(recipe client.Recipe) string {
+	var b strings.Builder
+	if recipe.ID != 0 {
+		fmt.Fprintf(&b, "# Recipe %d\n\n", recipe.ID)
+	} else {
+		b.WriteString("# Recipe\n\n")
+	}
+
+	b.WriteString("## Ingredients\n\n")
+	for _, ingredient := range recipe.Ingredients {
+		fmt.Fprintf(&b, "- %s\n", ingredient)
+	}
+
+	b.WriteString("\n## Steps\n\n")
+	for i, step := range recipe.Steps {
+		fmt.Fprintf(&b, "%d. %s\n", i+1, step)
+	}
+	return b.String()
+}
+
+func compact(items []string) []string {
+	out := items[:0]
+	for _, item := range items {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
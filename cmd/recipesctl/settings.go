@@ -0,0 +1,103 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gmaschi/go-recipes-book/pkg/client"
+	"os"
+	"path/filepath"
+)
+
+const (
+	// settingsVar is the environment variable that points to the settings file
+	settingsVar = "RECIPESCTL_CONFIG"
+
+	// serverVar is the environment variable that sets the API base URL
+	serverVar = "RECIPESCTL_SERVER"
+
+	defaultServer = "http://localhost:8080"
+)
+
+// settings is the content of the recipesctl config file, written by login
+type settings struct {
+	Server      string `json:"server"`
+	Username    string `json:"username,omitempty"`
+	AccessToken string `json:"access_token,omitempty"`
+}
+
+// settingsFile keeps the settings in a JSON file readable by its owner only and implements
+// client.TokenStore on top of it
+type settingsFile struct {
+	path string
+}
+
+var _ client.TokenStore = settingsFile{}
+
+// defaultSettingsPath returns $RECIPESCTL_CONFIG or recipesctl/config.json in the user
+// config directory
+func defaultSettingsPath(getenv func(string) string) string {
+	if path := getenv(settingsVar); path != "" {
+		return path
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "recipesctl.json"
+	}
+	return filepath.Join(dir, "recipesctl", "config.json")
+}
+
+func (f settingsFile) read() (settings, error) {
+	content, err := os.ReadFile(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return settings{}, nil
+	}
+	if err != nil {
+		return settings{}, fmt.Errorf("cannot read config file: %w", err)
+	}
+
+	var s settings
+	if err := json.Unmarshal(content, &s); err != nil {
+		return settings{}, fmt.Errorf("%s: %w", f.path, err)
+	}
+	return s, nil
+}
+
+func (f settingsFile) write(s settings) error {
+	content, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(f.path), 0o700); err != nil {
+		return fmt.Errorf("cannot create config directory: %w", err)
+	}
+	if err := os.WriteFile(f.path, append(content, '\n'), 0o600); err != nil {
+		return fmt.Errorf("cannot write config file: %w", err)
+	}
+	return nil
+}
+
+func (f settingsFile) Load() (client.Token, error) {
+	s, err := f.read()
+	if err != nil {
+		return client.Token{}, err
+	}
+	if s.AccessToken == "" {
+		return client.Token{}, client.ErrNoToken
+	}
+	return client.Token{AccessToken: s.AccessToken, Username: s.Username}, nil
+}
+
+func (f settingsFile) Save(token client.Token) error {
+	s, err := f.read()
+	if err != nil {
+		return err
+	}
+	s.AccessToken = token.AccessToken
+	s.Username = token.Username
+	return f.write(s)
+}
+
+func (f settingsFile) Clear() error {
+	return f.Save(client.Token{})
+}
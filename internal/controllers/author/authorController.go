@@ -1,32 +1,50 @@
 package authorController
 
 import (
-	"database/sql"
-	"errors"
 	"github.com/gin-gonic/gin"
 	authMiddleware "github.com/gmaschi/go-recipes-book/internal/controllers/middlewares/auth"
+	errorMiddleware "github.com/gmaschi/go-recipes-book/internal/controllers/middlewares/errors"
+	versionMiddleware "github.com/gmaschi/go-recipes-book/internal/controllers/middlewares/version"
+	authorDomain "github.com/gmaschi/go-recipes-book/internal/domain/author"
 	authorModel "github.com/gmaschi/go-recipes-book/internal/models/author"
 	db "github.com/gmaschi/go-recipes-book/internal/services/datastore/postgresql/recipes/sqlc"
 	"github.com/gmaschi/go-recipes-book/pkg/auth/tokenAuth"
-	pasetoToken "github.com/gmaschi/go-recipes-book/pkg/auth/tokenAuth/paseto"
-	"github.com/gmaschi/go-recipes-book/pkg/config/env"
-	"github.com/gmaschi/go-recipes-book/pkg/tools/parseErrors"
-	"github.com/gmaschi/go-recipes-book/pkg/tools/password"
-	"github.com/gmaschi/go-recipes-book/pkg/tools/validators"
-	"github.com/lib/pq"
+	"github.com/gmaschi/go-recipes-book/pkg/tools/etag"
+	"github.com/gmaschi/go-recipes-book/pkg/tools/fieldset"
+	"github.com/gmaschi/go-recipes-book/pkg/tools/filter"
+	"github.com/gmaschi/go-recipes-book/pkg/tools/pagination"
 	"net/http"
-	"strings"
-	"time"
 )
 
+// The filters of the authors listed by page
+const (
+	createdAfterKey  = "created_after"
+	createdBeforeKey = "created_before"
+)
+
+// filterKeys are the sort and filter parameters, which cursors do not support
+var filterKeys = []string{filter.SortKey, createdAfterKey, createdBeforeKey}
+
+// relations are the relations an author can embed. Recipes are private to their author, so
+// the public author route has none.
+var relations map[string]fieldset.Relation[db.Author]
+
+// authorCursor is the sort key of the authors listed with a cursor
+type authorCursor struct {
+	Username string `json:"u"`
+}
+
 type Controller struct {
-	store db.Store
+	store   db.Store
+	authors *authorDomain.Service
 }
 
-// New creates a pointer to a Controller
-func New(store db.Store) *Controller {
+// New creates a pointer to a Controller applying the rules of authors, and reading and
+// listing the authors of store
+func New(store db.Store, authors *authorDomain.Service) *Controller {
 	return &Controller{
-		store: store,
+		store:   store,
+		authors: authors,
 	}
 }
 
@@ -35,32 +53,13 @@ func (c *Controller) Create(ctx *gin.Context) {
 	var req authorModel.CreateRequest
 
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, parseErrors.ErrorResponse(err))
+		errorMiddleware.AbortBind(ctx, err)
 		return
 	}
 
-	hashedPassword, err := password.HashPassword(req.Password)
+	author, err := c.authors.Create(ctx.Request.Context(), req)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, parseErrors.ErrorResponse(err))
-		return
-	}
-
-	createArgs := db.CreateAuthorParams{
-		Username:       req.Username,
-		HashedPassword: hashedPassword,
-		Email:          req.Email,
-	}
-
-	author, err := c.store.CreateAuthor(ctx, createArgs)
-	if err != nil {
-		if pqError, ok := err.(*pq.Error); ok {
-			switch pqError.Code.Name() {
-			case "unique_violation":
-				ctx.JSON(http.StatusForbidden, parseErrors.ErrorResponse(pqError))
-				return
-			}
-		}
-		ctx.JSON(http.StatusInternalServerError, parseErrors.ErrorResponse(err))
+		errorMiddleware.Abort(ctx, err)
 		return
 	}
 
@@ -68,105 +67,73 @@ func (c *Controller) Create(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, authorResponse)
 }
 
-// Author handles the request to get an author based on the username
+// Author handles the request to get an author based on the username, restricted to the
+// fields selected by the fields parameter. The full response has the ETag of the author
+// version and the others a weak ETag of their content, and neither is sent again to clients
+// that have it in If-None-Match.
 func (c *Controller) Author(ctx *gin.Context) {
 	var req authorModel.GetRequest
 
 	if err := ctx.ShouldBindUri(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, parseErrors.ErrorResponse(err))
+		errorMiddleware.AbortBind(ctx, err)
 		return
 	}
 
-	author, err := c.store.GetAuthor(ctx, req.Username)
+	selection, err := fieldset.Parse(ctx.Request.URL.Query(), authorModel.GetResponse{}, relations)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, parseErrors.ErrorResponse(err))
-			return
-		}
-		ctx.JSON(http.StatusInternalServerError, parseErrors.ErrorResponse(err))
+		errorMiddleware.Abort(ctx, err)
 		return
 	}
 
-	res := authorModel.GetResponse{
+	author, err := c.store.GetAuthor(ctx.Request.Context(), req.Username)
+	if err != nil {
+		errorMiddleware.Abort(ctx, err)
+		return
+	}
+
+	var res interface{} = authorModel.GetResponse{
 		Username:  author.Username,
 		Email:     author.Email,
 		CreatedAt: author.CreatedAt,
 		UpdatedAt: author.UpdatedAt,
+		Version:   author.Version,
+	}
+	tag := etag.Version(author.Version)
+	if selection.Partial() {
+		if res, err = selection.Apply(res, nil); err != nil {
+			errorMiddleware.Abort(ctx, err)
+			return
+		}
+		if tag, err = etag.Weak(res); err != nil {
+			errorMiddleware.Abort(ctx, err)
+			return
+		}
+	}
+
+	ctx.Header(etag.Header, tag)
+	if etag.WeakMatch(ctx.GetHeader(etag.IfNoneMatchHeader), tag) {
+		ctx.Status(http.StatusNotModified)
+		return
 	}
 
 	ctx.JSON(http.StatusOK, res)
 }
 
-// Update handles the request to update an author email and/or password
+// Update handles the request to update an author email and/or password. The request
+// carries the version of the author it was made against, and conflicts with any later one.
 func (c *Controller) Update(ctx *gin.Context) {
 	var req authorModel.UpdateRequest
 
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, parseErrors.ErrorResponse(err))
+		errorMiddleware.AbortBind(ctx, err)
 		return
 	}
 
 	authPayload := ctx.MustGet(authMiddleware.AuthorizationPayloadKey).(*tokenAuth.Payload)
 
-	if authPayload.Username != req.Username {
-		err := errors.New("user to update is not logged user")
-		ctx.JSON(http.StatusUnauthorized, parseErrors.ErrorResponse(err))
-		return
-	}
-
-	author, err := c.store.GetAuthor(ctx, req.Username)
+	updatedAuthor, err := c.authors.Update(ctx.Request.Context(), authPayload.Username, req)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, parseErrors.ErrorResponse(err))
-			return
-		}
-		ctx.JSON(http.StatusInternalServerError, parseErrors.ErrorResponse(err))
-		return
-	}
-
-	updateArgs := db.UpdateAuthorParams{
-		Username:       authPayload.Username,
-		Email:          author.Email,
-		HashedPassword: author.HashedPassword,
-		UpdatedAt:      author.UpdatedAt,
-	}
-
-	now := time.Now().UTC()
-	trimmedEmail := strings.Trim(req.Email, " ")
-	trimmedPassword := strings.Trim(req.Password, " ")
-
-	if trimmedEmail != "" {
-		if !validators.Email(trimmedEmail) {
-			ctx.JSON(http.StatusBadRequest, map[string]interface{}{"error:": "Invalid email"})
-			return
-		}
-		updateArgs.Email = trimmedEmail
-		updateArgs.UpdatedAt = now
-	}
-	if trimmedPassword != "" {
-		if !validators.Password(trimmedPassword) {
-			ctx.JSON(http.StatusBadRequest, map[string]interface{}{"error:": "Invalid password"})
-			return
-		}
-		hashedPassword, err := password.HashPassword(trimmedPassword)
-		if err != nil {
-			ctx.JSON(http.StatusInternalServerError, parseErrors.ErrorResponse(err))
-			return
-		}
-		updateArgs.HashedPassword = hashedPassword
-		updateArgs.UpdatedAt = now
-	}
-
-	updatedAuthor, err := c.store.UpdateAuthor(ctx, updateArgs)
-	if err != nil {
-		if pqError, ok := err.(*pq.Error); ok {
-			switch pqError.Code.Name() {
-			case "unique_violation":
-				ctx.JSON(http.StatusForbidden, parseErrors.ErrorResponse(pqError))
-				return
-			}
-		}
-		ctx.JSON(http.StatusInternalServerError, parseErrors.ErrorResponse(err))
+		errorMiddleware.Abort(ctx, err)
 		return
 	}
 
@@ -180,101 +147,157 @@ func (c *Controller) Delete(ctx *gin.Context) {
 	var req authorModel.DeleteRequest
 
 	if err := ctx.ShouldBindUri(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, parseErrors.ErrorResponse(err))
+		errorMiddleware.AbortBind(ctx, err)
 		return
 	}
 
 	authPayload := ctx.MustGet(authMiddleware.AuthorizationPayloadKey).(*tokenAuth.Payload)
 
-	if authPayload.Username != req.Username {
-		err := errors.New("account to delete does not belong to authenticated user")
-		ctx.JSON(http.StatusUnauthorized, parseErrors.ErrorResponse(err))
-		return
-	}
-
-	err := c.store.DeleteAuthor(ctx, req.Username)
-	if err != nil {
-		if pqError, ok := err.(*pq.Error); ok {
-			switch pqError.Code.Name() {
-			case "foreign_key_violation":
-				ctx.JSON(http.StatusForbidden, parseErrors.ErrorResponse(pqError))
-				return
-			}
-		}
-		ctx.JSON(http.StatusInternalServerError, parseErrors.ErrorResponse(err))
+	if err := c.authors.Delete(ctx.Request.Context(), authPayload.Username, req.Username); err != nil {
+		errorMiddleware.Abort(ctx, err)
 		return
 	}
 
 	ctx.JSON(http.StatusOK, "ok")
 }
 
-// List handles the request to list the authors with pagination
+// List handles the request to list the authors with pagination, by page with page_id and
+// page_size or after a cursor with cursor and limit. Pages can be sorted and filtered, and are
+// sent in an envelope with the total count from version 2 of the API.
 func (c *Controller) List(ctx *gin.Context) {
+	if pagination.IsCursorQuery(ctx.Request.URL.Query()) {
+		c.listAfter(ctx)
+		return
+	}
+
 	var req authorModel.ListRequest
 	if err := ctx.ShouldBindQuery(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, parseErrors.ErrorResponse(err))
+		errorMiddleware.AbortBind(ctx, err)
 		return
 	}
 
-	listArgs := db.ListAuthorsParams{
-		Limit:  req.PageSize,
-		Offset: req.PageSize * (req.PageID - 1),
+	p := filter.New(ctx.Request.URL.Query())
+	listArgs := db.SearchAuthorsParams{
+		CreatedAfter:  p.Time(createdAfterKey),
+		CreatedBefore: p.Time(createdBeforeKey),
+		Sort:          db.Sort(p.Sort(filter.Sort{Field: "username"}, db.AuthorSortFields...)),
+		Limit:         req.PageSize,
+		Offset:        req.PageSize * (req.PageID - 1),
+	}
+	if err := p.Err(); err != nil {
+		errorMiddleware.Abort(ctx, err)
+		return
 	}
 
-	authors, err := c.store.ListAuthors(ctx, listArgs)
+	authors, err := c.store.SearchAuthors(ctx.Request.Context(), listArgs)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, parseErrors.ErrorResponse(err))
-			return
-		}
-		ctx.JSON(http.StatusInternalServerError, parseErrors.ErrorResponse(err))
+		errorMiddleware.Abort(ctx, err)
 		return
 	}
 
 	k := len(authors)
-	res := make([]authorModel.ListResponse, 0, k)
+	items := make([]authorModel.ListResponse, 0, k)
 	for _, author := range authors {
-		res = append(res, authorModel.ListResponse(author))
+		items = append(items, authorModel.ListResponse(author))
 	}
 
+	if versionMiddleware.Version(ctx) < versionMiddleware.V2 {
+		setLinks(ctx, pagination.PageLinks(ctx.Request.URL.Query(), req.PageID, int32(k) == req.PageSize))
+		ctx.JSON(http.StatusOK, items)
+		return
+	}
+
+	total, err := c.store.CountSearchAuthors(ctx.Request.Context(), listArgs)
+	if err != nil {
+		errorMiddleware.Abort(ctx, err)
+		return
+	}
+
+	res := authorModel.ListEnvelopeResponse{
+		Items:    items,
+		Page:     req.PageID,
+		PageSize: req.PageSize,
+		Total:    total,
+		HasMore:  int64(listArgs.Offset)+int64(k) < total,
+	}
+	setLinks(ctx, pagination.PageLinks(ctx.Request.URL.Query(), req.PageID, res.HasMore))
 	ctx.JSON(http.StatusOK, res)
 }
 
-func (c *Controller) Login(ctx *gin.Context) {
-	var req authorModel.LoginRequest
+// listAfter lists the authors ordered by username, resuming after the author encoded in the
+// cursor
+func (c *Controller) listAfter(ctx *gin.Context) {
+	var req authorModel.ListCursorRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		errorMiddleware.AbortBind(ctx, err)
+		return
+	}
 
-	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, parseErrors.ErrorResponse(err))
+	p := filter.New(ctx.Request.URL.Query())
+	p.Reject("not supported with a cursor, which lists by username", filterKeys...)
+	if err := p.Err(); err != nil {
+		errorMiddleware.Abort(ctx, err)
 		return
 	}
 
-	author, err := c.store.GetAuthor(ctx, req.Username)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, parseErrors.ErrorResponse(err))
+	var after authorCursor
+	if req.Cursor != "" {
+		if err := pagination.DecodeCursor(req.Cursor, &after); err != nil {
+			errorMiddleware.Abort(ctx, err)
 			return
 		}
-		ctx.JSON(http.StatusInternalServerError, parseErrors.ErrorResponse(err))
-		return
 	}
 
-	err = password.CheckPassword(req.Password, author.HashedPassword)
+	// one more row than requested tells whether there is a next page
+	authors, err := c.store.ListAuthorsAfter(ctx.Request.Context(), db.ListAuthorsAfterParams{
+		Username: after.Username,
+		Limit:    req.Limit + 1,
+	})
 	if err != nil {
-		ctx.JSON(http.StatusUnauthorized, parseErrors.ErrorResponse(err))
+		errorMiddleware.Abort(ctx, err)
 		return
 	}
 
-	config, err := env.NewConfig()
+	var res authorModel.ListPageResponse
+	if len(authors) > int(req.Limit) {
+		authors = authors[:req.Limit]
+		res.NextCursor, err = pagination.EncodeCursor(authorCursor{Username: authors[len(authors)-1].Username})
+		if err != nil {
+			errorMiddleware.Abort(ctx, err)
+			return
+		}
+	}
 
-	maker, err := pasetoToken.NewPasetoMaker(config.TokenSymmetricKey)
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, parseErrors.ErrorResponse(err))
+	res.Items = make([]authorModel.ListResponse, 0, len(authors))
+	for _, author := range authors {
+		res.Items = append(res.Items, authorModel.ListResponse(author))
+	}
+
+	if req.Total {
+		total, err := c.store.CountAuthors(ctx.Request.Context())
+		if err != nil {
+			errorMiddleware.Abort(ctx, err)
+			return
+		}
+		res.Total = &total
+	}
+
+	setLinks(ctx, pagination.CursorLinks(ctx.Request.URL.Query(), res.NextCursor))
+	ctx.JSON(http.StatusOK, res)
+}
+
+// Login handles the request to authenticate an author and issue an access token
+func (c *Controller) Login(ctx *gin.Context) {
+	var req authorModel.LoginRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		errorMiddleware.AbortBind(ctx, err)
 		return
 	}
 
-	token, err := maker.CreateToken(author.Username, time.Duration(config.TokenDuration) * time.Minute)
+	author, token, err := c.authors.Login(ctx.Request.Context(), req)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, parseErrors.ErrorResponse(err))
+		errorMiddleware.Abort(ctx, err)
 		return
 	}
 
@@ -284,7 +307,15 @@ func (c *Controller) Login(ctx *gin.Context) {
 		Email:       author.Email,
 		CreatedAt:   author.CreatedAt,
 		UpdatedAt:   author.UpdatedAt,
+		Version:     author.Version,
 	}
 
 	ctx.JSON(http.StatusOK, res)
 }
+
+// setLinks sets the Link header of the response to links, if any
+func setLinks(ctx *gin.Context, links []pagination.Link) {
+	if len(links) > 0 {
+		ctx.Header("Link", pagination.LinkHeader(ctx.Request.URL, links...))
+	}
+}
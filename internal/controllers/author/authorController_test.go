@@ -12,7 +12,9 @@ import (
 	db "github.com/gmaschi/go-recipes-book/internal/services/datastore/postgresql/recipes/sqlc"
 	"github.com/gmaschi/go-recipes-book/pkg/auth/tokenAuth"
 	"github.com/gmaschi/go-recipes-book/pkg/config/env"
+	"github.com/gmaschi/go-recipes-book/pkg/tools/pagination"
 	"github.com/gmaschi/go-recipes-book/pkg/tools/password"
+	"github.com/gmaschi/go-recipes-book/pkg/tools/problem"
 	"github.com/gmaschi/go-recipes-book/pkg/tools/random"
 	"github.com/golang/mock/gomock"
 	"github.com/lib/pq"
@@ -21,6 +23,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 )
@@ -193,6 +196,7 @@ func TestCreate(t *testing.T) {
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireProblem(t, recorder, problem.CodeConflict)
 			},
 		},
 	}
@@ -202,7 +206,7 @@ func TestCreate(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
 
-			store := mockedstore.NewMockStore(ctrl)
+			store := newMockStore(ctrl)
 			// build stubs
 			tc.buildStubs(store)
 
@@ -295,7 +299,7 @@ func TestAuthor(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
 
-			store := mockedstore.NewMockStore(ctrl)
+			store := newMockStore(ctrl)
 			tc.buildStubs(store)
 
 			config, err := env.NewConfig()
@@ -315,6 +319,121 @@ func TestAuthor(t *testing.T) {
 	}
 }
 
+func TestAuthorFields(t *testing.T) {
+	author, _ := randomAuthor(t)
+
+	testCases := []struct {
+		name          string
+		query         string
+		buildStubs    func(store *mockedstore.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "OK",
+			query: "fields=username,email",
+			buildStubs: func(store *mockedstore.MockStore) {
+				store.EXPECT().GetAuthor(gomock.Any(), gomock.Eq(author.Username)).Times(1).Return(author, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res map[string]string
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Equal(t, map[string]string{"username": author.Username, "email": author.Email}, res)
+			},
+		},
+		{
+			name:  "HiddenField",
+			query: "fields=hashed_password",
+			buildStubs: func(store *mockedstore.MockStore) {
+				store.EXPECT().GetAuthor(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireProblem(t, recorder, problem.CodeInvalidRequest)
+			},
+		},
+		{
+			name:  "NoRelation",
+			query: "include=recipes",
+			buildStubs: func(store *mockedstore.MockStore) {
+				store.EXPECT().GetAuthor(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireProblem(t, recorder, problem.CodeInvalidRequest)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := newMockStore(ctrl)
+			tc.buildStubs(store)
+
+			config, err := env.NewConfig()
+			require.NoError(t, err)
+
+			server, err := bookRecipeFactory.New(config, store)
+			require.NoError(t, err)
+			recorder := httptest.NewRecorder()
+
+			req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/authors/%s?%s", author.Username, tc.query), nil)
+			require.NoError(t, err)
+
+			server.Router.ServeHTTP(recorder, req)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestAuthorNotModified(t *testing.T) {
+	author, _ := randomAuthor(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := newMockStore(ctrl)
+	store.EXPECT().GetAuthor(gomock.Any(), gomock.Eq(author.Username)).Times(4).Return(author, nil)
+
+	config, err := env.NewConfig()
+	require.NoError(t, err)
+	server, err := bookRecipeFactory.New(config, store)
+	require.NoError(t, err)
+
+	get := func(query, ifNoneMatch string) *httptest.ResponseRecorder {
+		req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/authors/%s?%s", author.Username, query), nil)
+		require.NoError(t, err)
+		req.Header.Set("If-None-Match", ifNoneMatch)
+
+		recorder := httptest.NewRecorder()
+		server.Router.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	recorder := get("", "")
+	require.Equal(t, http.StatusOK, recorder.Code)
+	tag := recorder.Header().Get("ETag")
+	require.NotEmpty(t, tag)
+
+	recorder = get("", tag)
+	require.Equal(t, http.StatusNotModified, recorder.Code)
+	require.Empty(t, recorder.Body.Bytes())
+
+	// a sparse fieldset is a different representation, with a weak tag of its own
+	recorder = get("fields=username", tag)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	sparseTag := recorder.Header().Get("ETag")
+	require.True(t, strings.HasPrefix(sparseTag, "W/"))
+
+	recorder = get("fields=username", sparseTag)
+	require.Equal(t, http.StatusNotModified, recorder.Code)
+	require.Equal(t, sparseTag, recorder.Header().Get("ETag"))
+}
+
 func TestUpdate(t *testing.T) {
 	author, _ := randomAuthor(t)
 	updatedEmail := random.Email()
@@ -329,6 +448,7 @@ func TestUpdate(t *testing.T) {
 		Email:          updatedEmail,
 		CreatedAt:      author.CreatedAt,
 		UpdatedAt:      updatedTime,
+		Version:        author.Version + 1,
 	}
 
 	testCases := []struct {
@@ -342,6 +462,7 @@ func TestUpdate(t *testing.T) {
 			name: "OK",
 			body: map[string]interface{}{
 				"username": author.Username,
+				"version":  author.Version,
 				"email":    updatedEmail,
 				"password": updatedPassword,
 			},
@@ -351,6 +472,7 @@ func TestUpdate(t *testing.T) {
 			buildStubs: func(store *mockedstore.MockStore) {
 				updateArgs := db.UpdateAuthorParams{
 					Username:  author.Username,
+					Version:   author.Version,
 					Email:     updatedEmail,
 					UpdatedAt: updatedTime,
 				}
@@ -372,6 +494,7 @@ func TestUpdate(t *testing.T) {
 			name: "NoAuthorization",
 			body: map[string]interface{}{
 				"username": author.Username,
+				"version":  author.Version,
 				"email":    updatedEmail,
 				"password": updatedPassword,
 			},
@@ -379,6 +502,7 @@ func TestUpdate(t *testing.T) {
 			buildStubs: func(store *mockedstore.MockStore) {
 				updateArgs := db.UpdateAuthorParams{
 					Username:  author.Username,
+					Version:   author.Version,
 					Email:     updatedEmail,
 					UpdatedAt: updatedTime,
 				}
@@ -397,6 +521,7 @@ func TestUpdate(t *testing.T) {
 			name: "UnauthorizedUser",
 			body: map[string]interface{}{
 				"username": author.Username,
+				"version":  author.Version,
 				"email":    updatedEmail,
 				"password": updatedPassword,
 			},
@@ -406,6 +531,7 @@ func TestUpdate(t *testing.T) {
 			buildStubs: func(store *mockedstore.MockStore) {
 				updateArgs := db.UpdateAuthorParams{
 					Username:  author.Username,
+					Version:   author.Version,
 					Email:     updatedEmail,
 					UpdatedAt: updatedTime,
 				}
@@ -418,12 +544,14 @@ func TestUpdate(t *testing.T) {
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				requireProblem(t, recorder, problem.CodeAuthorNotAuthenticated)
 			},
 		},
 		{
 			name: "InvalidUsername",
 			body: map[string]interface{}{
 				"username": "invalid-username#",
+				"version":  author.Version,
 				"email":    updatedEmail,
 				"password": updatedPassword,
 			},
@@ -443,6 +571,7 @@ func TestUpdate(t *testing.T) {
 			name: "InvalidUpdatedEmail",
 			body: map[string]interface{}{
 				"username": author.Username,
+				"version":  author.Version,
 				"email":    "invalid-email",
 				"password": updatedPassword,
 			},
@@ -452,6 +581,7 @@ func TestUpdate(t *testing.T) {
 			buildStubs: func(store *mockedstore.MockStore) {
 				updateArgs := db.UpdateAuthorParams{
 					Username:  author.Username,
+					Version:   author.Version,
 					Email:     updatedEmail,
 					UpdatedAt: updatedTime,
 				}
@@ -465,12 +595,16 @@ func TestUpdate(t *testing.T) {
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				p := requireProblem(t, recorder, problem.CodeInvalidRequest)
+				require.Len(t, p.Errors, 1)
+				require.Equal(t, "email", p.Errors[0].Field)
 			},
 		},
 		{
 			name: "InvalidUpdatedPassword",
 			body: map[string]interface{}{
 				"username": author.Username,
+				"version":  author.Version,
 				"email":    updatedEmail,
 				"password": "367",
 			},
@@ -480,6 +614,7 @@ func TestUpdate(t *testing.T) {
 			buildStubs: func(store *mockedstore.MockStore) {
 				updateArgs := db.UpdateAuthorParams{
 					Username:  author.Username,
+					Version:   author.Version,
 					Email:     updatedEmail,
 					UpdatedAt: updatedTime,
 				}
@@ -499,6 +634,7 @@ func TestUpdate(t *testing.T) {
 			name: "NotFound",
 			body: map[string]interface{}{
 				"username": author.Username,
+				"version":  author.Version,
 				"email":    updatedEmail,
 				"password": updatedPassword,
 			},
@@ -508,6 +644,7 @@ func TestUpdate(t *testing.T) {
 			buildStubs: func(store *mockedstore.MockStore) {
 				updateArgs := db.UpdateAuthorParams{
 					Username:  author.Username,
+					Version:   author.Version,
 					Email:     updatedEmail,
 					UpdatedAt: updatedTime,
 				}
@@ -527,6 +664,7 @@ func TestUpdate(t *testing.T) {
 			name: "GetAuthorInternalError",
 			body: map[string]interface{}{
 				"username": author.Username,
+				"version":  author.Version,
 				"email":    updatedEmail,
 				"password": updatedPassword,
 			},
@@ -536,6 +674,7 @@ func TestUpdate(t *testing.T) {
 			buildStubs: func(store *mockedstore.MockStore) {
 				updateArgs := db.UpdateAuthorParams{
 					Username:  author.Username,
+					Version:   author.Version,
 					Email:     updatedEmail,
 					UpdatedAt: updatedTime,
 				}
@@ -555,6 +694,7 @@ func TestUpdate(t *testing.T) {
 			name: "UpdateAuthorInternalError",
 			body: map[string]interface{}{
 				"username": author.Username,
+				"version":  author.Version,
 				"email":    updatedEmail,
 				"password": updatedPassword,
 			},
@@ -564,6 +704,7 @@ func TestUpdate(t *testing.T) {
 			buildStubs: func(store *mockedstore.MockStore) {
 				updateArgs := db.UpdateAuthorParams{
 					Username:  author.Username,
+					Version:   author.Version,
 					Email:     updatedEmail,
 					UpdatedAt: updatedTime,
 				}
@@ -584,6 +725,7 @@ func TestUpdate(t *testing.T) {
 			name: "UniqueEmailViolation",
 			body: map[string]interface{}{
 				"username": author.Username,
+				"version":  author.Version,
 				"email":    updatedEmail,
 				"password": updatedPassword,
 			},
@@ -593,6 +735,7 @@ func TestUpdate(t *testing.T) {
 			buildStubs: func(store *mockedstore.MockStore) {
 				updateArgs := db.UpdateAuthorParams{
 					Username:  author.Username,
+					Version:   author.Version,
 					Email:     updatedEmail,
 					UpdatedAt: updatedTime,
 				}
@@ -609,6 +752,84 @@ func TestUpdate(t *testing.T) {
 				require.Equal(t, http.StatusForbidden, recorder.Code)
 			},
 		},
+		{
+			name: "MissingVersion",
+			body: map[string]interface{}{
+				"username": author.Username,
+				"email":    updatedEmail,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker tokenAuth.Maker) {
+				addAuthorization(t, request, tokenMaker, authMiddleware.AuthorizationTypeBearer, author.Username, time.Minute)
+			},
+			buildStubs: func(store *mockedstore.MockStore) {
+				store.EXPECT().
+					GetAuthor(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					UpdateAuthor(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				p := requireProblem(t, recorder, problem.CodeInvalidRequest)
+				require.Equal(t, "version", p.Errors[0].Field)
+			},
+		},
+		{
+			name: "StaleVersion",
+			body: map[string]interface{}{
+				"username": author.Username,
+				"version":  author.Version,
+				"email":    updatedEmail,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker tokenAuth.Maker) {
+				addAuthorization(t, request, tokenMaker, authMiddleware.AuthorizationTypeBearer, author.Username, time.Minute)
+			},
+			buildStubs: func(store *mockedstore.MockStore) {
+				store.EXPECT().
+					GetAuthor(gomock.Any(), gomock.Eq(author.Username)).
+					Times(1).
+					Return(updatedAuthor, nil)
+				store.EXPECT().
+					UpdateAuthor(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+				requireConflict(t, recorder, updatedAuthor)
+			},
+		},
+		{
+			name: "ConcurrentUpdate",
+			body: map[string]interface{}{
+				"username": author.Username,
+				"version":  author.Version,
+				"email":    updatedEmail,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker tokenAuth.Maker) {
+				addAuthorization(t, request, tokenMaker, authMiddleware.AuthorizationTypeBearer, author.Username, time.Minute)
+			},
+			buildStubs: func(store *mockedstore.MockStore) {
+				gomock.InOrder(
+					store.EXPECT().
+						GetAuthor(gomock.Any(), gomock.Eq(author.Username)).
+						Times(1).
+						Return(author, nil),
+					store.EXPECT().
+						UpdateAuthor(gomock.Any(), gomock.Any()).
+						Times(1).
+						Return(db.Author{}, sql.ErrNoRows),
+					store.EXPECT().
+						GetAuthor(gomock.Any(), gomock.Eq(author.Username)).
+						Times(1).
+						Return(updatedAuthor, nil),
+				)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+				requireConflict(t, recorder, updatedAuthor)
+			},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -616,7 +837,7 @@ func TestUpdate(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
 
-			store := mockedstore.NewMockStore(ctrl)
+			store := newMockStore(ctrl)
 			tc.buildStubs(store)
 
 			config, err := env.NewConfig()
@@ -748,7 +969,7 @@ func TestDelete(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
 
-			store := mockedstore.NewMockStore(ctrl)
+			store := newMockStore(ctrl)
 			tc.buildStubs(store)
 
 			config, err := env.NewConfig()
@@ -797,12 +1018,13 @@ func TestList(t *testing.T) {
 				pageSize int
 			}{pageID: pageID, pageSize: pageSize},
 			buildStubs: func(store *mockedstore.MockStore) {
-				listArgs := db.ListAuthorsParams{
+				listArgs := db.SearchAuthorsParams{
+					Sort:   db.Sort{Field: "username"},
 					Limit:  int32(pageSize),
 					Offset: int32(pageSize * (pageID - 1)),
 				}
 				store.EXPECT().
-					ListAuthors(gomock.Any(), gomock.Eq(listArgs)).
+					SearchAuthors(gomock.Any(), gomock.Eq(listArgs)).
 					Times(1).
 					Return(authorsSlice, nil)
 			},
@@ -818,12 +1040,13 @@ func TestList(t *testing.T) {
 				pageSize int
 			}{pageID: 0, pageSize: pageSize},
 			buildStubs: func(store *mockedstore.MockStore) {
-				listArgs := db.ListAuthorsParams{
+				listArgs := db.SearchAuthorsParams{
+					Sort:   db.Sort{Field: "username"},
 					Limit:  int32(pageSize),
 					Offset: int32(pageSize * (pageID - 1)),
 				}
 				store.EXPECT().
-					ListAuthors(gomock.Any(), gomock.Eq(listArgs)).
+					SearchAuthors(gomock.Any(), gomock.Eq(listArgs)).
 					Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
@@ -835,14 +1058,15 @@ func TestList(t *testing.T) {
 			paginationData: struct {
 				pageID   int
 				pageSize int
-			}{pageID: pageID, pageSize: 20},
+			}{pageID: pageID, pageSize: 101},
 			buildStubs: func(store *mockedstore.MockStore) {
-				listArgs := db.ListAuthorsParams{
+				listArgs := db.SearchAuthorsParams{
+					Sort:   db.Sort{Field: "username"},
 					Limit:  int32(pageSize),
 					Offset: int32(pageSize * (pageID - 1)),
 				}
 				store.EXPECT().
-					ListAuthors(gomock.Any(), gomock.Eq(listArgs)).
+					SearchAuthors(gomock.Any(), gomock.Eq(listArgs)).
 					Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
@@ -856,12 +1080,13 @@ func TestList(t *testing.T) {
 				pageSize int
 			}{pageID: pageID, pageSize: pageSize},
 			buildStubs: func(store *mockedstore.MockStore) {
-				listArgs := db.ListAuthorsParams{
+				listArgs := db.SearchAuthorsParams{
+					Sort:   db.Sort{Field: "username"},
 					Limit:  int32(pageSize),
 					Offset: int32(pageSize * (pageID - 1)),
 				}
 				store.EXPECT().
-					ListAuthors(gomock.Any(), gomock.Eq(listArgs)).
+					SearchAuthors(gomock.Any(), gomock.Eq(listArgs)).
 					Times(1).
 					Return([]db.Author{}, sql.ErrNoRows)
 			},
@@ -876,12 +1101,13 @@ func TestList(t *testing.T) {
 				pageSize int
 			}{pageID: pageID, pageSize: pageSize},
 			buildStubs: func(store *mockedstore.MockStore) {
-				listArgs := db.ListAuthorsParams{
+				listArgs := db.SearchAuthorsParams{
+					Sort:   db.Sort{Field: "username"},
 					Limit:  int32(pageSize),
 					Offset: int32(pageSize * (pageID - 1)),
 				}
 				store.EXPECT().
-					ListAuthors(gomock.Any(), gomock.Eq(listArgs)).
+					SearchAuthors(gomock.Any(), gomock.Eq(listArgs)).
 					Times(1).
 					Return([]db.Author{}, sql.ErrConnDone)
 			},
@@ -896,7 +1122,7 @@ func TestList(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
 
-			store := mockedstore.NewMockStore(ctrl)
+			store := newMockStore(ctrl)
 			tc.buildStubs(store)
 
 			config, err := env.NewConfig()
@@ -916,6 +1142,443 @@ func TestList(t *testing.T) {
 	}
 }
 
+func TestListCursor(t *testing.T) {
+	authors := make([]db.Author, 0, 4)
+	for i := 0; i < 4; i++ {
+		author, _ := randomAuthor(t)
+		authors = append(authors, author)
+	}
+	cursor, err := pagination.EncodeCursor(map[string]string{"u": authors[2].Username})
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name          string
+		query         string
+		buildStubs    func(store *mockedstore.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "FirstPage",
+			query: "limit=3",
+			buildStubs: func(store *mockedstore.MockStore) {
+				store.EXPECT().
+					ListAuthorsAfter(gomock.Any(), gomock.Eq(db.ListAuthorsAfterParams{Limit: 4})).
+					Times(1).
+					Return(authors, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res authorModel.ListPageResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Len(t, res.Items, 3)
+				require.Equal(t, cursor, res.NextCursor)
+				require.Nil(t, res.Total)
+				require.Contains(t, recorder.Header().Get("Link"), fmt.Sprintf(`</authors?cursor=%s&limit=3>; rel="next"`, cursor))
+			},
+		},
+		{
+			name:  "LastPageWithTotal",
+			query: "limit=3&total=true&cursor=" + cursor,
+			buildStubs: func(store *mockedstore.MockStore) {
+				arg := db.ListAuthorsAfterParams{Username: authors[2].Username, Limit: 4}
+				store.EXPECT().
+					ListAuthorsAfter(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(authors[3:], nil)
+				store.EXPECT().
+					CountAuthors(gomock.Any()).
+					Times(1).
+					Return(int64(len(authors)), nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res authorModel.ListPageResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Len(t, res.Items, 1)
+				require.Equal(t, authors[3].Username, res.Items[0].Username)
+				require.Empty(t, res.NextCursor)
+				require.NotNil(t, res.Total)
+				require.EqualValues(t, len(authors), *res.Total)
+				require.NotContains(t, recorder.Header().Get("Link"), `rel="next"`)
+			},
+		},
+		{
+			name:  "InvalidCursor",
+			query: "limit=3&cursor=" + cursor[1:],
+			buildStubs: func(store *mockedstore.MockStore) {
+				store.EXPECT().ListAuthorsAfter(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireProblem(t, recorder, problem.CodeInvalidRequest)
+			},
+		},
+		{
+			name:  "InternalError",
+			query: "limit=3&total=true",
+			buildStubs: func(store *mockedstore.MockStore) {
+				store.EXPECT().
+					ListAuthorsAfter(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(authors, nil)
+				store.EXPECT().
+					CountAuthors(gomock.Any()).
+					Times(1).
+					Return(int64(0), sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := newMockStore(ctrl)
+			tc.buildStubs(store)
+
+			config, err := env.NewConfig()
+			require.NoError(t, err)
+
+			server, err := bookRecipeFactory.New(config, store)
+			require.NoError(t, err)
+			recorder := httptest.NewRecorder()
+
+			req, err := http.NewRequest(http.MethodGet, "/authors?"+tc.query, nil)
+			require.NoError(t, err)
+
+			server.Router.ServeHTTP(recorder, req)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestListFilters(t *testing.T) {
+	author, _ := randomAuthor(t)
+	createdBefore := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name          string
+		query         string
+		buildStubs    func(store *mockedstore.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "OK",
+			query: "page_id=1&page_size=5&sort=-created_at&created_before=2022-01-01T00:00:00Z",
+			buildStubs: func(store *mockedstore.MockStore) {
+				arg := db.SearchAuthorsParams{
+					CreatedBefore: sql.NullTime{Time: createdBefore, Valid: true},
+					Sort:          db.Sort{Field: "created_at", Desc: true},
+					Limit:         5,
+				}
+				store.EXPECT().
+					SearchAuthors(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return([]db.Author{author}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchListAuthors(t, recorder.Body, []db.Author{author})
+			},
+		},
+		{
+			name:  "InvalidSort",
+			query: "page_id=1&page_size=5&sort=email",
+			buildStubs: func(store *mockedstore.MockStore) {
+				store.EXPECT().SearchAuthors(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireProblem(t, recorder, problem.CodeInvalidRequest)
+			},
+		},
+		{
+			name:  "SortWithCursor",
+			query: "limit=5&sort=username",
+			buildStubs: func(store *mockedstore.MockStore) {
+				store.EXPECT().ListAuthorsAfter(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireProblem(t, recorder, problem.CodeInvalidRequest)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := newMockStore(ctrl)
+			tc.buildStubs(store)
+
+			config, err := env.NewConfig()
+			require.NoError(t, err)
+
+			server, err := bookRecipeFactory.New(config, store)
+			require.NoError(t, err)
+			recorder := httptest.NewRecorder()
+
+			req, err := http.NewRequest(http.MethodGet, "/authors?"+tc.query, nil)
+			require.NoError(t, err)
+
+			server.Router.ServeHTTP(recorder, req)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestListEnvelope(t *testing.T) {
+	authors := make([]db.Author, 0, 5)
+	for i := 0; i < 5; i++ {
+		author, _ := randomAuthor(t)
+		authors = append(authors, author)
+	}
+	searchArgs := db.SearchAuthorsParams{Sort: db.Sort{Field: "username"}, Limit: 5, Offset: 5}
+
+	testCases := []struct {
+		name          string
+		version       string
+		buildStubs    func(store *mockedstore.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:    "OK",
+			version: "2",
+			buildStubs: func(store *mockedstore.MockStore) {
+				store.EXPECT().SearchAuthors(gomock.Any(), gomock.Eq(searchArgs)).Times(1).Return(authors, nil)
+				store.EXPECT().CountSearchAuthors(gomock.Any(), gomock.Eq(searchArgs)).Times(1).Return(int64(12), nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res authorModel.ListEnvelopeResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Len(t, res.Items, 5)
+				require.Equal(t, authors[0].Username, res.Items[0].Username)
+				require.EqualValues(t, 2, res.Page)
+				require.EqualValues(t, 5, res.PageSize)
+				require.EqualValues(t, 12, res.Total)
+				require.True(t, res.HasMore)
+			},
+		},
+		{
+			name:    "LastPage",
+			version: "2",
+			buildStubs: func(store *mockedstore.MockStore) {
+				store.EXPECT().SearchAuthors(gomock.Any(), gomock.Eq(searchArgs)).Times(1).Return(authors, nil)
+				store.EXPECT().CountSearchAuthors(gomock.Any(), gomock.Eq(searchArgs)).Times(1).Return(int64(10), nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.NotContains(t, recorder.Header().Get("Link"), `rel="next"`)
+
+				var res authorModel.ListEnvelopeResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.EqualValues(t, 10, res.Total)
+				require.False(t, res.HasMore)
+			},
+		},
+		{
+			name: "DefaultVersion",
+			buildStubs: func(store *mockedstore.MockStore) {
+				store.EXPECT().SearchAuthors(gomock.Any(), gomock.Eq(searchArgs)).Times(1).Return(authors, nil)
+				store.EXPECT().CountSearchAuthors(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, "1", recorder.Header().Get("API-Version"))
+				requireBodyMatchListAuthors(t, recorder.Body, authors)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := newMockStore(ctrl)
+			tc.buildStubs(store)
+
+			config, err := env.NewConfig()
+			require.NoError(t, err)
+
+			server, err := bookRecipeFactory.New(config, store)
+			require.NoError(t, err)
+			recorder := httptest.NewRecorder()
+
+			req, err := http.NewRequest(http.MethodGet, "/authors?page_id=2&page_size=5", nil)
+			require.NoError(t, err)
+			if tc.version != "" {
+				req.Header.Set("API-Version", tc.version)
+			}
+
+			server.Router.ServeHTTP(recorder, req)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestLogin(t *testing.T) {
+	author, randomPassword := randomAuthor(t)
+
+	testCases := []struct {
+		name          string
+		body          map[string]interface{}
+		buildStubs    func(store *mockedstore.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder, tokenMaker tokenAuth.Maker)
+	}{
+		{
+			name: "OK",
+			body: map[string]interface{}{
+				"username": author.Username,
+				"password": randomPassword,
+			},
+			buildStubs: func(store *mockedstore.MockStore) {
+				store.EXPECT().
+					GetAuthor(gomock.Any(), gomock.Eq(author.Username)).
+					Times(1).
+					Return(author, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, tokenMaker tokenAuth.Maker) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res authorModel.LoginResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &res)
+				require.NoError(t, err)
+				require.Equal(t, author.Username, res.Username)
+				require.Equal(t, author.Email, res.Email)
+
+				payload, err := tokenMaker.VerifyToken(res.AccessToken)
+				require.NoError(t, err)
+				require.Equal(t, author.Username, payload.Username)
+				require.WithinDuration(t, time.Now().Add(time.Minute), payload.ExpiredAt, time.Second)
+			},
+		},
+		{
+			name: "NotFound",
+			body: map[string]interface{}{
+				"username": author.Username,
+				"password": randomPassword,
+			},
+			buildStubs: func(store *mockedstore.MockStore) {
+				store.EXPECT().
+					GetAuthor(gomock.Any(), gomock.Eq(author.Username)).
+					Times(1).
+					Return(db.Author{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, tokenMaker tokenAuth.Maker) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "IncorrectPassword",
+			body: map[string]interface{}{
+				"username": author.Username,
+				"password": "incorrect",
+			},
+			buildStubs: func(store *mockedstore.MockStore) {
+				store.EXPECT().
+					GetAuthor(gomock.Any(), gomock.Eq(author.Username)).
+					Times(1).
+					Return(author, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, tokenMaker tokenAuth.Maker) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "DisabledAuthor",
+			body: map[string]interface{}{
+				"username": author.Username,
+				"password": randomPassword,
+			},
+			buildStubs: func(store *mockedstore.MockStore) {
+				disabled := author
+				disabled.DisabledAt = sql.NullTime{Time: time.Now(), Valid: true}
+				store.EXPECT().
+					GetAuthor(gomock.Any(), gomock.Eq(author.Username)).
+					Times(1).
+					Return(disabled, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, tokenMaker tokenAuth.Maker) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireProblem(t, recorder, problem.CodeAuthorDisabled)
+			},
+		},
+		{
+			name: "InvalidUsername",
+			body: map[string]interface{}{
+				"username": "invalid-username#",
+				"password": randomPassword,
+			},
+			buildStubs: func(store *mockedstore.MockStore) {
+				store.EXPECT().
+					GetAuthor(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, tokenMaker tokenAuth.Maker) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InternalError",
+			body: map[string]interface{}{
+				"username": author.Username,
+				"password": randomPassword,
+			},
+			buildStubs: func(store *mockedstore.MockStore) {
+				store.EXPECT().
+					GetAuthor(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Author{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, tokenMaker tokenAuth.Maker) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := newMockStore(ctrl)
+			tc.buildStubs(store)
+
+			// the config is built in memory so that login does not depend on an env file
+			config := env.Config{
+				Auth: env.AuthConfig{
+					TokenSymmetricKey: random.String(32),
+					TokenDuration:     time.Minute,
+				},
+			}
+
+			server, err := bookRecipeFactory.New(config, store)
+			require.NoError(t, err)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			req, err := http.NewRequest(http.MethodPost, "/authors/login", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			server.Router.ServeHTTP(recorder, req)
+			tc.checkResponse(t, recorder, server.TokenAuth)
+		})
+	}
+}
+
 func randomAuthor(t *testing.T) (db.Author, string) {
 	randomPassword := random.String(8)
 	hashedPassword, err := password.HashPassword(randomPassword)
@@ -929,11 +1592,35 @@ func randomAuthor(t *testing.T) (db.Author, string) {
 		HashedPassword: hashedPassword,
 		CreatedAt:      now,
 		UpdatedAt:      now,
+		Version:        1,
 	}
 
 	return author, randomPassword
 }
 
+func requireProblem(t *testing.T, recorder *httptest.ResponseRecorder, code string) problem.Problem {
+	require.Equal(t, problem.ContentType, recorder.Header().Get("Content-Type"))
+
+	var p problem.Problem
+	err := json.Unmarshal(recorder.Body.Bytes(), &p)
+	require.NoError(t, err)
+	require.Equal(t, code, p.Code)
+	require.Equal(t, recorder.Code, p.Status)
+	return p
+}
+
+// requireConflict requires a version conflict reporting current as the current author
+func requireConflict(t *testing.T, recorder *httptest.ResponseRecorder, current db.Author) {
+	p := requireProblem(t, recorder, problem.CodeVersionConflict)
+	require.Equal(t, map[string]interface{}{
+		"username":   current.Username,
+		"email":      current.Email,
+		"created_at": current.CreatedAt.Format(time.RFC3339Nano),
+		"updated_at": current.UpdatedAt.Format(time.RFC3339Nano),
+		"version":    float64(current.Version),
+	}, p.Current)
+}
+
 func requireBodyMatchCreate(t *testing.T, body *bytes.Buffer, author db.Author) {
 	data, err := ioutil.ReadAll(body)
 	require.NoError(t, err)
@@ -1024,6 +1711,14 @@ func requireBodyMatchListAuthors(t *testing.T, body *bytes.Buffer, authors []db.
 	}
 }
 
+// newMockStore returns a mocked store in which the authors of the tokens are not disabled,
+// as checked by the authentication middleware on every request
+func newMockStore(ctrl *gomock.Controller) *mockedstore.MockStore {
+	store := mockedstore.NewMockStore(ctrl)
+	store.EXPECT().GetAuthorDisabledAt(gomock.Any(), gomock.Any()).AnyTimes().Return(sql.NullTime{}, nil)
+	return store
+}
+
 func addAuthorization(
 	t *testing.T,
 	request *http.Request,
@@ -0,0 +1,183 @@
+package docsController
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/gmaschi/go-recipes-book/pkg/openapi"
+	"html/template"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// SpecPath is the route serving the OpenAPI document
+const SpecPath = "/openapi.json"
+
+// contentSecurityPolicy forbids the docs page any script and any resource from elsewhere
+const contentSecurityPolicy = "default-src 'none'; style-src 'unsafe-inline'"
+
+// pageTemplate renders the OpenAPI document as a static page, so that the documentation
+// does not depend on a script loaded from a third party
+//
+//go:embed docs.html
+var pageTemplate string
+
+// methodOrder sorts the operations of a path
+var methodOrder = map[string]int{"get": 0, "post": 1, "put": 2, "patch": 3, "delete": 4}
+
+type (
+	pageData struct {
+		Info     openapi.Info
+		SpecPath string
+		Tags     []pageTag
+		Schemas  []pageSchema
+	}
+
+	pageTag struct {
+		Name       string
+		Operations []pageOperation
+	}
+
+	pageOperation struct {
+		Method    string
+		Path      string
+		Operation *openapi.Operation
+	}
+
+	pageSchema struct {
+		Name   string
+		Schema *openapi.Schema
+	}
+)
+
+type Controller struct {
+	spec []byte
+	page []byte
+}
+
+// New creates a pointer to a Controller serving doc, which is encoded once
+func New(doc *openapi.Document) (*Controller, error) {
+	if err := doc.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid OpenAPI document: %w", err)
+	}
+	spec, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("cannot encode OpenAPI document: %w", err)
+	}
+
+	page, err := renderPage(doc)
+	if err != nil {
+		return nil, fmt.Errorf("cannot render docs page: %w", err)
+	}
+
+	return &Controller{
+		spec: spec,
+		page: page,
+	}, nil
+}
+
+// Spec handles the request for the OpenAPI document
+func (c *Controller) Spec(ctx *gin.Context) {
+	ctx.Data(http.StatusOK, "application/json", c.spec)
+}
+
+// Docs handles the request for the interactive documentation
+func (c *Controller) Docs(ctx *gin.Context) {
+	ctx.Header("Content-Security-Policy", contentSecurityPolicy)
+	ctx.Data(http.StatusOK, "text/html; charset=utf-8", c.page)
+}
+
+// renderPage renders the operations of doc grouped by their first tag, followed by the
+// schemas of its components
+func renderPage(doc *openapi.Document) ([]byte, error) {
+	tmpl, err := template.New("docs").Funcs(template.FuncMap{
+		"schemaType":   schemaType,
+		"schemaAnchor": schemaAnchor,
+		"contains":     contains,
+	}).Parse(pageTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	data := pageData{Info: doc.Info, SpecPath: SpecPath}
+	tags := make(map[string]*pageTag)
+	for path, item := range doc.Paths {
+		for method, op := range *item {
+			name := "default"
+			if len(op.Tags) > 0 {
+				name = op.Tags[0]
+			}
+			tag, ok := tags[name]
+			if !ok {
+				tag = &pageTag{Name: name}
+				tags[name] = tag
+			}
+			tag.Operations = append(tag.Operations, pageOperation{Method: method, Path: path, Operation: op})
+		}
+	}
+	for _, tag := range tags {
+		sort.Slice(tag.Operations, func(i, j int) bool {
+			a, b := tag.Operations[i], tag.Operations[j]
+			if a.Path != b.Path {
+				return a.Path < b.Path
+			}
+			return methodOrder[a.Method] < methodOrder[b.Method]
+		})
+		data.Tags = append(data.Tags, *tag)
+	}
+	sort.Slice(data.Tags, func(i, j int) bool { return data.Tags[i].Name < data.Tags[j].Name })
+
+	for name, schema := range doc.Components.Schemas {
+		data.Schemas = append(data.Schemas, pageSchema{Name: name, Schema: schema})
+	}
+	sort.Slice(data.Schemas, func(i, j int) bool { return data.Schemas[i].Name < data.Schemas[j].Name })
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// schemaType describes the type of schema, linking the referenced component schemas
+func schemaType(schema *openapi.Schema) template.HTML {
+	switch {
+	case schema == nil:
+		return ""
+	case schema.Ref != "":
+		name := strings.TrimPrefix(schema.Ref, "#/components/schemas/")
+		return template.HTML(fmt.Sprintf(`<a href="#%s"><code>%s</code></a>`,
+			template.HTMLEscapeString(schemaAnchor(name)), template.HTMLEscapeString(name)))
+	case len(schema.OneOf) > 0:
+		types := make([]string, len(schema.OneOf))
+		for i, one := range schema.OneOf {
+			types[i] = string(schemaType(one))
+		}
+		return template.HTML("one of " + strings.Join(types, ", "))
+	case schema.Type == "array":
+		return "array of " + schemaType(schema.Items)
+	case schema.Type == "object" && schema.AdditionalProperties != nil:
+		return "map of " + schemaType(schema.AdditionalProperties)
+	case schema.Format != "":
+		return template.HTML(template.HTMLEscapeString(schema.Type + " (" + schema.Format + ")"))
+	default:
+		return template.HTML(template.HTMLEscapeString(schema.Type))
+	}
+}
+
+// schemaAnchor returns the id of the section of the component schema with the given name
+func schemaAnchor(name string) string {
+	return "schema-" + name
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,84 @@
+package graphqlController
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	authMiddleware "github.com/gmaschi/go-recipes-book/internal/controllers/middlewares/auth"
+	errorMiddleware "github.com/gmaschi/go-recipes-book/internal/controllers/middlewares/errors"
+	loggingMiddleware "github.com/gmaschi/go-recipes-book/internal/controllers/middlewares/logging"
+	authorDomain "github.com/gmaschi/go-recipes-book/internal/domain/author"
+	recipeDomain "github.com/gmaschi/go-recipes-book/internal/domain/recipe"
+	graphqlResolver "github.com/gmaschi/go-recipes-book/internal/graphql"
+	graphqlModel "github.com/gmaschi/go-recipes-book/internal/models/graphql"
+	db "github.com/gmaschi/go-recipes-book/internal/services/datastore/postgresql/recipes/sqlc"
+	"github.com/gmaschi/go-recipes-book/pkg/auth/tokenAuth"
+	"github.com/gmaschi/go-recipes-book/pkg/tools/problem"
+	"github.com/graph-gophers/graphql-go"
+	"net/http"
+)
+
+type (
+	Controller struct {
+		store     db.Store
+		schema    *graphql.Schema
+		rateLimit RateLimit
+	}
+
+	// RateLimit charges a request to the rate limit of a route, keyed by
+	// ratelimit.RouteKey, returning the problem to report when the client has exceeded it
+	RateLimit func(ctx *gin.Context, route string) error
+)
+
+// New creates a pointer to a Controller executing the GraphQL API on store, with the rules of
+// authors and recipes. The mutations are charged with rateLimit to the REST routes they
+// mirror.
+func New(store db.Store, authors *authorDomain.Service, recipes *recipeDomain.Service, rateLimit RateLimit) (*Controller, error) {
+	schema, err := graphqlResolver.NewSchema(store, authors, recipes)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Controller{
+		store:     store,
+		schema:    schema,
+		rateLimit: rateLimit,
+	}, nil
+}
+
+// Query handles a GraphQL query or mutation. Requests may be anonymous, in which case only
+// the public fields resolve. Errors of the resolvers are reported in the response with a
+// 200 status, as GraphQL clients expect.
+func (c *Controller) Query(ctx *gin.Context) {
+	var req graphqlModel.Request
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		errorMiddleware.AbortBind(ctx, err)
+		return
+	}
+
+	var payload *tokenAuth.Payload
+	if value, ok := ctx.Get(authMiddleware.AuthorizationPayloadKey); ok {
+		payload = value.(*tokenAuth.Payload)
+	}
+
+	rateLimit := func(route string) error {
+		return c.rateLimit(ctx, route)
+	}
+	reqCtx := graphqlResolver.WithRequest(ctx.Request.Context(), c.store, payload, rateLimit)
+	result := c.schema.Exec(reqCtx, req.Query, req.OperationName, req.Variables)
+
+	res := graphqlModel.Response{Data: result.Data}
+	for _, queryErr := range result.Errors {
+		var p *problem.Error
+		if errors.As(queryErr.ResolverError, &p) && p.Status >= http.StatusInternalServerError {
+			loggingMiddleware.Logger(ctx).Error("graphql resolver failed", "path", queryErr.Path, "error", p.Err)
+		}
+		res.Errors = append(res.Errors, graphqlModel.Error{
+			Message:    queryErr.Message,
+			Path:       queryErr.Path,
+			Extensions: queryErr.Extensions,
+		})
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,254 @@
+package graphqlController_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	authMiddleware "github.com/gmaschi/go-recipes-book/internal/controllers/middlewares/auth"
+	bookRecipeFactory "github.com/gmaschi/go-recipes-book/internal/factories/book-recipe-factory"
+	memoryStore "github.com/gmaschi/go-recipes-book/internal/mocks/datastore/memory"
+	graphqlModel "github.com/gmaschi/go-recipes-book/internal/models/graphql"
+	db "github.com/gmaschi/go-recipes-book/internal/services/datastore/postgresql/recipes/sqlc"
+	"github.com/gmaschi/go-recipes-book/pkg/config/env"
+	"github.com/gmaschi/go-recipes-book/pkg/tools/problem"
+	"github.com/gmaschi/go-recipes-book/pkg/tools/random"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countingStore counts the queries the dataloaders batch or cache
+type countingStore struct {
+	db.Store
+	listAuthorsByUsernames int32
+	listRecipes            int32
+	getAuthor              int32
+}
+
+func (s *countingStore) ListAuthorsByUsernames(ctx context.Context, usernames []string) ([]db.Author, error) {
+	atomic.AddInt32(&s.listAuthorsByUsernames, 1)
+	return s.Store.ListAuthorsByUsernames(ctx, usernames)
+}
+
+func (s *countingStore) ListRecipes(ctx context.Context, arg db.ListRecipesParams) ([]db.Recipe, error) {
+	atomic.AddInt32(&s.listRecipes, 1)
+	return s.Store.ListRecipes(ctx, arg)
+}
+
+func (s *countingStore) GetAuthor(ctx context.Context, username string) (db.Author, error) {
+	atomic.AddInt32(&s.getAuthor, 1)
+	return s.Store.GetAuthor(ctx, username)
+}
+
+func (s *countingStore) reset() {
+	atomic.StoreInt32(&s.listAuthorsByUsernames, 0)
+	atomic.StoreInt32(&s.listRecipes, 0)
+	atomic.StoreInt32(&s.getAuthor, 0)
+}
+
+type testServer struct {
+	t      *testing.T
+	server *bookRecipeFactory.Factory
+}
+
+type response struct {
+	Data   map[string]json.RawMessage `json:"data"`
+	Errors []graphqlModel.Error       `json:"errors"`
+}
+
+func newTestServer(t *testing.T, store db.Store) *testServer {
+	config := env.Config{
+		Auth: env.AuthConfig{
+			TokenSymmetricKey: random.String(32),
+			TokenDuration:     time.Minute,
+		},
+	}
+	server, err := bookRecipeFactory.New(config, store)
+	require.NoError(t, err)
+	return &testServer{t: t, server: server}
+}
+
+func (s *testServer) token(username string) string {
+	token, err := s.server.TokenAuth.CreateToken(username, time.Minute)
+	require.NoError(s.t, err)
+	return token
+}
+
+func (s *testServer) do(token, query string, variables map[string]interface{}) *httptest.ResponseRecorder {
+	body, err := json.Marshal(graphqlModel.Request{Query: query, Variables: variables})
+	require.NoError(s.t, err)
+
+	req, err := http.NewRequest(http.MethodPost, "/graphql", bytes.NewReader(body))
+	require.NoError(s.t, err)
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set(authMiddleware.AuthorizationHeaderKey, fmt.Sprintf("%s %s", authMiddleware.AuthorizationTypeBearer, token))
+	}
+
+	recorder := httptest.NewRecorder()
+	s.server.Router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func (s *testServer) query(token, query string, variables map[string]interface{}) response {
+	recorder := s.do(token, query, variables)
+	require.Equal(s.t, http.StatusOK, recorder.Code, recorder.Body.String())
+
+	var res response
+	require.NoError(s.t, json.Unmarshal(recorder.Body.Bytes(), &res))
+	return res
+}
+
+func (s *testServer) createAuthor() string {
+	username := random.String(10)
+	res := s.query("", `mutation($input: CreateAuthorInput!) { createAuthor(input: $input) { username } }`, map[string]interface{}{
+		"input": map[string]interface{}{"username": username, "password": random.String(8), "email": random.Email()},
+	})
+	require.Empty(s.t, res.Errors)
+	return username
+}
+
+func (s *testServer) createRecipe(token string) string {
+	res := s.query(token, `mutation { createRecipe(input: {ingredients: ["flour", "water"], steps: ["mix"]}) { id } }`, nil)
+	require.Empty(s.t, res.Errors)
+
+	var created struct{ ID string }
+	require.NoError(s.t, json.Unmarshal(res.Data["createRecipe"], &created))
+	return created.ID
+}
+
+func requireErrorCode(t *testing.T, res response, code string) {
+	t.Helper()
+	require.Len(t, res.Errors, 1)
+	require.Equal(t, code, res.Errors[0].Extensions["code"], res.Errors[0].Message)
+}
+
+func TestQuery(t *testing.T) {
+	store := &countingStore{Store: memoryStore.New()}
+	s := newTestServer(t, store)
+
+	author := s.createAuthor()
+	other := s.createAuthor()
+	token := s.token(author)
+	for i := 0; i < 3; i++ {
+		s.createRecipe(token)
+	}
+
+	t.Run("Batched relations", func(t *testing.T) {
+		store.reset()
+		res := s.query(token, `{ recipes(pageId: 1, pageSize: 5) { id author { username recipes(pageId: 1, pageSize: 2) { id } } } }`, nil)
+		require.Empty(t, res.Errors)
+
+		var recipes []struct {
+			ID     string
+			Author struct {
+				Username string
+				Recipes  []struct{ ID string }
+			}
+		}
+		require.NoError(t, json.Unmarshal(res.Data["recipes"], &recipes))
+		require.Len(t, recipes, 3)
+		for _, recipe := range recipes {
+			require.Equal(t, author, recipe.Author.Username)
+			require.Len(t, recipe.Author.Recipes, 2)
+		}
+
+		// the page of the recipes of the author is queried once, for all the recipes
+		require.EqualValues(t, 1, atomic.LoadInt32(&store.listAuthorsByUsernames))
+		require.EqualValues(t, 2, atomic.LoadInt32(&store.listRecipes))
+		require.Zero(t, atomic.LoadInt32(&store.getAuthor))
+	})
+
+	t.Run("Public author", func(t *testing.T) {
+		res := s.query("", `query($username: String!) { author(username: $username) { username } }`, map[string]interface{}{"username": author})
+		require.Empty(t, res.Errors)
+		require.JSONEq(t, fmt.Sprintf(`{"username": %q}`, author), string(res.Data["author"]))
+
+		res = s.query("", `{ author(username: "missing1") { username } }`, nil)
+		requireErrorCode(t, res, problem.CodeAuthorNotFound)
+	})
+
+	t.Run("Recipes of another author", func(t *testing.T) {
+		res := s.query(s.token(other), `query($username: String!) { author(username: $username) { username recipes(pageId: 1, pageSize: 5) { id } } }`, map[string]interface{}{"username": author})
+		requireErrorCode(t, res, problem.CodeRecipeNotOwned)
+		require.JSONEq(t, fmt.Sprintf(`{"username": %q, "recipes": null}`, author), string(res.Data["author"]))
+	})
+
+	t.Run("Anonymous", func(t *testing.T) {
+		res := s.query("", `{ me { username } }`, nil)
+		requireErrorCode(t, res, problem.CodeMissingAuthorization)
+	})
+
+	t.Run("Invalid token", func(t *testing.T) {
+		recorder := s.do("invalid", `{ me { username } }`, nil)
+		require.Equal(t, http.StatusUnauthorized, recorder.Code)
+	})
+
+	t.Run("Invalid arguments", func(t *testing.T) {
+		res := s.query(token, `{ recipes(pageId: 0, pageSize: 5) { id } }`, nil)
+		requireErrorCode(t, res, problem.CodeInvalidRequest)
+		require.NotEmpty(t, res.Errors[0].Extensions["fields"])
+	})
+
+	t.Run("Malformed request", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPost, "/graphql", bytes.NewReader([]byte("{")))
+		require.NoError(t, err)
+		recorder := httptest.NewRecorder()
+		s.server.Router.ServeHTTP(recorder, req)
+		require.Equal(t, http.StatusBadRequest, recorder.Code)
+	})
+}
+
+func TestMutation(t *testing.T) {
+	s := newTestServer(t, memoryStore.New())
+
+	author := s.createAuthor()
+	other := s.createAuthor()
+	token := s.token(author)
+	id := s.createRecipe(token)
+
+	res := s.query(token, `mutation($id: ID!) { updateRecipe(input: {id: $id, version: 1, steps: ["knead"]}) { ingredients steps version } }`, map[string]interface{}{"id": id})
+	require.Empty(t, res.Errors)
+	require.JSONEq(t, `{"ingredients": ["flour", "water"], "steps": ["knead"], "version": 2}`, string(res.Data["updateRecipe"]))
+
+	res = s.query(token, `mutation($id: ID!) { updateRecipe(input: {id: $id, version: 1, steps: ["lost"]}) { steps } }`, map[string]interface{}{"id": id})
+	requireErrorCode(t, res, problem.CodeVersionConflict)
+
+	res = s.query(s.token(other), `mutation($id: ID!) { deleteRecipe(id: $id) }`, map[string]interface{}{"id": id})
+	requireErrorCode(t, res, problem.CodeRecipeNotOwned)
+
+	res = s.query(token, `mutation { createRecipe(input: {ingredients: [], steps: ["mix"]}) { id } }`, nil)
+	requireErrorCode(t, res, problem.CodeInvalidRequest)
+
+	res = s.query(token, `mutation($id: ID!) { deleteRecipe(id: $id) }`, map[string]interface{}{"id": id})
+	require.Empty(t, res.Errors)
+	require.JSONEq(t, `true`, string(res.Data["deleteRecipe"]))
+
+	res = s.query(token, `query($id: ID!) { recipe(id: $id) { id } }`, map[string]interface{}{"id": id})
+	requireErrorCode(t, res, problem.CodeRecipeNotFound)
+
+	res = s.query(token, `{ recipe(id: "abc") { id } }`, nil)
+	requireErrorCode(t, res, problem.CodeInvalidRequest)
+
+	email := random.Email()
+	res = s.query(token, `mutation($input: UpdateAuthorInput!) { updateAuthor(input: $input) { email } }`, map[string]interface{}{
+		"input": map[string]interface{}{"username": author, "version": 1, "email": email},
+	})
+	require.Empty(t, res.Errors)
+	require.JSONEq(t, fmt.Sprintf(`{"email": %q}`, email), string(res.Data["updateAuthor"]))
+
+	res = s.query(token, `mutation($input: UpdateAuthorInput!) { updateAuthor(input: $input) { email } }`, map[string]interface{}{
+		"input": map[string]interface{}{"username": author, "version": 1, "email": random.Email()},
+	})
+	requireErrorCode(t, res, problem.CodeVersionConflict)
+
+	res = s.query(token, `mutation($username: String!) { deleteAuthor(username: $username) }`, map[string]interface{}{"username": other})
+	requireErrorCode(t, res, problem.CodeAuthorNotAuthenticated)
+
+	res = s.query(token, `mutation($username: String!) { deleteAuthor(username: $username) }`, map[string]interface{}{"username": author})
+	require.Empty(t, res.Errors)
+}
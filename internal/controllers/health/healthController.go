@@ -0,0 +1,100 @@
+package healthController
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	loggingMiddleware "github.com/gmaschi/go-recipes-book/internal/controllers/middlewares/logging"
+	healthModel "github.com/gmaschi/go-recipes-book/internal/models/health"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// checkTimeout bounds the time a single readiness check may take
+const checkTimeout = 2 * time.Second
+
+type (
+	// Check is a named readiness check
+	Check struct {
+		Name string
+		Run  func(ctx context.Context) error
+	}
+
+	Controller struct {
+		checks       []Check
+		shuttingDown int32
+	}
+)
+
+// New creates a pointer to a Controller running the given readiness checks
+func New(checks ...Check) *Controller {
+	return &Controller{
+		checks: checks,
+	}
+}
+
+// SetShuttingDown makes every following readiness probe fail, so that the instance is taken
+// out of rotation while it drains its connections
+func (c *Controller) SetShuttingDown() {
+	atomic.StoreInt32(&c.shuttingDown, 1)
+}
+
+// Live handles the liveness probe, which succeeds as long as the process serves requests
+func (c *Controller) Live(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, healthModel.LiveResponse{Status: healthModel.StatusOK})
+}
+
+// Ready handles the readiness probe, running every check concurrently. The probe is public,
+// so the errors of the failed checks are logged rather than returned.
+func (c *Controller) Ready(ctx *gin.Context) {
+	res := healthModel.ReadyResponse{
+		Status: healthModel.StatusOK,
+		Checks: make(map[string]healthModel.CheckResponse, len(c.checks)+1),
+	}
+
+	if atomic.LoadInt32(&c.shuttingDown) == 1 {
+		res.Checks["shutdown"] = healthModel.CheckResponse{Status: healthModel.StatusUnavailable}
+	}
+
+	checkCtx, cancel := context.WithTimeout(ctx.Request.Context(), checkTimeout)
+	defer cancel()
+
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	for _, check := range c.checks {
+		wg.Add(1)
+		go func(check Check) {
+			defer wg.Done()
+
+			start := time.Now()
+			err := check.Run(checkCtx)
+			checkRes := healthModel.CheckResponse{Status: healthModel.StatusOK}
+			if err != nil {
+				checkRes.Status = healthModel.StatusUnavailable
+				loggingMiddleware.Logger(ctx).Warn("readiness check failed",
+					"check", check.Name,
+					"latency_ms", float64(time.Since(start).Microseconds())/1000,
+					"error", err,
+				)
+			}
+
+			mu.Lock()
+			res.Checks[check.Name] = checkRes
+			mu.Unlock()
+		}(check)
+	}
+	wg.Wait()
+
+	status := http.StatusOK
+	for _, checkRes := range res.Checks {
+		if checkRes.Status != healthModel.StatusOK {
+			res.Status = healthModel.StatusUnavailable
+			status = http.StatusServiceUnavailable
+		}
+	}
+
+	ctx.JSON(status, res)
+}
@@ -0,0 +1,174 @@
+package healthController_test
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/gin-gonic/gin"
+	healthController "github.com/gmaschi/go-recipes-book/internal/controllers/health"
+	bookRecipeFactory "github.com/gmaschi/go-recipes-book/internal/factories/book-recipe-factory"
+	mockedstore "github.com/gmaschi/go-recipes-book/internal/mocks/datastore/postgresql/recipes"
+	healthModel "github.com/gmaschi/go-recipes-book/internal/models/health"
+	"github.com/gmaschi/go-recipes-book/internal/services/datastore/postgresql/recipes/migration"
+	db "github.com/gmaschi/go-recipes-book/internal/services/datastore/postgresql/recipes/sqlc"
+	"github.com/gmaschi/go-recipes-book/pkg/config/env"
+	"github.com/gmaschi/go-recipes-book/pkg/tools/random"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newTestServer(t *testing.T, store db.Store) *bookRecipeFactory.Factory {
+	config := env.Config{
+		Auth: env.AuthConfig{
+			TokenSymmetricKey: random.String(32),
+			TokenDuration:     time.Minute,
+		},
+	}
+
+	server, err := bookRecipeFactory.New(config, store)
+	require.NoError(t, err)
+	return server
+}
+
+func TestLive(t *testing.T) {
+	server := newTestServer(t, nil)
+	recorder := httptest.NewRecorder()
+
+	req, err := http.NewRequest(http.MethodGet, "/healthz", nil)
+	require.NoError(t, err)
+
+	server.Router.ServeHTTP(recorder, req)
+	require.Equal(t, http.StatusOK, recorder.Code)
+}
+
+func TestReady(t *testing.T) {
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockedstore.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockedstore.MockStore) {
+				store.EXPECT().Ping(gomock.Any()).Times(1).Return(nil)
+				store.EXPECT().MigrationVersion(gomock.Any()).Times(1).Return(int64(latestVersion(t)), false, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				res := readyResponse(t, recorder)
+				require.Equal(t, healthModel.StatusOK, res.Status)
+				require.Len(t, res.Checks, 3)
+				for name, check := range res.Checks {
+					require.Equal(t, healthModel.StatusOK, check.Status, name)
+				}
+			},
+		},
+		{
+			name: "DatabaseDown",
+			buildStubs: func(store *mockedstore.MockStore) {
+				store.EXPECT().Ping(gomock.Any()).Times(1).Return(errors.New("connection refused"))
+				store.EXPECT().MigrationVersion(gomock.Any()).Times(1).Return(int64(0), false, errors.New("connection refused"))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+				res := readyResponse(t, recorder)
+				require.Equal(t, healthModel.StatusUnavailable, res.Status)
+				require.Equal(t, healthModel.StatusUnavailable, res.Checks["database"].Status)
+				require.NotContains(t, recorder.Body.String(), "connection refused")
+				require.Equal(t, healthModel.StatusOK, res.Checks["token_maker"].Status)
+			},
+		},
+		{
+			name: "DirtyMigration",
+			buildStubs: func(store *mockedstore.MockStore) {
+				store.EXPECT().Ping(gomock.Any()).Times(1).Return(nil)
+				store.EXPECT().MigrationVersion(gomock.Any()).Times(1).Return(int64(latestVersion(t)), true, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+				res := readyResponse(t, recorder)
+				require.Equal(t, healthModel.StatusUnavailable, res.Checks["migrations"].Status)
+			},
+		},
+		{
+			name: "SchemaBehind",
+			buildStubs: func(store *mockedstore.MockStore) {
+				store.EXPECT().Ping(gomock.Any()).Times(1).Return(nil)
+				store.EXPECT().MigrationVersion(gomock.Any()).Times(1).Return(int64(latestVersion(t)-1), false, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+				res := readyResponse(t, recorder)
+				require.Equal(t, healthModel.StatusUnavailable, res.Checks["migrations"].Status)
+				require.Equal(t, healthModel.StatusOK, res.Checks["database"].Status)
+			},
+		},
+		{
+			name: "SchemaAhead",
+			buildStubs: func(store *mockedstore.MockStore) {
+				store.EXPECT().Ping(gomock.Any()).Times(1).Return(nil)
+				store.EXPECT().MigrationVersion(gomock.Any()).Times(1).Return(int64(latestVersion(t)+1), false, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				res := readyResponse(t, recorder)
+				require.Equal(t, healthModel.StatusOK, res.Checks["migrations"].Status)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockedstore.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			req, err := http.NewRequest(http.MethodGet, "/readyz", nil)
+			require.NoError(t, err)
+
+			server.Router.ServeHTTP(recorder, req)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func latestVersion(t *testing.T) uint {
+	version, err := migration.LatestVersion()
+	require.NoError(t, err)
+	return version
+}
+
+func readyResponse(t *testing.T, recorder *httptest.ResponseRecorder) healthModel.ReadyResponse {
+	var res healthModel.ReadyResponse
+	err := json.Unmarshal(recorder.Body.Bytes(), &res)
+	require.NoError(t, err)
+	return res
+}
+
+func TestReadyShuttingDown(t *testing.T) {
+	controller := healthController.New()
+	router := gin.New()
+	router.GET("/readyz", controller.Ready)
+
+	recorder := httptest.NewRecorder()
+	req, err := http.NewRequest(http.MethodGet, "/readyz", nil)
+	require.NoError(t, err)
+	router.ServeHTTP(recorder, req)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	controller.SetShuttingDown()
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	require.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+	res := readyResponse(t, recorder)
+	require.Equal(t, healthModel.StatusUnavailable, res.Checks["shutdown"].Status)
+}
@@ -1,11 +1,16 @@
 package authMiddleware
 
 import (
+	"context"
+	"database/sql"
 	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
+	errorMiddleware "github.com/gmaschi/go-recipes-book/internal/controllers/middlewares/errors"
+	loggingMiddleware "github.com/gmaschi/go-recipes-book/internal/controllers/middlewares/logging"
+	authorDomain "github.com/gmaschi/go-recipes-book/internal/domain/author"
 	"github.com/gmaschi/go-recipes-book/pkg/auth/tokenAuth"
-	"github.com/gmaschi/go-recipes-book/pkg/tools/parseErrors"
+	"github.com/gmaschi/go-recipes-book/pkg/tools/problem"
 	"net/http"
 	"strings"
 )
@@ -16,26 +21,56 @@ const (
 	AuthorizationPayloadKey = "authorization_payload"
 )
 
-func AuthMiddleware(tokenMaker tokenAuth.Maker) gin.HandlerFunc {
+// The problems reported for the access tokens, shared by the authentication of every API
+var (
+	ErrMissingAuthorization = problem.New(http.StatusUnauthorized, problem.CodeMissingAuthorization, "authorization not provided")
+	ErrExpiredToken         = problem.New(http.StatusUnauthorized, problem.CodeTokenExpired, "token has expired")
+	ErrInvalidToken         = problem.New(http.StatusUnauthorized, problem.CodeTokenInvalid, "token is invalid")
+
+	errInvalidAuthorization = problem.New(http.StatusUnauthorized, problem.CodeInvalidAuthorization, "invalid authorization header format")
+)
+
+// Authors tells whether the authors the tokens were issued to were disabled since
+type Authors interface {
+	GetAuthorDisabledAt(ctx context.Context, username string) (sql.NullTime, error)
+}
+
+// CheckAuthor reports the tokens of the authors that were disabled or deleted after they
+// were issued, which would be valid until they expire otherwise. A disabled author is
+// reported with authorDomain.ErrDisabledAuthor, as when it logs in.
+func CheckAuthor(ctx context.Context, authors Authors, username string) error {
+	disabledAt, err := authors.GetAuthorDisabledAt(ctx, username)
+	if err == sql.ErrNoRows {
+		return ErrInvalidToken
+	}
+	if err != nil {
+		return err
+	}
+	if disabledAt.Valid {
+		return authorDomain.ErrDisabledAuthor
+	}
+	return nil
+}
+
+// AuthMiddleware verifies the access token sent in the authorization header, checks that its
+// author can still use it, and stores its payload in the context
+func AuthMiddleware(tokenMaker tokenAuth.Maker, authors Authors) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		authorizationHeader := ctx.GetHeader(AuthorizationHeaderKey)
 		if len(authorizationHeader) == 0 {
-			err := errors.New("authorization not provided")
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, parseErrors.ErrorResponse(err))
+			errorMiddleware.Abort(ctx, ErrMissingAuthorization)
 			return
 		}
 
 		fields := strings.Fields(authorizationHeader)
 		if len(fields) < 2 {
-			err := errors.New("invalid authorization header format")
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, parseErrors.ErrorResponse(err))
+			errorMiddleware.Abort(ctx, errInvalidAuthorization)
 			return
 		}
 
 		authorizationType := strings.ToLower(fields[0])
 		if authorizationType != AuthorizationTypeBearer {
-			err := fmt.Errorf("unsupported authorization format %s", authorizationType)
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, parseErrors.ErrorResponse(err))
+			errorMiddleware.Abort(ctx, problem.New(http.StatusUnauthorized, problem.CodeUnsupportedAuthorization, fmt.Sprintf("unsupported authorization format %s", authorizationType)))
 			return
 		}
 
@@ -43,11 +78,33 @@ func AuthMiddleware(tokenMaker tokenAuth.Maker) gin.HandlerFunc {
 
 		payload, err := tokenMaker.VerifyToken(accessToken)
 		if err != nil {
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, parseErrors.ErrorResponse(err))
+			if errors.Is(err, tokenAuth.ErrExpiredToken) {
+				errorMiddleware.Abort(ctx, ErrExpiredToken)
+				return
+			}
+			errorMiddleware.Abort(ctx, ErrInvalidToken.WithCause(err))
+			return
+		}
+		if err := CheckAuthor(ctx.Request.Context(), authors, payload.Username); err != nil {
+			errorMiddleware.Abort(ctx, err)
 			return
 		}
 
 		ctx.Set(AuthorizationPayloadKey, payload)
+		loggingMiddleware.SetLogger(ctx, loggingMiddleware.Logger(ctx).With("username", payload.Username))
 		ctx.Next()
 	}
 }
+
+// OptionalAuthMiddleware authenticates the requests that send an authorization header, as
+// AuthMiddleware does, and lets the others through anonymously
+func OptionalAuthMiddleware(tokenMaker tokenAuth.Maker, authors Authors) gin.HandlerFunc {
+	authenticate := AuthMiddleware(tokenMaker, authors)
+	return func(ctx *gin.Context) {
+		if len(ctx.GetHeader(AuthorizationHeaderKey)) == 0 {
+			ctx.Next()
+			return
+		}
+		authenticate(ctx)
+	}
+}
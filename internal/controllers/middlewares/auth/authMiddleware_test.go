@@ -1,12 +1,17 @@
 package authMiddleware_test
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"github.com/gin-gonic/gin"
 	authMiddleware "github.com/gmaschi/go-recipes-book/internal/controllers/middlewares/auth"
 	bookRecipeFactory "github.com/gmaschi/go-recipes-book/internal/factories/book-recipe-factory"
+	memoryStore "github.com/gmaschi/go-recipes-book/internal/mocks/datastore/memory"
+	db "github.com/gmaschi/go-recipes-book/internal/services/datastore/postgresql/recipes/sqlc"
 	"github.com/gmaschi/go-recipes-book/pkg/auth/tokenAuth"
 	"github.com/gmaschi/go-recipes-book/pkg/config/env"
+	"github.com/gmaschi/go-recipes-book/pkg/tools/problem"
 	"github.com/stretchr/testify/require"
 	"net/http"
 	"net/http/httptest"
@@ -54,6 +59,27 @@ func TestAuthMiddleware(t *testing.T) {
 				require.Equal(t, http.StatusUnauthorized, recorder.Code)
 			},
 		},
+		{
+			name: "DisabledAuthor",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker tokenAuth.Maker) {
+				addAuthorization(t, request, tokenMaker, authMiddleware.AuthorizationTypeBearer, "disabled", time.Minute)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				var p problem.Problem
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &p))
+				require.Equal(t, problem.CodeAuthorDisabled, p.Code)
+			},
+		},
+		{
+			name: "DeletedAuthor",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker tokenAuth.Maker) {
+				addAuthorization(t, request, tokenMaker, authMiddleware.AuthorizationTypeBearer, "deleted", time.Minute)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
 		{
 			name: "ExpiredToken",
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker tokenAuth.Maker) {
@@ -72,11 +98,19 @@ func TestAuthMiddleware(t *testing.T) {
 			server, err := bookRecipeFactory.New(config, nil)
 			require.NoError(t, err)
 
+			store := memoryStore.New()
+			for _, username := range []string{"user", "disabled"} {
+				_, err = store.CreateAuthor(context.Background(), db.CreateAuthorParams{Username: username, HashedPassword: "hashed", Email: username + "@example.com"})
+				require.NoError(t, err)
+			}
+			_, err = store.DisableAuthor(context.Background(), "disabled")
+			require.NoError(t, err)
+
 			authPath := "/auth"
 
 			server.Router.GET(
 				authPath,
-				authMiddleware.AuthMiddleware(server.TokenAuth),
+				authMiddleware.AuthMiddleware(server.TokenAuth, store),
 				func(ctx *gin.Context) {
 					ctx.JSON(http.StatusOK, map[string]interface{}{})
 				},
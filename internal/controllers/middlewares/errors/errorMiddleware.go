@@ -0,0 +1,238 @@
+package errorMiddleware
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	loggingMiddleware "github.com/gmaschi/go-recipes-book/internal/controllers/middlewares/logging"
+	"github.com/gmaschi/go-recipes-book/pkg/tools/problem"
+	"github.com/go-playground/validator/v10"
+	"github.com/lib/pq"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// ResourceKey is the context key holding the name of the resource served by a route group
+const ResourceKey = "error_resource"
+
+type constraintError struct {
+	code   string
+	detail string
+}
+
+var (
+	// uniqueConstraints maps the unique constraints of the schema to the error reported when
+	// they are violated
+	uniqueConstraints = map[string]constraintError{
+		"authors_pkey":      {code: problem.CodeAuthorUsernameTaken, detail: "username is already taken"},
+		"authors_email_key": {code: problem.CodeAuthorEmailTaken, detail: "email is already registered"},
+	}
+
+	// referencedConstraints maps the foreign keys of the schema to the error reported when a
+	// row still referenced through them is deleted
+	referencedConstraints = map[string]constraintError{
+		"recipes_author_fkey": {code: problem.CodeAuthorHasRecipes, detail: "author still has recipes"},
+	}
+
+	// missingReferenceConstraints maps the foreign keys of the schema to the error reported
+	// when a row references a missing one through them
+	missingReferenceConstraints = map[string]constraintError{
+		"recipes_author_fkey": {code: problem.CodeRecipeAuthorNotFound, detail: "author of the recipe does not exist"},
+	}
+)
+
+func init() {
+	// report the fields under the names the client sent them with
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(fieldName)
+	}
+}
+
+// ErrorMiddleware writes the last error recorded by the handlers as an
+// application/problem+json response, unless they already wrote one
+func ErrorMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Next()
+
+		last := ctx.Errors.Last()
+		if last == nil || ctx.Writer.Written() {
+			return
+		}
+
+		p := translate(ctx, last).Problem(ctx.Request.URL.Path)
+		p.RequestID = ctx.GetString(loggingMiddleware.RequestIDKey)
+
+		body, err := json.Marshal(p)
+		if err != nil {
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+		ctx.Data(p.Status, problem.ContentType, body)
+	}
+}
+
+// Resource names the resource served by the routes it is used on, e.g. in the code of the
+// not found errors
+func Resource(name string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Set(ResourceKey, name)
+		ctx.Next()
+	}
+}
+
+// NoRoute reports the requests that did not match any route
+func NoRoute(ctx *gin.Context) {
+	Abort(ctx, problem.New(http.StatusNotFound, problem.CodeRouteNotFound, "no route matches the request"))
+}
+
+// Abort records err, stops the pending handlers and sets the status err is reported with.
+// The response body is written by ErrorMiddleware.
+func Abort(ctx *gin.Context, err error) {
+	ginErr := ctx.Error(err)
+	ctx.Status(translate(ctx, ginErr).Status)
+	ctx.Abort()
+}
+
+// AbortBind is like Abort for the errors returned when binding a request
+func AbortBind(ctx *gin.Context, err error) {
+	ginErr := ctx.Error(err).SetType(gin.ErrorTypeBind)
+	ctx.Status(translate(ctx, ginErr).Status)
+	ctx.Abort()
+}
+
+// translate maps a recorded error to the error reported to the client
+func translate(ctx *gin.Context, ginErr *gin.Error) *problem.Error {
+	if ginErr.IsType(gin.ErrorTypeBind) {
+		var problemErr *problem.Error
+		if errors.As(ginErr.Err, &problemErr) {
+			return problemErr
+		}
+		return TranslateBind(ginErr.Err)
+	}
+	return Translate(ginErr.Err, ctx.GetString(ResourceKey))
+}
+
+// Translate maps an error returned by a handler or the store to the error reported to the
+// client. resource names the resource in the not found errors, when not empty. Errors that
+// are not recognised are reported as internal errors without any detail.
+func Translate(err error, resource string) *problem.Error {
+	var problemErr *problem.Error
+	if errors.As(err, &problemErr) {
+		return problemErr
+	}
+
+	if errors.Is(err, sql.ErrNoRows) {
+		if resource == "" {
+			return problem.New(http.StatusNotFound, problem.CodeNotFound, "resource not found").WithCause(err)
+		}
+		return problem.New(http.StatusNotFound, resource+".not_found", resource+" not found").WithCause(err)
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		if translated := translatePQ(pqErr); translated != nil {
+			return translated
+		}
+	}
+
+	return problem.Internal(err)
+}
+
+// translatePQ maps the constraint violations. Violations keep the status they were reported
+// with before the problem details were introduced.
+func translatePQ(err *pq.Error) *problem.Error {
+	switch err.Code.Name() {
+	case "unique_violation":
+		if c, ok := uniqueConstraints[err.Constraint]; ok {
+			return problem.New(http.StatusForbidden, c.code, c.detail).WithCause(err)
+		}
+		return problem.New(http.StatusForbidden, problem.CodeConflict, "resource already exists").WithCause(err)
+	case "foreign_key_violation":
+		constraints := missingReferenceConstraints
+		if strings.Contains(err.Detail, "is still referenced") {
+			constraints = referencedConstraints
+		}
+		if c, ok := constraints[err.Constraint]; ok {
+			return problem.New(http.StatusForbidden, c.code, c.detail).WithCause(err)
+		}
+		return problem.New(http.StatusForbidden, problem.CodeReferenceViolation, "resource is referenced by or references another resource").WithCause(err)
+	default:
+		return nil
+	}
+}
+
+// TranslateBind maps the errors of the gin bindings and validator to field errors, without
+// echoing the raw decoder and validator messages
+func TranslateBind(err error) *problem.Error {
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		fields := make([]problem.FieldError, 0, len(validationErrs))
+		for _, fe := range validationErrs {
+			fields = append(fields, problem.FieldError{
+				Field:   fe.Field(),
+				Code:    fe.Tag(),
+				Message: fieldMessage(fe),
+			})
+		}
+		return problem.Invalid(fields...).WithCause(err)
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return problem.Invalid(problem.FieldError{
+			Field:   typeErr.Field,
+			Code:    "type",
+			Message: fmt.Sprintf("must be of type %s", typeErr.Type),
+		}).WithCause(err)
+	}
+
+	if errors.Is(err, io.EOF) {
+		return problem.New(http.StatusBadRequest, problem.CodeMalformedRequest, "request body is empty").WithCause(err)
+	}
+	return problem.New(http.StatusBadRequest, problem.CodeMalformedRequest, "request could not be decoded").WithCause(err)
+}
+
+func fieldMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "email":
+		return "must be a valid email address"
+	case "alphanum":
+		return "must contain only letters and digits"
+	case "min", "max":
+		bound := "at least"
+		if fe.Tag() == "max" {
+			bound = "at most"
+		}
+		switch fe.Kind() {
+		case reflect.String:
+			return fmt.Sprintf("must be %s %s characters long", bound, fe.Param())
+		case reflect.Slice, reflect.Array, reflect.Map:
+			return fmt.Sprintf("must have %s %s items", bound, fe.Param())
+		default:
+			return fmt.Sprintf("must be %s %s", bound, fe.Param())
+		}
+	default:
+		return "is invalid"
+	}
+}
+
+// fieldName returns the name of a request field from its json, form or uri tag
+func fieldName(sf reflect.StructField) string {
+	for _, tag := range []string{"json", "form", "uri"} {
+		name := strings.Split(sf.Tag.Get(tag), ",")[0]
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
+		}
+	}
+	return sf.Name
+}
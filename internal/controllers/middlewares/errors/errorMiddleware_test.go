@@ -0,0 +1,181 @@
+package errorMiddleware_test
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"github.com/gin-gonic/gin"
+	errorMiddleware "github.com/gmaschi/go-recipes-book/internal/controllers/middlewares/errors"
+	loggingMiddleware "github.com/gmaschi/go-recipes-book/internal/controllers/middlewares/logging"
+	"github.com/gmaschi/go-recipes-book/pkg/tools/problem"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type createRequest struct {
+	Username string   `json:"username" binding:"required,alphanum"`
+	Email    string   `json:"email" binding:"required,email"`
+	Password string   `json:"password" binding:"required,min=6"`
+	Tags     []string `json:"tags"`
+}
+
+func newTestRouter() *gin.Engine {
+	router := gin.New()
+	router.Use(
+		loggingMiddleware.RequestIDMiddleware(slog.New(slog.NewJSONHandler(io.Discard, nil))),
+		errorMiddleware.ErrorMiddleware(),
+		loggingMiddleware.RecoveryMiddleware(),
+	)
+	router.NoRoute(errorMiddleware.NoRoute)
+
+	authors := router.Group("/authors", errorMiddleware.Resource("author"))
+	authors.POST("", func(ctx *gin.Context) {
+		var req createRequest
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			errorMiddleware.AbortBind(ctx, err)
+			return
+		}
+		ctx.JSON(http.StatusOK, req)
+	})
+	authors.GET("/missing", func(ctx *gin.Context) {
+		errorMiddleware.Abort(ctx, sql.ErrNoRows)
+	})
+	authors.GET("/taken", func(ctx *gin.Context) {
+		errorMiddleware.Abort(ctx, &pq.Error{Code: "23505", Constraint: "authors_pkey", Message: "duplicate key value"})
+	})
+	authors.GET("/referenced", func(ctx *gin.Context) {
+		errorMiddleware.Abort(ctx, &pq.Error{Code: "23503", Constraint: "recipes_author_fkey", Detail: `Key (username)=(alice) is still referenced from table "recipes".`})
+	})
+
+	router.GET("/unique", func(ctx *gin.Context) {
+		errorMiddleware.Abort(ctx, &pq.Error{Code: "23505"})
+	})
+	router.GET("/internal", func(ctx *gin.Context) {
+		errorMiddleware.Abort(ctx, errors.New(`pq: relation "authors" does not exist`))
+	})
+	router.GET("/typed", func(ctx *gin.Context) {
+		errorMiddleware.Abort(ctx, problem.New(http.StatusTeapot, "teapot.brewing", "short and stout"))
+	})
+	router.GET("/panic", func(ctx *gin.Context) {
+		panic("boom")
+	})
+	router.GET("/written", func(ctx *gin.Context) {
+		_ = ctx.Error(errors.New("ignored"))
+		ctx.JSON(http.StatusAccepted, gin.H{})
+	})
+	return router
+}
+
+func serve(t *testing.T, router *gin.Engine, method, url string, body string) (*httptest.ResponseRecorder, problem.Problem) {
+	req, err := http.NewRequest(method, url, bytes.NewBufferString(body))
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	var p problem.Problem
+	if recorder.Header().Get("Content-Type") == problem.ContentType {
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &p))
+	}
+	return recorder, p
+}
+
+func TestErrorMiddleware(t *testing.T) {
+	router := newTestRouter()
+
+	t.Run("Validation errors", func(t *testing.T) {
+		recorder, p := serve(t, router, http.MethodPost, "/authors", `{"username":"not valid!","password":"abc"}`)
+		require.Equal(t, http.StatusBadRequest, recorder.Code)
+		require.Equal(t, problem.ContentType, recorder.Header().Get("Content-Type"))
+		require.Equal(t, problem.CodeInvalidRequest, p.Code)
+		require.Equal(t, http.StatusBadRequest, p.Status)
+		require.Equal(t, "/authors", p.Instance)
+		require.Equal(t, recorder.Header().Get(loggingMiddleware.RequestIDHeaderKey), p.RequestID)
+		require.Equal(t, []problem.FieldError{
+			{Field: "username", Code: "alphanum", Message: "must contain only letters and digits"},
+			{Field: "email", Code: "required", Message: "is required"},
+			{Field: "password", Code: "min", Message: "must be at least 6 characters long"},
+		}, p.Errors)
+	})
+
+	t.Run("Wrong field type", func(t *testing.T) {
+		recorder, p := serve(t, router, http.MethodPost, "/authors", `{"tags":"one"}`)
+		require.Equal(t, http.StatusBadRequest, recorder.Code)
+		require.Equal(t, problem.CodeInvalidRequest, p.Code)
+		require.Len(t, p.Errors, 1)
+		require.Equal(t, "tags", p.Errors[0].Field)
+	})
+
+	t.Run("Malformed body", func(t *testing.T) {
+		recorder, p := serve(t, router, http.MethodPost, "/authors", `{"username":`)
+		require.Equal(t, http.StatusBadRequest, recorder.Code)
+		require.Equal(t, problem.CodeMalformedRequest, p.Code)
+		require.Empty(t, p.Errors)
+	})
+
+	t.Run("Not found resource", func(t *testing.T) {
+		recorder, p := serve(t, router, http.MethodGet, "/authors/missing", "")
+		require.Equal(t, http.StatusNotFound, recorder.Code)
+		require.Equal(t, "author.not_found", p.Code)
+		require.Equal(t, "author not found", p.Detail)
+	})
+
+	t.Run("Unique constraint", func(t *testing.T) {
+		recorder, p := serve(t, router, http.MethodGet, "/authors/taken", "")
+		require.Equal(t, http.StatusForbidden, recorder.Code)
+		require.Equal(t, "author.username_taken", p.Code)
+		require.NotContains(t, recorder.Body.String(), "duplicate key")
+	})
+
+	t.Run("Still referenced", func(t *testing.T) {
+		recorder, p := serve(t, router, http.MethodGet, "/authors/referenced", "")
+		require.Equal(t, http.StatusForbidden, recorder.Code)
+		require.Equal(t, "author.has_recipes", p.Code)
+	})
+
+	t.Run("Unknown constraint", func(t *testing.T) {
+		recorder, p := serve(t, router, http.MethodGet, "/unique", "")
+		require.Equal(t, http.StatusForbidden, recorder.Code)
+		require.Equal(t, problem.CodeConflict, p.Code)
+	})
+
+	t.Run("Internal error", func(t *testing.T) {
+		recorder, p := serve(t, router, http.MethodGet, "/internal", "")
+		require.Equal(t, http.StatusInternalServerError, recorder.Code)
+		require.Equal(t, problem.CodeInternal, p.Code)
+		require.NotContains(t, recorder.Body.String(), "relation")
+	})
+
+	t.Run("Typed error", func(t *testing.T) {
+		recorder, p := serve(t, router, http.MethodGet, "/typed", "")
+		require.Equal(t, http.StatusTeapot, recorder.Code)
+		require.Equal(t, "teapot.brewing", p.Code)
+		require.Equal(t, "short and stout", p.Detail)
+		require.Equal(t, http.StatusText(http.StatusTeapot), p.Title)
+	})
+
+	t.Run("Panic", func(t *testing.T) {
+		recorder, p := serve(t, router, http.MethodGet, "/panic", "")
+		require.Equal(t, http.StatusInternalServerError, recorder.Code)
+		require.Equal(t, problem.CodeInternal, p.Code)
+		require.NotContains(t, recorder.Body.String(), "boom")
+	})
+
+	t.Run("No route", func(t *testing.T) {
+		recorder, p := serve(t, router, http.MethodGet, "/does/not/exist", "")
+		require.Equal(t, http.StatusNotFound, recorder.Code)
+		require.Equal(t, problem.CodeRouteNotFound, p.Code)
+	})
+
+	t.Run("Response already written", func(t *testing.T) {
+		recorder, _ := serve(t, router, http.MethodGet, "/written", "")
+		require.Equal(t, http.StatusAccepted, recorder.Code)
+		require.Equal(t, "{}", recorder.Body.String())
+	})
+}
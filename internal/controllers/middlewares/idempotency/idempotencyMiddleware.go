@@ -0,0 +1,228 @@
+package idempotencyMiddleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	authMiddleware "github.com/gmaschi/go-recipes-book/internal/controllers/middlewares/auth"
+	errorMiddleware "github.com/gmaschi/go-recipes-book/internal/controllers/middlewares/errors"
+	loggingMiddleware "github.com/gmaschi/go-recipes-book/internal/controllers/middlewares/logging"
+	db "github.com/gmaschi/go-recipes-book/internal/services/datastore/postgresql/recipes/sqlc"
+	"github.com/gmaschi/go-recipes-book/pkg/auth/tokenAuth"
+	"github.com/gmaschi/go-recipes-book/pkg/tools/problem"
+	"io"
+	"net/http"
+	"time"
+)
+
+const (
+	// HeaderKey is the request header holding the key chosen by the client for a request
+	// that must not be applied twice
+	HeaderKey = "Idempotency-Key"
+
+	// ReplayedHeaderKey is set on the responses replayed from a previous request
+	ReplayedHeaderKey = "Idempotent-Replayed"
+
+	// MaxKeyLength is the maximum length of an idempotency key
+	MaxKeyLength = 255
+
+	// MaxBodySize is the maximum size of the body of a request with an idempotency key,
+	// which is read whole to be hashed
+	MaxBodySize = 1 << 20
+)
+
+// storedHeaders are the response headers replayed along with the status and the body
+var storedHeaders = []string{"Content-Type", "ETag", "Location"}
+
+var (
+	errKeyTooLong    = problem.New(http.StatusBadRequest, problem.CodeIdempotencyKeyInvalid, fmt.Sprintf("%s must be at most %d characters", HeaderKey, MaxKeyLength))
+	errKeyReused     = problem.New(http.StatusUnprocessableEntity, problem.CodeIdempotencyKeyReused, fmt.Sprintf("%s was already used for a different request", HeaderKey))
+	errKeyInProgress = problem.New(http.StatusConflict, problem.CodeIdempotencyKeyInProgress, fmt.Sprintf("a request with the same %s is in progress", HeaderKey))
+	errBodyTooLarge  = problem.New(http.StatusRequestEntityTooLarge, problem.CodeRequestTooLarge, fmt.Sprintf("the body of a request with an %s must be at most %d bytes", HeaderKey, MaxBodySize))
+)
+
+// recorder copies the response body written by the handlers
+type recorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *recorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *recorder) WriteString(s string) (int, error) {
+	r.body.WriteString(s)
+	return r.ResponseWriter.WriteString(s)
+}
+
+// IdempotencyMiddleware applies the authenticated requests sending an Idempotency-Key
+// header at most once per author and key. The successful response is stored and replayed
+// to the repeats of the request for ttl, repeats with a different method, path or body are
+// rejected and repeats arriving while the first request runs are told to retry later.
+// Failed requests release their key so that they can be retried with it. A key whose
+// request did not store its response within lease, e.g. because the server stopped
+// meanwhile, can be used again; lease is usually the write timeout of the server, after
+// which the request cannot succeed anymore, and keys are held for ttl when it is not
+// positive.
+func IdempotencyMiddleware(store db.Store, ttl, lease time.Duration) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		key := ctx.GetHeader(HeaderKey)
+		if key == "" {
+			ctx.Next()
+			return
+		}
+		if len(key) > MaxKeyLength {
+			errorMiddleware.Abort(ctx, errKeyTooLong)
+			return
+		}
+
+		hash, err := requestHash(ctx.Writer, ctx.Request)
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			errorMiddleware.Abort(ctx, errBodyTooLarge)
+			return
+		}
+		if err != nil {
+			errorMiddleware.Abort(ctx, problem.New(http.StatusBadRequest, problem.CodeMalformedRequest, "cannot read the request body").WithCause(err))
+			return
+		}
+
+		now := time.Now()
+		arg := db.CreateIdempotencyKeyParams{
+			Author:             ctx.MustGet(authMiddleware.AuthorizationPayloadKey).(*tokenAuth.Payload).Username,
+			Key:                key,
+			RequestHash:        hash,
+			ExpiredBefore:      now.Add(-ttl),
+			LeaseExpiredBefore: now.Add(-ttl),
+		}
+		if lease > 0 {
+			arg.LeaseExpiredBefore = now.Add(-lease)
+		}
+		_, err = store.CreateIdempotencyKey(ctx.Request.Context(), arg)
+		if errors.Is(err, sql.ErrNoRows) {
+			// the key is held by a request made less than ttl ago
+			replay(ctx, store, db.GetIdempotencyKeyParams{Author: arg.Author, Key: key}, hash)
+			return
+		}
+		if err != nil {
+			errorMiddleware.Abort(ctx, err)
+			return
+		}
+
+		// the key outlives the request, which may be cancelled once the response is written
+		storeCtx := context.WithoutCancel(ctx.Request.Context())
+		rec := &recorder{ResponseWriter: ctx.Writer}
+		ctx.Writer = rec
+		saved := false
+		defer func() {
+			if !saved {
+				release(ctx, store, storeCtx, arg.Author, key)
+			}
+		}()
+
+		ctx.Next()
+		ctx.Writer = rec.ResponseWriter
+
+		status := rec.Status()
+		if !rec.Written() || status < http.StatusOK || status >= http.StatusMultipleChoices {
+			return
+		}
+
+		headers := make(map[string]string, len(storedHeaders))
+		for _, name := range storedHeaders {
+			if value := rec.Header().Get(name); value != "" {
+				headers[name] = value
+			}
+		}
+		encodedHeaders, err := json.Marshal(headers)
+		if err != nil {
+			loggingMiddleware.Logger(ctx).Error("cannot encode idempotent response headers", "error", err)
+			return
+		}
+
+		err = store.UpdateIdempotencyKey(storeCtx, db.UpdateIdempotencyKeyParams{
+			Author:          arg.Author,
+			Key:             key,
+			ResponseStatus:  int32(status),
+			ResponseHeaders: encodedHeaders,
+			ResponseBody:    rec.body.Bytes(),
+		})
+		if err != nil {
+			loggingMiddleware.Logger(ctx).Error("cannot store idempotent response", "error", err)
+			return
+		}
+		saved = true
+	}
+}
+
+// replay writes the response stored for a repeated request, or the reason it cannot be
+// replayed
+func replay(ctx *gin.Context, store db.Store, arg db.GetIdempotencyKeyParams, hash string) {
+	stored, err := store.GetIdempotencyKey(ctx.Request.Context(), arg)
+	if errors.Is(err, sql.ErrNoRows) {
+		// the first request failed and released the key in the meantime
+		errorMiddleware.Abort(ctx, errKeyInProgress)
+		return
+	}
+	if err != nil {
+		errorMiddleware.Abort(ctx, err)
+		return
+	}
+
+	if stored.RequestHash != hash {
+		errorMiddleware.Abort(ctx, errKeyReused)
+		return
+	}
+	if stored.ResponseStatus == 0 {
+		errorMiddleware.Abort(ctx, errKeyInProgress)
+		return
+	}
+
+	var headers map[string]string
+	if err := json.Unmarshal(stored.ResponseHeaders, &headers); err != nil {
+		errorMiddleware.Abort(ctx, fmt.Errorf("cannot decode idempotent response headers: %w", err))
+		return
+	}
+	for name, value := range headers {
+		ctx.Header(name, value)
+	}
+	ctx.Header(ReplayedHeaderKey, "true")
+	ctx.Data(int(stored.ResponseStatus), headers["Content-Type"], stored.ResponseBody)
+	ctx.Abort()
+}
+
+// release deletes the key of a request that did not succeed
+func release(ctx *gin.Context, store db.Store, storeCtx context.Context, author, key string) {
+	err := store.DeleteIdempotencyKey(storeCtx, db.DeleteIdempotencyKeyParams{Author: author, Key: key})
+	if err != nil {
+		loggingMiddleware.Logger(ctx).Error("cannot release idempotency key", "error", err)
+	}
+}
+
+// requestHash identifies a request by its method, path and body. The body, of at most
+// MaxBodySize bytes, is read and replaced with a copy for the handlers.
+func requestHash(w http.ResponseWriter, req *http.Request) (string, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(http.MaxBytesReader(w, req.Body, MaxBodySize))
+		if err != nil {
+			return "", err
+		}
+		_ = req.Body.Close()
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+
+	h := sha256.New()
+	fmt.Fprintf(h, "%s %s\n", req.Method, req.URL.RequestURI())
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,191 @@
+package idempotencyMiddleware_test
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	authMiddleware "github.com/gmaschi/go-recipes-book/internal/controllers/middlewares/auth"
+	errorMiddleware "github.com/gmaschi/go-recipes-book/internal/controllers/middlewares/errors"
+	idempotencyMiddleware "github.com/gmaschi/go-recipes-book/internal/controllers/middlewares/idempotency"
+	memoryStore "github.com/gmaschi/go-recipes-book/internal/mocks/datastore/memory"
+	db "github.com/gmaschi/go-recipes-book/internal/services/datastore/postgresql/recipes/sqlc"
+	"github.com/gmaschi/go-recipes-book/pkg/auth/tokenAuth"
+	"github.com/gmaschi/go-recipes-book/pkg/tools/problem"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const username = "author"
+
+// testServer counts the requests reaching the handler, which answers with the request body
+// or fails when the body is "fail"
+type testServer struct {
+	router *gin.Engine
+	store  *memoryStore.Store
+	calls  int
+}
+
+func newTestServer(t *testing.T, ttl, lease time.Duration) *testServer {
+	store := memoryStore.New()
+	_, err := store.CreateAuthor(context.Background(), db.CreateAuthorParams{Username: username, Email: "author@example.com"})
+	require.NoError(t, err)
+
+	s := &testServer{router: gin.New(), store: store}
+	s.router.Use(errorMiddleware.ErrorMiddleware(), func(ctx *gin.Context) {
+		ctx.Set(authMiddleware.AuthorizationPayloadKey, &tokenAuth.Payload{Username: username})
+	})
+	s.router.POST("/recipes", idempotencyMiddleware.IdempotencyMiddleware(store, ttl, lease), func(ctx *gin.Context) {
+		s.calls++
+		var body map[string]interface{}
+		if err := ctx.ShouldBindJSON(&body); err != nil {
+			errorMiddleware.AbortBind(ctx, err)
+			return
+		}
+		if body["fail"] == true {
+			errorMiddleware.Abort(ctx, problem.New(http.StatusBadRequest, problem.CodeInvalidRequest, "failed"))
+			return
+		}
+		body["call"] = s.calls
+		ctx.Header("Location", "/recipes/1")
+		ctx.JSON(http.StatusOK, body)
+	})
+	return s
+}
+
+func (s *testServer) post(t *testing.T, key, body string) *httptest.ResponseRecorder {
+	req, err := http.NewRequest(http.MethodPost, "/recipes", strings.NewReader(body))
+	require.NoError(t, err)
+	if key != "" {
+		req.Header.Set(idempotencyMiddleware.HeaderKey, key)
+	}
+
+	recorder := httptest.NewRecorder()
+	s.router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	t.Run("WithoutKey", func(t *testing.T) {
+		s := newTestServer(t, time.Hour, time.Minute)
+
+		require.Equal(t, http.StatusOK, s.post(t, "", `{}`).Code)
+		require.Equal(t, http.StatusOK, s.post(t, "", `{}`).Code)
+		require.Equal(t, 2, s.calls)
+	})
+
+	t.Run("Replay", func(t *testing.T) {
+		s := newTestServer(t, time.Hour, time.Minute)
+
+		first := s.post(t, "key", `{"name":"soup"}`)
+		require.Equal(t, http.StatusOK, first.Code)
+		require.Empty(t, first.Header().Get(idempotencyMiddleware.ReplayedHeaderKey))
+
+		repeat := s.post(t, "key", `{"name":"soup"}`)
+		require.Equal(t, http.StatusOK, repeat.Code)
+		require.Equal(t, "true", repeat.Header().Get(idempotencyMiddleware.ReplayedHeaderKey))
+		require.Equal(t, first.Header().Get("Content-Type"), repeat.Header().Get("Content-Type"))
+		require.Equal(t, "/recipes/1", repeat.Header().Get("Location"))
+		require.Equal(t, first.Body.String(), repeat.Body.String())
+		require.Equal(t, 1, s.calls)
+
+		require.Equal(t, http.StatusOK, s.post(t, "other", `{"name":"soup"}`).Code)
+		require.Equal(t, 2, s.calls)
+	})
+
+	t.Run("DifferentBody", func(t *testing.T) {
+		s := newTestServer(t, time.Hour, time.Minute)
+
+		require.Equal(t, http.StatusOK, s.post(t, "key", `{"name":"soup"}`).Code)
+
+		recorder := s.post(t, "key", `{"name":"salad"}`)
+		require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+		requireCode(t, recorder, problem.CodeIdempotencyKeyReused)
+		require.Equal(t, 1, s.calls)
+	})
+
+	t.Run("InProgress", func(t *testing.T) {
+		s := newTestServer(t, time.Hour, time.Minute)
+
+		first := s.post(t, "key", `{"name":"soup"}`)
+		require.Equal(t, http.StatusOK, first.Code)
+		// a key whose response is not stored yet
+		require.NoError(t, s.store.UpdateIdempotencyKey(context.Background(), db.UpdateIdempotencyKeyParams{Author: username, Key: "key"}))
+
+		recorder := s.post(t, "key", `{"name":"soup"}`)
+		require.Equal(t, http.StatusConflict, recorder.Code)
+		requireCode(t, recorder, problem.CodeIdempotencyKeyInProgress)
+		require.Equal(t, 1, s.calls)
+	})
+
+	t.Run("LeaseExpired", func(t *testing.T) {
+		s := newTestServer(t, time.Hour, time.Nanosecond)
+
+		require.Equal(t, http.StatusOK, s.post(t, "key", `{"name":"soup"}`).Code)
+		// the request holding the key stopped before storing its response
+		require.NoError(t, s.store.UpdateIdempotencyKey(context.Background(), db.UpdateIdempotencyKeyParams{Author: username, Key: "key"}))
+		time.Sleep(time.Millisecond)
+
+		recorder := s.post(t, "key", `{"name":"soup"}`)
+		require.Equal(t, http.StatusOK, recorder.Code)
+		require.Empty(t, recorder.Header().Get(idempotencyMiddleware.ReplayedHeaderKey))
+		require.Equal(t, 2, s.calls)
+
+		// the stored response is replayed whatever the lease
+		time.Sleep(time.Millisecond)
+		recorder = s.post(t, "key", `{"name":"soup"}`)
+		require.Equal(t, "true", recorder.Header().Get(idempotencyMiddleware.ReplayedHeaderKey))
+		require.Equal(t, 2, s.calls)
+	})
+
+	t.Run("BodyTooLarge", func(t *testing.T) {
+		s := newTestServer(t, time.Hour, time.Minute)
+
+		body := `{"name":"` + strings.Repeat("a", idempotencyMiddleware.MaxBodySize) + `"}`
+		recorder := s.post(t, "key", body)
+		require.Equal(t, http.StatusRequestEntityTooLarge, recorder.Code)
+		requireCode(t, recorder, problem.CodeRequestTooLarge)
+		require.Zero(t, s.calls)
+	})
+
+	t.Run("FailureReleasesKey", func(t *testing.T) {
+		s := newTestServer(t, time.Hour, time.Minute)
+
+		require.Equal(t, http.StatusBadRequest, s.post(t, "key", `{"fail":true}`).Code)
+		require.Equal(t, http.StatusBadRequest, s.post(t, "key", `{"fail":true}`).Code)
+		require.Equal(t, 2, s.calls)
+
+		_, err := s.store.GetIdempotencyKey(context.Background(), db.GetIdempotencyKeyParams{Author: username, Key: "key"})
+		require.Error(t, err)
+	})
+
+	t.Run("Expired", func(t *testing.T) {
+		s := newTestServer(t, time.Nanosecond, time.Nanosecond)
+
+		require.Equal(t, http.StatusOK, s.post(t, "key", `{"name":"soup"}`).Code)
+		time.Sleep(time.Millisecond)
+
+		recorder := s.post(t, "key", `{"name":"salad"}`)
+		require.Equal(t, http.StatusOK, recorder.Code)
+		require.Empty(t, recorder.Header().Get(idempotencyMiddleware.ReplayedHeaderKey))
+		require.Equal(t, 2, s.calls)
+	})
+
+	t.Run("KeyTooLong", func(t *testing.T) {
+		s := newTestServer(t, time.Hour, time.Minute)
+
+		recorder := s.post(t, strings.Repeat("k", idempotencyMiddleware.MaxKeyLength+1), `{}`)
+		require.Equal(t, http.StatusBadRequest, recorder.Code)
+		requireCode(t, recorder, problem.CodeIdempotencyKeyInvalid)
+		require.Zero(t, s.calls)
+	})
+}
+
+func requireCode(t *testing.T, recorder *httptest.ResponseRecorder, code string) {
+	var p problem.Problem
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &p))
+	require.Equal(t, code, p.Code)
+}
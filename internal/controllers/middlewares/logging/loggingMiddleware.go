@@ -0,0 +1,101 @@
+package loggingMiddleware
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/gmaschi/go-recipes-book/pkg/logger"
+	"github.com/google/uuid"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"time"
+)
+
+const (
+	RequestIDHeaderKey = "X-Request-ID"
+	RequestIDKey       = "request_id"
+)
+
+var requestIDRegex = regexp.MustCompile(`^[A-Za-z0-9._-]{1,128}$`)
+
+// RequestIDMiddleware propagates the X-Request-ID header of the request, or generates one,
+// echoes it in the response and attaches a logger carrying it to the request context
+func RequestIDMiddleware(log *slog.Logger) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		requestID := ctx.GetHeader(RequestIDHeaderKey)
+		if !ValidRequestID(requestID) {
+			requestID = uuid.New().String()
+		}
+
+		ctx.Set(RequestIDKey, requestID)
+		ctx.Header(RequestIDHeaderKey, requestID)
+		SetLogger(ctx, log.With(RequestIDKey, requestID))
+
+		ctx.Next()
+	}
+}
+
+// ValidRequestID reports whether a request ID sent by a client can be propagated
+func ValidRequestID(requestID string) bool {
+	return requestIDRegex.MatchString(requestID)
+}
+
+// LoggerMiddleware logs every request once it has been handled, along with the errors
+// attached to the context by the handlers
+func LoggerMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		start := time.Now()
+		ctx.Next()
+
+		status := ctx.Writer.Status()
+		level := slog.LevelInfo
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= http.StatusBadRequest:
+			level = slog.LevelWarn
+		}
+
+		route := ctx.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+
+		attrs := []slog.Attr{
+			slog.String("method", ctx.Request.Method),
+			slog.String("route", route),
+			slog.String("path", ctx.Request.URL.Path),
+			slog.Int("status", status),
+			slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.String("client_ip", ctx.ClientIP()),
+			slog.Int("response_size", ctx.Writer.Size()),
+		}
+		if len(ctx.Errors) > 0 {
+			attrs = append(attrs, slog.Any("errors", ctx.Errors.Errors()))
+		}
+
+		Logger(ctx).LogAttrs(ctx.Request.Context(), level, "request handled", attrs...)
+	}
+}
+
+// RecoveryMiddleware logs panics of the handlers and records them as internal server errors,
+// leaving the response body to the error middleware
+func RecoveryMiddleware() gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(nil, func(ctx *gin.Context, recovered interface{}) {
+		Logger(ctx).Error("panic recovered", "panic", recovered)
+		_ = ctx.Error(fmt.Errorf("panic: %v", recovered))
+		ctx.Status(http.StatusInternalServerError)
+		ctx.Abort()
+	})
+}
+
+// Logger returns the logger of the request
+func Logger(ctx *gin.Context) *slog.Logger {
+	return logger.FromContext(ctx.Request.Context())
+}
+
+// SetLogger replaces the logger of the request, e.g. to add attributes known once the
+// request is authenticated
+func SetLogger(ctx *gin.Context, log *slog.Logger) {
+	ctx.Request = ctx.Request.WithContext(logger.WithContext(ctx.Request.Context(), log))
+}
@@ -0,0 +1,160 @@
+package loggingMiddleware_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	authMiddleware "github.com/gmaschi/go-recipes-book/internal/controllers/middlewares/auth"
+	errorMiddleware "github.com/gmaschi/go-recipes-book/internal/controllers/middlewares/errors"
+	loggingMiddleware "github.com/gmaschi/go-recipes-book/internal/controllers/middlewares/logging"
+	memoryStore "github.com/gmaschi/go-recipes-book/internal/mocks/datastore/memory"
+	db "github.com/gmaschi/go-recipes-book/internal/services/datastore/postgresql/recipes/sqlc"
+	pasetoToken "github.com/gmaschi/go-recipes-book/pkg/auth/tokenAuth/paseto"
+	"github.com/gmaschi/go-recipes-book/pkg/tools/random"
+	"github.com/stretchr/testify/require"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newTestRouter(buf *bytes.Buffer) *gin.Engine {
+	log := slog.New(slog.NewJSONHandler(buf, nil))
+
+	router := gin.New()
+	router.Use(
+		loggingMiddleware.RequestIDMiddleware(log),
+		loggingMiddleware.LoggerMiddleware(),
+		loggingMiddleware.RecoveryMiddleware(),
+	)
+	return router
+}
+
+func logLine(t *testing.T, buf *bytes.Buffer) map[string]interface{} {
+	var line map[string]interface{}
+	err := json.Unmarshal(buf.Bytes(), &line)
+	require.NoError(t, err)
+	return line
+}
+
+func TestRequestID(t *testing.T) {
+	testCases := []struct {
+		name      string
+		requestID string
+		generated bool
+	}{
+		{name: "Generated", requestID: "", generated: true},
+		{name: "Propagated", requestID: "abc-123.def_456"},
+		{name: "Invalid", requestID: "not valid!", generated: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			router := newTestRouter(&buf)
+			router.GET("/ok", func(ctx *gin.Context) {
+				ctx.JSON(http.StatusOK, gin.H{})
+			})
+
+			recorder := httptest.NewRecorder()
+			req, err := http.NewRequest(http.MethodGet, "/ok", nil)
+			require.NoError(t, err)
+			if tc.requestID != "" {
+				req.Header.Set(loggingMiddleware.RequestIDHeaderKey, tc.requestID)
+			}
+
+			router.ServeHTTP(recorder, req)
+			require.Equal(t, http.StatusOK, recorder.Code)
+
+			requestID := recorder.Header().Get(loggingMiddleware.RequestIDHeaderKey)
+			if tc.generated {
+				require.Len(t, requestID, 36)
+			} else {
+				require.Equal(t, tc.requestID, requestID)
+			}
+
+			line := logLine(t, &buf)
+			require.Equal(t, requestID, line[loggingMiddleware.RequestIDKey])
+			require.Equal(t, "INFO", line["level"])
+			require.Equal(t, "/ok", line["route"])
+			require.Equal(t, float64(http.StatusOK), line["status"])
+		})
+	}
+}
+
+func TestLoggerMiddleware(t *testing.T) {
+	t.Run("Authenticated username and errors", func(t *testing.T) {
+		maker, err := pasetoToken.NewPasetoMaker(random.String(32))
+		require.NoError(t, err)
+
+		store := memoryStore.New()
+		_, err = store.CreateAuthor(context.Background(), db.CreateAuthorParams{Username: "alice", HashedPassword: "hashed", Email: random.Email()})
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+		router := newTestRouter(&buf)
+		router.GET("/fail/:id", authMiddleware.AuthMiddleware(maker, store), func(ctx *gin.Context) {
+			errorMiddleware.Abort(ctx, errors.New("query failed"))
+		})
+
+		token, err := maker.CreateToken("alice", time.Minute)
+		require.NoError(t, err)
+
+		recorder := httptest.NewRecorder()
+		req, err := http.NewRequest(http.MethodGet, "/fail/1", nil)
+		require.NoError(t, err)
+		req.Header.Set(authMiddleware.AuthorizationHeaderKey, fmt.Sprintf("%s %s", authMiddleware.AuthorizationTypeBearer, token))
+
+		router.ServeHTTP(recorder, req)
+		require.Equal(t, http.StatusInternalServerError, recorder.Code)
+
+		line := logLine(t, &buf)
+		require.Equal(t, "ERROR", line["level"])
+		require.Equal(t, "alice", line["username"])
+		require.Equal(t, "/fail/:id", line["route"])
+		require.Equal(t, "/fail/1", line["path"])
+		require.Equal(t, []interface{}{"query failed"}, line["errors"])
+	})
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		maker, err := pasetoToken.NewPasetoMaker(random.String(32))
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+		router := newTestRouter(&buf)
+		router.GET("/private", authMiddleware.AuthMiddleware(maker, memoryStore.New()), func(ctx *gin.Context) {})
+
+		recorder := httptest.NewRecorder()
+		req, err := http.NewRequest(http.MethodGet, "/private", nil)
+		require.NoError(t, err)
+
+		router.ServeHTTP(recorder, req)
+		require.Equal(t, http.StatusUnauthorized, recorder.Code)
+
+		line := logLine(t, &buf)
+		require.Equal(t, "WARN", line["level"])
+		require.NotContains(t, line, "username")
+		require.Equal(t, []interface{}{"authorization not provided"}, line["errors"])
+	})
+
+	t.Run("Panic", func(t *testing.T) {
+		var buf bytes.Buffer
+		router := newTestRouter(&buf)
+		router.GET("/panic", func(ctx *gin.Context) {
+			panic("boom")
+		})
+
+		recorder := httptest.NewRecorder()
+		req, err := http.NewRequest(http.MethodGet, "/panic", nil)
+		require.NoError(t, err)
+
+		router.ServeHTTP(recorder, req)
+		require.Equal(t, http.StatusInternalServerError, recorder.Code)
+		require.Contains(t, buf.String(), `"panic":"boom"`)
+		require.Contains(t, buf.String(), `"status":500`)
+	})
+}
@@ -0,0 +1,25 @@
+package metricsMiddleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/gmaschi/go-recipes-book/internal/services/metrics"
+	"time"
+)
+
+// unmatchedRoute labels the requests that did not match any route, so that arbitrary
+// paths do not create new series
+const unmatchedRoute = "unmatched"
+
+// MetricsMiddleware records the count and duration of the requests by route template
+func MetricsMiddleware(m *metrics.Metrics) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		start := time.Now()
+		ctx.Next()
+
+		route := ctx.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		m.ObserveRequest(ctx.Request.Method, route, ctx.Writer.Status(), time.Since(start))
+	}
+}
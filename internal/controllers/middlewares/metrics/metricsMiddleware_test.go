@@ -0,0 +1,86 @@
+package metricsMiddleware_test
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	bookRecipeFactory "github.com/gmaschi/go-recipes-book/internal/factories/book-recipe-factory"
+	mockedstore "github.com/gmaschi/go-recipes-book/internal/mocks/datastore/postgresql/recipes"
+	db "github.com/gmaschi/go-recipes-book/internal/services/datastore/postgresql/recipes/sqlc"
+	"github.com/gmaschi/go-recipes-book/pkg/config/env"
+	"github.com/gmaschi/go-recipes-book/pkg/tools/password"
+	"github.com/gmaschi/go-recipes-book/pkg/tools/random"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestMetrics(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	randomPassword := random.String(8)
+	hashedPassword, err := password.HashPassword(randomPassword)
+	require.NoError(t, err)
+	author := db.Author{
+		Username:       random.String(10),
+		Email:          random.Email(),
+		HashedPassword: hashedPassword,
+	}
+
+	store := mockedstore.NewMockStore(ctrl)
+	store.EXPECT().GetAuthor(gomock.Any(), gomock.Eq(author.Username)).Times(2).Return(author, nil)
+	store.EXPECT().GetAuthor(gomock.Any(), gomock.Any()).Times(1).Return(db.Author{}, sql.ErrConnDone)
+	store.EXPECT().Stats().AnyTimes().Return(sql.DBStats{MaxOpenConnections: 25, InUse: 2})
+
+	config := env.Config{
+		Auth: env.AuthConfig{
+			TokenSymmetricKey: random.String(32),
+			TokenDuration:     time.Minute,
+		},
+	}
+	server, err := bookRecipeFactory.New(config, store)
+	require.NoError(t, err)
+
+	serve := func(method, url string, body interface{}) int {
+		var data []byte
+		if body != nil {
+			data, err = json.Marshal(body)
+			require.NoError(t, err)
+		}
+		req, err := http.NewRequest(method, url, bytes.NewReader(data))
+		require.NoError(t, err)
+
+		recorder := httptest.NewRecorder()
+		server.Router.ServeHTTP(recorder, req)
+		return recorder.Code
+	}
+
+	require.Equal(t, http.StatusOK, serve(http.MethodGet, "/authors/"+author.Username, nil))
+	require.Equal(t, http.StatusInternalServerError, serve(http.MethodGet, "/authors/unknown", nil))
+	require.Equal(t, http.StatusOK, serve(http.MethodPost, "/authors/login", map[string]interface{}{
+		"username": author.Username,
+		"password": randomPassword,
+	}))
+	require.Equal(t, http.StatusNotFound, serve(http.MethodGet, "/does/not/exist", nil))
+
+	req, err := http.NewRequest(http.MethodGet, "/metrics", nil)
+	require.NoError(t, err)
+	recorder := httptest.NewRecorder()
+	server.Router.ServeHTTP(recorder, req)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	body := recorder.Body.String()
+	require.Contains(t, body, `recipes_book_http_requests_total{method="GET",route="/authors/:username",status="200"} 1`)
+	require.Contains(t, body, `recipes_book_http_requests_total{method="GET",route="/authors/:username",status="500"} 1`)
+	require.Contains(t, body, `recipes_book_http_requests_total{method="GET",route="unmatched",status="404"} 1`)
+	require.Contains(t, body, `recipes_book_http_request_duration_seconds_count{method="POST",route="/authors/login",status="200"} 1`)
+	require.Contains(t, body, `recipes_book_db_query_duration_seconds_count{outcome="success",query="GetAuthor"} 2`)
+	require.Contains(t, body, `recipes_book_db_query_duration_seconds_count{outcome="error",query="GetAuthor"} 1`)
+	require.Contains(t, body, `recipes_book_auth_logins_total{result="success"} 1`)
+	require.Contains(t, body, `recipes_book_db_max_open_connections 25`)
+	require.Contains(t, body, `recipes_book_db_in_use_connections 2`)
+}
@@ -0,0 +1,94 @@
+package rateLimitMiddleware
+
+import (
+	"github.com/gin-gonic/gin"
+	authMiddleware "github.com/gmaschi/go-recipes-book/internal/controllers/middlewares/auth"
+	errorMiddleware "github.com/gmaschi/go-recipes-book/internal/controllers/middlewares/errors"
+	loggingMiddleware "github.com/gmaschi/go-recipes-book/internal/controllers/middlewares/logging"
+	"github.com/gmaschi/go-recipes-book/internal/services/ratelimit"
+	"github.com/gmaschi/go-recipes-book/pkg/auth/tokenAuth"
+	"github.com/gmaschi/go-recipes-book/pkg/tools/problem"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// The headers describing the limit of the route to the client
+const (
+	LimitHeaderKey      = "RateLimit-Limit"
+	RemainingHeaderKey  = "RateLimit-Remaining"
+	ResetHeaderKey      = "RateLimit-Reset"
+	PolicyHeaderKey     = "RateLimit-Policy"
+	RetryAfterHeaderKey = "Retry-After"
+)
+
+// RateLimitMiddleware limits the requests each client sends to the routes of limits, keyed
+// by ratelimit.RouteKey. Clients are identified by username once authenticated, so it must
+// come after the authentication middleware on the authenticated routes, and by IP address
+// otherwise. Requests are let through when the backend fails.
+func RateLimitMiddleware(backend ratelimit.Backend, limits map[string]ratelimit.Limit) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if err := Charge(ctx, backend, limits, ratelimit.RouteKey(ctx.Request.Method, ctx.FullPath())); err != nil {
+			errorMiddleware.Abort(ctx, err)
+			return
+		}
+		ctx.Next()
+	}
+}
+
+// Charge takes a token from the bucket of the client of ctx on route, when limits has one
+// for it, and sets the headers describing the limit. It returns the problem to report when
+// the client has no token left. Backend failures are logged and let the request through.
+// The GraphQL mutations are charged to the route of the REST request they mirror with it.
+func Charge(ctx *gin.Context, backend ratelimit.Backend, limits map[string]ratelimit.Limit, route string) error {
+	limit, ok := limits[route]
+	if !ok {
+		return nil
+	}
+
+	res, err := backend.Take(ctx.Request.Context(), route+" "+client(ctx), limit)
+	if err != nil {
+		loggingMiddleware.Logger(ctx).Error("cannot apply rate limit", "route", route, "error", err)
+		return nil
+	}
+
+	ctx.Header(LimitHeaderKey, strconv.Itoa(res.Limit))
+	ctx.Header(RemainingHeaderKey, strconv.Itoa(res.Remaining))
+	ctx.Header(ResetHeaderKey, Seconds(res.Reset))
+	ctx.Header(PolicyHeaderKey, limit.Policy())
+	if !res.Allowed {
+		ctx.Header(RetryAfterHeaderKey, Seconds(res.RetryAfter))
+		return Exceeded(res)
+	}
+	return nil
+}
+
+// Exceeded is the problem reporting that a client has no token left in a bucket
+func Exceeded(res ratelimit.Result) *problem.Error {
+	return problem.New(http.StatusTooManyRequests, problem.CodeRateLimited, "too many requests, retry in "+Seconds(res.RetryAfter)+" second(s)")
+}
+
+// Client identifies a client in the keys of the buckets, by username when it is
+// authenticated and by IP address otherwise, so that it has the same buckets in every API
+func Client(username, ip string) string {
+	if username != "" {
+		return "user:" + username
+	}
+	return "ip:" + ip
+}
+
+// client identifies the client of the request by username, or by IP address when it is
+// not authenticated
+func client(ctx *gin.Context) string {
+	if value, ok := ctx.Get(authMiddleware.AuthorizationPayloadKey); ok {
+		return Client(value.(*tokenAuth.Payload).Username, "")
+	}
+	return Client("", ctx.ClientIP())
+}
+
+// Seconds formats d as a number of seconds, rounded up, as the headers describing the
+// limits write it
+func Seconds(d time.Duration) string {
+	return strconv.FormatInt(int64(math.Ceil(d.Seconds())), 10)
+}
@@ -0,0 +1,113 @@
+package rateLimitMiddleware_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/gin-gonic/gin"
+	authMiddleware "github.com/gmaschi/go-recipes-book/internal/controllers/middlewares/auth"
+	errorMiddleware "github.com/gmaschi/go-recipes-book/internal/controllers/middlewares/errors"
+	rateLimitMiddleware "github.com/gmaschi/go-recipes-book/internal/controllers/middlewares/ratelimit"
+	"github.com/gmaschi/go-recipes-book/internal/services/ratelimit"
+	"github.com/gmaschi/go-recipes-book/pkg/auth/tokenAuth"
+	"github.com/gmaschi/go-recipes-book/pkg/tools/problem"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// failingBackend fails every request for a token
+type failingBackend struct{}
+
+func (failingBackend) Take(context.Context, string, ratelimit.Limit) (ratelimit.Result, error) {
+	return ratelimit.Result{}, errors.New("backend unavailable")
+}
+
+func newRouter(backend ratelimit.Backend) *gin.Engine {
+	limits := map[string]ratelimit.Limit{
+		ratelimit.RouteKey(http.MethodPost, "/login"):      {Requests: 2, Period: time.Minute},
+		ratelimit.RouteKey(http.MethodGet, "/recipes/:id"): {Requests: 1, Period: time.Hour},
+	}
+	limit := rateLimitMiddleware.RateLimitMiddleware(backend, limits)
+	authenticate := func(ctx *gin.Context) {
+		if username := ctx.GetHeader("X-Username"); username != "" {
+			ctx.Set(authMiddleware.AuthorizationPayloadKey, &tokenAuth.Payload{Username: username})
+		}
+	}
+	ok := func(ctx *gin.Context) {
+		ctx.Status(http.StatusOK)
+	}
+
+	router := gin.New()
+	router.Use(errorMiddleware.ErrorMiddleware())
+	router.POST("/login", limit, ok)
+	router.GET("/recipes/:id", authenticate, limit, ok)
+	router.GET("/recipes", limit, ok)
+	return router
+}
+
+func send(router *gin.Engine, method, path, ip, username string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	req.RemoteAddr = ip + ":1234"
+	if username != "" {
+		req.Header.Set("X-Username", username)
+	}
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	t.Run("ByIP", func(t *testing.T) {
+		router := newRouter(ratelimit.NewMemoryBackend())
+
+		recorder := send(router, http.MethodPost, "/login", "10.0.0.1", "")
+		require.Equal(t, http.StatusOK, recorder.Code)
+		require.Equal(t, "2", recorder.Header().Get(rateLimitMiddleware.LimitHeaderKey))
+		require.Equal(t, "1", recorder.Header().Get(rateLimitMiddleware.RemainingHeaderKey))
+		require.Equal(t, "30", recorder.Header().Get(rateLimitMiddleware.ResetHeaderKey))
+		require.Equal(t, "2;w=60", recorder.Header().Get(rateLimitMiddleware.PolicyHeaderKey))
+		require.Empty(t, recorder.Header().Get(rateLimitMiddleware.RetryAfterHeaderKey))
+
+		require.Equal(t, http.StatusOK, send(router, http.MethodPost, "/login", "10.0.0.1", "").Code)
+
+		recorder = send(router, http.MethodPost, "/login", "10.0.0.1", "")
+		require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+		require.Equal(t, "0", recorder.Header().Get(rateLimitMiddleware.RemainingHeaderKey))
+		require.Equal(t, "30", recorder.Header().Get(rateLimitMiddleware.RetryAfterHeaderKey))
+		var p problem.Problem
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &p))
+		require.Equal(t, problem.CodeRateLimited, p.Code)
+
+		require.Equal(t, http.StatusOK, send(router, http.MethodPost, "/login", "10.0.0.2", "").Code)
+	})
+
+	t.Run("ByUsername", func(t *testing.T) {
+		router := newRouter(ratelimit.NewMemoryBackend())
+
+		require.Equal(t, http.StatusOK, send(router, http.MethodGet, "/recipes/1", "10.0.0.1", "alice").Code)
+		require.Equal(t, http.StatusTooManyRequests, send(router, http.MethodGet, "/recipes/2", "10.0.0.2", "alice").Code)
+		require.Equal(t, http.StatusOK, send(router, http.MethodGet, "/recipes/1", "10.0.0.1", "bob").Code)
+	})
+
+	t.Run("Unlimited", func(t *testing.T) {
+		router := newRouter(ratelimit.NewMemoryBackend())
+
+		for i := 0; i < 5; i++ {
+			recorder := send(router, http.MethodGet, "/recipes", "10.0.0.1", "")
+			require.Equal(t, http.StatusOK, recorder.Code)
+			require.Empty(t, recorder.Header().Get(rateLimitMiddleware.LimitHeaderKey))
+		}
+	})
+
+	t.Run("BackendFailure", func(t *testing.T) {
+		router := newRouter(failingBackend{})
+
+		recorder := send(router, http.MethodPost, "/login", "10.0.0.1", "")
+		require.Equal(t, http.StatusOK, recorder.Code)
+		require.Empty(t, recorder.Header().Get(rateLimitMiddleware.LimitHeaderKey))
+	})
+}
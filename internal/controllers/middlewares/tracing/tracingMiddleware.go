@@ -0,0 +1,60 @@
+package tracingMiddleware
+
+import (
+	"github.com/gin-gonic/gin"
+	loggingMiddleware "github.com/gmaschi/go-recipes-book/internal/controllers/middlewares/logging"
+	"github.com/gmaschi/go-recipes-book/internal/services/tracing"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+	"net/http"
+)
+
+// TracingMiddleware starts a server span for each request, continuing the trace context of
+// the incoming headers. The span is carried by the request context, so handlers must hand
+// ctx.Request.Context() to the layers they call for their spans to be nested.
+func TracingMiddleware(provider trace.TracerProvider, propagator propagation.TextMapPropagator) gin.HandlerFunc {
+	tracer := provider.Tracer(tracing.InstrumentationName)
+
+	return func(ctx *gin.Context) {
+		parent := propagator.Extract(ctx.Request.Context(), propagation.HeaderCarrier(ctx.Request.Header))
+
+		route := ctx.FullPath()
+		name := ctx.Request.Method
+		if route != "" {
+			name += " " + route
+		}
+
+		spanCtx, span := tracer.Start(parent, name,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(ctx.Request.Method),
+				semconv.HTTPRoute(route),
+				semconv.URLPath(ctx.Request.URL.Path),
+				semconv.ClientAddress(ctx.ClientIP()),
+			),
+		)
+		defer span.End()
+
+		ctx.Request = ctx.Request.WithContext(spanCtx)
+		if sc := span.SpanContext(); sc.IsValid() {
+			loggingMiddleware.SetLogger(ctx, loggingMiddleware.Logger(ctx).With("trace_id", sc.TraceID().String()))
+		}
+
+		ctx.Next()
+
+		status := ctx.Writer.Status()
+		span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+		if status >= http.StatusInternalServerError {
+			description := http.StatusText(status)
+			if len(ctx.Errors) > 0 {
+				description = ctx.Errors.String()
+			}
+			span.SetStatus(codes.Error, description)
+		}
+		for _, err := range ctx.Errors {
+			span.RecordError(err.Err)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package tracingMiddleware_test
+
+import (
+	"context"
+	"database/sql"
+	bookRecipeFactory "github.com/gmaschi/go-recipes-book/internal/factories/book-recipe-factory"
+	mockedstore "github.com/gmaschi/go-recipes-book/internal/mocks/datastore/postgresql/recipes"
+	instrumentedStore "github.com/gmaschi/go-recipes-book/internal/services/datastore/postgresql/recipes/instrumented"
+	db "github.com/gmaschi/go-recipes-book/internal/services/datastore/postgresql/recipes/sqlc"
+	"github.com/gmaschi/go-recipes-book/internal/services/tracing"
+	"github.com/gmaschi/go-recipes-book/pkg/config/env"
+	"github.com/gmaschi/go-recipes-book/pkg/tools/random"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+const (
+	parentTraceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	parentSpanID  = "00f067aa0ba902b7"
+)
+
+func attributeValue(span tracetest.SpanStub, key attribute.Key) (attribute.Value, bool) {
+	for _, kv := range span.Attributes {
+		if kv.Key == key {
+			return kv.Value, true
+		}
+	}
+	return attribute.Value{}, false
+}
+
+func TestTracing(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := tracing.NewWithExporter(env.TracingConfig{SampleRatio: 1}, exporter)
+	defer provider.Shutdown(context.Background())
+
+	previousProvider, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	tracing.SetGlobal(provider)
+	defer func() {
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	}()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	author := db.Author{
+		Username: random.String(10),
+		Email:    random.Email(),
+	}
+	store := mockedstore.NewMockStore(ctrl)
+	store.EXPECT().GetAuthor(gomock.Any(), gomock.Eq(author.Username)).Times(1).Return(author, nil)
+	store.EXPECT().GetAuthor(gomock.Any(), gomock.Any()).Times(1).Return(db.Author{}, sql.ErrConnDone)
+	store.EXPECT().Stats().AnyTimes().Return(sql.DBStats{})
+
+	server, err := bookRecipeFactory.New(env.Config{
+		Auth: env.AuthConfig{
+			TokenSymmetricKey: random.String(32),
+			TokenDuration:     time.Minute,
+		},
+	}, store)
+	require.NoError(t, err)
+
+	t.Run("Propagated trace context", func(t *testing.T) {
+		exporter.Reset()
+
+		req, err := http.NewRequest(http.MethodGet, "/authors/"+author.Username, nil)
+		require.NoError(t, err)
+		req.Header.Set("traceparent", "00-"+parentTraceID+"-"+parentSpanID+"-01")
+
+		recorder := httptest.NewRecorder()
+		server.Router.ServeHTTP(recorder, req)
+		require.Equal(t, http.StatusOK, recorder.Code)
+
+		spans := exporter.GetSpans()
+		require.Len(t, spans, 2)
+		dbSpan, requestSpan := spans[0], spans[1]
+
+		require.Equal(t, "GET /authors/:username", requestSpan.Name)
+		require.Equal(t, trace.SpanKindServer, requestSpan.SpanKind)
+		require.Equal(t, parentTraceID, requestSpan.SpanContext.TraceID().String())
+		require.Equal(t, parentSpanID, requestSpan.Parent.SpanID().String())
+		require.True(t, requestSpan.Parent.IsRemote())
+		status, ok := attributeValue(requestSpan, semconv.HTTPResponseStatusCodeKey)
+		require.True(t, ok)
+		require.EqualValues(t, http.StatusOK, status.AsInt64())
+
+		require.Equal(t, "GetAuthor", dbSpan.Name)
+		require.Equal(t, trace.SpanKindClient, dbSpan.SpanKind)
+		require.Equal(t, requestSpan.SpanContext.SpanID(), dbSpan.Parent.SpanID())
+		require.Equal(t, parentTraceID, dbSpan.SpanContext.TraceID().String())
+		query, ok := attributeValue(dbSpan, instrumentedStore.QueryKey)
+		require.True(t, ok)
+		require.Equal(t, "GetAuthor", query.AsString())
+		rows, ok := attributeValue(dbSpan, instrumentedStore.RowsKey)
+		require.True(t, ok)
+		require.EqualValues(t, 1, rows.AsInt64())
+	})
+
+	t.Run("Failing query", func(t *testing.T) {
+		exporter.Reset()
+
+		req, err := http.NewRequest(http.MethodGet, "/authors/unknown", nil)
+		require.NoError(t, err)
+
+		recorder := httptest.NewRecorder()
+		server.Router.ServeHTTP(recorder, req)
+		require.Equal(t, http.StatusInternalServerError, recorder.Code)
+
+		spans := exporter.GetSpans()
+		require.Len(t, spans, 2)
+		dbSpan, requestSpan := spans[0], spans[1]
+
+		require.False(t, requestSpan.Parent.IsValid())
+		require.Equal(t, codes.Error, requestSpan.Status.Code)
+		require.Equal(t, codes.Error, dbSpan.Status.Code)
+		require.Equal(t, requestSpan.SpanContext.TraceID(), dbSpan.SpanContext.TraceID())
+	})
+
+	t.Run("Unmatched route", func(t *testing.T) {
+		exporter.Reset()
+
+		req, err := http.NewRequest(http.MethodGet, "/does/not/exist", nil)
+		require.NoError(t, err)
+
+		recorder := httptest.NewRecorder()
+		server.Router.ServeHTTP(recorder, req)
+		require.Equal(t, http.StatusNotFound, recorder.Code)
+
+		spans := exporter.GetSpans()
+		require.Len(t, spans, 1)
+		require.Equal(t, http.MethodGet, spans[0].Name)
+		require.Equal(t, codes.Unset, spans[0].Status.Code)
+	})
+}
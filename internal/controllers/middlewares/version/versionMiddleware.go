@@ -0,0 +1,54 @@
+package versionMiddleware
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	errorMiddleware "github.com/gmaschi/go-recipes-book/internal/controllers/middlewares/errors"
+	"github.com/gmaschi/go-recipes-book/pkg/tools/problem"
+	"net/http"
+	"strconv"
+)
+
+// HeaderKey is the request header selecting the version of the API, echoed in the response
+const HeaderKey = "API-Version"
+
+// The versions of the API. Requests without a version header get V1.
+const (
+	// V1 lists with bare JSON arrays
+	V1 = 1
+	// V2 lists with an envelope holding the items and the pagination metadata
+	V2 = 2
+
+	Latest = V2
+)
+
+// versionKey is the context key of the requested version
+const versionKey = "api_version"
+
+// VersionMiddleware reads the version requested with the API-Version header, rejecting the
+// versions that do not exist
+func VersionMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		version := V1
+		if header := ctx.GetHeader(HeaderKey); header != "" {
+			v, err := strconv.Atoi(header)
+			if err != nil || v < V1 || v > Latest {
+				errorMiddleware.Abort(ctx, problem.New(http.StatusBadRequest, problem.CodeUnsupportedVersion, fmt.Sprintf("%s must be between %d and %d", HeaderKey, V1, Latest)))
+				return
+			}
+			version = v
+		}
+
+		ctx.Set(versionKey, version)
+		ctx.Header(HeaderKey, strconv.Itoa(version))
+		ctx.Next()
+	}
+}
+
+// Version returns the version requested, V1 when the middleware did not run
+func Version(ctx *gin.Context) int {
+	if version, ok := ctx.Get(versionKey); ok {
+		return version.(int)
+	}
+	return V1
+}
@@ -0,0 +1,81 @@
+package versionMiddleware_test
+
+import (
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	errorMiddleware "github.com/gmaschi/go-recipes-book/internal/controllers/middlewares/errors"
+	versionMiddleware "github.com/gmaschi/go-recipes-book/internal/controllers/middlewares/version"
+	"github.com/gmaschi/go-recipes-book/pkg/tools/problem"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestVersionMiddleware(t *testing.T) {
+	testCases := []struct {
+		name          string
+		header        string
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Default",
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, "1", recorder.Header().Get(versionMiddleware.HeaderKey))
+				require.Equal(t, "1", recorder.Body.String())
+			},
+		},
+		{
+			name:   "Latest",
+			header: "2",
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, "2", recorder.Header().Get(versionMiddleware.HeaderKey))
+				require.Equal(t, "2", recorder.Body.String())
+			},
+		},
+		{
+			name:   "Unknown",
+			header: "3",
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				requireUnsupported(t, recorder)
+			},
+		},
+		{
+			name:   "NotANumber",
+			header: "v2",
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				requireUnsupported(t, recorder)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(errorMiddleware.ErrorMiddleware(), versionMiddleware.VersionMiddleware())
+			router.GET("/version", func(ctx *gin.Context) {
+				ctx.JSON(http.StatusOK, versionMiddleware.Version(ctx))
+			})
+
+			req, err := http.NewRequest(http.MethodGet, "/version", nil)
+			require.NoError(t, err)
+			if tc.header != "" {
+				req.Header.Set(versionMiddleware.HeaderKey, tc.header)
+			}
+
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func requireUnsupported(t *testing.T, recorder *httptest.ResponseRecorder) {
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	var p problem.Problem
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &p))
+	require.Equal(t, problem.CodeUnsupportedVersion, p.Code)
+}
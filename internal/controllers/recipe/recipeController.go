@@ -1,28 +1,63 @@
 package recipeController
 
 import (
-	"database/sql"
-	"errors"
+	"context"
 	"github.com/gin-gonic/gin"
 	authMiddleware "github.com/gmaschi/go-recipes-book/internal/controllers/middlewares/auth"
+	errorMiddleware "github.com/gmaschi/go-recipes-book/internal/controllers/middlewares/errors"
+	versionMiddleware "github.com/gmaschi/go-recipes-book/internal/controllers/middlewares/version"
+	recipeDomain "github.com/gmaschi/go-recipes-book/internal/domain/recipe"
+	authorModel "github.com/gmaschi/go-recipes-book/internal/models/author"
 	recipeModel "github.com/gmaschi/go-recipes-book/internal/models/recipe"
 	db "github.com/gmaschi/go-recipes-book/internal/services/datastore/postgresql/recipes/sqlc"
 	"github.com/gmaschi/go-recipes-book/pkg/auth/tokenAuth"
-	"github.com/gmaschi/go-recipes-book/pkg/tools/parseErrors"
-	"github.com/lib/pq"
+	"github.com/gmaschi/go-recipes-book/pkg/tools/etag"
+	"github.com/gmaschi/go-recipes-book/pkg/tools/fieldset"
+	"github.com/gmaschi/go-recipes-book/pkg/tools/filter"
+	"github.com/gmaschi/go-recipes-book/pkg/tools/pagination"
+	"github.com/gmaschi/go-recipes-book/pkg/tools/problem"
 	"net/http"
 	"time"
 )
 
+// recipeCursor is the sort key of the recipes listed with a cursor
+type recipeCursor struct {
+	CreatedAt time.Time `json:"c"`
+	ID        int64     `json:"i"`
+}
+
+// The filters of the recipes listed by page
+const (
+	createdAfterKey  = "created_after"
+	createdBeforeKey = "created_before"
+	updatedSinceKey  = "updated_since"
+	ingredientKey    = "has_ingredient"
+	authorKey        = "author"
+)
+
+// filterKeys are the sort and filter parameters, which cursors do not support
+var filterKeys = []string{filter.SortKey, createdAfterKey, createdBeforeKey, updatedSinceKey, ingredientKey, authorKey}
+
+// errNotOwnedRecipes is reported when an author lists the recipes of another author
+var errNotOwnedRecipes = problem.New(http.StatusUnauthorized, problem.CodeRecipeNotOwned, "recipes of other authors cannot be listed")
+
 type Controller struct {
-	store db.Store
+	store     db.Store
+	recipes   *recipeDomain.Service
+	relations map[string]fieldset.Relation[db.Recipe]
 }
 
-// New creates a pointer to a Controller
-func New(store db.Store) *Controller {
-	return &Controller{
-		store: store,
+// New creates a pointer to a Controller applying the rules of recipes, and listing the
+// recipes of store
+func New(store db.Store, recipes *recipeDomain.Service) *Controller {
+	c := &Controller{
+		store:   store,
+		recipes: recipes,
+	}
+	c.relations = map[string]fieldset.Relation[db.Recipe]{
+		"author": c.author,
 	}
+	return c
 }
 
 // Create handles the request to create a new recipe
@@ -30,193 +65,287 @@ func (c *Controller) Create(ctx *gin.Context) {
 	var req recipeModel.CreateRequest
 
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, parseErrors.ErrorResponse(err))
-		return
-	}
-
-	if len(req.Ingredients) == 0 || len(req.Steps) == 0 {
-		ctx.JSON(http.StatusBadRequest, "There should be at least one step and one ingredient per recipe")
+		errorMiddleware.AbortBind(ctx, err)
 		return
 	}
 
 	authPayload := ctx.MustGet(authMiddleware.AuthorizationPayloadKey).(*tokenAuth.Payload)
-	createArgs := db.CreateRecipeParams{
-		Author:      authPayload.Username,
-		Ingredients: req.Ingredients,
-		Steps:       req.Steps,
-	}
 
-	recipe, err := c.store.CreateRecipe(ctx, createArgs)
+	recipe, err := c.recipes.Create(ctx.Request.Context(), authPayload.Username, req)
 	if err != nil {
-		if pqErr, ok := err.(*pq.Error); ok {
-			switch pqErr.Code.Name() {
-			case "foreign_key_violation":
-				ctx.JSON(http.StatusForbidden, parseErrors.ErrorResponse(pqErr))
-				return
-			}
-		}
-		ctx.JSON(http.StatusInternalServerError, parseErrors.ErrorResponse(err))
+		errorMiddleware.Abort(ctx, err)
 		return
 	}
 
+	ctx.Header(etag.Header, etag.Version(recipe.Version))
+
 	res := recipeModel.CreateResponse(recipe)
 	ctx.JSON(http.StatusOK, res)
 }
 
-// Recipe handles the request to get a recipe by ID
+// Recipe handles the request to get a recipe by ID, restricted to the fields and with the
+// relations selected by the fields and include parameters. The full response has the ETag
+// of the recipe version and the others a weak ETag of their content, and neither is sent
+// again to clients that have it in If-None-Match.
 func (c *Controller) Recipe(ctx *gin.Context) {
 	var req recipeModel.GetRequest
 
 	if err := ctx.ShouldBindUri(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, parseErrors.ErrorResponse(err))
+		errorMiddleware.AbortBind(ctx, err)
 		return
 	}
 
-	recipe, err := c.store.GetRecipe(ctx, req.ID)
+	selection, err := fieldset.Parse(ctx.Request.URL.Query(), recipeModel.GetResponse{}, c.relations)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, parseErrors.ErrorResponse(err))
-			return
-		}
-		ctx.JSON(http.StatusInternalServerError, parseErrors.ErrorResponse(err))
+		errorMiddleware.Abort(ctx, err)
 		return
 	}
 
 	authPayload := ctx.MustGet(authMiddleware.AuthorizationPayloadKey).(*tokenAuth.Payload)
 
-	if authPayload.Username != recipe.Author {
-		err := errors.New("recipe does not belong to authenticated user")
-		ctx.JSON(http.StatusUnauthorized, parseErrors.ErrorResponse(err))
+	recipe, err := c.recipes.Get(ctx.Request.Context(), authPayload.Username, req.ID)
+	if err != nil {
+		errorMiddleware.Abort(ctx, err)
 		return
 	}
 
-	res := recipeModel.GetResponse(recipe)
+	var res interface{} = recipeModel.GetResponse(recipe)
+	tag := etag.Version(recipe.Version)
+	if selection.Partial() {
+		included, err := fieldset.Load(ctx.Request.Context(), selection, recipe, c.relations)
+		if err != nil {
+			errorMiddleware.Abort(ctx, err)
+			return
+		}
+
+		if res, err = selection.Apply(res, included); err != nil {
+			errorMiddleware.Abort(ctx, err)
+			return
+		}
+		if tag, err = etag.Weak(res); err != nil {
+			errorMiddleware.Abort(ctx, err)
+			return
+		}
+	}
+
+	ctx.Header(etag.Header, tag)
+	if etag.WeakMatch(ctx.GetHeader(etag.IfNoneMatchHeader), tag) {
+		ctx.Status(http.StatusNotModified)
+		return
+	}
 
 	ctx.JSON(http.StatusOK, res)
 }
 
-// Update handles the request to update a specific recipe by ID
+// Update handles the request to update a specific recipe by ID. If-Match must hold the
+// ETag of the recipe and the request the version it read, so that an update never
+// overwrites changes the client has not seen. An outdated If-Match fails the precondition
+// and an outdated version is a conflict, reported with the current recipe; both are sent
+// with the current ETag.
 func (c *Controller) Update(ctx *gin.Context) {
 	var req recipeModel.UpdateRequest
 
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, parseErrors.ErrorResponse(err))
+		errorMiddleware.AbortBind(ctx, err)
 		return
 	}
 
-	recipe, err := c.store.GetRecipe(ctx, req.ID)
+	authPayload := ctx.MustGet(authMiddleware.AuthorizationPayloadKey).(*tokenAuth.Payload)
+
+	updatedRecipe, err := c.recipes.Update(ctx.Request.Context(), authPayload.Username, req, recipeDomain.Precondition{
+		IfMatch:  ctx.GetHeader(etag.IfMatchHeader),
+		Required: true,
+	})
+	if tag, ok := recipeDomain.CurrentETag(err); ok {
+		ctx.Header(etag.Header, tag)
+	}
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, parseErrors.ErrorResponse(err))
-			return
-		}
-		ctx.JSON(http.StatusInternalServerError, parseErrors.ErrorResponse(err))
+		errorMiddleware.Abort(ctx, err)
 		return
 	}
 
-	authPayload := ctx.MustGet(authMiddleware.AuthorizationPayloadKey).(*tokenAuth.Payload)
+	ctx.Header(etag.Header, etag.Version(updatedRecipe.Version))
+
+	res := recipeModel.UpdateResponse(updatedRecipe)
+
+	ctx.JSON(http.StatusOK, res)
+}
 
-	if authPayload.Username != recipe.Author {
-		err := errors.New("recipe does not belong to authenticated user")
-		ctx.JSON(http.StatusUnauthorized, parseErrors.ErrorResponse(err))
+// Delete handles a request do delete an recipe. The recipe is soft-deleted, and only
+// removed from the database by the admin purge command.
+func (c *Controller) Delete(ctx *gin.Context) {
+	var req recipeModel.DeleteRequest
+
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		errorMiddleware.AbortBind(ctx, err)
 		return
 	}
 
-	now := time.Now().UTC()
-	updateArgs := db.UpdateRecipeParams{
-		ID:          recipe.ID,
-		Steps:       recipe.Steps,
-		Ingredients: recipe.Ingredients,
-	}
+	authPayload := ctx.MustGet(authMiddleware.AuthorizationPayloadKey).(*tokenAuth.Payload)
 
-	if len(req.Steps) != 0 {
-		updateArgs.Steps = req.Steps
-		updateArgs.UpdatedAt = now
-	}
-	if len(req.Ingredients) != 0 {
-		updateArgs.Ingredients = req.Ingredients
-		updateArgs.UpdatedAt = now
+	if err := c.recipes.Delete(ctx.Request.Context(), authPayload.Username, req.ID); err != nil {
+		errorMiddleware.Abort(ctx, err)
+		return
 	}
 
-	updatedRecipe, err := c.store.UpdateRecipe(ctx, updateArgs)
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, parseErrors.ErrorResponse(err))
+	ctx.JSON(http.StatusOK, "ok")
+}
+
+// List handles a request to list recipes with pagination, by page with page_id and page_size
+// or after a cursor with cursor and limit. Pages can be sorted and filtered, and are sent in
+// an envelope with the total count from version 2 of the API.
+func (c *Controller) List(ctx *gin.Context) {
+	if pagination.IsCursorQuery(ctx.Request.URL.Query()) {
+		c.listAfter(ctx)
 		return
 	}
 
-	res := recipeModel.UpdateResponse(updatedRecipe)
+	var req recipeModel.ListRequest
 
-	ctx.JSON(http.StatusOK, res)
-}
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		errorMiddleware.AbortBind(ctx, err)
+		return
+	}
 
-// Delete handles a request do delete an recipe
-func (c *Controller) Delete(ctx *gin.Context) {
-	var req recipeModel.DeleteRequest
+	authPayload := ctx.MustGet(authMiddleware.AuthorizationPayloadKey).(*tokenAuth.Payload)
 
-	if err := ctx.ShouldBindUri(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, parseErrors.ErrorResponse(err))
+	p := filter.New(ctx.Request.URL.Query())
+	listArgs := db.SearchRecipesParams{
+		Author:        authPayload.Username,
+		CreatedAfter:  p.Time(createdAfterKey),
+		CreatedBefore: p.Time(createdBeforeKey),
+		UpdatedSince:  p.Time(updatedSinceKey),
+		Ingredient:    p.String(ingredientKey),
+		Sort:          db.Sort(p.Sort(filter.Sort{Field: "id"}, db.RecipeSortFields...)),
+		Limit:         req.PageSize,
+		Offset:        req.PageSize * (req.PageID - 1),
+	}
+	author := p.String(authorKey)
+	if err := p.Err(); err != nil {
+		errorMiddleware.Abort(ctx, err)
+		return
+	}
+	// recipes are private, so the only author that can be selected is the authenticated one
+	if author.Valid && author.String != authPayload.Username {
+		errorMiddleware.Abort(ctx, errNotOwnedRecipes)
 		return
 	}
 
-	recipe, err := c.store.GetRecipe(ctx, req.ID)
+	recipes, err := c.store.SearchRecipes(ctx.Request.Context(), listArgs)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, parseErrors.ErrorResponse(err))
-			return
-		}
-		ctx.JSON(http.StatusInternalServerError, parseErrors.ErrorResponse(err))
+		errorMiddleware.Abort(ctx, err)
 		return
 	}
 
-	authPayload := ctx.MustGet(authMiddleware.AuthorizationPayloadKey).(*tokenAuth.Payload)
+	k := len(recipes)
+	items := make([]recipeModel.ListResponse, 0, k)
+	for _, recipe := range recipes {
+		items = append(items, recipeModel.ListResponse(recipe))
+	}
 
-	if authPayload.Username != recipe.Author {
-		err := errors.New("recipe does not belong to authenticated user")
-		ctx.JSON(http.StatusUnauthorized, parseErrors.ErrorResponse(err))
+	if versionMiddleware.Version(ctx) < versionMiddleware.V2 {
+		setLinks(ctx, pagination.PageLinks(ctx.Request.URL.Query(), req.PageID, int32(k) == req.PageSize))
+		ctx.JSON(http.StatusOK, items)
 		return
 	}
 
-	err = c.store.DeleteRecipe(ctx, req.ID)
+	total, err := c.store.CountSearchRecipes(ctx.Request.Context(), listArgs)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, parseErrors.ErrorResponse(err))
+		errorMiddleware.Abort(ctx, err)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, "ok")
+	res := recipeModel.ListEnvelopeResponse{
+		Items:    items,
+		Page:     req.PageID,
+		PageSize: req.PageSize,
+		Total:    total,
+		HasMore:  int64(listArgs.Offset)+int64(k) < total,
+	}
+	setLinks(ctx, pagination.PageLinks(ctx.Request.URL.Query(), req.PageID, res.HasMore))
+	ctx.JSON(http.StatusOK, res)
 }
 
-// List handles a request to list recipes with pagination
-func (c *Controller) List(ctx *gin.Context) {
-	var req recipeModel.ListRequest
+// listAfter lists the recipes of the authenticated author ordered by creation, resuming
+// after the recipe encoded in the cursor
+func (c *Controller) listAfter(ctx *gin.Context) {
+	var req recipeModel.ListCursorRequest
 
 	if err := ctx.ShouldBindQuery(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, parseErrors.ErrorResponse(err))
+		errorMiddleware.AbortBind(ctx, err)
 		return
 	}
 
-	authPayload := ctx.MustGet(authMiddleware.AuthorizationPayloadKey).(*tokenAuth.Payload)
+	p := filter.New(ctx.Request.URL.Query())
+	p.Reject("not supported with a cursor, which lists by creation", filterKeys...)
+	if err := p.Err(); err != nil {
+		errorMiddleware.Abort(ctx, err)
+		return
+	}
 
-	listArgs := db.ListRecipesParams{
-		Author: authPayload.Username,
-		Limit:  req.PageSize,
-		Offset: req.PageSize * (req.PageID - 1),
+	var after recipeCursor
+	if req.Cursor != "" {
+		if err := pagination.DecodeCursor(req.Cursor, &after); err != nil {
+			errorMiddleware.Abort(ctx, err)
+			return
+		}
 	}
 
-	recipes, err := c.store.ListRecipes(ctx, listArgs)
+	authPayload := ctx.MustGet(authMiddleware.AuthorizationPayloadKey).(*tokenAuth.Payload)
+
+	// one more row than requested tells whether there is a next page
+	recipes, err := c.store.ListRecipesAfter(ctx.Request.Context(), db.ListRecipesAfterParams{
+		Author:    authPayload.Username,
+		CreatedAt: after.CreatedAt,
+		ID:        after.ID,
+		Limit:     req.Limit + 1,
+	})
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, parseErrors.ErrorResponse(err))
+		errorMiddleware.Abort(ctx, err)
+		return
+	}
+
+	var res recipeModel.ListPageResponse
+	if len(recipes) > int(req.Limit) {
+		recipes = recipes[:req.Limit]
+		last := recipes[len(recipes)-1]
+		res.NextCursor, err = pagination.EncodeCursor(recipeCursor{CreatedAt: last.CreatedAt, ID: last.ID})
+		if err != nil {
+			errorMiddleware.Abort(ctx, err)
 			return
 		}
-		ctx.JSON(http.StatusInternalServerError, parseErrors.ErrorResponse(err))
-		return
 	}
 
-	k := len(recipes)
-	res := make([]recipeModel.ListResponse, 0, k)
+	res.Items = make([]recipeModel.ListResponse, 0, len(recipes))
 	for _, recipe := range recipes {
-		res = append(res, recipeModel.ListResponse(recipe))
+		res.Items = append(res.Items, recipeModel.ListResponse(recipe))
+	}
+
+	if req.Total {
+		total, err := c.store.CountRecipes(ctx.Request.Context(), authPayload.Username)
+		if err != nil {
+			errorMiddleware.Abort(ctx, err)
+			return
+		}
+		res.Total = &total
 	}
 
+	setLinks(ctx, pagination.CursorLinks(ctx.Request.URL.Query(), res.NextCursor))
 	ctx.JSON(http.StatusOK, res)
 }
+
+// author loads the author of a recipe, embedded with include=author
+func (c *Controller) author(ctx context.Context, recipe db.Recipe) (interface{}, error) {
+	author, err := c.store.GetAuthor(ctx, recipe.Author)
+	if err != nil {
+		return nil, err
+	}
+	return authorModel.GetResponse(author), nil
+}
+
+// setLinks sets the Link header of the response to links, if any
+func setLinks(ctx *gin.Context, links []pagination.Link) {
+	if len(links) > 0 {
+		ctx.Header("Link", pagination.LinkHeader(ctx.Request.URL, links...))
+	}
+}
@@ -2,17 +2,22 @@ package recipeController_test
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
 	authMiddleware "github.com/gmaschi/go-recipes-book/internal/controllers/middlewares/auth"
 	"github.com/gmaschi/go-recipes-book/internal/factories/book-recipe-factory"
+	memoryStore "github.com/gmaschi/go-recipes-book/internal/mocks/datastore/memory"
 	mockedstore "github.com/gmaschi/go-recipes-book/internal/mocks/datastore/postgresql/recipes"
 	recipeModel "github.com/gmaschi/go-recipes-book/internal/models/recipe"
 	db "github.com/gmaschi/go-recipes-book/internal/services/datastore/postgresql/recipes/sqlc"
 	"github.com/gmaschi/go-recipes-book/pkg/auth/tokenAuth"
 	"github.com/gmaschi/go-recipes-book/pkg/config/env"
+	"github.com/gmaschi/go-recipes-book/pkg/tools/etag"
+	"github.com/gmaschi/go-recipes-book/pkg/tools/pagination"
 	"github.com/gmaschi/go-recipes-book/pkg/tools/password"
+	"github.com/gmaschi/go-recipes-book/pkg/tools/problem"
 	"github.com/gmaschi/go-recipes-book/pkg/tools/random"
 	"github.com/golang/mock/gomock"
 	"github.com/lib/pq"
@@ -21,6 +26,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 )
@@ -208,7 +214,7 @@ func TestCreate(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
 
-			store := mockedstore.NewMockStore(ctrl)
+			store := newMockStore(ctrl)
 			tc.buildStubs(store)
 
 			config, err := env.NewConfig()
@@ -344,7 +350,7 @@ func TestRecipe(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
 
-			store := mockedstore.NewMockStore(ctrl)
+			store := newMockStore(ctrl)
 			tc.buildStubs(store)
 
 			config, err := env.NewConfig()
@@ -365,6 +371,209 @@ func TestRecipe(t *testing.T) {
 	}
 }
 
+func TestRecipeFields(t *testing.T) {
+	author := randomAuthor(t)
+	recipe := randomRecipe(author.Username)
+
+	testCases := []struct {
+		name          string
+		query         string
+		buildStubs    func(store *mockedstore.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "FieldsAndInclude",
+			query: "fields=id,ingredients&include=author",
+			buildStubs: func(store *mockedstore.MockStore) {
+				store.EXPECT().GetRecipe(gomock.Any(), gomock.Eq(recipe.ID)).Times(1).Return(recipe, nil)
+				store.EXPECT().GetAuthor(gomock.Any(), gomock.Eq(author.Username)).Times(1).Return(author, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res map[string]json.RawMessage
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Len(t, res, 3)
+				require.JSONEq(t, fmt.Sprint(recipe.ID), string(res["id"]))
+				require.Contains(t, res, "ingredients")
+
+				var included struct {
+					Author map[string]interface{} `json:"author"`
+				}
+				require.NoError(t, json.Unmarshal(res["included"], &included))
+				require.Equal(t, author.Username, included.Author["username"])
+				require.NotContains(t, included.Author, "hashed_password")
+			},
+		},
+		{
+			name:  "Fields",
+			query: "fields=steps",
+			buildStubs: func(store *mockedstore.MockStore) {
+				store.EXPECT().GetRecipe(gomock.Any(), gomock.Eq(recipe.ID)).Times(1).Return(recipe, nil)
+				store.EXPECT().GetAuthor(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res map[string][]string
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Equal(t, map[string][]string{"steps": recipe.Steps}, res)
+			},
+		},
+		{
+			name:  "UnknownFieldAndRelation",
+			query: "fields=title,ingredients&include=tags",
+			buildStubs: func(store *mockedstore.MockStore) {
+				store.EXPECT().GetRecipe(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+
+				var p problem.Problem
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &p))
+				require.Len(t, p.Errors, 2)
+				require.Equal(t, "fields", p.Errors[0].Field)
+				require.Equal(t, "include", p.Errors[1].Field)
+			},
+		},
+		{
+			name:  "IncludeError",
+			query: "include=author",
+			buildStubs: func(store *mockedstore.MockStore) {
+				store.EXPECT().GetRecipe(gomock.Any(), gomock.Eq(recipe.ID)).Times(1).Return(recipe, nil)
+				store.EXPECT().GetAuthor(gomock.Any(), gomock.Any()).Times(1).Return(db.Author{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := newMockStore(ctrl)
+			tc.buildStubs(store)
+
+			config, err := env.NewConfig()
+			require.NoError(t, err)
+
+			server, err := bookRecipeFactory.New(config, store)
+			require.NoError(t, err)
+			recorder := httptest.NewRecorder()
+
+			req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/recipes/%d?%s", recipe.ID, tc.query), nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, req, server.TokenAuth, authMiddleware.AuthorizationTypeBearer, author.Username, time.Minute)
+			server.Router.ServeHTTP(recorder, req)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestRecipeNotModified(t *testing.T) {
+	author := randomAuthor(t)
+	recipe := randomRecipe(author.Username)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := newMockStore(ctrl)
+	store.EXPECT().GetRecipe(gomock.Any(), gomock.Eq(recipe.ID)).Times(3).Return(recipe, nil)
+
+	config, err := env.NewConfig()
+	require.NoError(t, err)
+	server, err := bookRecipeFactory.New(config, store)
+	require.NoError(t, err)
+
+	get := func(ifNoneMatch string) *httptest.ResponseRecorder {
+		req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/recipes/%d", recipe.ID), nil)
+		require.NoError(t, err)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		addAuthorization(t, req, server.TokenAuth, authMiddleware.AuthorizationTypeBearer, author.Username, time.Minute)
+
+		recorder := httptest.NewRecorder()
+		server.Router.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	recorder := get("")
+	require.Equal(t, http.StatusOK, recorder.Code)
+	tag := recorder.Header().Get("ETag")
+	require.NotEmpty(t, tag)
+
+	recorder = get("W/" + tag)
+	require.Equal(t, http.StatusNotModified, recorder.Code)
+	require.Equal(t, tag, recorder.Header().Get("ETag"))
+	require.Empty(t, recorder.Body.Bytes())
+
+	recorder = get(`"other"`)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	requireBodyMatchRecipe(t, recorder.Body, recipe)
+}
+
+func TestRecipeIncludeModifiedAuthor(t *testing.T) {
+	store := memoryStore.New()
+	author, err := store.CreateAuthor(context.Background(), db.CreateAuthorParams{
+		Username:       random.String(8),
+		HashedPassword: "hashed",
+		Email:          random.Email(),
+	})
+	require.NoError(t, err)
+	recipe, err := store.CreateRecipe(context.Background(), db.CreateRecipeParams{
+		Author:      author.Username,
+		Ingredients: random.StringSlice(3),
+		Steps:       random.StringSlice(3),
+	})
+	require.NoError(t, err)
+
+	config, err := env.NewConfig()
+	require.NoError(t, err)
+	server, err := bookRecipeFactory.New(config, store)
+	require.NoError(t, err)
+
+	get := func(ifNoneMatch string) *httptest.ResponseRecorder {
+		req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/recipes/%d?include=author", recipe.ID), nil)
+		require.NoError(t, err)
+		req.Header.Set("If-None-Match", ifNoneMatch)
+		addAuthorization(t, req, server.TokenAuth, authMiddleware.AuthorizationTypeBearer, author.Username, time.Minute)
+
+		recorder := httptest.NewRecorder()
+		server.Router.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	recorder := get("")
+	require.Equal(t, http.StatusOK, recorder.Code)
+	tag := recorder.Header().Get("ETag")
+	require.True(t, strings.HasPrefix(tag, "W/"))
+	require.NotEqual(t, "W/"+etag.Version(recipe.Version), tag)
+
+	recorder = get(tag)
+	require.Equal(t, http.StatusNotModified, recorder.Code)
+
+	// the recipe is unchanged, but the embedded author is not
+	email := random.Email()
+	_, err = store.UpdateAuthor(context.Background(), db.UpdateAuthorParams{
+		Username:       author.Username,
+		Email:          email,
+		HashedPassword: author.HashedPassword,
+		UpdatedAt:      time.Now().UTC(),
+		Version:        author.Version,
+	})
+	require.NoError(t, err)
+
+	recorder = get(tag)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.NotEqual(t, tag, recorder.Header().Get("ETag"))
+	require.Contains(t, recorder.Body.String(), email)
+}
+
 func TestUpdate(t *testing.T) {
 	author := randomAuthor(t)
 	recipe := randomRecipe(author.Username)
@@ -379,8 +588,11 @@ func TestUpdate(t *testing.T) {
 		Steps:       updatedSteps,
 		CreatedAt:   recipe.CreatedAt,
 		UpdatedAt:   updatedTime,
+		Version:     recipe.Version + 1,
 	}
 
+	recipeTag := etag.Version(recipe.Version)
+
 	testCases := []struct {
 		name          string
 		body          map[string]interface{}
@@ -392,6 +604,7 @@ func TestUpdate(t *testing.T) {
 			name: "OK",
 			body: map[string]interface{}{
 				"id":          recipe.ID,
+				"version":     recipe.Version,
 				"ingredients": updatedIngredients,
 				"steps":       updatedSteps,
 			},
@@ -401,6 +614,7 @@ func TestUpdate(t *testing.T) {
 			buildStubs: func(store *mockedstore.MockStore) {
 				arg := db.UpdateRecipeParams{
 					ID:          recipe.ID,
+					Version:     recipe.Version,
 					Steps:       updatedSteps,
 					Ingredients: updatedIngredients,
 					UpdatedAt:   updatedTime,
@@ -417,12 +631,15 @@ func TestUpdate(t *testing.T) {
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, recorder.Code)
 				requireBodyMatchUpdate(t, recorder.Body, updatedRecipe)
+
+				require.Equal(t, etag.Version(updatedRecipe.Version), recorder.Header().Get("ETag"))
 			},
 		},
 		{
 			name: "NoAuthorization",
 			body: map[string]interface{}{
 				"id":          recipe.ID,
+				"version":     recipe.Version,
 				"ingredients": updatedIngredients,
 				"steps":       updatedSteps,
 			},
@@ -430,6 +647,7 @@ func TestUpdate(t *testing.T) {
 			buildStubs: func(store *mockedstore.MockStore) {
 				arg := db.UpdateRecipeParams{
 					ID:          recipe.ID,
+					Version:     recipe.Version,
 					Steps:       updatedSteps,
 					Ingredients: updatedIngredients,
 					UpdatedAt:   updatedTime,
@@ -449,6 +667,7 @@ func TestUpdate(t *testing.T) {
 			name: "UnauthorizedUser",
 			body: map[string]interface{}{
 				"id":          recipe.ID,
+				"version":     recipe.Version,
 				"ingredients": updatedIngredients,
 				"steps":       updatedSteps,
 			},
@@ -458,6 +677,7 @@ func TestUpdate(t *testing.T) {
 			buildStubs: func(store *mockedstore.MockStore) {
 				arg := db.UpdateRecipeParams{
 					ID:          recipe.ID,
+					Version:     recipe.Version,
 					Steps:       updatedSteps,
 					Ingredients: updatedIngredients,
 					UpdatedAt:   updatedTime,
@@ -497,6 +717,7 @@ func TestUpdate(t *testing.T) {
 			name: "NotFound",
 			body: map[string]interface{}{
 				"id":          recipe.ID,
+				"version":     recipe.Version,
 				"ingredients": updatedIngredients,
 				"steps":       updatedSteps,
 			},
@@ -517,6 +738,7 @@ func TestUpdate(t *testing.T) {
 			name: "GetStepInternalError",
 			body: map[string]interface{}{
 				"id":          recipe.ID,
+				"version":     recipe.Version,
 				"ingredients": updatedIngredients,
 				"steps":       updatedSteps,
 			},
@@ -537,6 +759,7 @@ func TestUpdate(t *testing.T) {
 			name: "UpdateStepInternalError",
 			body: map[string]interface{}{
 				"id":          recipe.ID,
+				"version":     recipe.Version,
 				"ingredients": updatedIngredients,
 				"steps":       updatedSteps,
 			},
@@ -546,6 +769,7 @@ func TestUpdate(t *testing.T) {
 			buildStubs: func(store *mockedstore.MockStore) {
 				arg := db.UpdateRecipeParams{
 					ID:          recipe.ID,
+					Version:     recipe.Version,
 					Steps:       updatedSteps,
 					Ingredients: updatedIngredients,
 					UpdatedAt:   updatedTime,
@@ -563,6 +787,114 @@ func TestUpdate(t *testing.T) {
 				require.Equal(t, http.StatusInternalServerError, recorder.Code)
 			},
 		},
+		{
+			name: "MissingVersion",
+			body: map[string]interface{}{
+				"id":    recipe.ID,
+				"steps": updatedSteps,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker tokenAuth.Maker) {
+				addAuthorization(t, request, tokenMaker, authMiddleware.AuthorizationTypeBearer, recipe.Author, time.Minute)
+			},
+			buildStubs: func(store *mockedstore.MockStore) {
+				store.EXPECT().
+					GetRecipe(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					UpdateRecipe(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				p := requireProblem(t, recorder, problem.CodeInvalidRequest)
+				require.Equal(t, "version", p.Errors[0].Field)
+			},
+		},
+		{
+			name: "StaleVersion",
+			body: map[string]interface{}{
+				"id":      recipe.ID,
+				"version": recipe.Version + 1,
+				"steps":   updatedSteps,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker tokenAuth.Maker) {
+				addAuthorization(t, request, tokenMaker, authMiddleware.AuthorizationTypeBearer, recipe.Author, time.Minute)
+			},
+			buildStubs: func(store *mockedstore.MockStore) {
+				store.EXPECT().
+					GetRecipe(gomock.Any(), gomock.Eq(recipe.ID)).
+					Times(1).
+					Return(recipe, nil)
+				store.EXPECT().
+					UpdateRecipe(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+				requireConflict(t, recorder, recipe)
+			},
+		},
+		{
+			name: "ConcurrentUpdate",
+			body: map[string]interface{}{
+				"id":      recipe.ID,
+				"version": recipe.Version,
+				"steps":   updatedSteps,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker tokenAuth.Maker) {
+				addAuthorization(t, request, tokenMaker, authMiddleware.AuthorizationTypeBearer, recipe.Author, time.Minute)
+			},
+			buildStubs: func(store *mockedstore.MockStore) {
+				gomock.InOrder(
+					store.EXPECT().
+						GetRecipe(gomock.Any(), gomock.Eq(recipe.ID)).
+						Times(1).
+						Return(recipe, nil),
+					store.EXPECT().
+						UpdateRecipe(gomock.Any(), gomock.Any()).
+						Times(1).
+						Return(db.Recipe{}, sql.ErrNoRows),
+					store.EXPECT().
+						GetRecipe(gomock.Any(), gomock.Eq(recipe.ID)).
+						Times(1).
+						Return(updatedRecipe, nil),
+				)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+				requireConflict(t, recorder, updatedRecipe)
+			},
+		},
+		{
+			name: "DeletedMeanwhile",
+			body: map[string]interface{}{
+				"id":      recipe.ID,
+				"version": recipe.Version,
+				"steps":   updatedSteps,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker tokenAuth.Maker) {
+				addAuthorization(t, request, tokenMaker, authMiddleware.AuthorizationTypeBearer, recipe.Author, time.Minute)
+			},
+			buildStubs: func(store *mockedstore.MockStore) {
+				gomock.InOrder(
+					store.EXPECT().
+						GetRecipe(gomock.Any(), gomock.Eq(recipe.ID)).
+						Times(1).
+						Return(recipe, nil),
+					store.EXPECT().
+						UpdateRecipe(gomock.Any(), gomock.Any()).
+						Times(1).
+						Return(db.Recipe{}, sql.ErrNoRows),
+					store.EXPECT().
+						GetRecipe(gomock.Any(), gomock.Eq(recipe.ID)).
+						Times(1).
+						Return(db.Recipe{}, sql.ErrNoRows),
+				)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -570,7 +902,7 @@ func TestUpdate(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
 
-			store := mockedstore.NewMockStore(ctrl)
+			store := newMockStore(ctrl)
 			tc.buildStubs(store)
 
 			config, err := env.NewConfig()
@@ -586,6 +918,7 @@ func TestUpdate(t *testing.T) {
 			url := "/recipes"
 			req, err := http.NewRequest(http.MethodPatch, url, bytes.NewReader(data))
 			require.NoError(t, err)
+			req.Header.Set("If-Match", recipeTag)
 
 			tc.setupAuth(t, req, server.TokenAuth)
 			server.Router.ServeHTTP(recorder, req)
@@ -594,6 +927,166 @@ func TestUpdate(t *testing.T) {
 	}
 }
 
+func TestUpdatePrecondition(t *testing.T) {
+	author := randomAuthor(t)
+	recipe := randomRecipe(author.Username)
+	recipeTag := etag.Version(recipe.Version)
+
+	testCases := []struct {
+		name          string
+		ifMatch       string
+		buildStubs    func(store *mockedstore.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:    "AnyVersion",
+			ifMatch: "*",
+			buildStubs: func(store *mockedstore.MockStore) {
+				store.EXPECT().GetRecipe(gomock.Any(), gomock.Eq(recipe.ID)).Times(1).Return(recipe, nil)
+				store.EXPECT().UpdateRecipe(gomock.Any(), gomock.Any()).Times(1).Return(recipe, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "Missing",
+			buildStubs: func(store *mockedstore.MockStore) {
+				store.EXPECT().GetRecipe(gomock.Any(), gomock.Eq(recipe.ID)).Times(1).Return(recipe, nil)
+				store.EXPECT().UpdateRecipe(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusPreconditionRequired, recorder.Code)
+				requireProblem(t, recorder, problem.CodeMissingPrecondition)
+			},
+		},
+		{
+			name:    "Modified",
+			ifMatch: `"stale"`,
+			buildStubs: func(store *mockedstore.MockStore) {
+				store.EXPECT().GetRecipe(gomock.Any(), gomock.Eq(recipe.ID)).Times(1).Return(recipe, nil)
+				store.EXPECT().UpdateRecipe(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusPreconditionFailed, recorder.Code)
+				requireProblem(t, recorder, problem.CodePreconditionFailed)
+				require.Equal(t, recipeTag, recorder.Header().Get("ETag"))
+			},
+		},
+		{
+			name:    "WeakTag",
+			ifMatch: "W/" + recipeTag,
+			buildStubs: func(store *mockedstore.MockStore) {
+				store.EXPECT().GetRecipe(gomock.Any(), gomock.Eq(recipe.ID)).Times(1).Return(recipe, nil)
+				store.EXPECT().UpdateRecipe(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusPreconditionFailed, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := newMockStore(ctrl)
+			tc.buildStubs(store)
+
+			config, err := env.NewConfig()
+			require.NoError(t, err)
+
+			server, err := bookRecipeFactory.New(config, store)
+			require.NoError(t, err)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(map[string]interface{}{"id": recipe.ID, "version": recipe.Version, "steps": []string{"mix"}})
+			require.NoError(t, err)
+
+			req, err := http.NewRequest(http.MethodPatch, "/recipes", bytes.NewReader(data))
+			require.NoError(t, err)
+			if tc.ifMatch != "" {
+				req.Header.Set("If-Match", tc.ifMatch)
+			}
+
+			addAuthorization(t, req, server.TokenAuth, authMiddleware.AuthorizationTypeBearer, author.Username, time.Minute)
+			server.Router.ServeHTTP(recorder, req)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestUpdateAfterSparseGet(t *testing.T) {
+	store := memoryStore.New()
+	author, err := store.CreateAuthor(context.Background(), db.CreateAuthorParams{
+		Username:       random.String(8),
+		HashedPassword: "hashed",
+		Email:          random.Email(),
+	})
+	require.NoError(t, err)
+	recipe, err := store.CreateRecipe(context.Background(), db.CreateRecipeParams{
+		Author:      author.Username,
+		Ingredients: random.StringSlice(3),
+		Steps:       random.StringSlice(3),
+	})
+	require.NoError(t, err)
+
+	config, err := env.NewConfig()
+	require.NoError(t, err)
+	server, err := bookRecipeFactory.New(config, store)
+	require.NoError(t, err)
+
+	serve := func(req *http.Request) *httptest.ResponseRecorder {
+		addAuthorization(t, req, server.TokenAuth, authMiddleware.AuthorizationTypeBearer, author.Username, time.Minute)
+		recorder := httptest.NewRecorder()
+		server.Router.ServeHTTP(recorder, req)
+		return recorder
+	}
+	patch := func(ifMatch string, version int32, steps []string) *httptest.ResponseRecorder {
+		data, err := json.Marshal(map[string]interface{}{"id": recipe.ID, "version": version, "steps": steps})
+		require.NoError(t, err)
+		req, err := http.NewRequest(http.MethodPatch, "/recipes", bytes.NewReader(data))
+		require.NoError(t, err)
+		req.Header.Set("If-Match", ifMatch)
+		return serve(req)
+	}
+
+	// the ETag of a sparse fieldset is weak, so it fails If-Match, which reports the ETag of
+	// the recipe
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/recipes/%d?fields=steps", recipe.ID), nil)
+	require.NoError(t, err)
+	recorder := serve(req)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.True(t, strings.HasPrefix(recorder.Header().Get("ETag"), "W/"))
+
+	recorder = patch(recorder.Header().Get("ETag"), recipe.Version, []string{"mix"})
+	require.Equal(t, http.StatusPreconditionFailed, recorder.Code)
+	tag := recorder.Header().Get("ETag")
+	require.Equal(t, etag.Version(recipe.Version), tag)
+
+	recorder = patch(tag, recipe.Version, []string{"mix"})
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.NotEqual(t, tag, recorder.Header().Get("ETag"))
+
+	// a client that read the recipe before that update fails the precondition with the ETag
+	// it read, and gets the current ETag
+	recorder = patch(tag, recipe.Version, []string{"stir"})
+	require.Equal(t, http.StatusPreconditionFailed, recorder.Code)
+	requireProblem(t, recorder, problem.CodePreconditionFailed)
+	currentTag := recorder.Header().Get("ETag")
+	require.Equal(t, etag.Version(recipe.Version+1), currentTag)
+
+	// and with the current ETag but the version it read, gets the current recipe to merge its
+	// change with
+	recorder = patch(currentTag, recipe.Version, []string{"stir"})
+	require.Equal(t, http.StatusConflict, recorder.Code)
+	current, err := store.GetRecipe(context.Background(), recipe.ID)
+	require.NoError(t, err)
+	require.Equal(t, []string{"mix"}, current.Steps)
+	requireConflict(t, recorder, current)
+}
+
 func TestDelete(t *testing.T) {
 	author := randomAuthor(t)
 	recipe := randomRecipe(author.Username)
@@ -743,7 +1236,7 @@ func TestDelete(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
 
-			store := mockedstore.NewMockStore(ctrl)
+			store := newMockStore(ctrl)
 			tc.buildStubs(store)
 
 			config, err := env.NewConfig()
@@ -797,19 +1290,21 @@ func TestList(t *testing.T) {
 				addAuthorization(t, request, tokenMaker, authMiddleware.AuthorizationTypeBearer, recipe.Author, time.Minute)
 			},
 			buildStubs: func(store *mockedstore.MockStore) {
-				arg := db.ListRecipesParams{
+				arg := db.SearchRecipesParams{
 					Author: recipe.Author,
+					Sort:   db.Sort{Field: "id"},
 					Limit:  int32(pageSize),
 					Offset: int32(pageSize * (pageID - 1)),
 				}
 				store.EXPECT().
-					ListRecipes(gomock.Any(), gomock.Eq(arg)).
+					SearchRecipes(gomock.Any(), gomock.Eq(arg)).
 					Times(1).
 					Return(recipes, nil)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, recorder.Code)
 				requireBodyMatchList(t, recorder.Body, recipes)
+				require.Equal(t, `</recipes?page_id=2&page_size=10>; rel="next"`, recorder.Header().Get("Link"))
 			},
 		},
 		{
@@ -820,13 +1315,14 @@ func TestList(t *testing.T) {
 			}{pageID: int32(pageID), pageSize: int32(pageSize)},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker tokenAuth.Maker) {},
 			buildStubs: func(store *mockedstore.MockStore) {
-				arg := db.ListRecipesParams{
+				arg := db.SearchRecipesParams{
 					Author: recipe.Author,
+					Sort:   db.Sort{Field: "id"},
 					Limit:  int32(pageSize),
 					Offset: int32(pageSize * (pageID - 1)),
 				}
 				store.EXPECT().
-					ListRecipes(gomock.Any(), gomock.Eq(arg)).
+					SearchRecipes(gomock.Any(), gomock.Eq(arg)).
 					Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
@@ -843,13 +1339,14 @@ func TestList(t *testing.T) {
 				addAuthorization(t, request, tokenMaker, authMiddleware.AuthorizationTypeBearer, recipe.Author, time.Minute)
 			},
 			buildStubs: func(store *mockedstore.MockStore) {
-				arg := db.ListRecipesParams{
+				arg := db.SearchRecipesParams{
 					Author: recipe.Author,
+					Sort:   db.Sort{Field: "id"},
 					Limit:  int32(pageSize),
 					Offset: int32(pageSize * (pageID - 1)),
 				}
 				store.EXPECT().
-					ListRecipes(gomock.Any(), gomock.Eq(arg)).
+					SearchRecipes(gomock.Any(), gomock.Eq(arg)).
 					Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
@@ -861,18 +1358,19 @@ func TestList(t *testing.T) {
 			paginationData: struct {
 				pageID   int32
 				pageSize int32
-			}{pageID: int32(pageID), pageSize: int32(15)},
+			}{pageID: int32(pageID), pageSize: int32(101)},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker tokenAuth.Maker) {
 				addAuthorization(t, request, tokenMaker, authMiddleware.AuthorizationTypeBearer, recipe.Author, time.Minute)
 			},
 			buildStubs: func(store *mockedstore.MockStore) {
-				arg := db.ListRecipesParams{
+				arg := db.SearchRecipesParams{
 					Author: recipe.Author,
+					Sort:   db.Sort{Field: "id"},
 					Limit:  int32(pageSize),
 					Offset: int32(pageSize * (pageID - 1)),
 				}
 				store.EXPECT().
-					ListRecipes(gomock.Any(), gomock.Eq(arg)).
+					SearchRecipes(gomock.Any(), gomock.Eq(arg)).
 					Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
@@ -889,13 +1387,14 @@ func TestList(t *testing.T) {
 				addAuthorization(t, request, tokenMaker, authMiddleware.AuthorizationTypeBearer, recipe.Author, time.Minute)
 			},
 			buildStubs: func(store *mockedstore.MockStore) {
-				arg := db.ListRecipesParams{
+				arg := db.SearchRecipesParams{
 					Author: recipe.Author,
+					Sort:   db.Sort{Field: "id"},
 					Limit:  int32(pageSize),
 					Offset: int32(pageSize * (pageID - 1)),
 				}
 				store.EXPECT().
-					ListRecipes(gomock.Any(), gomock.Eq(arg)).
+					SearchRecipes(gomock.Any(), gomock.Eq(arg)).
 					Times(1).
 					Return([]db.Recipe{}, sql.ErrNoRows)
 			},
@@ -913,13 +1412,14 @@ func TestList(t *testing.T) {
 				addAuthorization(t, request, tokenMaker, authMiddleware.AuthorizationTypeBearer, recipe.Author, time.Minute)
 			},
 			buildStubs: func(store *mockedstore.MockStore) {
-				arg := db.ListRecipesParams{
+				arg := db.SearchRecipesParams{
 					Author: recipe.Author,
+					Sort:   db.Sort{Field: "id"},
 					Limit:  int32(pageSize),
 					Offset: int32(pageSize * (pageID - 1)),
 				}
 				store.EXPECT().
-					ListRecipes(gomock.Any(), gomock.Eq(arg)).
+					SearchRecipes(gomock.Any(), gomock.Eq(arg)).
 					Times(1).
 					Return([]db.Recipe{}, sql.ErrConnDone)
 			},
@@ -934,7 +1434,7 @@ func TestList(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
 
-			store := mockedstore.NewMockStore(ctrl)
+			store := newMockStore(ctrl)
 			tc.buildStubs(store)
 
 			config, err := env.NewConfig()
@@ -955,6 +1455,406 @@ func TestList(t *testing.T) {
 	}
 }
 
+func TestListCursor(t *testing.T) {
+	author := randomAuthor(t)
+	recipes := make([]db.Recipe, 0, 6)
+	for i := 0; i < 6; i++ {
+		recipes = append(recipes, randomRecipe(author.Username))
+	}
+	last := recipes[4]
+	cursor, err := pagination.EncodeCursor(map[string]interface{}{"c": last.CreatedAt, "i": last.ID})
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name          string
+		query         string
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker tokenAuth.Maker)
+		buildStubs    func(store *mockedstore.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "FirstPage",
+			query: "limit=5",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker tokenAuth.Maker) {
+				addAuthorization(t, request, tokenMaker, authMiddleware.AuthorizationTypeBearer, author.Username, time.Minute)
+			},
+			buildStubs: func(store *mockedstore.MockStore) {
+				store.EXPECT().
+					ListRecipesAfter(gomock.Any(), gomock.Eq(db.ListRecipesAfterParams{Author: author.Username, Limit: 6})).
+					Times(1).
+					Return(recipes, nil)
+				store.EXPECT().CountRecipes(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				res := requireBodyMatchPage(t, recorder.Body, recipes[:5])
+				require.Equal(t, cursor, res.NextCursor)
+				require.Nil(t, res.Total)
+				require.Equal(t, fmt.Sprintf(`</recipes?limit=5>; rel="first", </recipes?cursor=%s&limit=5>; rel="next"`, cursor), recorder.Header().Get("Link"))
+			},
+		},
+		{
+			name:  "LastPageWithTotal",
+			query: "limit=5&total=true&cursor=" + cursor,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker tokenAuth.Maker) {
+				addAuthorization(t, request, tokenMaker, authMiddleware.AuthorizationTypeBearer, author.Username, time.Minute)
+			},
+			buildStubs: func(store *mockedstore.MockStore) {
+				arg := db.ListRecipesAfterParams{
+					Author:    author.Username,
+					CreatedAt: last.CreatedAt,
+					ID:        last.ID,
+					Limit:     6,
+				}
+				store.EXPECT().
+					ListRecipesAfter(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(recipes[5:], nil)
+				store.EXPECT().
+					CountRecipes(gomock.Any(), gomock.Eq(author.Username)).
+					Times(1).
+					Return(int64(len(recipes)), nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				res := requireBodyMatchPage(t, recorder.Body, recipes[5:])
+				require.Empty(t, res.NextCursor)
+				require.NotNil(t, res.Total)
+				require.EqualValues(t, len(recipes), *res.Total)
+				require.Equal(t, `</recipes?limit=5&total=true>; rel="first"`, recorder.Header().Get("Link"))
+			},
+		},
+		{
+			name:  "NoAuthorization",
+			query: "limit=5",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker tokenAuth.Maker) {
+			},
+			buildStubs: func(store *mockedstore.MockStore) {
+				store.EXPECT().ListRecipesAfter(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:  "InvalidCursor",
+			query: "limit=5&cursor=invalid",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker tokenAuth.Maker) {
+				addAuthorization(t, request, tokenMaker, authMiddleware.AuthorizationTypeBearer, author.Username, time.Minute)
+			},
+			buildStubs: func(store *mockedstore.MockStore) {
+				store.EXPECT().ListRecipesAfter(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				require.Contains(t, recorder.Body.String(), `"field":"cursor"`)
+			},
+		},
+		{
+			name:  "MissingLimit",
+			query: "cursor=" + cursor,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker tokenAuth.Maker) {
+				addAuthorization(t, request, tokenMaker, authMiddleware.AuthorizationTypeBearer, author.Username, time.Minute)
+			},
+			buildStubs: func(store *mockedstore.MockStore) {
+				store.EXPECT().ListRecipesAfter(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "InvalidLimit",
+			query: "limit=500",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker tokenAuth.Maker) {
+				addAuthorization(t, request, tokenMaker, authMiddleware.AuthorizationTypeBearer, author.Username, time.Minute)
+			},
+			buildStubs: func(store *mockedstore.MockStore) {
+				store.EXPECT().ListRecipesAfter(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "InternalError",
+			query: "limit=5",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker tokenAuth.Maker) {
+				addAuthorization(t, request, tokenMaker, authMiddleware.AuthorizationTypeBearer, author.Username, time.Minute)
+			},
+			buildStubs: func(store *mockedstore.MockStore) {
+				store.EXPECT().
+					ListRecipesAfter(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.Recipe{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := newMockStore(ctrl)
+			tc.buildStubs(store)
+
+			config, err := env.NewConfig()
+			require.NoError(t, err)
+
+			server, err := bookRecipeFactory.New(config, store)
+			require.NoError(t, err)
+			recorder := httptest.NewRecorder()
+
+			req, err := http.NewRequest(http.MethodGet, "/recipes?"+tc.query, nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, req, server.TokenAuth)
+			server.Router.ServeHTTP(recorder, req)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestListFilters(t *testing.T) {
+	author := randomAuthor(t)
+	recipes := []db.Recipe{randomRecipe(author.Username), randomRecipe(author.Username)}
+	createdAfter := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	updatedSince := time.Date(2022, 3, 1, 12, 30, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name          string
+		query         string
+		buildStubs    func(store *mockedstore.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "OK",
+			query: "page_id=2&page_size=5&sort=-updated_at&created_after=2022-01-01T00:00:00Z&updated_since=2022-03-01T12:30:00Z&has_ingredient=salt&author=" + author.Username,
+			buildStubs: func(store *mockedstore.MockStore) {
+				arg := db.SearchRecipesParams{
+					Author:       author.Username,
+					CreatedAfter: sql.NullTime{Time: createdAfter, Valid: true},
+					UpdatedSince: sql.NullTime{Time: updatedSince, Valid: true},
+					Ingredient:   sql.NullString{String: "salt", Valid: true},
+					Sort:         db.Sort{Field: "updated_at", Desc: true},
+					Limit:        5,
+					Offset:       5,
+				}
+				store.EXPECT().
+					SearchRecipes(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(recipes, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchList(t, recorder.Body, recipes)
+				require.Contains(t, recorder.Header().Get("Link"), "sort=-updated_at")
+			},
+		},
+		{
+			name:  "InvalidFilters",
+			query: "page_id=1&page_size=5&sort=title&created_before=yesterday",
+			buildStubs: func(store *mockedstore.MockStore) {
+				store.EXPECT().SearchRecipes(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+
+				var p problem.Problem
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &p))
+				require.Len(t, p.Errors, 2)
+				require.Equal(t, "created_before", p.Errors[0].Field)
+				require.Equal(t, "sort", p.Errors[1].Field)
+			},
+		},
+		{
+			name:  "OtherAuthor",
+			query: "page_id=1&page_size=5&author=" + author.Username + "x",
+			buildStubs: func(store *mockedstore.MockStore) {
+				store.EXPECT().SearchRecipes(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+
+				var p problem.Problem
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &p))
+				require.Equal(t, problem.CodeRecipeNotOwned, p.Code)
+			},
+		},
+		{
+			name:  "FiltersWithCursor",
+			query: "limit=5&has_ingredient=salt",
+			buildStubs: func(store *mockedstore.MockStore) {
+				store.EXPECT().ListRecipesAfter(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+
+				var p problem.Problem
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &p))
+				require.Len(t, p.Errors, 1)
+				require.Equal(t, "has_ingredient", p.Errors[0].Field)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := newMockStore(ctrl)
+			tc.buildStubs(store)
+
+			config, err := env.NewConfig()
+			require.NoError(t, err)
+
+			server, err := bookRecipeFactory.New(config, store)
+			require.NoError(t, err)
+			recorder := httptest.NewRecorder()
+
+			req, err := http.NewRequest(http.MethodGet, "/recipes?"+tc.query, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, req, server.TokenAuth, authMiddleware.AuthorizationTypeBearer, author.Username, time.Minute)
+			server.Router.ServeHTTP(recorder, req)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestListEnvelope(t *testing.T) {
+	author := randomAuthor(t)
+	recipes := make([]db.Recipe, 0, 7)
+	for i := 0; i < 7; i++ {
+		recipes = append(recipes, randomRecipe(author.Username))
+	}
+	searchArgs := func(offset int32) db.SearchRecipesParams {
+		return db.SearchRecipesParams{Author: author.Username, Sort: db.Sort{Field: "id"}, Limit: 5, Offset: offset}
+	}
+
+	testCases := []struct {
+		name          string
+		query         string
+		version       string
+		buildStubs    func(store *mockedstore.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:    "FirstPage",
+			query:   "page_id=1&page_size=5",
+			version: "2",
+			buildStubs: func(store *mockedstore.MockStore) {
+				store.EXPECT().SearchRecipes(gomock.Any(), gomock.Eq(searchArgs(0))).Times(1).Return(recipes[:5], nil)
+				store.EXPECT().CountSearchRecipes(gomock.Any(), gomock.Eq(searchArgs(0))).Times(1).Return(int64(7), nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, "2", recorder.Header().Get("API-Version"))
+				require.Equal(t, `</recipes?page_id=2&page_size=5>; rel="next"`, recorder.Header().Get("Link"))
+
+				var res recipeModel.ListEnvelopeResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Len(t, res.Items, 5)
+				require.Equal(t, recipes[0].ID, res.Items[0].ID)
+				require.EqualValues(t, 1, res.Page)
+				require.EqualValues(t, 5, res.PageSize)
+				require.EqualValues(t, 7, res.Total)
+				require.True(t, res.HasMore)
+			},
+		},
+		{
+			name:    "LastPage",
+			query:   "page_id=2&page_size=5",
+			version: "2",
+			buildStubs: func(store *mockedstore.MockStore) {
+				store.EXPECT().SearchRecipes(gomock.Any(), gomock.Eq(searchArgs(5))).Times(1).Return(recipes[5:], nil)
+				store.EXPECT().CountSearchRecipes(gomock.Any(), gomock.Any()).Times(1).Return(int64(7), nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, `</recipes?page_id=1&page_size=5>; rel="prev"`, recorder.Header().Get("Link"))
+
+				var res recipeModel.ListEnvelopeResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Len(t, res.Items, 2)
+				require.EqualValues(t, 2, res.Page)
+				require.EqualValues(t, 7, res.Total)
+				require.False(t, res.HasMore)
+			},
+		},
+		{
+			name:    "CountError",
+			query:   "page_id=1&page_size=5",
+			version: "2",
+			buildStubs: func(store *mockedstore.MockStore) {
+				store.EXPECT().SearchRecipes(gomock.Any(), gomock.Any()).Times(1).Return(recipes[:5], nil)
+				store.EXPECT().CountSearchRecipes(gomock.Any(), gomock.Any()).Times(1).Return(int64(0), sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name:    "Version1",
+			query:   "page_id=1&page_size=5",
+			version: "1",
+			buildStubs: func(store *mockedstore.MockStore) {
+				store.EXPECT().SearchRecipes(gomock.Any(), gomock.Any()).Times(1).Return(recipes[:5], nil)
+				store.EXPECT().CountSearchRecipes(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchList(t, recorder.Body, recipes[:5])
+			},
+		},
+		{
+			name:    "UnsupportedVersion",
+			query:   "page_id=1&page_size=5",
+			version: "3",
+			buildStubs: func(store *mockedstore.MockStore) {
+				store.EXPECT().SearchRecipes(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+
+				var p problem.Problem
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &p))
+				require.Equal(t, problem.CodeUnsupportedVersion, p.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := newMockStore(ctrl)
+			tc.buildStubs(store)
+
+			config, err := env.NewConfig()
+			require.NoError(t, err)
+
+			server, err := bookRecipeFactory.New(config, store)
+			require.NoError(t, err)
+			recorder := httptest.NewRecorder()
+
+			req, err := http.NewRequest(http.MethodGet, "/recipes?"+tc.query, nil)
+			require.NoError(t, err)
+			req.Header.Set("API-Version", tc.version)
+
+			addAuthorization(t, req, server.TokenAuth, authMiddleware.AuthorizationTypeBearer, author.Username, time.Minute)
+			server.Router.ServeHTTP(recorder, req)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
 func randomAuthor(t *testing.T) db.Author {
 	randomPassword := random.String(8)
 	hashedPassword, err := password.HashPassword(randomPassword)
@@ -982,10 +1882,25 @@ func randomRecipe(authorName string) db.Recipe {
 		Ingredients: random.StringSlice(5),
 		CreatedAt:   now,
 		UpdatedAt:   now,
+		Version:     1,
 	}
 	return recipe
 }
 
+// requireConflict requires a version conflict reporting current as the current recipe,
+// along with its ETag
+func requireConflict(t *testing.T, recorder *httptest.ResponseRecorder, current db.Recipe) {
+	p := requireProblem(t, recorder, problem.CodeVersionConflict)
+
+	data, err := json.Marshal(recipeModel.GetResponse(current))
+	require.NoError(t, err)
+	var want interface{}
+	require.NoError(t, json.Unmarshal(data, &want))
+	require.Equal(t, want, p.Current)
+
+	require.Equal(t, etag.Version(current.Version), recorder.Header().Get("ETag"))
+}
+
 func requireBodyMatchCreate(t *testing.T, body *bytes.Buffer, recipe db.Recipe) {
 	data, err := ioutil.ReadAll(body)
 	require.NoError(t, err)
@@ -1005,7 +1920,7 @@ func requireBodyMatchCreate(t *testing.T, body *bytes.Buffer, recipe db.Recipe)
 	require.Equal(t, expectedRecipeModel.Ingredients, gotRecipe.Ingredients)
 	require.Equal(t, expectedRecipeModel.Steps, gotRecipe.Steps)
 	require.Equal(t, expectedRecipeModel.CreatedAt, gotRecipe.CreatedAt)
-	require.Empty(t, gotRecipe.ID)
+	require.Equal(t, recipe.ID, gotRecipe.ID)
 	require.Empty(t, gotRecipe.UpdatedAt)
 }
 
@@ -1027,7 +1942,7 @@ func requireBodyMatchRecipe(t *testing.T, body *bytes.Buffer, recipe db.Recipe)
 	require.Equal(t, expectedRecipeModel.Steps, gotRecipe.Steps)
 	require.Equal(t, expectedRecipeModel.CreatedAt, gotRecipe.CreatedAt)
 	require.Equal(t, expectedRecipeModel.UpdatedAt, gotRecipe.UpdatedAt)
-	require.Empty(t, gotRecipe.ID)
+	require.Equal(t, recipe.ID, gotRecipe.ID)
 }
 
 func requireBodyMatchUpdate(t *testing.T, body *bytes.Buffer, recipe db.Recipe) {
@@ -1047,7 +1962,7 @@ func requireBodyMatchUpdate(t *testing.T, body *bytes.Buffer, recipe db.Recipe)
 	require.Equal(t, expectedUpdatedRecipeModel.Ingredients, gotRecipe.Ingredients)
 	require.Equal(t, expectedUpdatedRecipeModel.CreatedAt, gotRecipe.CreatedAt)
 	require.Equal(t, expectedUpdatedRecipeModel.UpdatedAt, gotRecipe.UpdatedAt)
-	require.Empty(t, gotRecipe.ID)
+	require.Equal(t, recipe.ID, gotRecipe.ID)
 }
 
 func requireBodyMatchList(t *testing.T, body *bytes.Buffer, recipes []db.Recipe) {
@@ -1067,7 +1982,7 @@ func requireBodyMatchList(t *testing.T, body *bytes.Buffer, recipes []db.Recipe)
 
 	for i, recipe := range gotRecipes {
 		require.NotEmpty(t, recipe)
-		require.Empty(t, recipe.ID)
+		require.Equal(t, expectedListRecipesModel[i].ID, recipe.ID)
 
 		require.Equal(t, expectedListRecipesModel[i].Author, recipe.Author)
 		require.Equal(t, expectedListRecipesModel[i].Steps, recipe.Steps)
@@ -1077,6 +1992,14 @@ func requireBodyMatchList(t *testing.T, body *bytes.Buffer, recipes []db.Recipe)
 	}
 }
 
+// newMockStore returns a mocked store in which the authors of the tokens are not disabled,
+// as checked by the authentication middleware on every request
+func newMockStore(ctrl *gomock.Controller) *mockedstore.MockStore {
+	store := mockedstore.NewMockStore(ctrl)
+	store.EXPECT().GetAuthorDisabledAt(gomock.Any(), gomock.Any()).AnyTimes().Return(sql.NullTime{}, nil)
+	return store
+}
+
 func addAuthorization(
 	t *testing.T,
 	request *http.Request,
@@ -1091,3 +2014,28 @@ func addAuthorization(
 	authorizationHeader := fmt.Sprintf("%s %s", authorizationType, token)
 	request.Header.Set(authMiddleware.AuthorizationHeaderKey, authorizationHeader)
 }
+
+func requireBodyMatchPage(t *testing.T, body *bytes.Buffer, recipes []db.Recipe) recipeModel.ListPageResponse {
+	data, err := ioutil.ReadAll(body)
+	require.NoError(t, err)
+
+	var res recipeModel.ListPageResponse
+	require.NoError(t, json.Unmarshal(data, &res))
+	require.Len(t, res.Items, len(recipes))
+	for i, recipe := range recipes {
+		require.Equal(t, recipe.ID, res.Items[i].ID)
+		require.Equal(t, recipe.Author, res.Items[i].Author)
+	}
+	return res
+}
+
+func requireProblem(t *testing.T, recorder *httptest.ResponseRecorder, code string) problem.Problem {
+	require.Equal(t, problem.ContentType, recorder.Header().Get("Content-Type"))
+
+	var p problem.Problem
+	err := json.Unmarshal(recorder.Body.Bytes(), &p)
+	require.NoError(t, err)
+	require.Equal(t, code, p.Code)
+	require.Equal(t, recorder.Code, p.Status)
+	return p
+}
@@ -0,0 +1,180 @@
+package authorDomain
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	authorModel "github.com/gmaschi/go-recipes-book/internal/models/author"
+	db "github.com/gmaschi/go-recipes-book/internal/services/datastore/postgresql/recipes/sqlc"
+	"github.com/gmaschi/go-recipes-book/internal/services/metrics"
+	"github.com/gmaschi/go-recipes-book/pkg/auth/tokenAuth"
+	"github.com/gmaschi/go-recipes-book/pkg/tools/password"
+	"github.com/gmaschi/go-recipes-book/pkg/tools/problem"
+	"github.com/gmaschi/go-recipes-book/pkg/tools/validators"
+	"net/http"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrNotAuthenticatedAuthor is reported when an author tries to change another account
+	ErrNotAuthenticatedAuthor = problem.New(http.StatusUnauthorized, problem.CodeAuthorNotAuthenticated, "author is not the authenticated author")
+
+	// ErrInvalidCredentials is reported when the password of a login does not match
+	ErrInvalidCredentials = problem.New(http.StatusUnauthorized, problem.CodeInvalidCredentials, "invalid username or password")
+
+	// ErrDisabledAuthor is reported when a disabled author logs in with valid credentials or
+	// uses a token issued before it was disabled
+	ErrDisabledAuthor = problem.New(http.StatusForbidden, problem.CodeAuthorDisabled, "author account is disabled")
+
+	// ErrVersionConflict is reported with the current author when an update is made against
+	// a version that is no longer the current one
+	ErrVersionConflict = problem.New(http.StatusConflict, problem.CodeVersionConflict, "author was updated by another request")
+)
+
+// Service applies the rules of the author accounts, shared by the REST, gRPC and GraphQL
+// APIs, which decode the requests, validate them against the models and encode the results
+type Service struct {
+	store         db.Store
+	tokenMaker    tokenAuth.Maker
+	tokenDuration time.Duration
+	metrics       *metrics.Metrics
+}
+
+// New creates a pointer to a Service that issues access tokens valid for tokenDuration with
+// tokenMaker, which must be the same maker used to verify them
+func New(store db.Store, tokenMaker tokenAuth.Maker, tokenDuration time.Duration, m *metrics.Metrics) *Service {
+	return &Service{
+		store:         store,
+		tokenMaker:    tokenMaker,
+		tokenDuration: tokenDuration,
+		metrics:       m,
+	}
+}
+
+// Create creates an author account, storing the hash of its password
+func (s *Service) Create(ctx context.Context, req authorModel.CreateRequest) (db.Author, error) {
+	hashedPassword, err := password.HashPassword(req.Password)
+	if err != nil {
+		return db.Author{}, err
+	}
+
+	return s.store.CreateAuthor(ctx, db.CreateAuthorParams{
+		Username:       req.Username,
+		HashedPassword: hashedPassword,
+		Email:          req.Email,
+	})
+}
+
+// Login authenticates an author and issues an access token
+func (s *Service) Login(ctx context.Context, req authorModel.LoginRequest) (db.Author, string, error) {
+	author, err := s.store.GetAuthor(ctx, req.Username)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			s.metrics.ObserveLogin(metrics.LoginFailure)
+		} else {
+			s.metrics.ObserveLogin(metrics.LoginError)
+		}
+		return db.Author{}, "", err
+	}
+
+	if err := password.CheckPassword(req.Password, author.HashedPassword); err != nil {
+		s.metrics.ObserveLogin(metrics.LoginFailure)
+		return db.Author{}, "", ErrInvalidCredentials.WithCause(err)
+	}
+
+	if author.DisabledAt.Valid {
+		s.metrics.ObserveLogin(metrics.LoginFailure)
+		return db.Author{}, "", ErrDisabledAuthor
+	}
+
+	token, err := s.tokenMaker.CreateToken(author.Username, s.tokenDuration)
+	if err != nil {
+		s.metrics.ObserveLogin(metrics.LoginError)
+		return db.Author{}, "", err
+	}
+	s.metrics.ObserveLogin(metrics.LoginSuccess)
+
+	return author, token, nil
+}
+
+// Update changes the non-blank email and/or password of the author of req, who must be the
+// authenticated author username, if it still has the version the update is made against.
+// Either being outdated is a conflict, reported with the current author.
+func (s *Service) Update(ctx context.Context, username string, req authorModel.UpdateRequest) (db.Author, error) {
+	if username != req.Username {
+		return db.Author{}, ErrNotAuthenticatedAuthor
+	}
+
+	author, err := s.store.GetAuthor(ctx, req.Username)
+	if err != nil {
+		return db.Author{}, err
+	}
+	if req.Version != author.Version {
+		return db.Author{}, conflict(author)
+	}
+
+	updateArgs := db.UpdateAuthorParams{
+		Username:       author.Username,
+		Email:          author.Email,
+		HashedPassword: author.HashedPassword,
+		UpdatedAt:      author.UpdatedAt,
+		Version:        req.Version,
+	}
+
+	now := time.Now().UTC()
+	trimmedEmail := strings.Trim(req.Email, " ")
+	trimmedPassword := strings.Trim(req.Password, " ")
+
+	if trimmedEmail != "" {
+		if !validators.Email(trimmedEmail) {
+			return db.Author{}, problem.Invalid(problem.FieldError{
+				Field:   "email",
+				Code:    "email",
+				Message: "must be a valid email address",
+			})
+		}
+		updateArgs.Email = trimmedEmail
+		updateArgs.UpdatedAt = now
+	}
+	if trimmedPassword != "" {
+		if !validators.Password(trimmedPassword) {
+			return db.Author{}, problem.Invalid(problem.FieldError{
+				Field:   "password",
+				Code:    "password",
+				Message: "must be between 6 and 24 characters long",
+			})
+		}
+		hashedPassword, err := password.HashPassword(trimmedPassword)
+		if err != nil {
+			return db.Author{}, err
+		}
+		updateArgs.HashedPassword = hashedPassword
+		updateArgs.UpdatedAt = now
+	}
+
+	updatedAuthor, err := s.store.UpdateAuthor(ctx, updateArgs)
+	if errors.Is(err, sql.ErrNoRows) {
+		// the author was updated or deleted by another request since it was read
+		if author, err = s.store.GetAuthor(ctx, req.Username); err == nil {
+			return db.Author{}, conflict(author)
+		}
+	}
+	if err != nil {
+		return db.Author{}, err
+	}
+	return updatedAuthor, nil
+}
+
+// Delete deletes the author of deleted, who must be the authenticated author username
+func (s *Service) Delete(ctx context.Context, username, deleted string) error {
+	if username != deleted {
+		return ErrNotAuthenticatedAuthor
+	}
+	return s.store.DeleteAuthor(ctx, deleted)
+}
+
+// conflict reports that an update was made against an outdated version of author
+func conflict(author db.Author) error {
+	return ErrVersionConflict.WithCurrent(authorModel.GetResponse(author))
+}
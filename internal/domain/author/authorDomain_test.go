@@ -0,0 +1,100 @@
+package authorDomain_test
+
+import (
+	"context"
+	"errors"
+	authorDomain "github.com/gmaschi/go-recipes-book/internal/domain/author"
+	memoryStore "github.com/gmaschi/go-recipes-book/internal/mocks/datastore/memory"
+	authorModel "github.com/gmaschi/go-recipes-book/internal/models/author"
+	db "github.com/gmaschi/go-recipes-book/internal/services/datastore/postgresql/recipes/sqlc"
+	"github.com/gmaschi/go-recipes-book/internal/services/metrics"
+	pasetoToken "github.com/gmaschi/go-recipes-book/pkg/auth/tokenAuth/paseto"
+	"github.com/gmaschi/go-recipes-book/pkg/tools/password"
+	"github.com/gmaschi/go-recipes-book/pkg/tools/problem"
+	"github.com/gmaschi/go-recipes-book/pkg/tools/random"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func newService(t *testing.T) (*authorDomain.Service, *memoryStore.Store) {
+	tokenMaker, err := pasetoToken.NewPasetoMaker(random.String(32))
+	require.NoError(t, err)
+
+	store := memoryStore.New()
+	return authorDomain.New(store, tokenMaker, time.Minute, metrics.New()), store
+}
+
+// createAuthor creates an author with the password pass
+func createAuthor(t *testing.T, service *authorDomain.Service, pass string) db.Author {
+	author, err := service.Create(context.Background(), authorModel.CreateRequest{
+		Username: random.String(10),
+		Password: pass,
+		Email:    random.Email(),
+	})
+	require.NoError(t, err)
+	require.NoError(t, password.CheckPassword(pass, author.HashedPassword))
+	return author
+}
+
+// requireCode requires err to be a problem with code
+func requireCode(t *testing.T, err error, code string) *problem.Error {
+	t.Helper()
+	var p *problem.Error
+	require.True(t, errors.As(err, &p), "not a problem: %v", err)
+	require.Equal(t, code, p.Code)
+	return p
+}
+
+func TestLogin(t *testing.T) {
+	service, store := newService(t)
+	pass := random.String(8)
+	author := createAuthor(t, service, pass)
+
+	got, token, err := service.Login(context.Background(), authorModel.LoginRequest{Username: author.Username, Password: pass})
+	require.NoError(t, err)
+	require.Equal(t, author.Username, got.Username)
+	require.NotEmpty(t, token)
+
+	_, _, err = service.Login(context.Background(), authorModel.LoginRequest{Username: author.Username, Password: random.String(8)})
+	requireCode(t, err, problem.CodeInvalidCredentials)
+
+	_, err = store.DisableAuthor(context.Background(), author.Username)
+	require.NoError(t, err)
+	_, _, err = service.Login(context.Background(), authorModel.LoginRequest{Username: author.Username, Password: pass})
+	require.ErrorIs(t, err, authorDomain.ErrDisabledAuthor)
+}
+
+func TestUpdate(t *testing.T) {
+	service, _ := newService(t)
+	author := createAuthor(t, service, random.String(8))
+
+	_, err := service.Update(context.Background(), random.String(10), authorModel.UpdateRequest{Username: author.Username, Version: author.Version})
+	require.ErrorIs(t, err, authorDomain.ErrNotAuthenticatedAuthor)
+
+	_, err = service.Update(context.Background(), author.Username, authorModel.UpdateRequest{Username: author.Username, Version: author.Version, Email: "invalid"})
+	p := requireCode(t, err, problem.CodeInvalidRequest)
+	require.Equal(t, "email", p.Fields[0].Field)
+
+	email := random.Email()
+	updated, err := service.Update(context.Background(), author.Username, authorModel.UpdateRequest{Username: author.Username, Version: author.Version, Email: " " + email + " "})
+	require.NoError(t, err)
+	require.Equal(t, email, updated.Email)
+	require.Equal(t, author.Version+1, updated.Version)
+
+	_, err = service.Update(context.Background(), author.Username, authorModel.UpdateRequest{Username: author.Username, Version: author.Version, Email: random.Email()})
+	p = requireCode(t, err, problem.CodeVersionConflict)
+	require.Equal(t, authorModel.GetResponse(updated), p.Current)
+}
+
+func TestDelete(t *testing.T) {
+	service, store := newService(t)
+	author := createAuthor(t, service, random.String(8))
+
+	err := service.Delete(context.Background(), random.String(10), author.Username)
+	require.ErrorIs(t, err, authorDomain.ErrNotAuthenticatedAuthor)
+
+	require.NoError(t, service.Delete(context.Background(), author.Username, author.Username))
+	_, err = store.GetAuthor(context.Background(), author.Username)
+	require.Error(t, err)
+}
@@ -0,0 +1,186 @@
+package recipeDomain
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	recipeModel "github.com/gmaschi/go-recipes-book/internal/models/recipe"
+	db "github.com/gmaschi/go-recipes-book/internal/services/datastore/postgresql/recipes/sqlc"
+	"github.com/gmaschi/go-recipes-book/pkg/tools/etag"
+	"github.com/gmaschi/go-recipes-book/pkg/tools/problem"
+	"net/http"
+	"time"
+)
+
+var (
+	// ErrNotOwnedRecipe is reported when an author accesses the recipe of another author
+	ErrNotOwnedRecipe = problem.New(http.StatusUnauthorized, problem.CodeRecipeNotOwned, "recipe does not belong to the authenticated author")
+
+	// ErrMissingIfMatch is reported when an update required to tell which version of the
+	// recipe it changes does not
+	ErrMissingIfMatch = problem.New(http.StatusPreconditionRequired, problem.CodeMissingPrecondition, "If-Match must be set to the ETag of the recipe")
+
+	// ErrPreconditionFailed is reported when the recipe changed since the client read the
+	// ETag sent in If-Match
+	ErrPreconditionFailed = problem.New(http.StatusPreconditionFailed, problem.CodePreconditionFailed, "recipe was modified since it was read")
+
+	// ErrVersionConflict is reported with the current recipe when an update is made against
+	// a version that is no longer the current one
+	ErrVersionConflict = problem.New(http.StatusConflict, problem.CodeVersionConflict, "recipe was updated by another request")
+)
+
+// PreconditionFailedError reports ErrPreconditionFailed along with the current ETag of the
+// recipe, which is not sent in the problem
+type PreconditionFailedError struct {
+	ETag string
+}
+
+func (e *PreconditionFailedError) Error() string {
+	return ErrPreconditionFailed.Error()
+}
+
+func (e *PreconditionFailedError) Unwrap() error {
+	return ErrPreconditionFailed
+}
+
+// Precondition is the If-Match precondition of an update, the ETag the recipe must still
+// have in addition to the version of the update
+type Precondition struct {
+	IfMatch string
+	// Required reports the updates without IfMatch with ErrMissingIfMatch, which are
+	// otherwise only checked against their version
+	Required bool
+}
+
+// Service applies the rules of the recipes, shared by the REST, gRPC and GraphQL APIs,
+// which decode the requests, validate them against the models and encode the results.
+// Recipes are private to their author.
+type Service struct {
+	store db.Store
+}
+
+// New creates a pointer to a Service
+func New(store db.Store) *Service {
+	return &Service{
+		store: store,
+	}
+}
+
+// Create creates a recipe of author, which must have ingredients and steps
+func (s *Service) Create(ctx context.Context, author string, req recipeModel.CreateRequest) (db.Recipe, error) {
+	var fields []problem.FieldError
+	if len(req.Ingredients) == 0 {
+		fields = append(fields, problem.FieldError{Field: "ingredients", Code: "min", Message: "must have at least 1 items"})
+	}
+	if len(req.Steps) == 0 {
+		fields = append(fields, problem.FieldError{Field: "steps", Code: "min", Message: "must have at least 1 items"})
+	}
+	if len(fields) > 0 {
+		return db.Recipe{}, problem.Invalid(fields...)
+	}
+
+	return s.store.CreateRecipe(ctx, db.CreateRecipeParams{
+		Author:      author,
+		Ingredients: req.Ingredients,
+		Steps:       req.Steps,
+	})
+}
+
+// Get returns the recipe with the given ID if it belongs to author
+func (s *Service) Get(ctx context.Context, author string, id int64) (db.Recipe, error) {
+	recipe, err := s.store.GetRecipe(ctx, id)
+	if err != nil {
+		return db.Recipe{}, err
+	}
+	if recipe.Author != author {
+		return db.Recipe{}, ErrNotOwnedRecipe
+	}
+	return recipe, nil
+}
+
+// Update replaces the non-empty ingredients and/or steps of a recipe of author, if it still
+// matches precondition and has the version the update is made against, so that an update
+// never overwrites changes the client has not seen. An outdated If-Match is reported with a
+// PreconditionFailedError, and an outdated version is a conflict, reported with the current
+// recipe.
+func (s *Service) Update(ctx context.Context, author string, req recipeModel.UpdateRequest, precondition Precondition) (db.Recipe, error) {
+	recipe, err := s.Get(ctx, author, req.ID)
+	if err != nil {
+		return db.Recipe{}, err
+	}
+
+	if precondition.IfMatch == "" && precondition.Required {
+		return db.Recipe{}, ErrMissingIfMatch
+	}
+	if tag := etag.Version(recipe.Version); precondition.IfMatch != "" && !etag.StrongMatch(precondition.IfMatch, tag) {
+		return db.Recipe{}, &PreconditionFailedError{ETag: tag}
+	}
+	if req.Version != recipe.Version {
+		return db.Recipe{}, conflict(recipe)
+	}
+
+	now := time.Now().UTC()
+	updateArgs := db.UpdateRecipeParams{
+		ID:          recipe.ID,
+		Steps:       recipe.Steps,
+		Ingredients: recipe.Ingredients,
+		Version:     req.Version,
+	}
+	if len(req.Steps) != 0 {
+		updateArgs.Steps = req.Steps
+		updateArgs.UpdatedAt = now
+	}
+	if len(req.Ingredients) != 0 {
+		updateArgs.Ingredients = req.Ingredients
+		updateArgs.UpdatedAt = now
+	}
+
+	updatedRecipe, err := s.store.UpdateRecipe(ctx, updateArgs)
+	if errors.Is(err, sql.ErrNoRows) {
+		// the recipe was updated or deleted by another request since it was read
+		if recipe, err = s.store.GetRecipe(ctx, req.ID); err == nil {
+			return db.Recipe{}, conflict(recipe)
+		}
+	}
+	if err != nil {
+		return db.Recipe{}, err
+	}
+	return updatedRecipe, nil
+}
+
+// Delete soft-deletes a recipe of author, which is only removed from the database by the
+// admin purge command
+func (s *Service) Delete(ctx context.Context, author string, id int64) error {
+	if _, err := s.Get(ctx, author, id); err != nil {
+		return err
+	}
+	return s.store.DeleteRecipe(ctx, id)
+}
+
+// Current returns the current recipe reported by the conflict err, if it is one
+func Current(err error) (recipeModel.GetResponse, bool) {
+	var p *problem.Error
+	if !errors.As(err, &p) {
+		return recipeModel.GetResponse{}, false
+	}
+	current, ok := p.Current.(recipeModel.GetResponse)
+	return current, ok
+}
+
+// CurrentETag returns the current ETag of the recipe reported by err, when it reports a
+// failed precondition or a conflict
+func CurrentETag(err error) (string, bool) {
+	var failed *PreconditionFailedError
+	if errors.As(err, &failed) {
+		return failed.ETag, true
+	}
+	if current, ok := Current(err); ok {
+		return etag.Version(current.Version), true
+	}
+	return "", false
+}
+
+// conflict reports that an update was made against an outdated version of recipe
+func conflict(recipe db.Recipe) error {
+	return ErrVersionConflict.WithCurrent(recipeModel.GetResponse(recipe))
+}
@@ -0,0 +1,128 @@
+package recipeDomain_test
+
+import (
+	"context"
+	"errors"
+	recipeDomain "github.com/gmaschi/go-recipes-book/internal/domain/recipe"
+	memoryStore "github.com/gmaschi/go-recipes-book/internal/mocks/datastore/memory"
+	recipeModel "github.com/gmaschi/go-recipes-book/internal/models/recipe"
+	db "github.com/gmaschi/go-recipes-book/internal/services/datastore/postgresql/recipes/sqlc"
+	"github.com/gmaschi/go-recipes-book/pkg/tools/etag"
+	"github.com/gmaschi/go-recipes-book/pkg/tools/problem"
+	"github.com/gmaschi/go-recipes-book/pkg/tools/random"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+// createRecipe creates an author and a recipe of the author
+func createRecipe(t *testing.T, store *memoryStore.Store, service *recipeDomain.Service) db.Recipe {
+	author, err := store.CreateAuthor(context.Background(), db.CreateAuthorParams{
+		Username:       random.String(10),
+		HashedPassword: random.String(20),
+		Email:          random.Email(),
+	})
+	require.NoError(t, err)
+
+	recipe, err := service.Create(context.Background(), author.Username, recipeModel.CreateRequest{
+		Ingredients: random.StringSlice(3),
+		Steps:       random.StringSlice(3),
+	})
+	require.NoError(t, err)
+	return recipe
+}
+
+// requireCode requires err to be a problem with code
+func requireCode(t *testing.T, err error, code string) *problem.Error {
+	t.Helper()
+	var p *problem.Error
+	require.True(t, errors.As(err, &p), "not a problem: %v", err)
+	require.Equal(t, code, p.Code)
+	return p
+}
+
+func TestCreate(t *testing.T) {
+	service := recipeDomain.New(memoryStore.New())
+
+	_, err := service.Create(context.Background(), random.String(10), recipeModel.CreateRequest{Ingredients: []string{"flour"}})
+	p := requireCode(t, err, problem.CodeInvalidRequest)
+	require.Len(t, p.Fields, 1)
+	require.Equal(t, "steps", p.Fields[0].Field)
+}
+
+func TestGet(t *testing.T) {
+	store := memoryStore.New()
+	service := recipeDomain.New(store)
+	recipe := createRecipe(t, store, service)
+
+	got, err := service.Get(context.Background(), recipe.Author, recipe.ID)
+	require.NoError(t, err)
+	require.Equal(t, recipe, got)
+
+	_, err = service.Get(context.Background(), random.String(10), recipe.ID)
+	require.ErrorIs(t, err, recipeDomain.ErrNotOwnedRecipe)
+}
+
+func TestUpdate(t *testing.T) {
+	store := memoryStore.New()
+	service := recipeDomain.New(store)
+	recipe := createRecipe(t, store, service)
+
+	update := func(version int32, precondition recipeDomain.Precondition) (db.Recipe, error) {
+		return service.Update(context.Background(), recipe.Author, recipeModel.UpdateRequest{
+			ID:      recipe.ID,
+			Version: version,
+			Steps:   random.StringSlice(2),
+		}, precondition)
+	}
+
+	_, err := update(recipe.Version, recipeDomain.Precondition{Required: true})
+	require.ErrorIs(t, err, recipeDomain.ErrMissingIfMatch)
+
+	updated, err := update(recipe.Version, recipeDomain.Precondition{IfMatch: etag.Version(recipe.Version), Required: true})
+	require.NoError(t, err)
+	require.Equal(t, recipe.Version+1, updated.Version)
+	require.Equal(t, recipe.Ingredients, updated.Ingredients)
+
+	t.Run("StaleVersion", func(t *testing.T) {
+		_, err := update(recipe.Version, recipeDomain.Precondition{})
+		requireCode(t, err, problem.CodeVersionConflict)
+
+		current, ok := recipeDomain.Current(err)
+		require.True(t, ok)
+		require.Equal(t, recipeModel.GetResponse(updated), current)
+
+		tag, ok := recipeDomain.CurrentETag(err)
+		require.True(t, ok)
+		require.Equal(t, etag.Version(updated.Version), tag)
+	})
+
+	t.Run("StaleIfMatch", func(t *testing.T) {
+		_, err := update(updated.Version, recipeDomain.Precondition{IfMatch: etag.Version(recipe.Version)})
+		requireCode(t, err, problem.CodePreconditionFailed)
+		require.ErrorIs(t, err, recipeDomain.ErrPreconditionFailed)
+
+		_, ok := recipeDomain.Current(err)
+		require.False(t, ok)
+
+		tag, ok := recipeDomain.CurrentETag(err)
+		require.True(t, ok)
+		require.Equal(t, etag.Version(updated.Version), tag)
+	})
+
+	_, ok := recipeDomain.CurrentETag(recipeDomain.ErrNotOwnedRecipe)
+	require.False(t, ok)
+}
+
+func TestDelete(t *testing.T) {
+	store := memoryStore.New()
+	service := recipeDomain.New(store)
+	recipe := createRecipe(t, store, service)
+
+	err := service.Delete(context.Background(), random.String(10), recipe.ID)
+	require.ErrorIs(t, err, recipeDomain.ErrNotOwnedRecipe)
+
+	require.NoError(t, service.Delete(context.Background(), recipe.Author, recipe.ID))
+
+	_, err = service.Get(context.Background(), recipe.Author, recipe.ID)
+	require.Error(t, err)
+}
@@ -4,15 +4,35 @@ import (
 	"fmt"
 	"github.com/gin-gonic/gin"
 	authorController "github.com/gmaschi/go-recipes-book/internal/controllers/author"
+	docsController "github.com/gmaschi/go-recipes-book/internal/controllers/docs"
+	graphqlController "github.com/gmaschi/go-recipes-book/internal/controllers/graphql"
+	healthController "github.com/gmaschi/go-recipes-book/internal/controllers/health"
 	authMiddleware "github.com/gmaschi/go-recipes-book/internal/controllers/middlewares/auth"
+	errorMiddleware "github.com/gmaschi/go-recipes-book/internal/controllers/middlewares/errors"
+	idempotencyMiddleware "github.com/gmaschi/go-recipes-book/internal/controllers/middlewares/idempotency"
+	loggingMiddleware "github.com/gmaschi/go-recipes-book/internal/controllers/middlewares/logging"
+	metricsMiddleware "github.com/gmaschi/go-recipes-book/internal/controllers/middlewares/metrics"
+	rateLimitMiddleware "github.com/gmaschi/go-recipes-book/internal/controllers/middlewares/ratelimit"
+	tracingMiddleware "github.com/gmaschi/go-recipes-book/internal/controllers/middlewares/tracing"
+	versionMiddleware "github.com/gmaschi/go-recipes-book/internal/controllers/middlewares/version"
 	recipeController "github.com/gmaschi/go-recipes-book/internal/controllers/recipe"
+	authorDomain "github.com/gmaschi/go-recipes-book/internal/domain/author"
+	recipeDomain "github.com/gmaschi/go-recipes-book/internal/domain/recipe"
+	instrumentedStore "github.com/gmaschi/go-recipes-book/internal/services/datastore/postgresql/recipes/instrumented"
 	db "github.com/gmaschi/go-recipes-book/internal/services/datastore/postgresql/recipes/sqlc"
+	"github.com/gmaschi/go-recipes-book/internal/services/metrics"
+	"github.com/gmaschi/go-recipes-book/internal/services/ratelimit"
 	"github.com/gmaschi/go-recipes-book/pkg/auth/tokenAuth"
 	pasetoToken "github.com/gmaschi/go-recipes-book/pkg/auth/tokenAuth/paseto"
 	"github.com/gmaschi/go-recipes-book/pkg/config/env"
+	"go.opentelemetry.io/otel"
+	"google.golang.org/grpc"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
 )
 
-
 type (
 	Factory struct {
 		store              db.Store
@@ -20,55 +40,141 @@ type (
 		TokenAuth          tokenAuth.Maker
 		Config             env.Config
 		Router             *gin.Engine
+		Logger             *slog.Logger
+		Metrics            *metrics.Metrics
+		GRPCServer         *grpc.Server
+
+		rateLimit        gin.HandlerFunc
+		authors          *authorDomain.Service
+		recipes          *recipeDomain.Service
+		rateLimitBackend ratelimit.Backend
+		rateLimits       map[string]ratelimit.Limit
+		httpServer       *http.Server
+		workers          sync.WaitGroup
+		workerStop       chan struct{}
+		closers          []func() error
 	}
 
 	bookRecipesHandler struct {
-		authorController *authorController.Controller
-		recipeController *recipeController.Controller
+		authorController  *authorController.Controller
+		recipeController  *recipeController.Controller
+		healthController  *healthController.Controller
+		docsController    *docsController.Controller
+		graphqlController *graphqlController.Controller
 	}
 )
 
 func New(config env.Config, store db.Store) (*Factory, error) {
-	tokenMaker, err := pasetoToken.NewPasetoMaker(config.TokenSymmetricKey)
+	tokenMaker, err := pasetoToken.NewPasetoMaker(config.Auth.TokenSymmetricKey)
 	if err != nil {
 		return nil, fmt.Errorf("cannot create token maker: %w", err)
 	}
 
+	// logs go to the default logger, installed by main with slog.SetDefault, so that the
+	// server and its startup share the same handler
+	log := slog.Default()
+
+	// spans go to the global tracer provider and propagator, installed by main with
+	// tracing.SetGlobal, and are dropped when none was installed
+	tracerProvider := otel.GetTracerProvider()
+
+	m := metrics.New()
+	if store != nil {
+		store = instrumentedStore.New(store, m, tracerProvider)
+		m.RegisterDBStats(store.Stats)
+	}
+
+	docs, err := docsController.New(openAPIDocument())
+	if err != nil {
+		return nil, err
+	}
+
+	// the rules of authors and recipes are shared by the REST, gRPC and GraphQL APIs
+	authors := authorDomain.New(store, tokenMaker, config.Auth.TokenDuration, m)
+	recipes := recipeDomain.New(store)
+
 	factory := &Factory{
-		store: store,
+		store:   store,
+		authors: authors,
+		recipes: recipes,
 		bookRecipesHandler: bookRecipesHandler{
-			authorController: authorController.New(store),
-			recipeController: recipeController.New(store),
+			authorController: authorController.New(store, authors),
+			recipeController: recipeController.New(store, recipes),
+			docsController:   docs,
 		},
-		TokenAuth: tokenMaker,
-		Config:    config,
+		TokenAuth:  tokenMaker,
+		Config:     config,
+		Logger:     log,
+		Metrics:    m,
+		workerStop: make(chan struct{}),
+	}
+	if err := factory.setupRateLimit(); err != nil {
+		return nil, err
+	}
+	factory.bookRecipesHandler.graphqlController, err = graphqlController.New(store, authors, recipes, factory.chargeRateLimit)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse graphql schema: %w", err)
+	}
+
+	router := gin.New()
+	if err := router.SetTrustedProxies(trustedProxies(config.HTTP.TrustedProxies)); err != nil {
+		return nil, fmt.Errorf("invalid HTTP_TRUSTED_PROXIES: %w", err)
 	}
-	router := gin.Default()
+	router.Use(
+		loggingMiddleware.RequestIDMiddleware(log),
+		tracingMiddleware.TracingMiddleware(tracerProvider, otel.GetTextMapPropagator()),
+		loggingMiddleware.LoggerMiddleware(),
+		errorMiddleware.ErrorMiddleware(),
+		loggingMiddleware.RecoveryMiddleware(),
+		metricsMiddleware.MetricsMiddleware(m),
+		versionMiddleware.VersionMiddleware(),
+	)
 
+	factory.bookRecipesHandler.healthController = healthController.New(factory.readinessChecks()...)
 	factory.setupRoutes(router)
 
 	factory.Router = router
+	factory.GRPCServer = factory.newGRPCServer(store)
+	factory.httpServer = &http.Server{
+		Addr:              config.HTTP.Address,
+		Handler:           router,
+		ReadTimeout:       config.HTTP.ReadTimeout,
+		ReadHeaderTimeout: config.HTTP.ReadHeaderTimeout,
+		WriteTimeout:      config.HTTP.WriteTimeout,
+		IdleTimeout:       config.HTTP.IdleTimeout,
+	}
 	return factory, nil
 }
 
 func (f *Factory) setupRoutes(router *gin.Engine) {
+	router.NoRoute(errorMiddleware.NoRoute)
+
+	router.GET("/healthz", f.bookRecipesHandler.healthController.Live)
+	router.GET("/readyz", f.bookRecipesHandler.healthController.Ready)
+	router.GET("/metrics", gin.WrapH(f.Metrics.Handler()))
+	router.GET(docsController.SpecPath, f.bookRecipesHandler.docsController.Spec)
+	router.GET("/docs", f.bookRecipesHandler.docsController.Docs)
 
-	authors := router.Group("/authors")
+	router.POST("/graphql", authMiddleware.OptionalAuthMiddleware(f.TokenAuth, f.store), f.rateLimit, f.bookRecipesHandler.graphqlController.Query)
+
+	authors := router.Group("/authors", errorMiddleware.Resource("author"))
 	{
-		authors.POST("/login", f.bookRecipesHandler.authorController.Login)
-		authors.POST("", f.bookRecipesHandler.authorController.Create)
-		authors.GET("/:username", f.bookRecipesHandler.authorController.Author)
-		authors.GET("", f.bookRecipesHandler.authorController.List)
+		anonymousAuthorsRoutes := authors.Group("").Use(f.rateLimit)
+
+		anonymousAuthorsRoutes.POST("/login", f.bookRecipesHandler.authorController.Login)
+		anonymousAuthorsRoutes.POST("", f.bookRecipesHandler.authorController.Create)
+		anonymousAuthorsRoutes.GET("/:username", f.bookRecipesHandler.authorController.Author)
+		anonymousAuthorsRoutes.GET("", f.bookRecipesHandler.authorController.List)
 
-		authAuthorsRoutes := authors.Group("").Use(authMiddleware.AuthMiddleware(f.TokenAuth))
+		authAuthorsRoutes := authors.Group("").Use(authMiddleware.AuthMiddleware(f.TokenAuth, f.store), f.rateLimit)
 
 		authAuthorsRoutes.PATCH("", f.bookRecipesHandler.authorController.Update)
 		authAuthorsRoutes.DELETE("/:username", f.bookRecipesHandler.authorController.Delete)
 	}
 
-	recipes := router.Group("/recipes").Use(authMiddleware.AuthMiddleware(f.TokenAuth))
+	recipes := router.Group("/recipes", errorMiddleware.Resource("recipe")).Use(authMiddleware.AuthMiddleware(f.TokenAuth, f.store), f.rateLimit)
 	{
-		recipes.POST("", f.bookRecipesHandler.recipeController.Create)
+		recipes.POST("", idempotencyMiddleware.IdempotencyMiddleware(f.store, f.Config.Idempotency.KeyTTL, f.Config.HTTP.WriteTimeout), f.bookRecipesHandler.recipeController.Create)
 		recipes.GET("/:id", f.bookRecipesHandler.recipeController.Recipe)
 		recipes.PATCH("", f.bookRecipesHandler.recipeController.Update)
 		recipes.DELETE("/:id", f.bookRecipesHandler.recipeController.Delete)
@@ -76,6 +182,44 @@ func (f *Factory) setupRoutes(router *gin.Engine) {
 	}
 }
 
-func (f *Factory) Start(address string) error {
-	return f.Router.Run(address)
+// trustedProxies splits the comma separated proxies of the config. Gin trusts every proxy
+// unless told otherwise, letting any client choose its IP, and hence its rate limit, with
+// X-Forwarded-For, so no proxy is trusted when none is configured.
+func trustedProxies(s string) []string {
+	var proxies []string
+	for _, proxy := range strings.Split(s, ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			proxies = append(proxies, proxy)
+		}
+	}
+	return proxies
+}
+
+// setupRateLimit creates the rate limiting middleware of the API routes from the config,
+// with the backend it selects
+func (f *Factory) setupRateLimit() error {
+	limits, err := ratelimit.ParseRoutes(f.Config.RateLimit.Routes)
+	if err != nil {
+		return fmt.Errorf("invalid RATE_LIMIT_ROUTES: %w", err)
+	}
+
+	var backend ratelimit.Backend
+	switch f.Config.RateLimit.Backend {
+	case "redis":
+		client := ratelimit.NewRESPClient(f.Config.RateLimit.RedisAddress, f.Config.RateLimit.RedisPassword, f.Config.RateLimit.RedisPoolSize, f.Config.RateLimit.RedisTimeout)
+		f.OnShutdown(client.Close)
+		backend = ratelimit.NewRedisBackend(client, "recipes-book:ratelimit:")
+	default:
+		backend = ratelimit.NewMemoryBackend()
+	}
+
+	f.rateLimitBackend, f.rateLimits = backend, limits
+	f.rateLimit = rateLimitMiddleware.RateLimitMiddleware(backend, limits)
+	return nil
+}
+
+// chargeRateLimit charges a request to the limit of route, for the GraphQL mutations that
+// mirror a REST route
+func (f *Factory) chargeRateLimit(ctx *gin.Context, route string) error {
+	return rateLimitMiddleware.Charge(ctx, f.rateLimitBackend, f.rateLimits, route)
 }
@@ -0,0 +1,57 @@
+package bookRecipeFactory
+
+import (
+	authorService "github.com/gmaschi/go-recipes-book/internal/rpc/author"
+	authInterceptor "github.com/gmaschi/go-recipes-book/internal/rpc/interceptors/auth"
+	errorInterceptor "github.com/gmaschi/go-recipes-book/internal/rpc/interceptors/errors"
+	loggingInterceptor "github.com/gmaschi/go-recipes-book/internal/rpc/interceptors/logging"
+	rateLimitInterceptor "github.com/gmaschi/go-recipes-book/internal/rpc/interceptors/ratelimit"
+	recipeService "github.com/gmaschi/go-recipes-book/internal/rpc/recipe"
+	db "github.com/gmaschi/go-recipes-book/internal/services/datastore/postgresql/recipes/sqlc"
+	"github.com/gmaschi/go-recipes-book/internal/services/ratelimit"
+	recipesbookv1 "github.com/gmaschi/go-recipes-book/pkg/pb/recipesbook/v1"
+	"google.golang.org/grpc"
+	"net/http"
+)
+
+// grpcRoutes maps the gRPC methods to the REST routes they mirror, whose rate limits they
+// share
+var grpcRoutes = map[string]string{
+	recipesbookv1.AuthorService_Login_FullMethodName:        ratelimit.RouteKey(http.MethodPost, "/authors/login"),
+	recipesbookv1.AuthorService_CreateAuthor_FullMethodName: ratelimit.RouteKey(http.MethodPost, "/authors"),
+	recipesbookv1.AuthorService_GetAuthor_FullMethodName:    ratelimit.RouteKey(http.MethodGet, "/authors/:username"),
+	recipesbookv1.AuthorService_ListAuthors_FullMethodName:  ratelimit.RouteKey(http.MethodGet, "/authors"),
+	recipesbookv1.AuthorService_UpdateAuthor_FullMethodName: ratelimit.RouteKey(http.MethodPatch, "/authors"),
+	recipesbookv1.AuthorService_DeleteAuthor_FullMethodName: ratelimit.RouteKey(http.MethodDelete, "/authors/:username"),
+	recipesbookv1.RecipeService_CreateRecipe_FullMethodName: ratelimit.RouteKey(http.MethodPost, "/recipes"),
+	recipesbookv1.RecipeService_GetRecipe_FullMethodName:    ratelimit.RouteKey(http.MethodGet, "/recipes/:id"),
+	recipesbookv1.RecipeService_ListRecipes_FullMethodName:  ratelimit.RouteKey(http.MethodGet, "/recipes"),
+	recipesbookv1.RecipeService_UpdateRecipe_FullMethodName: ratelimit.RouteKey(http.MethodPatch, "/recipes"),
+	recipesbookv1.RecipeService_DeleteRecipe_FullMethodName: ratelimit.RouteKey(http.MethodDelete, "/recipes/:id"),
+}
+
+// newGRPCServer creates the gRPC server of the API, registering the same operations as
+// setupRoutes. The methods that are public in the REST API are public here too, and the
+// methods share the rate limits of their REST routes.
+func (f *Factory) newGRPCServer(store db.Store) *grpc.Server {
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(
+			loggingInterceptor.LoggingInterceptor(f.Logger),
+			errorInterceptor.ErrorInterceptor(map[string]string{
+				recipesbookv1.AuthorService_ServiceDesc.ServiceName: "author",
+				recipesbookv1.RecipeService_ServiceDesc.ServiceName: "recipe",
+			}),
+			authInterceptor.AuthInterceptor(f.TokenAuth, store,
+				recipesbookv1.AuthorService_Login_FullMethodName,
+				recipesbookv1.AuthorService_CreateAuthor_FullMethodName,
+				recipesbookv1.AuthorService_GetAuthor_FullMethodName,
+				recipesbookv1.AuthorService_ListAuthors_FullMethodName,
+			),
+			rateLimitInterceptor.RateLimitInterceptor(f.rateLimitBackend, f.rateLimits, grpcRoutes),
+		),
+	)
+
+	recipesbookv1.RegisterAuthorServiceServer(server, authorService.New(store, f.authors))
+	recipesbookv1.RegisterRecipeServiceServer(server, recipeService.New(store, f.recipes))
+	return server
+}
@@ -0,0 +1,223 @@
+package bookRecipeFactory_test
+
+import (
+	"context"
+	bookRecipeFactory "github.com/gmaschi/go-recipes-book/internal/factories/book-recipe-factory"
+	memoryStore "github.com/gmaschi/go-recipes-book/internal/mocks/datastore/memory"
+	errorInterceptor "github.com/gmaschi/go-recipes-book/internal/rpc/interceptors/errors"
+	loggingInterceptor "github.com/gmaschi/go-recipes-book/internal/rpc/interceptors/logging"
+	"github.com/gmaschi/go-recipes-book/pkg/config/env"
+	recipesbookv1 "github.com/gmaschi/go-recipes-book/pkg/pb/recipesbook/v1"
+	"github.com/gmaschi/go-recipes-book/pkg/tools/etag"
+	"github.com/gmaschi/go-recipes-book/pkg/tools/problem"
+	"github.com/gmaschi/go-recipes-book/pkg/tools/random"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"net"
+	"testing"
+	"time"
+)
+
+func newGRPCClient(t *testing.T, store *memoryStore.Store) *grpc.ClientConn {
+	config := env.Config{
+		Auth: env.AuthConfig{
+			TokenSymmetricKey: random.String(32),
+			TokenDuration:     time.Minute,
+		},
+	}
+	factory, err := bookRecipeFactory.New(config, store)
+	require.NoError(t, err)
+	return dialGRPC(t, factory)
+}
+
+// dialGRPC serves the gRPC API of factory in memory and returns a connection to it
+func dialGRPC(t *testing.T, factory *bookRecipeFactory.Factory) *grpc.ClientConn {
+	listener := bufconn.Listen(1024 * 1024)
+	go func() {
+		_ = factory.GRPCServer.Serve(listener)
+	}()
+	t.Cleanup(factory.GRPCServer.Stop)
+
+	conn, err := grpc.DialContext(context.Background(), "bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
+
+// login creates an author and returns a context that carries its access token
+func login(t *testing.T, authors recipesbookv1.AuthorServiceClient) (string, context.Context) {
+	username, pass := random.String(10), random.String(8)
+	_, err := authors.CreateAuthor(context.Background(), &recipesbookv1.CreateAuthorRequest{
+		Username: username,
+		Password: pass,
+		Email:    random.Email(),
+	})
+	require.NoError(t, err)
+
+	res, err := authors.Login(context.Background(), &recipesbookv1.LoginRequest{Username: username, Password: pass})
+	require.NoError(t, err)
+	require.NotEmpty(t, res.GetAccessToken())
+	require.Equal(t, username, res.GetAuthor().GetUsername())
+
+	return username, metadata.AppendToOutgoingContext(context.Background(), "authorization", "bearer "+res.GetAccessToken())
+}
+
+func requireStatus(t *testing.T, err error, code codes.Code, reason string) *status.Status {
+	t.Helper()
+	st, ok := status.FromError(err)
+	require.True(t, ok, "not a status: %v", err)
+	require.Equal(t, code, st.Code(), st.Message())
+	require.Equal(t, reason, errorInterceptor.Code(err))
+	return st
+}
+
+func TestGRPCAuthors(t *testing.T) {
+	conn := newGRPCClient(t, memoryStore.New())
+	authors := recipesbookv1.NewAuthorServiceClient(conn)
+	username, authCtx := login(t, authors)
+
+	var header metadata.MD
+	author, err := authors.GetAuthor(context.Background(), &recipesbookv1.GetAuthorRequest{Username: username}, grpc.Header(&header))
+	require.NoError(t, err)
+	require.Equal(t, username, author.GetUsername())
+	require.NotEmpty(t, header.Get(loggingInterceptor.RequestIDMetadataKey))
+
+	_, err = authors.GetAuthor(context.Background(), &recipesbookv1.GetAuthorRequest{Username: random.String(10)})
+	requireStatus(t, err, codes.NotFound, problem.CodeAuthorNotFound)
+
+	_, err = authors.CreateAuthor(context.Background(), &recipesbookv1.CreateAuthorRequest{
+		Username: username,
+		Password: random.String(8),
+		Email:    random.Email(),
+	})
+	requireStatus(t, err, codes.AlreadyExists, problem.CodeAuthorUsernameTaken)
+
+	_, err = authors.Login(context.Background(), &recipesbookv1.LoginRequest{Username: username, Password: random.String(8)})
+	requireStatus(t, err, codes.Unauthenticated, problem.CodeInvalidCredentials)
+
+	email := random.Email()
+	updated, err := authors.UpdateAuthor(authCtx, &recipesbookv1.UpdateAuthorRequest{Username: username, Version: 1, Email: email})
+	require.NoError(t, err)
+	require.Equal(t, email, updated.GetEmail())
+	require.EqualValues(t, 2, updated.GetVersion())
+
+	_, err = authors.UpdateAuthor(authCtx, &recipesbookv1.UpdateAuthorRequest{Username: username, Version: 1, Email: random.Email()})
+	requireStatus(t, err, codes.Aborted, problem.CodeVersionConflict)
+
+	_, err = authors.UpdateAuthor(context.Background(), &recipesbookv1.UpdateAuthorRequest{Username: username, Version: 2, Email: email})
+	requireStatus(t, err, codes.Unauthenticated, problem.CodeMissingAuthorization)
+
+	other, _ := login(t, authors)
+	_, err = authors.DeleteAuthor(authCtx, &recipesbookv1.DeleteAuthorRequest{Username: other})
+	requireStatus(t, err, codes.PermissionDenied, problem.CodeAuthorNotAuthenticated)
+
+	list, err := authors.ListAuthors(context.Background(), &recipesbookv1.ListAuthorsRequest{PageId: 1, PageSize: 5})
+	require.NoError(t, err)
+	require.Len(t, list.GetAuthors(), 2)
+
+	_, err = authors.DeleteAuthor(authCtx, &recipesbookv1.DeleteAuthorRequest{Username: username})
+	require.NoError(t, err)
+}
+
+func TestGRPCRecipes(t *testing.T) {
+	conn := newGRPCClient(t, memoryStore.New())
+	authors := recipesbookv1.NewAuthorServiceClient(conn)
+	recipes := recipesbookv1.NewRecipeServiceClient(conn)
+	username, authCtx := login(t, authors)
+
+	_, err := recipes.ListRecipes(context.Background(), &recipesbookv1.ListRecipesRequest{PageId: 1, PageSize: 5})
+	requireStatus(t, err, codes.Unauthenticated, problem.CodeMissingAuthorization)
+
+	invalidCtx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "bearer invalid")
+	_, err = recipes.ListRecipes(invalidCtx, &recipesbookv1.ListRecipesRequest{PageId: 1, PageSize: 5})
+	requireStatus(t, err, codes.Unauthenticated, problem.CodeTokenInvalid)
+
+	_, err = recipes.CreateRecipe(authCtx, &recipesbookv1.CreateRecipeRequest{Ingredients: []string{"flour"}})
+	st := requireStatus(t, err, codes.InvalidArgument, problem.CodeInvalidRequest)
+	var violations []string
+	for _, detail := range st.Details() {
+		if badRequest, ok := detail.(*errdetails.BadRequest); ok {
+			for _, violation := range badRequest.GetFieldViolations() {
+				violations = append(violations, violation.GetField())
+			}
+		}
+	}
+	require.Equal(t, []string{"steps"}, violations)
+
+	recipe, err := recipes.CreateRecipe(authCtx, &recipesbookv1.CreateRecipeRequest{
+		Ingredients: []string{"flour", "water"},
+		Steps:       []string{"mix", "bake"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, username, recipe.GetAuthor())
+	require.NotZero(t, recipe.GetId())
+
+	got, err := recipes.GetRecipe(authCtx, &recipesbookv1.GetRecipeRequest{Id: recipe.GetId()})
+	require.NoError(t, err)
+	require.Equal(t, recipe.GetIngredients(), got.GetIngredients())
+
+	updated, err := recipes.UpdateRecipe(authCtx, &recipesbookv1.UpdateRecipeRequest{Id: recipe.GetId(), Version: recipe.GetVersion(), Steps: []string{"knead"}})
+	require.NoError(t, err)
+	require.Equal(t, []string{"knead"}, updated.GetSteps())
+	require.Equal(t, recipe.GetIngredients(), updated.GetIngredients())
+	require.Equal(t, recipe.GetVersion()+1, updated.GetVersion())
+
+	_, err = recipes.UpdateRecipe(authCtx, &recipesbookv1.UpdateRecipeRequest{Id: recipe.GetId(), Version: recipe.GetVersion(), Steps: []string{"lost"}})
+	requireStatus(t, err, codes.Aborted, problem.CodeVersionConflict)
+	_, err = recipes.UpdateRecipe(authCtx, &recipesbookv1.UpdateRecipeRequest{Id: recipe.GetId(), Steps: []string{"blind"}})
+	requireStatus(t, err, codes.InvalidArgument, problem.CodeInvalidRequest)
+
+	// the if-match metadata is checked as the If-Match header of the REST API
+	staleCtx := metadata.AppendToOutgoingContext(authCtx, "if-match", etag.Version(recipe.GetVersion()))
+	_, err = recipes.UpdateRecipe(staleCtx, &recipesbookv1.UpdateRecipeRequest{Id: recipe.GetId(), Version: updated.GetVersion(), Steps: []string{"lost"}})
+	requireStatus(t, err, codes.FailedPrecondition, problem.CodePreconditionFailed)
+	matchCtx := metadata.AppendToOutgoingContext(authCtx, "if-match", etag.Version(updated.GetVersion()))
+	updated, err = recipes.UpdateRecipe(matchCtx, &recipesbookv1.UpdateRecipeRequest{Id: recipe.GetId(), Version: updated.GetVersion(), Steps: []string{"rest"}})
+	require.NoError(t, err)
+	require.Equal(t, []string{"rest"}, updated.GetSteps())
+
+	list, err := recipes.ListRecipes(authCtx, &recipesbookv1.ListRecipesRequest{PageId: 1, PageSize: 5})
+	require.NoError(t, err)
+	require.Len(t, list.GetRecipes(), 1)
+
+	_, err = recipes.ListRecipes(authCtx, &recipesbookv1.ListRecipesRequest{PageId: 0, PageSize: 500})
+	requireStatus(t, err, codes.InvalidArgument, problem.CodeInvalidRequest)
+
+	_, otherCtx := login(t, authors)
+	_, err = recipes.GetRecipe(otherCtx, &recipesbookv1.GetRecipeRequest{Id: recipe.GetId()})
+	requireStatus(t, err, codes.PermissionDenied, problem.CodeRecipeNotOwned)
+
+	_, err = recipes.DeleteRecipe(authCtx, &recipesbookv1.DeleteRecipeRequest{Id: recipe.GetId()})
+	require.NoError(t, err)
+
+	_, err = recipes.GetRecipe(authCtx, &recipesbookv1.GetRecipeRequest{Id: recipe.GetId()})
+	requireStatus(t, err, codes.NotFound, problem.CodeRecipeNotFound)
+}
+
+func TestGRPCDisabledAuthor(t *testing.T) {
+	store := memoryStore.New()
+	conn := newGRPCClient(t, store)
+	authors := recipesbookv1.NewAuthorServiceClient(conn)
+	recipes := recipesbookv1.NewRecipeServiceClient(conn)
+	username, authCtx := login(t, authors)
+
+	_, err := recipes.ListRecipes(authCtx, &recipesbookv1.ListRecipesRequest{PageId: 1, PageSize: 5})
+	require.NoError(t, err)
+
+	// the token issued before the author was disabled is no longer accepted
+	_, err = store.DisableAuthor(context.Background(), username)
+	require.NoError(t, err)
+	_, err = recipes.ListRecipes(authCtx, &recipesbookv1.ListRecipesRequest{PageId: 1, PageSize: 5})
+	requireStatus(t, err, codes.PermissionDenied, problem.CodeAuthorDisabled)
+}
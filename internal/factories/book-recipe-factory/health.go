@@ -0,0 +1,68 @@
+package bookRecipeFactory
+
+import (
+	"context"
+	"errors"
+	healthController "github.com/gmaschi/go-recipes-book/internal/controllers/health"
+	"github.com/gmaschi/go-recipes-book/internal/services/datastore/postgresql/recipes/migration"
+	"time"
+)
+
+var errStoreNotConfigured = errors.New("store is not configured")
+
+// readinessChecks lists the checks that must pass before the instance receives traffic
+func (f *Factory) readinessChecks() []healthController.Check {
+	return []healthController.Check{
+		{Name: "database", Run: f.checkDatabase},
+		{Name: "migrations", Run: f.checkMigrations},
+		{Name: "token_maker", Run: f.checkTokenMaker},
+	}
+}
+
+func (f *Factory) checkDatabase(ctx context.Context) error {
+	if f.store == nil {
+		return errStoreNotConfigured
+	}
+	return f.store.Ping(ctx)
+}
+
+// checkMigrations fails while migrations are pending or the schema is dirty. A schema ahead
+// of the binary passes, so that the instances still running an older binary keep serving
+// during a rolling deploy, while CheckCompatible keeps new instances from starting.
+func (f *Factory) checkMigrations(ctx context.Context) error {
+	if f.store == nil {
+		return errStoreNotConfigured
+	}
+
+	latest, err := migration.LatestVersion()
+	if err != nil {
+		return err
+	}
+
+	version, dirty, err := f.store.MigrationVersion(ctx)
+	if err != nil {
+		return err
+	}
+	status := migration.Status{Dirty: dirty, Latest: latest}
+	if version > 0 {
+		// migrate force -1 leaves a negative version when it clears all the migrations
+		status.Version = uint(version)
+	}
+	if err := status.Check(); !errors.Is(err, migration.ErrSchemaAhead) {
+		return err
+	}
+	return nil
+}
+
+func (f *Factory) checkTokenMaker(context.Context) error {
+	if f.TokenAuth == nil {
+		return errors.New("token maker is not configured")
+	}
+
+	token, err := f.TokenAuth.CreateToken("readiness", time.Minute)
+	if err != nil {
+		return err
+	}
+	_, err = f.TokenAuth.VerifyToken(token)
+	return err
+}
@@ -0,0 +1,30 @@
+package bookRecipeFactory
+
+import (
+	"context"
+	"time"
+)
+
+// purgeIdempotencyKeys deletes the expired idempotency keys every purge interval until stop
+// is closed. Expired keys are already reusable, deleting them only keeps the table small.
+func (f *Factory) purgeIdempotencyKeys(stop <-chan struct{}) {
+	ticker := time.NewTicker(f.Config.Idempotency.PurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+
+		n, err := f.store.PurgeIdempotencyKeys(context.Background(), time.Now().Add(-f.Config.Idempotency.KeyTTL))
+		if err != nil {
+			f.Logger.Error("cannot purge expired idempotency keys", "error", err)
+			continue
+		}
+		if n > 0 {
+			f.Logger.Info("purged expired idempotency keys", "count", n)
+		}
+	}
+}
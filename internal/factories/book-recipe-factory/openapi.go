@@ -0,0 +1,256 @@
+package bookRecipeFactory
+
+import (
+	docsController "github.com/gmaschi/go-recipes-book/internal/controllers/docs"
+	idempotencyMiddleware "github.com/gmaschi/go-recipes-book/internal/controllers/middlewares/idempotency"
+	versionMiddleware "github.com/gmaschi/go-recipes-book/internal/controllers/middlewares/version"
+	authorModel "github.com/gmaschi/go-recipes-book/internal/models/author"
+	graphqlModel "github.com/gmaschi/go-recipes-book/internal/models/graphql"
+	healthModel "github.com/gmaschi/go-recipes-book/internal/models/health"
+	recipeModel "github.com/gmaschi/go-recipes-book/internal/models/recipe"
+	"github.com/gmaschi/go-recipes-book/pkg/openapi"
+	"github.com/gmaschi/go-recipes-book/pkg/tools/etag"
+	"github.com/gmaschi/go-recipes-book/pkg/tools/fieldset"
+	"github.com/gmaschi/go-recipes-book/pkg/tools/problem"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// bearerAuth names the security scheme of the access tokens issued by the login route
+const bearerAuth = "bearerAuth"
+
+// openAPIDocument documents the routes registered by setupRoutes. Every route must have an
+// entry, which is enforced by the tests.
+func openAPIDocument() *openapi.Document {
+	doc := openapi.New(openapi.Info{
+		Title:       "Recipes Book API",
+		Description: "Authors and their recipes. Errors are reported as " + problem.ContentType + " documents with a stable code.",
+		Version:     "1.0.0",
+	})
+	doc.Components.SecuritySchemes[bearerAuth] = openapi.SecurityScheme{
+		Type:         "http",
+		Scheme:       "bearer",
+		BearerFormat: "PASETO",
+	}
+	authenticated := []map[string][]string{{bearerAuth: {}}}
+
+	responses := func(success openapi.Response, errorStatuses ...int) map[string]openapi.Response {
+		res := map[string]openapi.Response{strconv.Itoa(http.StatusOK): success}
+		for _, status := range errorStatuses {
+			res[strconv.Itoa(status)] = doc.JSONResponse(http.StatusText(status), problem.ContentType, problem.Problem{})
+		}
+		return res
+	}
+	ok := func(v interface{}) openapi.Response {
+		return doc.JSONResponse(http.StatusText(http.StatusOK), "application/json", v)
+	}
+	// paginated documents the query parameters of the lists, which take either a page ID or
+	// a cursor, so that none of them is required on its own, the sort and filters of the
+	// pages and the version header selecting their format. The cursor pages are neither
+	// sorted nor filtered, and keep their format whatever the version.
+	paginated := func(pageRequest, cursorRequest, filterRequest interface{}) []openapi.Parameter {
+		cursorParams := doc.Parameters(cursorRequest)
+		for i := range cursorParams {
+			schema := *cursorParams[i].Schema
+			schema.Description = "Cursor pagination, which rejects sort and the filters and returns a page with the next cursor whatever the " + versionMiddleware.HeaderKey
+			cursorParams[i].Schema = &schema
+		}
+		params := append(doc.Parameters(pageRequest), cursorParams...)
+		params = append(params, doc.Parameters(filterRequest)...)
+		for i := range params {
+			params[i].Required = false
+		}
+		minVersion, maxVersion := float64(versionMiddleware.V1), float64(versionMiddleware.Latest)
+		return append(params, openapi.Parameter{
+			Name:   versionMiddleware.HeaderKey,
+			In:     "header",
+			Schema: &openapi.Schema{Type: "integer", Minimum: &minVersion, Maximum: &maxVersion},
+		})
+	}
+	// selectable documents the fields and include parameters of the routes getting a
+	// resource, whose response v can be restricted to some fields and embed relations
+	selectable := func(params []openapi.Parameter, v interface{}, relations ...string) []openapi.Parameter {
+		params = append(params, openapi.Parameter{
+			Name:   fieldset.FieldsKey,
+			In:     "query",
+			Schema: &openapi.Schema{Type: "string", Description: "Comma separated fields among " + strings.Join(fieldset.Fields(v), ", ")},
+		})
+		if len(relations) == 0 {
+			return params
+		}
+		return append(params, openapi.Parameter{
+			Name:   fieldset.IncludeKey,
+			In:     "query",
+			Schema: &openapi.Schema{Type: "string", Description: "Comma separated relations among " + strings.Join(relations, ", ") + ", embedded under " + fieldset.IncludedKey},
+		})
+	}
+	// precondition documents the header carrying the ETags of a conditional request
+	precondition := func(params []openapi.Parameter, header string, required bool) []openapi.Parameter {
+		return append(params, openapi.Parameter{
+			Name:     header,
+			In:       "header",
+			Required: required,
+			Schema:   &openapi.Schema{Type: "string", Description: "ETag of a previous response, or *"},
+		})
+	}
+	// notModified adds the response of the conditional gets whose ETag matched
+	notModified := func(res map[string]openapi.Response) map[string]openapi.Response {
+		res[strconv.Itoa(http.StatusNotModified)] = openapi.Response{Description: http.StatusText(http.StatusNotModified)}
+		return res
+	}
+	// idempotent documents the header making a request safe to retry, whose response is
+	// replayed to the repeats of the request
+	idempotent := func(params []openapi.Parameter) []openapi.Parameter {
+		maxLength := int64(idempotencyMiddleware.MaxKeyLength)
+		return append(params, openapi.Parameter{
+			Name:   idempotencyMiddleware.HeaderKey,
+			In:     "header",
+			Schema: &openapi.Schema{Type: "string", MaxLength: &maxLength, Description: "Key identifying the request, unique per author, whose response is replayed to the requests repeating it with the same body"},
+		})
+	}
+	// rateLimited adds the response of the requests over the rate limit of the route, sent
+	// on the routes behind the rate limiting middleware once a limit is configured for them
+	rateLimited := func(res map[string]openapi.Response) map[string]openapi.Response {
+		res[strconv.Itoa(http.StatusTooManyRequests)] = doc.JSONResponse(http.StatusText(http.StatusTooManyRequests), problem.ContentType, problem.Problem{})
+		return res
+	}
+	// okPaginated documents the responses of the lists selected by page ID, an array of
+	// items in version 1 and an envelope in version 2, and by cursor, a page with the next
+	// cursor
+	okPaginated := func(items, envelope, page interface{}) openapi.Response {
+		return doc.JSONResponseOneOf(http.StatusText(http.StatusOK), "application/json", items, envelope, page)
+	}
+
+	doc.Add(http.MethodGet, "/healthz", openapi.Operation{
+		OperationID: "live",
+		Summary:     "Liveness probe",
+		Tags:        []string{"operations"},
+		Responses:   responses(ok(healthModel.LiveResponse{})),
+	})
+	doc.Add(http.MethodGet, "/readyz", openapi.Operation{
+		OperationID: "ready",
+		Summary:     "Readiness probe reporting each dependency check",
+		Tags:        []string{"operations"},
+		Responses: map[string]openapi.Response{
+			strconv.Itoa(http.StatusOK):                 ok(healthModel.ReadyResponse{}),
+			strconv.Itoa(http.StatusServiceUnavailable): doc.JSONResponse(http.StatusText(http.StatusServiceUnavailable), "application/json", healthModel.ReadyResponse{}),
+		},
+	})
+	doc.Add(http.MethodGet, "/metrics", openapi.Operation{
+		OperationID: "metrics",
+		Summary:     "Prometheus metrics",
+		Tags:        []string{"operations"},
+		Responses:   responses(doc.JSONResponse(http.StatusText(http.StatusOK), "text/plain", "")),
+	})
+	doc.Add(http.MethodGet, docsController.SpecPath, openapi.Operation{
+		OperationID: "openAPISpec",
+		Summary:     "This OpenAPI document",
+		Tags:        []string{"documentation"},
+		Responses:   responses(doc.JSONResponse(http.StatusText(http.StatusOK), "application/json", map[string]interface{}{})),
+	})
+	doc.Add(http.MethodGet, "/docs", openapi.Operation{
+		OperationID: "docs",
+		Summary:     "Interactive documentation of the API",
+		Tags:        []string{"documentation"},
+		Responses:   responses(doc.JSONResponse(http.StatusText(http.StatusOK), "text/html", "")),
+	})
+
+	doc.Add(http.MethodPost, "/authors/login", openapi.Operation{
+		OperationID: "login",
+		Summary:     "Authenticate an author and issue an access token",
+		Tags:        []string{"authors"},
+		RequestBody: doc.JSONBody(authorModel.LoginRequest{}),
+		Responses:   rateLimited(responses(ok(authorModel.LoginResponse{}), http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound, http.StatusInternalServerError)),
+	})
+	doc.Add(http.MethodPost, "/authors", openapi.Operation{
+		OperationID: "createAuthor",
+		Summary:     "Create an author",
+		Tags:        []string{"authors"},
+		RequestBody: doc.JSONBody(authorModel.CreateRequest{}),
+		Responses:   rateLimited(responses(ok(authorModel.CreateResponse{}), http.StatusBadRequest, http.StatusForbidden, http.StatusInternalServerError)),
+	})
+	doc.Add(http.MethodGet, "/authors/:username", openapi.Operation{
+		OperationID: "getAuthor",
+		Summary:     "Get an author by username, with an ETag to revalidate it with If-None-Match",
+		Tags:        []string{"authors"},
+		Parameters:  precondition(selectable(doc.Parameters(authorModel.GetRequest{}), authorModel.GetResponse{}), etag.IfNoneMatchHeader, false),
+		Responses:   rateLimited(notModified(responses(ok(authorModel.GetResponse{}), http.StatusBadRequest, http.StatusNotFound, http.StatusInternalServerError))),
+	})
+	doc.Add(http.MethodGet, "/authors", openapi.Operation{
+		OperationID: "listAuthors",
+		Summary:     "List the authors, by page ID or after a cursor, with Link headers to the next pages. Pages are sorted by sort=username|created_at|updated_at, prefixed with - for a descending order, and filtered by creation time. Cursor pages are neither sorted nor filtered.",
+		Tags:        []string{"authors"},
+		Parameters:  paginated(authorModel.ListRequest{}, authorModel.ListCursorRequest{}, authorModel.ListFilterRequest{}),
+		Responses:   rateLimited(responses(okPaginated([]authorModel.ListResponse{}, authorModel.ListEnvelopeResponse{}, authorModel.ListPageResponse{}), http.StatusBadRequest, http.StatusInternalServerError)),
+	})
+	doc.Add(http.MethodPatch, "/authors", openapi.Operation{
+		OperationID: "updateAuthor",
+		Summary:     "Update the email and/or password of the authenticated author, if it still has the version sent, or report a conflict with its current state",
+		Tags:        []string{"authors"},
+		RequestBody: doc.JSONBody(authorModel.UpdateRequest{}),
+		Security:    authenticated,
+		Responses:   rateLimited(responses(ok(authorModel.UpdateResponse{}), http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound, http.StatusConflict, http.StatusInternalServerError)),
+	})
+	doc.Add(http.MethodDelete, "/authors/:username", openapi.Operation{
+		OperationID: "deleteAuthor",
+		Summary:     "Delete the authenticated author",
+		Tags:        []string{"authors"},
+		Parameters:  doc.Parameters(authorModel.DeleteRequest{}),
+		Security:    authenticated,
+		Responses:   rateLimited(responses(ok(""), http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusInternalServerError)),
+	})
+
+	doc.Add(http.MethodPost, "/recipes", openapi.Operation{
+		OperationID: "createRecipe",
+		Summary:     "Create a recipe of the authenticated author, once per Idempotency-Key",
+		Tags:        []string{"recipes"},
+		Parameters:  idempotent(nil),
+		RequestBody: doc.JSONBody(recipeModel.CreateRequest{}),
+		Security:    authenticated,
+		Responses:   rateLimited(responses(ok(recipeModel.CreateResponse{}), http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusConflict, http.StatusRequestEntityTooLarge, http.StatusUnprocessableEntity, http.StatusInternalServerError)),
+	})
+	doc.Add(http.MethodGet, "/recipes/:id", openapi.Operation{
+		OperationID: "getRecipe",
+		Summary:     "Get a recipe of the authenticated author, with an ETag to revalidate it with If-None-Match",
+		Tags:        []string{"recipes"},
+		Parameters:  precondition(selectable(doc.Parameters(recipeModel.GetRequest{}), recipeModel.GetResponse{}, "author"), etag.IfNoneMatchHeader, false),
+		Security:    authenticated,
+		Responses:   rateLimited(notModified(responses(ok(recipeModel.GetResponse{}), http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound, http.StatusInternalServerError))),
+	})
+	doc.Add(http.MethodPatch, "/recipes", openapi.Operation{
+		OperationID: "updateRecipe",
+		Summary:     "Update the ingredients and/or steps of a recipe, if it still has the ETag sent in If-Match and the version sent; a stale ETag fails the precondition and a stale version is a conflict reported with its current state",
+		Tags:        []string{"recipes"},
+		Parameters:  precondition(nil, etag.IfMatchHeader, true),
+		RequestBody: doc.JSONBody(recipeModel.UpdateRequest{}),
+		Security:    authenticated,
+		Responses:   rateLimited(responses(ok(recipeModel.UpdateResponse{}), http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound, http.StatusConflict, http.StatusPreconditionFailed, http.StatusPreconditionRequired, http.StatusInternalServerError)),
+	})
+	doc.Add(http.MethodDelete, "/recipes/:id", openapi.Operation{
+		OperationID: "deleteRecipe",
+		Summary:     "Delete a recipe of the authenticated author. The recipe is soft-deleted: it is no longer read, listed, updated or deleted again, which report it as not found, and is removed for good by the admin purge command.",
+		Tags:        []string{"recipes"},
+		Parameters:  doc.Parameters(recipeModel.DeleteRequest{}),
+		Security:    authenticated,
+		Responses:   rateLimited(responses(ok(""), http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound, http.StatusInternalServerError)),
+	})
+	doc.Add(http.MethodGet, "/recipes", openapi.Operation{
+		OperationID: "listRecipes",
+		Summary:     "List the recipes of the authenticated author, by page ID or after a cursor, with Link headers to the next pages. Pages are sorted by sort=id|created_at|updated_at, prefixed with - for a descending order, and filtered by creation and update time and by ingredient. Cursor pages are neither sorted nor filtered.",
+		Tags:        []string{"recipes"},
+		Parameters:  paginated(recipeModel.ListRequest{}, recipeModel.ListCursorRequest{}, recipeModel.ListFilterRequest{}),
+		Security:    authenticated,
+		Responses:   rateLimited(responses(okPaginated([]recipeModel.ListResponse{}, recipeModel.ListEnvelopeResponse{}, recipeModel.ListPageResponse{}), http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusInternalServerError)),
+	})
+
+	doc.Add(http.MethodPost, "/graphql", openapi.Operation{
+		OperationID: "graphql",
+		Summary:     "Execute a GraphQL query or mutation on the authors and recipes, authenticated when an access token is sent. Each mutation is also charged to the rate limit of the REST route it mirrors, and fails with a request.rate_limited error once it is exceeded.",
+		Tags:        []string{"graphql"},
+		RequestBody: doc.JSONBody(graphqlModel.Request{}),
+		Responses:   rateLimited(responses(ok(graphqlModel.Response{}), http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusInternalServerError)),
+	})
+
+	return doc
+}
@@ -0,0 +1,139 @@
+package bookRecipeFactory_test
+
+import (
+	"encoding/json"
+	"github.com/gmaschi/go-recipes-book/pkg/openapi"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func fetchOpenAPI(t *testing.T, handler http.Handler) openapi.Document {
+	req, err := http.NewRequest(http.MethodGet, "/openapi.json", nil)
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+
+	var doc openapi.Document
+	err = json.Unmarshal(recorder.Body.Bytes(), &doc)
+	require.NoError(t, err)
+	return doc
+}
+
+// parameter returns the parameter of op with the given name
+func parameter(t *testing.T, op *openapi.Operation, name string) openapi.Parameter {
+	for _, param := range op.Parameters {
+		if param.Name == name {
+			return param
+		}
+	}
+	require.FailNow(t, "missing parameter", "operation %s has no parameter %s", op.OperationID, name)
+	return openapi.Parameter{}
+}
+
+func TestOpenAPI(t *testing.T) {
+	factory := newTestFactory(t, time.Second)
+	doc := fetchOpenAPI(t, factory.Router)
+	require.Equal(t, openapi.Version, doc.OpenAPI)
+
+	t.Run("Every route is documented", func(t *testing.T) {
+		documented := 0
+		for _, route := range factory.Router.Routes() {
+			op := doc.Operation(route.Method, route.Path)
+			require.NotNil(t, op, "route %s %s has no OpenAPI entry", route.Method, route.Path)
+			require.NotEmpty(t, op.Responses, "route %s %s documents no response", route.Method, route.Path)
+			documented++
+		}
+
+		operations := 0
+		for _, item := range doc.Paths {
+			operations += len(*item)
+		}
+		require.Equal(t, documented, operations, "the document has entries for routes that do not exist")
+	})
+
+	t.Run("Schemas follow the binding tags", func(t *testing.T) {
+		create := doc.Components.Schemas["authorModel.CreateRequest"]
+		require.NotNil(t, create)
+		require.ElementsMatch(t, []string{"username", "password", "email"}, create.Required)
+		require.Equal(t, "email", create.Properties["email"].Format)
+		require.EqualValues(t, 6, *create.Properties["password"].MinLength)
+
+		response := doc.Components.Schemas["authorModel.GetResponse"]
+		require.NotNil(t, response)
+		require.NotContains(t, response.Properties, "hashed_password")
+		require.NotContains(t, response.Properties, "HashedPassword")
+		require.Equal(t, "date-time", response.Properties["created_at"].Format)
+
+		list := doc.Operation(http.MethodGet, "/recipes")
+		require.NotNil(t, list)
+		require.Len(t, list.Parameters, 12)
+		for _, param := range list.Parameters {
+			if param.Name == "API-Version" {
+				require.Equal(t, "header", param.In)
+				continue
+			}
+			require.Equal(t, "query", param.In, param.Name)
+			require.False(t, param.Required, param.Name)
+		}
+		pageSize := parameter(t, list, "page_size").Schema
+		require.EqualValues(t, 1, *pageSize.Minimum)
+		require.EqualValues(t, 100, *pageSize.Maximum)
+		limit := parameter(t, list, "limit").Schema
+		require.EqualValues(t, 100, *limit.Maximum)
+		require.Contains(t, limit.Description, "rejects sort")
+		require.Equal(t, "date-time", parameter(t, list, "created_after").Schema.Format)
+		require.Len(t, list.Responses["200"].Content["application/json"].Schema.OneOf, 3)
+
+		get := doc.Operation(http.MethodGet, "/recipes/:id")
+		require.NotNil(t, get)
+		require.Len(t, get.Parameters, 4)
+		require.Equal(t, "path", parameter(t, get, "id").In)
+		require.Contains(t, parameter(t, get, "fields").Schema.Description, "ingredients")
+		require.Equal(t, "query", parameter(t, get, "include").In)
+		require.Equal(t, "header", parameter(t, get, "If-None-Match").In)
+		require.Contains(t, get.Responses, "304")
+		require.NotEmpty(t, get.Security)
+
+		createRecipe := doc.Operation(http.MethodPost, "/recipes")
+		require.NotNil(t, createRecipe)
+		require.Len(t, createRecipe.Parameters, 1)
+		require.False(t, parameter(t, createRecipe, "Idempotency-Key").Required)
+		require.Contains(t, createRecipe.Responses, "409")
+		require.Contains(t, createRecipe.Responses, "413")
+		require.Contains(t, createRecipe.Responses, "422")
+		require.Contains(t, createRecipe.Responses, "429")
+
+		update := doc.Operation(http.MethodPatch, "/recipes")
+		require.NotNil(t, update)
+		require.Len(t, update.Parameters, 1)
+		require.True(t, parameter(t, update, "If-Match").Required)
+		require.Contains(t, update.Responses, "412")
+		require.Contains(t, update.Responses, "409")
+		body := doc.Components.Schemas["recipeModel.UpdateRequest"]
+		require.ElementsMatch(t, []string{"id", "version"}, body.Required)
+		require.EqualValues(t, 1, *body.Properties["version"].Minimum)
+	})
+
+	t.Run("Docs page", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "/docs", nil)
+		require.NoError(t, err)
+
+		recorder := httptest.NewRecorder()
+		factory.Router.ServeHTTP(recorder, req)
+		require.Equal(t, http.StatusOK, recorder.Code)
+		require.True(t, strings.HasPrefix(recorder.Header().Get("Content-Type"), "text/html"))
+		require.NotEmpty(t, recorder.Header().Get("Content-Security-Policy"))
+		body := recorder.Body.String()
+		require.NotContains(t, body, "<script")
+		require.Contains(t, body, `href="/openapi.json"`)
+		require.Contains(t, body, `id="createRecipe"`)
+		require.Contains(t, body, `href="#schema-recipeModel.CreateRequest"`)
+	})
+}
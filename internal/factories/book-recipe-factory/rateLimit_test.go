@@ -0,0 +1,210 @@
+package bookRecipeFactory_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	bookRecipeFactory "github.com/gmaschi/go-recipes-book/internal/factories/book-recipe-factory"
+	memoryStore "github.com/gmaschi/go-recipes-book/internal/mocks/datastore/memory"
+	graphqlModel "github.com/gmaschi/go-recipes-book/internal/models/graphql"
+	db "github.com/gmaschi/go-recipes-book/internal/services/datastore/postgresql/recipes/sqlc"
+	"github.com/gmaschi/go-recipes-book/pkg/config/env"
+	recipesbookv1 "github.com/gmaschi/go-recipes-book/pkg/pb/recipesbook/v1"
+	"github.com/gmaschi/go-recipes-book/pkg/tools/problem"
+	"github.com/gmaschi/go-recipes-book/pkg/tools/random"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRateLimit(t *testing.T) {
+	config := env.Config{
+		Auth: env.AuthConfig{
+			TokenSymmetricKey: random.String(32),
+			TokenDuration:     time.Minute,
+		},
+		RateLimit: env.RateLimitConfig{Routes: "POST /authors/login=2/m"},
+	}
+
+	t.Run("Limited route", func(t *testing.T) {
+		factory, err := bookRecipeFactory.New(config, nil)
+		require.NoError(t, err)
+
+		login := func() *httptest.ResponseRecorder {
+			req, err := http.NewRequest(http.MethodPost, "/authors/login", strings.NewReader(`{}`))
+			require.NoError(t, err)
+			recorder := httptest.NewRecorder()
+			factory.Router.ServeHTTP(recorder, req)
+			return recorder
+		}
+
+		for i := 0; i < 2; i++ {
+			recorder := login()
+			require.Equal(t, http.StatusBadRequest, recorder.Code)
+			require.Equal(t, "2", recorder.Header().Get("RateLimit-Limit"))
+		}
+		recorder := login()
+		require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+		require.Equal(t, "30", recorder.Header().Get("Retry-After"))
+	})
+
+	t.Run("Spoofed X-Forwarded-For", func(t *testing.T) {
+		factory, err := bookRecipeFactory.New(config, nil)
+		require.NoError(t, err)
+
+		login := func(forwardedFor string) *httptest.ResponseRecorder {
+			return forwardedLogin(t, factory, forwardedFor)
+		}
+
+		// no proxy is trusted by default, so a new address per request gets no new bucket
+		for i := 0; i < 2; i++ {
+			require.Equal(t, http.StatusBadRequest, login(fmt.Sprintf("198.51.100.%d", i)).Code)
+		}
+		require.Equal(t, http.StatusTooManyRequests, login("198.51.100.2").Code)
+	})
+
+	t.Run("Trusted proxy", func(t *testing.T) {
+		trusted := config
+		trusted.HTTP.TrustedProxies = "203.0.113.0/24, 192.0.2.1"
+		factory, err := bookRecipeFactory.New(trusted, nil)
+		require.NoError(t, err)
+
+		login := func(forwardedFor string) *httptest.ResponseRecorder {
+			return forwardedLogin(t, factory, forwardedFor)
+		}
+
+		// behind a trusted proxy, the clients it forwards have their own buckets
+		for i := 0; i < 2; i++ {
+			require.Equal(t, http.StatusBadRequest, login("198.51.100.1").Code)
+		}
+		require.Equal(t, http.StatusTooManyRequests, login("198.51.100.1").Code)
+		require.Equal(t, http.StatusBadRequest, login("198.51.100.2").Code)
+	})
+
+	t.Run("Invalid trusted proxies", func(t *testing.T) {
+		invalid := config
+		invalid.HTTP.TrustedProxies = "proxy.local"
+		_, err := bookRecipeFactory.New(invalid, nil)
+		require.ErrorContains(t, err, "HTTP_TRUSTED_PROXIES")
+	})
+
+	t.Run("GraphQL mutations", func(t *testing.T) {
+		limited := config
+		limited.RateLimit.Routes = "POST /authors=2/h"
+		factory, err := bookRecipeFactory.New(limited, memoryStore.New())
+		require.NoError(t, err)
+
+		// each mutation is charged to the REST route it mirrors, even in a single request
+		query := `mutation {
+			a: createAuthor(input: {username: "alice", password: "secret", email: "alice@example.com"}) { username }
+			b: createAuthor(input: {username: "bob", password: "secret", email: "bob@example.com"}) { username }
+			c: createAuthor(input: {username: "carol", password: "secret", email: "carol@example.com"}) { username }
+		}`
+		body, err := json.Marshal(map[string]string{"query": query})
+		require.NoError(t, err)
+		req, err := http.NewRequest(http.MethodPost, "/graphql", bytes.NewReader(body))
+		require.NoError(t, err)
+		recorder := httptest.NewRecorder()
+		factory.Router.ServeHTTP(recorder, req)
+		require.Equal(t, http.StatusOK, recorder.Code)
+
+		var res graphqlModel.Response
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+		require.Len(t, res.Errors, 1)
+		require.Equal(t, []interface{}{"c"}, res.Errors[0].Path)
+		require.Equal(t, problem.CodeRateLimited, res.Errors[0].Extensions["code"])
+
+		// and the REST route shares the bucket
+		req, err = http.NewRequest(http.MethodPost, "/authors", strings.NewReader(`{}`))
+		require.NoError(t, err)
+		recorder = httptest.NewRecorder()
+		factory.Router.ServeHTTP(recorder, req)
+		require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+	})
+
+	t.Run("Invalid GraphQL mutations", func(t *testing.T) {
+		limited := config
+		limited.RateLimit.Routes = "PATCH /authors=1/h"
+		store := memoryStore.New()
+		factory, err := bookRecipeFactory.New(limited, store)
+		require.NoError(t, err)
+
+		author, err := store.CreateAuthor(context.Background(), db.CreateAuthorParams{Username: "alice", HashedPassword: "hashed", Email: "alice@example.com"})
+		require.NoError(t, err)
+		token, err := factory.TokenAuth.CreateToken(author.Username, time.Minute)
+		require.NoError(t, err)
+
+		// a mutation is charged before its input is validated, as its REST route is
+		query := `mutation {
+			a: updateAuthor(input: {username: "not valid", version: 1}) { username }
+			b: updateAuthor(input: {username: "not valid", version: 1}) { username }
+		}`
+		body, err := json.Marshal(map[string]string{"query": query})
+		require.NoError(t, err)
+		req, err := http.NewRequest(http.MethodPost, "/graphql", bytes.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer "+token)
+		recorder := httptest.NewRecorder()
+		factory.Router.ServeHTTP(recorder, req)
+		require.Equal(t, http.StatusOK, recorder.Code)
+
+		var res graphqlModel.Response
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+		require.Len(t, res.Errors, 2)
+		require.Equal(t, problem.CodeInvalidRequest, res.Errors[0].Extensions["code"])
+		require.Equal(t, problem.CodeRateLimited, res.Errors[1].Extensions["code"])
+	})
+
+	t.Run("gRPC", func(t *testing.T) {
+		limited := config
+		limited.RateLimit.Routes = "POST /authors/login=2/m"
+		factory, err := bookRecipeFactory.New(limited, memoryStore.New())
+		require.NoError(t, err)
+		authors := recipesbookv1.NewAuthorServiceClient(dialGRPC(t, factory))
+
+		login := &recipesbookv1.LoginRequest{Username: "alice", Password: "secret"}
+		for i := 0; i < 2; i++ {
+			var header metadata.MD
+			_, err := authors.Login(context.Background(), login, grpc.Header(&header))
+			requireStatus(t, err, codes.NotFound, problem.CodeAuthorNotFound)
+			require.Equal(t, []string{"2"}, header.Get("ratelimit-limit"))
+		}
+
+		var header metadata.MD
+		_, err = authors.Login(context.Background(), login, grpc.Header(&header))
+		requireStatus(t, err, codes.ResourceExhausted, problem.CodeRateLimited)
+		require.Equal(t, []string{"30"}, header.Get("retry-after"))
+
+		// the methods without a limited REST route are not limited
+		for i := 0; i < 3; i++ {
+			_, err = authors.ListAuthors(context.Background(), &recipesbookv1.ListAuthorsRequest{PageId: 1, PageSize: 5})
+			require.NoError(t, err)
+		}
+	})
+
+	t.Run("Invalid routes", func(t *testing.T) {
+		invalid := config
+		invalid.RateLimit.Routes = "POST /authors/login"
+		_, err := bookRecipeFactory.New(invalid, nil)
+		require.ErrorContains(t, err, "RATE_LIMIT_ROUTES")
+	})
+}
+
+// forwardedLogin sends a login request through a proxy at 203.0.113.7, which forwards it
+// for forwardedFor
+func forwardedLogin(t *testing.T, factory *bookRecipeFactory.Factory, forwardedFor string) *httptest.ResponseRecorder {
+	req, err := http.NewRequest(http.MethodPost, "/authors/login", strings.NewReader(`{}`))
+	require.NoError(t, err)
+	req.RemoteAddr = "203.0.113.7:4321"
+	req.Header.Set("X-Forwarded-For", forwardedFor)
+	recorder := httptest.NewRecorder()
+	factory.Router.ServeHTTP(recorder, req)
+	return recorder
+}
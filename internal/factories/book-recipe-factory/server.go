@@ -0,0 +1,143 @@
+package bookRecipeFactory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+)
+
+// Go runs worker in the background until the server shuts down. The stop channel is
+// closed once the servers have drained, and shutdown waits for worker to return.
+func (f *Factory) Go(worker func(stop <-chan struct{})) {
+	f.workers.Add(1)
+	go func() {
+		defer f.workers.Done()
+		worker(f.workerStop)
+	}()
+}
+
+// OnShutdown registers a function, such as closing the database, that is called after the
+// servers and the background workers have stopped. Functions run in reverse order of
+// registration.
+func (f *Factory) OnShutdown(closer func() error) {
+	f.closers = append(f.closers, closer)
+}
+
+// Run listens on the configured HTTP address, and on the gRPC address when gRPC is enabled,
+// and serves requests until ctx is done
+func (f *Factory) Run(ctx context.Context) error {
+	listener, err := net.Listen("tcp", f.httpServer.Addr)
+	if err != nil {
+		return fmt.Errorf("cannot listen on %s: %w", f.httpServer.Addr, err)
+	}
+
+	var grpcListener net.Listener
+	if f.Config.GRPC.Enabled {
+		grpcListener, err = net.Listen("tcp", f.Config.GRPC.Address)
+		if err != nil {
+			_ = listener.Close()
+			return fmt.Errorf("cannot listen on %s: %w", f.Config.GRPC.Address, err)
+		}
+	}
+	return f.serve(ctx, listener, grpcListener)
+}
+
+// Serve serves HTTP requests on listener until ctx is done, then shuts the server down
+// gracefully: readiness starts failing, in-flight requests are drained for at most the configured shutdown timeout,
+// background workers are stopped and the shutdown functions are called
+func (f *Factory) Serve(ctx context.Context, listener net.Listener) error {
+	return f.serve(ctx, listener, nil)
+}
+
+// serve is Serve that also serves gRPC requests on grpcListener, when it is not nil, and
+// drains them along with the HTTP requests
+func (f *Factory) serve(ctx context.Context, listener, grpcListener net.Listener) error {
+	f.startWorkers()
+	f.Logger.Info("server listening", "address", listener.Addr().String())
+
+	serveErr := make(chan error, 2)
+	go func() {
+		serveErr <- f.httpServer.Serve(listener)
+	}()
+	if grpcListener != nil {
+		f.Logger.Info("grpc server listening", "address", grpcListener.Addr().String())
+		go func() {
+			serveErr <- f.GRPCServer.Serve(grpcListener)
+		}()
+	}
+
+	select {
+	case err := <-serveErr:
+		_ = f.httpServer.Close()
+		f.GRPCServer.Stop()
+		return errors.Join(err, f.stop())
+	case <-ctx.Done():
+	}
+
+	return f.shutdown()
+}
+
+func (f *Factory) shutdown() error {
+	f.Logger.Info("server shutting down", "delay", f.Config.HTTP.ShutdownDelay.String(), "timeout", f.Config.HTTP.ShutdownTimeout.String())
+
+	// keep serving with a failing readiness probe so that the instance is taken out of
+	// rotation before it stops accepting connections
+	f.bookRecipesHandler.healthController.SetShuttingDown()
+	time.Sleep(f.Config.HTTP.ShutdownDelay)
+
+	shutdownCtx := context.Background()
+	if timeout := f.Config.HTTP.ShutdownTimeout; timeout > 0 {
+		var cancel context.CancelFunc
+		shutdownCtx, cancel = context.WithTimeout(shutdownCtx, timeout)
+		defer cancel()
+	}
+
+	// the gRPC server drains alongside the HTTP server, within the same deadline
+	grpcStopped := make(chan struct{})
+	go func() {
+		defer close(grpcStopped)
+		f.GRPCServer.GracefulStop()
+	}()
+
+	err := f.httpServer.Shutdown(shutdownCtx)
+	if err != nil {
+		// the deadline expired with requests still running, so cut them
+		_ = f.httpServer.Close()
+		err = fmt.Errorf("cannot drain connections: %w", err)
+	}
+
+	select {
+	case <-grpcStopped:
+	case <-shutdownCtx.Done():
+		f.GRPCServer.Stop()
+		<-grpcStopped
+		if err == nil {
+			err = fmt.Errorf("cannot drain grpc connections: %w", shutdownCtx.Err())
+		}
+	}
+
+	return errors.Join(err, f.stop())
+}
+
+// startWorkers starts the background workers of the server, which only run while it serves
+func (f *Factory) startWorkers() {
+	if f.store != nil && f.Config.Idempotency.PurgeInterval > 0 {
+		f.Go(f.purgeIdempotencyKeys)
+	}
+}
+
+// stop stops the background workers and calls the shutdown functions
+func (f *Factory) stop() error {
+	close(f.workerStop)
+	f.workers.Wait()
+
+	var err error
+	for i := len(f.closers) - 1; i >= 0; i-- {
+		if closeErr := f.closers[i](); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
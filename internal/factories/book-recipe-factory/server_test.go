@@ -0,0 +1,199 @@
+package bookRecipeFactory_test
+
+import (
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	bookRecipeFactory "github.com/gmaschi/go-recipes-book/internal/factories/book-recipe-factory"
+	memoryStore "github.com/gmaschi/go-recipes-book/internal/mocks/datastore/memory"
+	db "github.com/gmaschi/go-recipes-book/internal/services/datastore/postgresql/recipes/sqlc"
+	"github.com/gmaschi/go-recipes-book/pkg/config/env"
+	"github.com/gmaschi/go-recipes-book/pkg/tools/random"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func newTestFactory(t *testing.T, shutdownTimeout time.Duration) *bookRecipeFactory.Factory {
+	config := env.Config{
+		HTTP: env.HTTPConfig{
+			Address:         "127.0.0.1:0",
+			ShutdownTimeout: shutdownTimeout,
+		},
+		Auth: env.AuthConfig{
+			TokenSymmetricKey: random.String(32),
+			TokenDuration:     time.Minute,
+		},
+	}
+
+	factory, err := bookRecipeFactory.New(config, nil)
+	require.NoError(t, err)
+	return factory
+}
+
+func TestServeGracefulShutdown(t *testing.T) {
+	t.Run("In-flight requests complete", func(t *testing.T) {
+		factory := newTestFactory(t, 5*time.Second)
+
+		started := make(chan struct{})
+		factory.Router.GET("/slow", func(ctx *gin.Context) {
+			close(started)
+			time.Sleep(200 * time.Millisecond)
+			ctx.String(http.StatusOK, "done")
+		})
+
+		workerStopped := make(chan struct{})
+		factory.Go(func(stop <-chan struct{}) {
+			<-stop
+			close(workerStopped)
+		})
+
+		var closed bool
+		factory.OnShutdown(func() error {
+			select {
+			case <-workerStopped:
+			default:
+				t.Error("shutdown function called before the workers stopped")
+			}
+			closed = true
+			return nil
+		})
+
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		serveErr := make(chan error, 1)
+		go func() {
+			serveErr <- factory.Serve(ctx, listener)
+		}()
+
+		type result struct {
+			status int
+			body   string
+			err    error
+		}
+		results := make(chan result, 1)
+		go func() {
+			res, err := http.Get("http://" + listener.Addr().String() + "/slow")
+			if err != nil {
+				results <- result{err: err}
+				return
+			}
+			defer res.Body.Close()
+			body, err := ioutil.ReadAll(res.Body)
+			results <- result{status: res.StatusCode, body: string(body), err: err}
+		}()
+
+		<-started
+		cancel()
+
+		res := <-results
+		require.NoError(t, res.err)
+		require.Equal(t, http.StatusOK, res.status)
+		require.Equal(t, "done", res.body)
+
+		require.NoError(t, <-serveErr)
+		require.True(t, closed)
+
+		_, err = http.Get("http://" + listener.Addr().String() + "/slow")
+		require.Error(t, err)
+	})
+
+	t.Run("Serve error", func(t *testing.T) {
+		factory := newTestFactory(t, time.Second)
+
+		errClose := errors.New("close failed")
+		factory.OnShutdown(func() error {
+			return errClose
+		})
+
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		require.NoError(t, listener.Close())
+
+		// both the serve error and the error of the shutdown functions are reported
+		err = factory.Serve(context.Background(), listener)
+		require.ErrorIs(t, err, net.ErrClosed)
+		require.ErrorIs(t, err, errClose)
+	})
+
+	t.Run("Shutdown deadline exceeded", func(t *testing.T) {
+		factory := newTestFactory(t, 50*time.Millisecond)
+
+		started := make(chan struct{})
+		release := make(chan struct{})
+		defer close(release)
+		factory.Router.GET("/stuck", func(ctx *gin.Context) {
+			close(started)
+			<-release
+		})
+
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		serveErr := make(chan error, 1)
+		go func() {
+			serveErr <- factory.Serve(ctx, listener)
+		}()
+
+		go func() {
+			res, err := http.Get("http://" + listener.Addr().String() + "/stuck")
+			if err == nil {
+				res.Body.Close()
+			}
+		}()
+
+		<-started
+		cancel()
+
+		err = <-serveErr
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
+
+func TestServeStartsWorkers(t *testing.T) {
+	store := memoryStore.New()
+	_, err := store.CreateAuthor(context.Background(), db.CreateAuthorParams{Username: "author", Email: "author@example.com"})
+	require.NoError(t, err)
+	_, err = store.CreateIdempotencyKey(context.Background(), db.CreateIdempotencyKeyParams{Author: "author", Key: "key"})
+	require.NoError(t, err)
+	keyExists := func() bool {
+		_, err := store.GetIdempotencyKey(context.Background(), db.GetIdempotencyKeyParams{Author: "author", Key: "key"})
+		return err == nil
+	}
+
+	config := env.Config{
+		HTTP: env.HTTPConfig{ShutdownTimeout: time.Second},
+		Auth: env.AuthConfig{
+			TokenSymmetricKey: random.String(32),
+			TokenDuration:     time.Minute,
+		},
+		Idempotency: env.IdempotencyConfig{
+			KeyTTL:        time.Nanosecond,
+			PurgeInterval: time.Millisecond,
+		},
+	}
+	factory, err := bookRecipeFactory.New(config, store)
+	require.NoError(t, err)
+
+	// the purge only runs once the server serves
+	time.Sleep(20 * time.Millisecond)
+	require.True(t, keyExists())
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- factory.Serve(ctx, listener)
+	}()
+
+	require.Eventually(t, func() bool { return !keyExists() }, time.Second, 5*time.Millisecond)
+	cancel()
+	require.NoError(t, <-serveErr)
+}
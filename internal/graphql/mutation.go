@@ -0,0 +1,193 @@
+package graphqlResolver
+
+import (
+	"context"
+	"github.com/gin-gonic/gin/binding"
+	errorMiddleware "github.com/gmaschi/go-recipes-book/internal/controllers/middlewares/errors"
+	recipeDomain "github.com/gmaschi/go-recipes-book/internal/domain/recipe"
+	authorModel "github.com/gmaschi/go-recipes-book/internal/models/author"
+	recipeModel "github.com/gmaschi/go-recipes-book/internal/models/recipe"
+	"github.com/graph-gophers/graphql-go"
+	"net/http"
+)
+
+type (
+	createAuthorInput struct {
+		Username string
+		Password string
+		Email    string
+	}
+
+	updateAuthorInput struct {
+		Username string
+		Version  int32
+		Email    *string
+		Password *string
+	}
+
+	createRecipeInput struct {
+		Ingredients []string
+		Steps       []string
+	}
+
+	updateRecipeInput struct {
+		ID          graphql.ID
+		Version     int32
+		Ingredients *[]string
+		Steps       *[]string
+	}
+)
+
+// CreateAuthor creates an author account
+func (r *Resolver) CreateAuthor(ctx context.Context, args struct{ Input createAuthorInput }) (*authorResolver, error) {
+	if err := r.request(ctx).charge(http.MethodPost, "/authors"); err != nil {
+		return nil, err
+	}
+
+	createReq := authorModel.CreateRequest{
+		Username: args.Input.Username,
+		Password: args.Input.Password,
+		Email:    args.Input.Email,
+	}
+	if err := binding.Validator.ValidateStruct(&createReq); err != nil {
+		return nil, fail(errorMiddleware.TranslateBind(err), "author")
+	}
+
+	author, err := r.authorService.Create(ctx, createReq)
+	if err != nil {
+		return nil, fail(err, "author")
+	}
+	return &authorResolver{req: r.request(ctx), author: author}, nil
+}
+
+// UpdateAuthor changes the email and/or password of the authenticated author
+func (r *Resolver) UpdateAuthor(ctx context.Context, args struct{ Input updateAuthorInput }) (*authorResolver, error) {
+	req := r.request(ctx)
+	payload, err := req.authenticated()
+	if err != nil {
+		return nil, err
+	}
+	if err := req.charge(http.MethodPatch, "/authors"); err != nil {
+		return nil, err
+	}
+
+	updateReq := authorModel.UpdateRequest{Username: args.Input.Username, Version: args.Input.Version}
+	if args.Input.Email != nil {
+		updateReq.Email = *args.Input.Email
+	}
+	if args.Input.Password != nil {
+		updateReq.Password = *args.Input.Password
+	}
+	if err := binding.Validator.ValidateStruct(&updateReq); err != nil {
+		return nil, fail(errorMiddleware.TranslateBind(err), "author")
+	}
+
+	updatedAuthor, err := r.authorService.Update(ctx, payload.Username, updateReq)
+	if err != nil {
+		return nil, fail(err, "author")
+	}
+	req.authors.Clear(ctx, updatedAuthor.Username).Prime(ctx, updatedAuthor.Username, updatedAuthor)
+	return &authorResolver{req: req, author: updatedAuthor}, nil
+}
+
+// DeleteAuthor deletes the authenticated author
+func (r *Resolver) DeleteAuthor(ctx context.Context, args struct{ Username string }) (bool, error) {
+	req := r.request(ctx)
+	payload, err := req.authenticated()
+	if err != nil {
+		return false, err
+	}
+	if err := req.charge(http.MethodDelete, "/authors/:username"); err != nil {
+		return false, err
+	}
+
+	deleteReq := authorModel.DeleteRequest{Username: args.Username}
+	if err := binding.Validator.ValidateStruct(&deleteReq); err != nil {
+		return false, fail(errorMiddleware.TranslateBind(err), "author")
+	}
+
+	if err := r.authorService.Delete(ctx, payload.Username, deleteReq.Username); err != nil {
+		return false, fail(err, "author")
+	}
+	req.authors.Clear(ctx, deleteReq.Username)
+	return true, nil
+}
+
+// CreateRecipe creates a recipe of the authenticated author
+func (r *Resolver) CreateRecipe(ctx context.Context, args struct{ Input createRecipeInput }) (*recipeResolver, error) {
+	req := r.request(ctx)
+	payload, err := req.authenticated()
+	if err != nil {
+		return nil, err
+	}
+	if err := req.charge(http.MethodPost, "/recipes"); err != nil {
+		return nil, err
+	}
+
+	recipe, err := r.recipeService.Create(ctx, payload.Username, recipeModel.CreateRequest{
+		Ingredients: args.Input.Ingredients,
+		Steps:       args.Input.Steps,
+	})
+	if err != nil {
+		return nil, fail(err, "recipe")
+	}
+	req.recipes.ClearAll()
+	return &recipeResolver{req: req, recipe: recipe}, nil
+}
+
+// UpdateRecipe replaces the non-empty ingredients and/or steps of a recipe of the
+// authenticated author, if it still has the version the update is made against
+func (r *Resolver) UpdateRecipe(ctx context.Context, args struct{ Input updateRecipeInput }) (*recipeResolver, error) {
+	req := r.request(ctx)
+	payload, err := req.authenticated()
+	if err != nil {
+		return nil, err
+	}
+	if err := req.charge(http.MethodPatch, "/recipes"); err != nil {
+		return nil, err
+	}
+	id, err := parseID(args.Input.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	updateReq := recipeModel.UpdateRequest{ID: id, Version: args.Input.Version}
+	if args.Input.Ingredients != nil {
+		updateReq.Ingredients = *args.Input.Ingredients
+	}
+	if args.Input.Steps != nil {
+		updateReq.Steps = *args.Input.Steps
+	}
+	if err := binding.Validator.ValidateStruct(&updateReq); err != nil {
+		return nil, fail(errorMiddleware.TranslateBind(err), "recipe")
+	}
+
+	updatedRecipe, err := r.recipeService.Update(ctx, payload.Username, updateReq, recipeDomain.Precondition{})
+	if err != nil {
+		return nil, fail(err, "recipe")
+	}
+	req.recipes.ClearAll()
+	return &recipeResolver{req: req, recipe: updatedRecipe}, nil
+}
+
+// DeleteRecipe deletes a recipe of the authenticated author
+func (r *Resolver) DeleteRecipe(ctx context.Context, args struct{ ID graphql.ID }) (bool, error) {
+	req := r.request(ctx)
+	payload, err := req.authenticated()
+	if err != nil {
+		return false, err
+	}
+	if err := req.charge(http.MethodDelete, "/recipes/:id"); err != nil {
+		return false, err
+	}
+	id, err := parseID(args.ID)
+	if err != nil {
+		return false, err
+	}
+
+	if err := r.recipeService.Delete(ctx, payload.Username, id); err != nil {
+		return false, fail(err, "recipe")
+	}
+	req.recipes.ClearAll()
+	return true, nil
+}
@@ -0,0 +1,113 @@
+package graphqlResolver
+
+import (
+	"context"
+	"github.com/gin-gonic/gin/binding"
+	errorMiddleware "github.com/gmaschi/go-recipes-book/internal/controllers/middlewares/errors"
+	authorModel "github.com/gmaschi/go-recipes-book/internal/models/author"
+	recipeModel "github.com/gmaschi/go-recipes-book/internal/models/recipe"
+	db "github.com/gmaschi/go-recipes-book/internal/services/datastore/postgresql/recipes/sqlc"
+	"github.com/gmaschi/go-recipes-book/pkg/tools/problem"
+	"github.com/graph-gophers/graphql-go"
+	"strconv"
+)
+
+type pageArgs struct {
+	PageID   int32
+	PageSize int32
+}
+
+// Me resolves the authenticated author
+func (r *Resolver) Me(ctx context.Context) (*authorResolver, error) {
+	req := r.request(ctx)
+	payload, err := req.authenticated()
+	if err != nil {
+		return nil, err
+	}
+
+	author, err := req.authors.Load(ctx, payload.Username)()
+	if err != nil {
+		return nil, fail(err, "author")
+	}
+	return &authorResolver{req: req, author: author}, nil
+}
+
+// Author resolves an author by username
+func (r *Resolver) Author(ctx context.Context, args struct{ Username string }) (*authorResolver, error) {
+	getReq := authorModel.GetRequest{Username: args.Username}
+	if err := binding.Validator.ValidateStruct(&getReq); err != nil {
+		return nil, fail(errorMiddleware.TranslateBind(err), "author")
+	}
+
+	req := r.request(ctx)
+	author, err := req.authors.Load(ctx, getReq.Username)()
+	if err != nil {
+		return nil, fail(err, "author")
+	}
+	return &authorResolver{req: req, author: author}, nil
+}
+
+// Authors resolves a page of authors ordered by username
+func (r *Resolver) Authors(ctx context.Context, args pageArgs) ([]*authorResolver, error) {
+	listReq := authorModel.ListRequest{PageID: args.PageID, PageSize: args.PageSize}
+	if err := binding.Validator.ValidateStruct(&listReq); err != nil {
+		return nil, fail(errorMiddleware.TranslateBind(err), "author")
+	}
+
+	authors, err := r.store.ListAuthors(ctx, db.ListAuthorsParams{
+		Limit:  listReq.PageSize,
+		Offset: listReq.PageSize * (listReq.PageID - 1),
+	})
+	if err != nil {
+		return nil, fail(err, "author")
+	}
+
+	req := r.request(ctx)
+	for _, author := range authors {
+		req.authors.Prime(ctx, author.Username, author)
+	}
+	return req.authorResolvers(authors), nil
+}
+
+// Recipe resolves a recipe of the authenticated author by ID
+func (r *Resolver) Recipe(ctx context.Context, args struct{ ID graphql.ID }) (*recipeResolver, error) {
+	req := r.request(ctx)
+	payload, err := req.authenticated()
+	if err != nil {
+		return nil, err
+	}
+	id, err := parseID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	recipe, err := r.recipeService.Get(ctx, payload.Username, id)
+	if err != nil {
+		return nil, fail(err, "recipe")
+	}
+	return &recipeResolver{req: req, recipe: recipe}, nil
+}
+
+// Recipes resolves a page of the recipes of the authenticated author
+func (r *Resolver) Recipes(ctx context.Context, args pageArgs) ([]*recipeResolver, error) {
+	req := r.request(ctx)
+	payload, err := req.authenticated()
+	if err != nil {
+		return nil, err
+	}
+	return req.recipePage(ctx, payload.Username, args)
+}
+
+// parseID parses the ID of a recipe, with the validation of its REST counterpart
+func parseID(id graphql.ID) (int64, error) {
+	recipeID, err := strconv.ParseInt(string(id), 10, 64)
+	if err != nil {
+		return 0, fail(problem.Invalid(problem.FieldError{Field: "id", Code: "type", Message: "must be of type int64"}), "recipe")
+	}
+
+	getReq := recipeModel.GetRequest{ID: recipeID}
+	if err := binding.Validator.ValidateStruct(&getReq); err != nil {
+		return 0, fail(errorMiddleware.TranslateBind(err), "recipe")
+	}
+	return recipeID, nil
+}
@@ -0,0 +1,155 @@
+package graphqlResolver
+
+import (
+	"context"
+	"database/sql"
+	"github.com/gin-gonic/gin/binding"
+	authMiddleware "github.com/gmaschi/go-recipes-book/internal/controllers/middlewares/auth"
+	errorMiddleware "github.com/gmaschi/go-recipes-book/internal/controllers/middlewares/errors"
+	recipeModel "github.com/gmaschi/go-recipes-book/internal/models/recipe"
+	db "github.com/gmaschi/go-recipes-book/internal/services/datastore/postgresql/recipes/sqlc"
+	"github.com/gmaschi/go-recipes-book/internal/services/ratelimit"
+	"github.com/gmaschi/go-recipes-book/pkg/auth/tokenAuth"
+	"github.com/graph-gophers/dataloader/v7"
+)
+
+type (
+	requestKey struct{}
+
+	// recipePage is a page of the recipes of an author, loaded like the pages of the REST
+	// route listing them
+	recipePage struct {
+		author   string
+		pageID   int32
+		pageSize int32
+	}
+
+	// request is the state of a GraphQL request shared by its resolvers
+	request struct {
+		payload *tokenAuth.Payload
+
+		// rateLimit charges the request to the rate limit of a REST route, nil when the
+		// request is not limited
+		rateLimit func(route string) error
+
+		// authors loads authors by username, batching the loads of the resolvers running
+		// concurrently into a single query, and recipes loads pages of recipes. Both cache
+		// the results until the end of the request, so that a page selected by many
+		// resolvers is only queried once.
+		authors *dataloader.Loader[string, db.Author]
+		recipes *dataloader.Loader[recipePage, []db.Recipe]
+	}
+)
+
+// WithRequest returns a copy of ctx carrying the authenticated author of a request, nil when
+// it is anonymous, the loaders batching the queries its resolvers make to store, and the
+// function charging its mutations to the rate limit of the REST routes they mirror. It must
+// be called once per request, since the loaders cache what they load.
+func WithRequest(ctx context.Context, store db.Store, payload *tokenAuth.Payload, rateLimit func(route string) error) context.Context {
+	req := newRequest(store, payload)
+	req.rateLimit = rateLimit
+	return context.WithValue(ctx, requestKey{}, req)
+}
+
+func newRequest(store db.Store, payload *tokenAuth.Payload) *request {
+	return &request{
+		payload: payload,
+		authors: dataloader.NewBatchedLoader(loadAuthors(store)),
+		recipes: dataloader.NewBatchedLoader(loadRecipes(store)),
+	}
+}
+
+// request returns the request executed in ctx, or an anonymous one without shared loaders
+// when ctx does not come from WithRequest
+func (r *Resolver) request(ctx context.Context) *request {
+	if req, ok := ctx.Value(requestKey{}).(*request); ok {
+		return req
+	}
+	return newRequest(r.store, nil)
+}
+
+// authenticated returns the payload of the authenticated author
+func (req *request) authenticated() (*tokenAuth.Payload, error) {
+	if req.payload == nil {
+		return nil, fail(authMiddleware.ErrMissingAuthorization, "")
+	}
+	return req.payload, nil
+}
+
+// charge charges a mutation to the rate limit of the REST route it mirrors, so that a
+// request running many mutations is limited as the REST requests would be. Mutations charge
+// after authenticating and before validating their input, where the REST middlewares do.
+func (req *request) charge(method, path string) error {
+	if req.rateLimit == nil {
+		return nil
+	}
+	if err := req.rateLimit(ratelimit.RouteKey(method, path)); err != nil {
+		return fail(err, "")
+	}
+	return nil
+}
+
+func loadAuthors(store db.Store) dataloader.BatchFunc[string, db.Author] {
+	return func(ctx context.Context, usernames []string) []*dataloader.Result[db.Author] {
+		results := make([]*dataloader.Result[db.Author], len(usernames))
+
+		authors, err := store.ListAuthorsByUsernames(ctx, usernames)
+		if err != nil {
+			for i := range results {
+				results[i] = &dataloader.Result[db.Author]{Error: err}
+			}
+			return results
+		}
+
+		byUsername := make(map[string]db.Author, len(authors))
+		for _, author := range authors {
+			byUsername[author.Username] = author
+		}
+		for i, username := range usernames {
+			author, ok := byUsername[username]
+			if !ok {
+				results[i] = &dataloader.Result[db.Author]{Error: sql.ErrNoRows}
+				continue
+			}
+			results[i] = &dataloader.Result[db.Author]{Data: author}
+		}
+		return results
+	}
+}
+
+// loadRecipes queries every page on its own, since each has its own limit and offset.
+// Recipes are private to their author, so the pages of a request are the ones of the
+// authenticated author, and rarely more than one.
+func loadRecipes(store db.Store) dataloader.BatchFunc[recipePage, []db.Recipe] {
+	return func(ctx context.Context, pages []recipePage) []*dataloader.Result[[]db.Recipe] {
+		results := make([]*dataloader.Result[[]db.Recipe], len(pages))
+		for i, page := range pages {
+			recipes, err := store.ListRecipes(ctx, db.ListRecipesParams{
+				Author: page.author,
+				Limit:  page.pageSize,
+				Offset: page.pageSize * (page.pageID - 1),
+			})
+			results[i] = &dataloader.Result[[]db.Recipe]{Data: recipes, Error: err}
+		}
+		return results
+	}
+}
+
+// recipePage resolves a page of the recipes of author, with the validation of the REST
+// route listing them
+func (req *request) recipePage(ctx context.Context, author string, args pageArgs) ([]*recipeResolver, error) {
+	listReq := recipeModel.ListRequest{PageID: args.PageID, PageSize: args.PageSize}
+	if err := binding.Validator.ValidateStruct(&listReq); err != nil {
+		return nil, fail(errorMiddleware.TranslateBind(err), "recipe")
+	}
+
+	recipes, err := req.recipes.Load(ctx, recipePage{
+		author:   author,
+		pageID:   listReq.PageID,
+		pageSize: listReq.PageSize,
+	})()
+	if err != nil {
+		return nil, fail(err, "recipe")
+	}
+	return req.recipeResolvers(recipes), nil
+}
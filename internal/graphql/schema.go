@@ -0,0 +1,100 @@
+package graphqlResolver
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	errorMiddleware "github.com/gmaschi/go-recipes-book/internal/controllers/middlewares/errors"
+	authorDomain "github.com/gmaschi/go-recipes-book/internal/domain/author"
+	recipeDomain "github.com/gmaschi/go-recipes-book/internal/domain/recipe"
+	db "github.com/gmaschi/go-recipes-book/internal/services/datastore/postgresql/recipes/sqlc"
+	"github.com/gmaschi/go-recipes-book/pkg/logger"
+	"github.com/gmaschi/go-recipes-book/pkg/tools/problem"
+	"github.com/graph-gophers/graphql-go"
+	gqlerrors "github.com/graph-gophers/graphql-go/errors"
+)
+
+// maxDepth bounds the nesting of the queries, which could otherwise walk the author and
+// recipe relationships indefinitely
+const maxDepth = 8
+
+//go:embed schema.graphql
+var schema string
+
+type (
+	// Resolver resolves the queries and mutations of the GraphQL API with the same rules as
+	// the author and recipe controllers of the REST API
+	Resolver struct {
+		store         db.Store
+		authorService *authorDomain.Service
+		recipeService *recipeDomain.Service
+	}
+
+	// resolverError reports a problem in the errors of a GraphQL response, with its code,
+	// status and invalid fields as extensions. Its cause is never sent to the client.
+	resolverError struct {
+		problem *problem.Error
+	}
+
+	// panicHandler reports the panics of the resolvers as internal errors
+	panicHandler struct{}
+)
+
+// NewSchema parses the GraphQL schema of the API, resolved with the rules of authors and
+// recipes and the queries of store
+func NewSchema(store db.Store, authors *authorDomain.Service, recipes *recipeDomain.Service) (*graphql.Schema, error) {
+	resolver := &Resolver{
+		store:         store,
+		authorService: authors,
+		recipeService: recipes,
+	}
+	return graphql.ParseSchema(schema, resolver,
+		graphql.UseStringDescriptions(),
+		graphql.MaxDepth(maxDepth),
+		graphql.Logger(panicHandler{}),
+		graphql.PanicHandler(panicHandler{}),
+	)
+}
+
+// fail translates err to the problem reported for resource
+func fail(err error, resource string) error {
+	var resolverErr *resolverError
+	if errors.As(err, &resolverErr) {
+		return resolverErr
+	}
+	return &resolverError{problem: errorMiddleware.Translate(err, resource)}
+}
+
+func (e *resolverError) Error() string {
+	return e.problem.Detail
+}
+
+func (e *resolverError) Unwrap() error {
+	return e.problem
+}
+
+// Extensions implements the extensions of the GraphQL errors
+func (e *resolverError) Extensions() map[string]interface{} {
+	extensions := map[string]interface{}{
+		"code":   e.problem.Code,
+		"status": e.problem.Status,
+	}
+	if len(e.problem.Fields) > 0 {
+		extensions["fields"] = e.problem.Fields
+	}
+	return extensions
+}
+
+// LogPanic logs a panic recovered while executing a query
+func (panicHandler) LogPanic(ctx context.Context, value interface{}) {
+	logger.FromContext(ctx).Error("panic recovered", "panic", value)
+}
+
+// MakePanicError reports a panic without disclosing its value
+func (panicHandler) MakePanicError(context.Context, interface{}) *gqlerrors.QueryError {
+	internal := &resolverError{problem: problem.Internal(nil)}
+	return &gqlerrors.QueryError{
+		Message:    internal.Error(),
+		Extensions: internal.Extensions(),
+	}
+}
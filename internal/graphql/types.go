@@ -0,0 +1,106 @@
+package graphqlResolver
+
+import (
+	"context"
+	recipeDomain "github.com/gmaschi/go-recipes-book/internal/domain/recipe"
+	db "github.com/gmaschi/go-recipes-book/internal/services/datastore/postgresql/recipes/sqlc"
+	"github.com/graph-gophers/graphql-go"
+	"strconv"
+)
+
+type (
+	authorResolver struct {
+		req    *request
+		author db.Author
+	}
+
+	recipeResolver struct {
+		req    *request
+		recipe db.Recipe
+	}
+)
+
+func (req *request) authorResolvers(authors []db.Author) []*authorResolver {
+	res := make([]*authorResolver, 0, len(authors))
+	for _, author := range authors {
+		res = append(res, &authorResolver{req: req, author: author})
+	}
+	return res
+}
+
+func (req *request) recipeResolvers(recipes []db.Recipe) []*recipeResolver {
+	res := make([]*recipeResolver, 0, len(recipes))
+	for _, recipe := range recipes {
+		res = append(res, &recipeResolver{req: req, recipe: recipe})
+	}
+	return res
+}
+
+func (a *authorResolver) Username() string {
+	return a.author.Username
+}
+
+func (a *authorResolver) Email() string {
+	return a.author.Email
+}
+
+func (a *authorResolver) CreatedAt() graphql.Time {
+	return graphql.Time{Time: a.author.CreatedAt}
+}
+
+func (a *authorResolver) UpdatedAt() graphql.Time {
+	return graphql.Time{Time: a.author.UpdatedAt}
+}
+
+func (a *authorResolver) Version() int32 {
+	return a.author.Version
+}
+
+// Recipes resolves a page of the recipes of the author, which only the author can read
+func (a *authorResolver) Recipes(ctx context.Context, args pageArgs) (*[]*recipeResolver, error) {
+	payload, err := a.req.authenticated()
+	if err != nil {
+		return nil, err
+	}
+	if payload.Username != a.author.Username {
+		return nil, fail(recipeDomain.ErrNotOwnedRecipe, "recipe")
+	}
+
+	res, err := a.req.recipePage(ctx, a.author.Username, args)
+	if err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+func (r *recipeResolver) ID() graphql.ID {
+	return graphql.ID(strconv.FormatInt(r.recipe.ID, 10))
+}
+
+func (r *recipeResolver) Author(ctx context.Context) (*authorResolver, error) {
+	author, err := r.req.authors.Load(ctx, r.recipe.Author)()
+	if err != nil {
+		return nil, fail(err, "author")
+	}
+	return &authorResolver{req: r.req, author: author}, nil
+}
+
+func (r *recipeResolver) Ingredients() []string {
+	return r.recipe.Ingredients
+}
+
+func (r *recipeResolver) Steps() []string {
+	return r.recipe.Steps
+}
+
+func (r *recipeResolver) CreatedAt() graphql.Time {
+	return graphql.Time{Time: r.recipe.CreatedAt}
+}
+
+func (r *recipeResolver) UpdatedAt() graphql.Time {
+	return graphql.Time{Time: r.recipe.UpdatedAt}
+}
+
+func (r *recipeResolver) Version() int32 {
+	return r.recipe.Version
+}
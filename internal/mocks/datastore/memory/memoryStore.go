@@ -0,0 +1,637 @@
+package memoryStore
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/gmaschi/go-recipes-book/internal/services/datastore/postgresql/recipes/migration"
+	db "github.com/gmaschi/go-recipes-book/internal/services/datastore/postgresql/recipes/sqlc"
+	"github.com/lib/pq"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Store is an in-memory db.Store for end-to-end tests. It follows the queries of the sqlc
+// package, including the constraint violations Postgres reports for them.
+type Store struct {
+	mu              sync.Mutex
+	authors         map[string]db.Author
+	recipes         map[int64]db.Recipe
+	idempotencyKeys map[idempotencyKeyID]db.IdempotencyKey
+	nextID          int64
+}
+
+// idempotencyKeyID is the primary key of the idempotency_keys table
+type idempotencyKeyID struct {
+	author string
+	key    string
+}
+
+var _ db.Store = (*Store)(nil)
+
+// New creates a pointer to an empty Store
+func New() *Store {
+	return &Store{
+		authors:         make(map[string]db.Author),
+		recipes:         make(map[int64]db.Recipe),
+		idempotencyKeys: make(map[idempotencyKeyID]db.IdempotencyKey),
+		nextID:          1,
+	}
+}
+
+func now() time.Time {
+	return time.Now().UTC().Truncate(time.Microsecond)
+}
+
+func (s *Store) CreateAuthor(_ context.Context, arg db.CreateAuthorParams) (db.Author, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.authors[arg.Username]; ok {
+		return db.Author{}, &pq.Error{Code: "23505", Constraint: "authors_pkey", Message: "duplicate key value violates unique constraint \"authors_pkey\""}
+	}
+	for _, author := range s.authors {
+		if author.Email == arg.Email {
+			return db.Author{}, &pq.Error{Code: "23505", Constraint: "authors_email_key", Message: "duplicate key value violates unique constraint \"authors_email_key\""}
+		}
+	}
+
+	t := now()
+	author := db.Author{
+		Username:       arg.Username,
+		HashedPassword: arg.HashedPassword,
+		Email:          arg.Email,
+		CreatedAt:      t,
+		UpdatedAt:      t,
+		Role:           db.RoleAuthor,
+		Version:        1,
+	}
+	s.authors[author.Username] = author
+	return author, nil
+}
+
+func (s *Store) GetAuthor(_ context.Context, username string) (db.Author, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	author, ok := s.authors[username]
+	if !ok {
+		return db.Author{}, sql.ErrNoRows
+	}
+	return author, nil
+}
+
+func (s *Store) ListAuthors(_ context.Context, arg db.ListAuthorsParams) ([]db.Author, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	authors := make([]db.Author, 0, len(s.authors))
+	for _, author := range s.authors {
+		authors = append(authors, author)
+	}
+	sort.Slice(authors, func(i, j int) bool { return authors[i].Username < authors[j].Username })
+	return page(authors, arg.Limit, arg.Offset), nil
+}
+
+func (s *Store) ListAuthorsAfter(_ context.Context, arg db.ListAuthorsAfterParams) ([]db.Author, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	authors := []db.Author{}
+	for _, author := range s.authors {
+		if author.Username > arg.Username {
+			authors = append(authors, author)
+		}
+	}
+	sort.Slice(authors, func(i, j int) bool { return authors[i].Username < authors[j].Username })
+	return page(authors, arg.Limit, 0), nil
+}
+
+func (s *Store) CountAuthors(context.Context) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return int64(len(s.authors)), nil
+}
+
+func (s *Store) SearchAuthors(_ context.Context, arg db.SearchAuthorsParams) ([]db.Author, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	authors := s.filterAuthors(arg)
+
+	var err error
+	sort.Slice(authors, func(i, j int) bool {
+		a, b := authors[i], authors[j]
+		switch arg.Sort.Field {
+		case "username":
+			return less(arg.Sort, 0, strings.Compare(a.Username, b.Username))
+		case "created_at":
+			return less(arg.Sort, a.CreatedAt.Compare(b.CreatedAt), strings.Compare(a.Username, b.Username))
+		case "updated_at":
+			return less(arg.Sort, a.UpdatedAt.Compare(b.UpdatedAt), strings.Compare(a.Username, b.Username))
+		}
+		err = fmt.Errorf("search: cannot sort by %q", arg.Sort.Field)
+		return false
+	})
+	if err != nil {
+		return nil, err
+	}
+	return page(authors, arg.Limit, arg.Offset), nil
+}
+
+func (s *Store) CountSearchAuthors(_ context.Context, arg db.SearchAuthorsParams) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return int64(len(s.filterAuthors(arg))), nil
+}
+
+func (s *Store) filterAuthors(arg db.SearchAuthorsParams) []db.Author {
+	authors := []db.Author{}
+	for _, author := range s.authors {
+		if inRange(author.CreatedAt, arg.CreatedAfter, arg.CreatedBefore) {
+			authors = append(authors, author)
+		}
+	}
+	return authors
+}
+
+func (s *Store) ListAuthorsByUsernames(_ context.Context, usernames []string) ([]db.Author, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	authors := []db.Author{}
+	for _, username := range usernames {
+		if author, ok := s.authors[username]; ok {
+			authors = append(authors, author)
+		}
+	}
+	sort.Slice(authors, func(i, j int) bool { return authors[i].Username < authors[j].Username })
+	return authors, nil
+}
+
+func (s *Store) UpdateAuthor(_ context.Context, arg db.UpdateAuthorParams) (db.Author, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	author, ok := s.authors[arg.Username]
+	if !ok || author.Version != arg.Version {
+		return db.Author{}, sql.ErrNoRows
+	}
+	for _, other := range s.authors {
+		if other.Username != arg.Username && other.Email == arg.Email {
+			return db.Author{}, &pq.Error{Code: "23505", Constraint: "authors_email_key", Message: "duplicate key value violates unique constraint \"authors_email_key\""}
+		}
+	}
+
+	author.Email = arg.Email
+	author.HashedPassword = arg.HashedPassword
+	author.UpdatedAt = arg.UpdatedAt
+	author.Version++
+	s.authors[author.Username] = author
+	return author, nil
+}
+
+func (s *Store) DeleteAuthor(_ context.Context, username string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, recipe := range s.recipes {
+		if recipe.Author == username && !recipe.DeletedAt.Valid {
+			return &pq.Error{
+				Code:       "23503",
+				Constraint: "recipes_author_fkey",
+				Detail:     fmt.Sprintf("Key (username)=(%s) is still referenced from table \"recipes\".", username),
+			}
+		}
+	}
+	for id, recipe := range s.recipes {
+		if recipe.Author == username {
+			delete(s.recipes, id)
+		}
+	}
+	for id := range s.idempotencyKeys {
+		if id.author == username {
+			delete(s.idempotencyKeys, id)
+		}
+	}
+	delete(s.authors, username)
+	return nil
+}
+
+func (s *Store) CreateRecipe(_ context.Context, arg db.CreateRecipeParams) (db.Recipe, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.authors[arg.Author]; !ok {
+		return db.Recipe{}, &pq.Error{
+			Code:       "23503",
+			Constraint: "recipes_author_fkey",
+			Detail:     fmt.Sprintf("Key (author)=(%s) is not present in table \"authors\".", arg.Author),
+		}
+	}
+
+	t := now()
+	recipe := db.Recipe{
+		ID:          s.nextID,
+		Author:      arg.Author,
+		Ingredients: arg.Ingredients,
+		Steps:       arg.Steps,
+		CreatedAt:   t,
+		UpdatedAt:   t,
+		Version:     1,
+	}
+	s.nextID++
+	s.recipes[recipe.ID] = recipe
+	return recipe, nil
+}
+
+func (s *Store) GetRecipe(_ context.Context, id int64) (db.Recipe, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	recipe, ok := s.recipes[id]
+	if !ok || recipe.DeletedAt.Valid {
+		return db.Recipe{}, sql.ErrNoRows
+	}
+	return recipe, nil
+}
+
+func (s *Store) ListRecipes(_ context.Context, arg db.ListRecipesParams) ([]db.Recipe, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var recipes []db.Recipe
+	for _, recipe := range s.recipes {
+		if recipe.Author == arg.Author && !recipe.DeletedAt.Valid {
+			recipes = append(recipes, recipe)
+		}
+	}
+	sort.Slice(recipes, func(i, j int) bool { return recipes[i].ID < recipes[j].ID })
+	return page(recipes, arg.Limit, arg.Offset), nil
+}
+
+func (s *Store) ListRecipesAfter(_ context.Context, arg db.ListRecipesAfterParams) ([]db.Recipe, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	recipes := []db.Recipe{}
+	for _, recipe := range s.recipes {
+		if recipe.Author != arg.Author || recipe.DeletedAt.Valid {
+			continue
+		}
+		if recipe.CreatedAt.After(arg.CreatedAt) || (recipe.CreatedAt.Equal(arg.CreatedAt) && recipe.ID > arg.ID) {
+			recipes = append(recipes, recipe)
+		}
+	}
+	sort.Slice(recipes, func(i, j int) bool {
+		if !recipes[i].CreatedAt.Equal(recipes[j].CreatedAt) {
+			return recipes[i].CreatedAt.Before(recipes[j].CreatedAt)
+		}
+		return recipes[i].ID < recipes[j].ID
+	})
+	return page(recipes, arg.Limit, 0), nil
+}
+
+func (s *Store) CountRecipes(_ context.Context, author string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var count int64
+	for _, recipe := range s.recipes {
+		if recipe.Author == author && !recipe.DeletedAt.Valid {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (s *Store) SearchRecipes(_ context.Context, arg db.SearchRecipesParams) ([]db.Recipe, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	recipes := s.filterRecipes(arg)
+
+	var err error
+	sort.Slice(recipes, func(i, j int) bool {
+		a, b := recipes[i], recipes[j]
+		switch arg.Sort.Field {
+		case "id":
+			return less(arg.Sort, 0, cmp.Compare(a.ID, b.ID))
+		case "created_at":
+			return less(arg.Sort, a.CreatedAt.Compare(b.CreatedAt), cmp.Compare(a.ID, b.ID))
+		case "updated_at":
+			return less(arg.Sort, a.UpdatedAt.Compare(b.UpdatedAt), cmp.Compare(a.ID, b.ID))
+		}
+		err = fmt.Errorf("search: cannot sort by %q", arg.Sort.Field)
+		return false
+	})
+	if err != nil {
+		return nil, err
+	}
+	return page(recipes, arg.Limit, arg.Offset), nil
+}
+
+func (s *Store) CountSearchRecipes(_ context.Context, arg db.SearchRecipesParams) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return int64(len(s.filterRecipes(arg))), nil
+}
+
+func (s *Store) filterRecipes(arg db.SearchRecipesParams) []db.Recipe {
+	recipes := []db.Recipe{}
+	for _, recipe := range s.recipes {
+		if recipe.Author != arg.Author || recipe.DeletedAt.Valid || !inRange(recipe.CreatedAt, arg.CreatedAfter, arg.CreatedBefore) {
+			continue
+		}
+		if arg.UpdatedSince.Valid && recipe.UpdatedAt.Before(arg.UpdatedSince.Time) {
+			continue
+		}
+		if arg.Ingredient.Valid && !contains(recipe.Ingredients, arg.Ingredient.String) {
+			continue
+		}
+		recipes = append(recipes, recipe)
+	}
+	return recipes
+}
+
+func (s *Store) UpdateRecipe(_ context.Context, arg db.UpdateRecipeParams) (db.Recipe, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	recipe, ok := s.recipes[arg.ID]
+	if !ok || recipe.DeletedAt.Valid || recipe.Version != arg.Version {
+		return db.Recipe{}, sql.ErrNoRows
+	}
+	recipe.Ingredients = arg.Ingredients
+	recipe.Steps = arg.Steps
+	recipe.UpdatedAt = arg.UpdatedAt
+	recipe.Version++
+	s.recipes[recipe.ID] = recipe
+	return recipe, nil
+}
+
+func (s *Store) DeleteRecipe(_ context.Context, id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if recipe, ok := s.recipes[id]; ok && !recipe.DeletedAt.Valid {
+		recipe.DeletedAt = sql.NullTime{Time: now(), Valid: true}
+		s.recipes[id] = recipe
+	}
+	return nil
+}
+
+func (s *Store) DisableAuthor(_ context.Context, username string) (db.Author, error) {
+	return s.updateAuthor(username, func(author *db.Author) error {
+		author.DisabledAt = sql.NullTime{Time: now(), Valid: true}
+		return nil
+	})
+}
+
+func (s *Store) EnableAuthor(_ context.Context, username string) (db.Author, error) {
+	return s.updateAuthor(username, func(author *db.Author) error {
+		author.DisabledAt = sql.NullTime{}
+		return nil
+	})
+}
+
+func (s *Store) GetAuthorDisabledAt(_ context.Context, username string) (sql.NullTime, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	author, ok := s.authors[username]
+	if !ok {
+		return sql.NullTime{}, sql.ErrNoRows
+	}
+	return author.DisabledAt, nil
+}
+
+func (s *Store) UpdateAuthorRole(_ context.Context, arg db.UpdateAuthorRoleParams) (db.Author, error) {
+	return s.updateAuthor(arg.Username, func(author *db.Author) error {
+		if arg.Role != db.RoleAuthor && arg.Role != db.RoleAdmin {
+			return &pq.Error{Code: "23514", Constraint: "authors_role_check", Message: "new row for relation \"authors\" violates check constraint \"authors_role_check\""}
+		}
+		author.Role = arg.Role
+		author.UpdatedAt = arg.UpdatedAt
+		return nil
+	})
+}
+
+func (s *Store) ReassignRecipes(_ context.Context, arg db.ReassignRecipesParams) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var n int64
+	t := now()
+	for id, recipe := range s.recipes {
+		if recipe.Author != arg.FromAuthor || recipe.DeletedAt.Valid {
+			continue
+		}
+		if _, ok := s.authors[arg.ToAuthor]; !ok {
+			return 0, &pq.Error{
+				Code:       "23503",
+				Constraint: "recipes_author_fkey",
+				Detail:     fmt.Sprintf("Key (author)=(%s) is not present in table \"authors\".", arg.ToAuthor),
+			}
+		}
+		recipe.Author = arg.ToAuthor
+		recipe.UpdatedAt = t
+		recipe.Version++
+		s.recipes[id] = recipe
+		n++
+	}
+	return n, nil
+}
+
+func (s *Store) PurgeDeletedRecipes(_ context.Context, deletedBefore time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var n int64
+	for id, recipe := range s.recipes {
+		if recipe.DeletedAt.Valid && recipe.DeletedAt.Time.Before(deletedBefore) {
+			delete(s.recipes, id)
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (s *Store) CreateIdempotencyKey(_ context.Context, arg db.CreateIdempotencyKeyParams) (db.IdempotencyKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.authors[arg.Author]; !ok {
+		return db.IdempotencyKey{}, &pq.Error{
+			Code:       "23503",
+			Constraint: "idempotency_keys_author_fkey",
+			Detail:     fmt.Sprintf("Key (author)=(%s) is not present in table \"authors\".", arg.Author),
+		}
+	}
+
+	id := idempotencyKeyID{author: arg.Author, key: arg.Key}
+	if existing, ok := s.idempotencyKeys[id]; ok {
+		expired := existing.CreatedAt.Before(arg.ExpiredBefore) ||
+			existing.ResponseStatus == 0 && existing.CreatedAt.Before(arg.LeaseExpiredBefore)
+		if !expired {
+			return db.IdempotencyKey{}, sql.ErrNoRows
+		}
+	}
+	key := db.IdempotencyKey{
+		Author:          arg.Author,
+		Key:             arg.Key,
+		RequestHash:     arg.RequestHash,
+		ResponseHeaders: []byte("{}"),
+		ResponseBody:    []byte{},
+		CreatedAt:       now(),
+	}
+	s.idempotencyKeys[id] = key
+	return key, nil
+}
+
+func (s *Store) GetIdempotencyKey(_ context.Context, arg db.GetIdempotencyKeyParams) (db.IdempotencyKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key, ok := s.idempotencyKeys[idempotencyKeyID{author: arg.Author, key: arg.Key}]
+	if !ok {
+		return db.IdempotencyKey{}, sql.ErrNoRows
+	}
+	return key, nil
+}
+
+func (s *Store) UpdateIdempotencyKey(_ context.Context, arg db.UpdateIdempotencyKeyParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := idempotencyKeyID{author: arg.Author, key: arg.Key}
+	if key, ok := s.idempotencyKeys[id]; ok {
+		key.ResponseStatus = arg.ResponseStatus
+		key.ResponseHeaders = arg.ResponseHeaders
+		key.ResponseBody = arg.ResponseBody
+		s.idempotencyKeys[id] = key
+	}
+	return nil
+}
+
+func (s *Store) DeleteIdempotencyKey(_ context.Context, arg db.DeleteIdempotencyKeyParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.idempotencyKeys, idempotencyKeyID{author: arg.Author, key: arg.Key})
+	return nil
+}
+
+func (s *Store) PurgeIdempotencyKeys(_ context.Context, createdBefore time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var n int64
+	for id, key := range s.idempotencyKeys {
+		if key.CreatedAt.Before(createdBefore) {
+			delete(s.idempotencyKeys, id)
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (s *Store) GetStats(context.Context) (db.GetStatsRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var stats db.GetStatsRow
+	for _, author := range s.authors {
+		stats.Authors++
+		if author.DisabledAt.Valid {
+			stats.DisabledAuthors++
+		}
+		if author.Role == db.RoleAdmin {
+			stats.Admins++
+		}
+	}
+	for _, recipe := range s.recipes {
+		if recipe.DeletedAt.Valid {
+			stats.DeletedRecipes++
+		} else {
+			stats.Recipes++
+		}
+	}
+	return stats, nil
+}
+
+// updateAuthor applies update to the author named username
+func (s *Store) updateAuthor(username string, update func(*db.Author) error) (db.Author, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	author, ok := s.authors[username]
+	if !ok {
+		return db.Author{}, sql.ErrNoRows
+	}
+	if err := update(&author); err != nil {
+		return db.Author{}, err
+	}
+	author.Version++
+	s.authors[username] = author
+	return author, nil
+}
+
+func (s *Store) Ping(context.Context) error {
+	return nil
+}
+
+// MigrationVersion reports the schema as fully migrated
+func (s *Store) MigrationVersion(context.Context) (int64, bool, error) {
+	latest, err := migration.LatestVersion()
+	return int64(latest), false, err
+}
+
+func (s *Store) Stats() sql.DBStats {
+	return sql.DBStats{}
+}
+
+// page applies a LIMIT and OFFSET to rows
+// less tells whether a row sorts before another in the order of sort, given the comparison
+// of their sort field and of their unique key used on a tie, as returned by cmp.Compare
+func less(sort db.Sort, field, key int) bool {
+	c := field
+	if c == 0 {
+		c = key
+	}
+	if sort.Desc {
+		return c > 0
+	}
+	return c < 0
+}
+
+// inRange tells whether t is strictly between after and before, when they are set
+func inRange(t time.Time, after, before sql.NullTime) bool {
+	return (!after.Valid || t.After(after.Time)) && (!before.Valid || t.Before(before.Time))
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func page[T any](rows []T, limit, offset int32) []T {
+	if int(offset) >= len(rows) {
+		return []T{}
+	}
+	rows = rows[offset:]
+	if int(limit) < len(rows) {
+		rows = rows[:limit]
+	}
+	return rows
+}
@@ -6,7 +6,9 @@ package mockedstore
 
 import (
 	context "context"
+	sql "database/sql"
 	reflect "reflect"
+	time "time"
 
 	db "github.com/gmaschi/go-recipes-book/internal/services/datastore/postgresql/recipes/sqlc"
 	gomock "github.com/golang/mock/gomock"
@@ -35,6 +37,66 @@ func (m *MockStore) EXPECT() *MockStoreMockRecorder {
 	return m.recorder
 }
 
+// CountAuthors mocks base method.
+func (m *MockStore) CountAuthors(arg0 context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountAuthors", arg0)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountAuthors indicates an expected call of CountAuthors.
+func (mr *MockStoreMockRecorder) CountAuthors(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountAuthors", reflect.TypeOf((*MockStore)(nil).CountAuthors), arg0)
+}
+
+// CountRecipes mocks base method.
+func (m *MockStore) CountRecipes(arg0 context.Context, arg1 string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountRecipes", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountRecipes indicates an expected call of CountRecipes.
+func (mr *MockStoreMockRecorder) CountRecipes(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountRecipes", reflect.TypeOf((*MockStore)(nil).CountRecipes), arg0, arg1)
+}
+
+// CountSearchAuthors mocks base method.
+func (m *MockStore) CountSearchAuthors(arg0 context.Context, arg1 db.SearchAuthorsParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountSearchAuthors", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountSearchAuthors indicates an expected call of CountSearchAuthors.
+func (mr *MockStoreMockRecorder) CountSearchAuthors(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountSearchAuthors", reflect.TypeOf((*MockStore)(nil).CountSearchAuthors), arg0, arg1)
+}
+
+// CountSearchRecipes mocks base method.
+func (m *MockStore) CountSearchRecipes(arg0 context.Context, arg1 db.SearchRecipesParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountSearchRecipes", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountSearchRecipes indicates an expected call of CountSearchRecipes.
+func (mr *MockStoreMockRecorder) CountSearchRecipes(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountSearchRecipes", reflect.TypeOf((*MockStore)(nil).CountSearchRecipes), arg0, arg1)
+}
+
 // CreateAuthor mocks base method.
 func (m *MockStore) CreateAuthor(arg0 context.Context, arg1 db.CreateAuthorParams) (db.Author, error) {
 	m.ctrl.T.Helper()
@@ -50,6 +112,21 @@ func (mr *MockStoreMockRecorder) CreateAuthor(arg0, arg1 interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAuthor", reflect.TypeOf((*MockStore)(nil).CreateAuthor), arg0, arg1)
 }
 
+// CreateIdempotencyKey mocks base method.
+func (m *MockStore) CreateIdempotencyKey(arg0 context.Context, arg1 db.CreateIdempotencyKeyParams) (db.IdempotencyKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateIdempotencyKey", arg0, arg1)
+	ret0, _ := ret[0].(db.IdempotencyKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateIdempotencyKey indicates an expected call of CreateIdempotencyKey.
+func (mr *MockStoreMockRecorder) CreateIdempotencyKey(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateIdempotencyKey", reflect.TypeOf((*MockStore)(nil).CreateIdempotencyKey), arg0, arg1)
+}
+
 // CreateRecipe mocks base method.
 func (m *MockStore) CreateRecipe(arg0 context.Context, arg1 db.CreateRecipeParams) (db.Recipe, error) {
 	m.ctrl.T.Helper()
@@ -79,6 +156,20 @@ func (mr *MockStoreMockRecorder) DeleteAuthor(arg0, arg1 interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAuthor", reflect.TypeOf((*MockStore)(nil).DeleteAuthor), arg0, arg1)
 }
 
+// DeleteIdempotencyKey mocks base method.
+func (m *MockStore) DeleteIdempotencyKey(arg0 context.Context, arg1 db.DeleteIdempotencyKeyParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteIdempotencyKey", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteIdempotencyKey indicates an expected call of DeleteIdempotencyKey.
+func (mr *MockStoreMockRecorder) DeleteIdempotencyKey(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteIdempotencyKey", reflect.TypeOf((*MockStore)(nil).DeleteIdempotencyKey), arg0, arg1)
+}
+
 // DeleteRecipe mocks base method.
 func (m *MockStore) DeleteRecipe(arg0 context.Context, arg1 int64) error {
 	m.ctrl.T.Helper()
@@ -93,6 +184,36 @@ func (mr *MockStoreMockRecorder) DeleteRecipe(arg0, arg1 interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRecipe", reflect.TypeOf((*MockStore)(nil).DeleteRecipe), arg0, arg1)
 }
 
+// DisableAuthor mocks base method.
+func (m *MockStore) DisableAuthor(arg0 context.Context, arg1 string) (db.Author, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisableAuthor", arg0, arg1)
+	ret0, _ := ret[0].(db.Author)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DisableAuthor indicates an expected call of DisableAuthor.
+func (mr *MockStoreMockRecorder) DisableAuthor(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableAuthor", reflect.TypeOf((*MockStore)(nil).DisableAuthor), arg0, arg1)
+}
+
+// EnableAuthor mocks base method.
+func (m *MockStore) EnableAuthor(arg0 context.Context, arg1 string) (db.Author, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnableAuthor", arg0, arg1)
+	ret0, _ := ret[0].(db.Author)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnableAuthor indicates an expected call of EnableAuthor.
+func (mr *MockStoreMockRecorder) EnableAuthor(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnableAuthor", reflect.TypeOf((*MockStore)(nil).EnableAuthor), arg0, arg1)
+}
+
 // GetAuthor mocks base method.
 func (m *MockStore) GetAuthor(arg0 context.Context, arg1 string) (db.Author, error) {
 	m.ctrl.T.Helper()
@@ -108,6 +229,36 @@ func (mr *MockStoreMockRecorder) GetAuthor(arg0, arg1 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuthor", reflect.TypeOf((*MockStore)(nil).GetAuthor), arg0, arg1)
 }
 
+// GetAuthorDisabledAt mocks base method.
+func (m *MockStore) GetAuthorDisabledAt(arg0 context.Context, arg1 string) (sql.NullTime, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAuthorDisabledAt", arg0, arg1)
+	ret0, _ := ret[0].(sql.NullTime)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAuthorDisabledAt indicates an expected call of GetAuthorDisabledAt.
+func (mr *MockStoreMockRecorder) GetAuthorDisabledAt(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuthorDisabledAt", reflect.TypeOf((*MockStore)(nil).GetAuthorDisabledAt), arg0, arg1)
+}
+
+// GetIdempotencyKey mocks base method.
+func (m *MockStore) GetIdempotencyKey(arg0 context.Context, arg1 db.GetIdempotencyKeyParams) (db.IdempotencyKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetIdempotencyKey", arg0, arg1)
+	ret0, _ := ret[0].(db.IdempotencyKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetIdempotencyKey indicates an expected call of GetIdempotencyKey.
+func (mr *MockStoreMockRecorder) GetIdempotencyKey(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetIdempotencyKey", reflect.TypeOf((*MockStore)(nil).GetIdempotencyKey), arg0, arg1)
+}
+
 // GetRecipe mocks base method.
 func (m *MockStore) GetRecipe(arg0 context.Context, arg1 int64) (db.Recipe, error) {
 	m.ctrl.T.Helper()
@@ -123,6 +274,21 @@ func (mr *MockStoreMockRecorder) GetRecipe(arg0, arg1 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRecipe", reflect.TypeOf((*MockStore)(nil).GetRecipe), arg0, arg1)
 }
 
+// GetStats mocks base method.
+func (m *MockStore) GetStats(arg0 context.Context) (db.GetStatsRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStats", arg0)
+	ret0, _ := ret[0].(db.GetStatsRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStats indicates an expected call of GetStats.
+func (mr *MockStoreMockRecorder) GetStats(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStats", reflect.TypeOf((*MockStore)(nil).GetStats), arg0)
+}
+
 // ListAuthors mocks base method.
 func (m *MockStore) ListAuthors(arg0 context.Context, arg1 db.ListAuthorsParams) ([]db.Author, error) {
 	m.ctrl.T.Helper()
@@ -138,6 +304,36 @@ func (mr *MockStoreMockRecorder) ListAuthors(arg0, arg1 interface{}) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAuthors", reflect.TypeOf((*MockStore)(nil).ListAuthors), arg0, arg1)
 }
 
+// ListAuthorsAfter mocks base method.
+func (m *MockStore) ListAuthorsAfter(arg0 context.Context, arg1 db.ListAuthorsAfterParams) ([]db.Author, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAuthorsAfter", arg0, arg1)
+	ret0, _ := ret[0].([]db.Author)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAuthorsAfter indicates an expected call of ListAuthorsAfter.
+func (mr *MockStoreMockRecorder) ListAuthorsAfter(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAuthorsAfter", reflect.TypeOf((*MockStore)(nil).ListAuthorsAfter), arg0, arg1)
+}
+
+// ListAuthorsByUsernames mocks base method.
+func (m *MockStore) ListAuthorsByUsernames(arg0 context.Context, arg1 []string) ([]db.Author, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAuthorsByUsernames", arg0, arg1)
+	ret0, _ := ret[0].([]db.Author)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAuthorsByUsernames indicates an expected call of ListAuthorsByUsernames.
+func (mr *MockStoreMockRecorder) ListAuthorsByUsernames(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAuthorsByUsernames", reflect.TypeOf((*MockStore)(nil).ListAuthorsByUsernames), arg0, arg1)
+}
+
 // ListRecipes mocks base method.
 func (m *MockStore) ListRecipes(arg0 context.Context, arg1 db.ListRecipesParams) ([]db.Recipe, error) {
 	m.ctrl.T.Helper()
@@ -153,6 +349,140 @@ func (mr *MockStoreMockRecorder) ListRecipes(arg0, arg1 interface{}) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRecipes", reflect.TypeOf((*MockStore)(nil).ListRecipes), arg0, arg1)
 }
 
+// ListRecipesAfter mocks base method.
+func (m *MockStore) ListRecipesAfter(arg0 context.Context, arg1 db.ListRecipesAfterParams) ([]db.Recipe, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRecipesAfter", arg0, arg1)
+	ret0, _ := ret[0].([]db.Recipe)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRecipesAfter indicates an expected call of ListRecipesAfter.
+func (mr *MockStoreMockRecorder) ListRecipesAfter(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRecipesAfter", reflect.TypeOf((*MockStore)(nil).ListRecipesAfter), arg0, arg1)
+}
+
+// MigrationVersion mocks base method.
+func (m *MockStore) MigrationVersion(arg0 context.Context) (int64, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MigrationVersion", arg0)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// MigrationVersion indicates an expected call of MigrationVersion.
+func (mr *MockStoreMockRecorder) MigrationVersion(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MigrationVersion", reflect.TypeOf((*MockStore)(nil).MigrationVersion), arg0)
+}
+
+// Ping mocks base method.
+func (m *MockStore) Ping(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ping", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Ping indicates an expected call of Ping.
+func (mr *MockStoreMockRecorder) Ping(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ping", reflect.TypeOf((*MockStore)(nil).Ping), arg0)
+}
+
+// PurgeDeletedRecipes mocks base method.
+func (m *MockStore) PurgeDeletedRecipes(arg0 context.Context, arg1 time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeDeletedRecipes", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeDeletedRecipes indicates an expected call of PurgeDeletedRecipes.
+func (mr *MockStoreMockRecorder) PurgeDeletedRecipes(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeDeletedRecipes", reflect.TypeOf((*MockStore)(nil).PurgeDeletedRecipes), arg0, arg1)
+}
+
+// PurgeIdempotencyKeys mocks base method.
+func (m *MockStore) PurgeIdempotencyKeys(arg0 context.Context, arg1 time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeIdempotencyKeys", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeIdempotencyKeys indicates an expected call of PurgeIdempotencyKeys.
+func (mr *MockStoreMockRecorder) PurgeIdempotencyKeys(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeIdempotencyKeys", reflect.TypeOf((*MockStore)(nil).PurgeIdempotencyKeys), arg0, arg1)
+}
+
+// ReassignRecipes mocks base method.
+func (m *MockStore) ReassignRecipes(arg0 context.Context, arg1 db.ReassignRecipesParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReassignRecipes", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReassignRecipes indicates an expected call of ReassignRecipes.
+func (mr *MockStoreMockRecorder) ReassignRecipes(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReassignRecipes", reflect.TypeOf((*MockStore)(nil).ReassignRecipes), arg0, arg1)
+}
+
+// SearchAuthors mocks base method.
+func (m *MockStore) SearchAuthors(arg0 context.Context, arg1 db.SearchAuthorsParams) ([]db.Author, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchAuthors", arg0, arg1)
+	ret0, _ := ret[0].([]db.Author)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchAuthors indicates an expected call of SearchAuthors.
+func (mr *MockStoreMockRecorder) SearchAuthors(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchAuthors", reflect.TypeOf((*MockStore)(nil).SearchAuthors), arg0, arg1)
+}
+
+// SearchRecipes mocks base method.
+func (m *MockStore) SearchRecipes(arg0 context.Context, arg1 db.SearchRecipesParams) ([]db.Recipe, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchRecipes", arg0, arg1)
+	ret0, _ := ret[0].([]db.Recipe)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchRecipes indicates an expected call of SearchRecipes.
+func (mr *MockStoreMockRecorder) SearchRecipes(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchRecipes", reflect.TypeOf((*MockStore)(nil).SearchRecipes), arg0, arg1)
+}
+
+// Stats mocks base method.
+func (m *MockStore) Stats() sql.DBStats {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stats")
+	ret0, _ := ret[0].(sql.DBStats)
+	return ret0
+}
+
+// Stats indicates an expected call of Stats.
+func (mr *MockStoreMockRecorder) Stats() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stats", reflect.TypeOf((*MockStore)(nil).Stats))
+}
+
 // UpdateAuthor mocks base method.
 func (m *MockStore) UpdateAuthor(arg0 context.Context, arg1 db.UpdateAuthorParams) (db.Author, error) {
 	m.ctrl.T.Helper()
@@ -168,6 +498,35 @@ func (mr *MockStoreMockRecorder) UpdateAuthor(arg0, arg1 interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAuthor", reflect.TypeOf((*MockStore)(nil).UpdateAuthor), arg0, arg1)
 }
 
+// UpdateAuthorRole mocks base method.
+func (m *MockStore) UpdateAuthorRole(arg0 context.Context, arg1 db.UpdateAuthorRoleParams) (db.Author, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateAuthorRole", arg0, arg1)
+	ret0, _ := ret[0].(db.Author)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateAuthorRole indicates an expected call of UpdateAuthorRole.
+func (mr *MockStoreMockRecorder) UpdateAuthorRole(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAuthorRole", reflect.TypeOf((*MockStore)(nil).UpdateAuthorRole), arg0, arg1)
+}
+
+// UpdateIdempotencyKey mocks base method.
+func (m *MockStore) UpdateIdempotencyKey(arg0 context.Context, arg1 db.UpdateIdempotencyKeyParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateIdempotencyKey", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateIdempotencyKey indicates an expected call of UpdateIdempotencyKey.
+func (mr *MockStoreMockRecorder) UpdateIdempotencyKey(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateIdempotencyKey", reflect.TypeOf((*MockStore)(nil).UpdateIdempotencyKey), arg0, arg1)
+}
+
 // UpdateRecipe mocks base method.
 func (m *MockStore) UpdateRecipe(arg0 context.Context, arg1 db.UpdateRecipeParams) (db.Recipe, error) {
 	m.ctrl.T.Helper()
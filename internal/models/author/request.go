@@ -1,5 +1,7 @@
 package authorModel
 
+import "time"
+
 type (
 	CreateRequest struct {
 		Username string `json:"username" binding:"required,alphanum"`
@@ -13,6 +15,7 @@ type (
 
 	UpdateRequest struct {
 		Username string `json:"username" binding:"required,alphanum"`
+		Version  int32  `json:"version" binding:"required,min=1"`
 		Email    string `json:"email"`
 		Password string `json:"password"`
 	}
@@ -23,7 +26,23 @@ type (
 
 	ListRequest struct {
 		PageID   int32 `form:"page_id" binding:"required,min=1"`
-		PageSize int32 `form:"page_size" binding:"required,min=5,max=10"`
+		PageSize int32 `form:"page_size" binding:"required,min=1,max=100"`
+	}
+
+	// ListFilterRequest documents the sort and filter parameters of the list, which are read
+	// with the filter package. Timestamps are in RFC 3339.
+	ListFilterRequest struct {
+		Sort          string    `form:"sort"`
+		CreatedAfter  time.Time `form:"created_after"`
+		CreatedBefore time.Time `form:"created_before"`
+	}
+
+	// ListCursorRequest selects the page of a list after the cursor returned with the
+	// previous page, or the first page without a cursor
+	ListCursorRequest struct {
+		Cursor string `form:"cursor"`
+		Limit  int32  `form:"limit" binding:"required,min=1,max=100"`
+		Total  bool   `form:"total"`
 	}
 
 	LoginRequest struct {
@@ -1,46 +1,82 @@
 package authorModel
 
-import "time"
+import (
+	"database/sql"
+	"time"
+)
 
 type (
 	CreateResponse struct {
-		Username       string    `json:"username"`
-		HashedPassword string    `json:"-"`
-		Email          string    `json:"-"`
-		CreatedAt      time.Time `json:"created_at"`
-		UpdatedAt      time.Time `json:"-"`
+		Username       string       `json:"username"`
+		HashedPassword string       `json:"-"`
+		Email          string       `json:"-"`
+		CreatedAt      time.Time    `json:"created_at"`
+		UpdatedAt      time.Time    `json:"-"`
+		Role           string       `json:"-"`
+		DisabledAt     sql.NullTime `json:"-"`
+		Version        int32        `json:"version"`
 	}
 
 	GetResponse struct {
-		Username       string    `json:"username"`
-		HashedPassword string    `json:"-"`
-		Email          string    `json:"email"`
-		CreatedAt      time.Time `json:"created_at"`
-		UpdatedAt      time.Time `json:"updated_at"`
+		Username       string       `json:"username"`
+		HashedPassword string       `json:"-"`
+		Email          string       `json:"email"`
+		CreatedAt      time.Time    `json:"created_at"`
+		UpdatedAt      time.Time    `json:"updated_at"`
+		Role           string       `json:"-"`
+		DisabledAt     sql.NullTime `json:"-"`
+		Version        int32        `json:"version"`
 	}
 
 	UpdateResponse struct {
-		Username       string    `json:"username"`
-		HashedPassword string    `json:"-"`
-		Email          string    `json:"email"`
-		CreatedAt      time.Time `json:"created_at"`
-		UpdatedAt      time.Time `json:"updated_at"`
+		Username       string       `json:"username"`
+		HashedPassword string       `json:"-"`
+		Email          string       `json:"email"`
+		CreatedAt      time.Time    `json:"created_at"`
+		UpdatedAt      time.Time    `json:"updated_at"`
+		Role           string       `json:"-"`
+		DisabledAt     sql.NullTime `json:"-"`
+		Version        int32        `json:"version"`
 	}
 
 	ListResponse struct {
-		Username       string    `json:"username"`
-		HashedPassword string    `json:"-"`
-		Email          string    `json:"email"`
-		CreatedAt      time.Time `json:"created_at"`
-		UpdatedAt      time.Time `json:"updated_at"`
+		Username       string       `json:"username"`
+		HashedPassword string       `json:"-"`
+		Email          string       `json:"email"`
+		CreatedAt      time.Time    `json:"created_at"`
+		UpdatedAt      time.Time    `json:"updated_at"`
+		Role           string       `json:"-"`
+		DisabledAt     sql.NullTime `json:"-"`
+		Version        int32        `json:"version"`
 	}
 
 	LoginResponse struct {
-		AccessToken    string    `json:"access_token"`
-		Username       string    `json:"username"`
-		HashedPassword string    `json:"-"`
-		Email          string    `json:"email"`
-		CreatedAt      time.Time `json:"created_at"`
-		UpdatedAt      time.Time `json:"updated_at"`
+		AccessToken    string       `json:"access_token"`
+		Username       string       `json:"username"`
+		HashedPassword string       `json:"-"`
+		Email          string       `json:"email"`
+		CreatedAt      time.Time    `json:"created_at"`
+		UpdatedAt      time.Time    `json:"updated_at"`
+		Role           string       `json:"-"`
+		DisabledAt     sql.NullTime `json:"-"`
+		Version        int32        `json:"version"`
+	}
+
+	// ListPageResponse is a page of a list paginated with a cursor. NextCursor is empty on
+	// the last page and Total is only set when requested.
+	ListPageResponse struct {
+		Items      []ListResponse `json:"items"`
+		NextCursor string         `json:"next_cursor,omitempty"`
+		Total      *int64         `json:"total,omitempty"`
+	}
+
+	// ListEnvelopeResponse is a page of a list paginated by page ID, sent from version 2 of
+	// the API instead of the bare array of items
+	ListEnvelopeResponse struct {
+		Items    []ListResponse `json:"items"`
+		Page     int32          `json:"page"`
+		PageSize int32          `json:"page_size"`
+		Total    int64          `json:"total"`
+		HasMore  bool           `json:"has_more"`
 	}
 )
@@ -0,0 +1,9 @@
+package graphqlModel
+
+type (
+	Request struct {
+		Query         string                 `json:"query" binding:"required"`
+		OperationName string                 `json:"operationName"`
+		Variables     map[string]interface{} `json:"variables"`
+	}
+)
@@ -0,0 +1,16 @@
+package graphqlModel
+
+type (
+	Response struct {
+		Data   interface{} `json:"data"`
+		Errors []Error     `json:"errors,omitempty"`
+	}
+
+	// Error is a GraphQL error. The errors reported by the resolvers hold the same code,
+	// status and invalid fields as the problems of the REST API in their extensions.
+	Error struct {
+		Message    string                 `json:"message"`
+		Path       []interface{}          `json:"path,omitempty"`
+		Extensions map[string]interface{} `json:"extensions,omitempty"`
+	}
+)
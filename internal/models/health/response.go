@@ -0,0 +1,21 @@
+package healthModel
+
+const (
+	StatusOK          = "ok"
+	StatusUnavailable = "unavailable"
+)
+
+type (
+	LiveResponse struct {
+		Status string `json:"status"`
+	}
+
+	CheckResponse struct {
+		Status string `json:"status"`
+	}
+
+	ReadyResponse struct {
+		Status string                   `json:"status"`
+		Checks map[string]CheckResponse `json:"checks"`
+	}
+)
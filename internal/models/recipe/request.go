@@ -1,5 +1,7 @@
 package recipeModel
 
+import "time"
+
 type (
 	CreateRequest struct {
 		Ingredients []string `json:"ingredients" binding:"required"`
@@ -12,6 +14,7 @@ type (
 
 	UpdateRequest struct {
 		ID          int64    `json:"id" binding:"required"`
+		Version     int32    `json:"version" binding:"required,min=1"`
 		Ingredients []string `json:"ingredients"`
 		Steps       []string `json:"steps"`
 	}
@@ -22,6 +25,25 @@ type (
 
 	ListRequest struct {
 		PageID   int32 `form:"page_id" binding:"required,min=1"`
-		PageSize int32 `form:"page_size" binding:"required,min=5,max=10"`
+		PageSize int32 `form:"page_size" binding:"required,min=1,max=100"`
+	}
+
+	// ListFilterRequest documents the sort and filter parameters of the list, which are read
+	// with the filter package. Timestamps are in RFC 3339.
+	ListFilterRequest struct {
+		Sort          string    `form:"sort"`
+		CreatedAfter  time.Time `form:"created_after"`
+		CreatedBefore time.Time `form:"created_before"`
+		UpdatedSince  time.Time `form:"updated_since"`
+		HasIngredient string    `form:"has_ingredient"`
+		Author        string    `form:"author"`
+	}
+
+	// ListCursorRequest selects the page of a list after the cursor returned with the
+	// previous page, or the first page without a cursor
+	ListCursorRequest struct {
+		Cursor string `form:"cursor"`
+		Limit  int32  `form:"limit" binding:"required,min=1,max=100"`
+		Total  bool   `form:"total"`
 	}
 )
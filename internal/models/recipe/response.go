@@ -1,41 +1,70 @@
 package recipeModel
 
-import "time"
+import (
+	"database/sql"
+	"time"
+)
 
 type (
 	CreateResponse struct {
-		ID          int64     `json:"-"`
-		Author      string    `json:"author"`
-		Ingredients []string  `json:"ingredients"`
-		Steps       []string  `json:"steps"`
-		CreatedAt   time.Time `json:"created_at"`
-		UpdatedAt   time.Time `json:"-"`
+		ID          int64        `json:"id"`
+		Author      string       `json:"author"`
+		Ingredients []string     `json:"ingredients"`
+		Steps       []string     `json:"steps"`
+		CreatedAt   time.Time    `json:"created_at"`
+		UpdatedAt   time.Time    `json:"-"`
+		DeletedAt   sql.NullTime `json:"-"`
+		Version     int32        `json:"version"`
 	}
 
 	GetResponse struct {
-		ID          int64     `json:"-"`
-		Author      string    `json:"author"`
-		Ingredients []string  `json:"ingredients"`
-		Steps       []string  `json:"steps"`
-		CreatedAt   time.Time `json:"created_at"`
-		UpdatedAt   time.Time `json:"updated_at"`
+		ID          int64        `json:"id"`
+		Author      string       `json:"author"`
+		Ingredients []string     `json:"ingredients"`
+		Steps       []string     `json:"steps"`
+		CreatedAt   time.Time    `json:"created_at"`
+		UpdatedAt   time.Time    `json:"updated_at"`
+		DeletedAt   sql.NullTime `json:"-"`
+		Version     int32        `json:"version"`
 	}
 
 	UpdateResponse struct {
-		ID          int64     `json:"-"`
-		Author      string    `json:"author"`
-		Ingredients []string  `json:"ingredients"`
-		Steps       []string  `json:"steps"`
-		CreatedAt   time.Time `json:"created_at"`
-		UpdatedAt   time.Time `json:"updated_at"`
+		ID          int64        `json:"id"`
+		Author      string       `json:"author"`
+		Ingredients []string     `json:"ingredients"`
+		Steps       []string     `json:"steps"`
+		CreatedAt   time.Time    `json:"created_at"`
+		UpdatedAt   time.Time    `json:"updated_at"`
+		DeletedAt   sql.NullTime `json:"-"`
+		Version     int32        `json:"version"`
 	}
 
 	ListResponse struct {
-		ID          int64     `json:"-"`
-		Author      string    `json:"author"`
-		Ingredients []string  `json:"ingredients"`
-		Steps       []string  `json:"steps"`
-		CreatedAt   time.Time `json:"created_at"`
-		UpdatedAt   time.Time `json:"updated_at"`
+		ID          int64        `json:"id"`
+		Author      string       `json:"author"`
+		Ingredients []string     `json:"ingredients"`
+		Steps       []string     `json:"steps"`
+		CreatedAt   time.Time    `json:"created_at"`
+		UpdatedAt   time.Time    `json:"updated_at"`
+		DeletedAt   sql.NullTime `json:"-"`
+		Version     int32        `json:"version"`
+	}
+
+	// ListPageResponse is a page of a list paginated with a cursor. NextCursor is empty on
+	// the last page and Total is only set when requested.
+	ListPageResponse struct {
+		Items      []ListResponse `json:"items"`
+		NextCursor string         `json:"next_cursor,omitempty"`
+		Total      *int64         `json:"total,omitempty"`
+	}
+
+	// ListEnvelopeResponse is a page of a list paginated by page ID, sent from version 2 of
+	// the API instead of the bare array of items
+	ListEnvelopeResponse struct {
+		Items    []ListResponse `json:"items"`
+		Page     int32          `json:"page"`
+		PageSize int32          `json:"page_size"`
+		Total    int64          `json:"total"`
+		HasMore  bool           `json:"has_more"`
 	}
 )
@@ -0,0 +1,148 @@
+package authorService
+
+import (
+	"context"
+	"github.com/gin-gonic/gin/binding"
+	errorMiddleware "github.com/gmaschi/go-recipes-book/internal/controllers/middlewares/errors"
+	authorDomain "github.com/gmaschi/go-recipes-book/internal/domain/author"
+	authorModel "github.com/gmaschi/go-recipes-book/internal/models/author"
+	authInterceptor "github.com/gmaschi/go-recipes-book/internal/rpc/interceptors/auth"
+	db "github.com/gmaschi/go-recipes-book/internal/services/datastore/postgresql/recipes/sqlc"
+	recipesbookv1 "github.com/gmaschi/go-recipes-book/pkg/pb/recipesbook/v1"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Service implements the AuthorService of the gRPC API with the same rules as the author
+// controller of the REST API
+type Service struct {
+	recipesbookv1.UnimplementedAuthorServiceServer
+
+	store   db.Store
+	authors *authorDomain.Service
+}
+
+// New creates a pointer to a Service applying the rules of authors, and reading and listing
+// the authors of store
+func New(store db.Store, authors *authorDomain.Service) *Service {
+	return &Service{
+		store:   store,
+		authors: authors,
+	}
+}
+
+// Login authenticates an author and issues an access token
+func (s *Service) Login(ctx context.Context, in *recipesbookv1.LoginRequest) (*recipesbookv1.LoginResponse, error) {
+	req := authorModel.LoginRequest{Username: in.GetUsername(), Password: in.GetPassword()}
+	if err := binding.Validator.ValidateStruct(&req); err != nil {
+		return nil, errorMiddleware.TranslateBind(err)
+	}
+
+	author, token, err := s.authors.Login(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return &recipesbookv1.LoginResponse{
+		AccessToken: token,
+		Author:      toAuthor(author),
+	}, nil
+}
+
+// CreateAuthor creates an author account
+func (s *Service) CreateAuthor(ctx context.Context, in *recipesbookv1.CreateAuthorRequest) (*recipesbookv1.Author, error) {
+	req := authorModel.CreateRequest{Username: in.GetUsername(), Password: in.GetPassword(), Email: in.GetEmail()}
+	if err := binding.Validator.ValidateStruct(&req); err != nil {
+		return nil, errorMiddleware.TranslateBind(err)
+	}
+
+	author, err := s.authors.Create(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return toAuthor(author), nil
+}
+
+// GetAuthor returns an author by username
+func (s *Service) GetAuthor(ctx context.Context, in *recipesbookv1.GetAuthorRequest) (*recipesbookv1.Author, error) {
+	req := authorModel.GetRequest{Username: in.GetUsername()}
+	if err := binding.Validator.ValidateStruct(&req); err != nil {
+		return nil, errorMiddleware.TranslateBind(err)
+	}
+
+	author, err := s.store.GetAuthor(ctx, req.Username)
+	if err != nil {
+		return nil, err
+	}
+	return toAuthor(author), nil
+}
+
+// ListAuthors returns a page of authors ordered by username
+func (s *Service) ListAuthors(ctx context.Context, in *recipesbookv1.ListAuthorsRequest) (*recipesbookv1.ListAuthorsResponse, error) {
+	req := authorModel.ListRequest{PageID: in.GetPageId(), PageSize: in.GetPageSize()}
+	if err := binding.Validator.ValidateStruct(&req); err != nil {
+		return nil, errorMiddleware.TranslateBind(err)
+	}
+
+	authors, err := s.store.ListAuthors(ctx, db.ListAuthorsParams{
+		Limit:  req.PageSize,
+		Offset: req.PageSize * (req.PageID - 1),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	res := &recipesbookv1.ListAuthorsResponse{Authors: make([]*recipesbookv1.Author, 0, len(authors))}
+	for _, author := range authors {
+		res.Authors = append(res.Authors, toAuthor(author))
+	}
+	return res, nil
+}
+
+// UpdateAuthor changes the email and/or password of the authenticated author, if it still
+// has the version the update is made against
+func (s *Service) UpdateAuthor(ctx context.Context, in *recipesbookv1.UpdateAuthorRequest) (*recipesbookv1.Author, error) {
+	req := authorModel.UpdateRequest{Username: in.GetUsername(), Version: in.GetVersion(), Email: in.GetEmail(), Password: in.GetPassword()}
+	if err := binding.Validator.ValidateStruct(&req); err != nil {
+		return nil, errorMiddleware.TranslateBind(err)
+	}
+
+	payload, err := authInterceptor.Payload(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	updatedAuthor, err := s.authors.Update(ctx, payload.Username, req)
+	if err != nil {
+		return nil, err
+	}
+	return toAuthor(updatedAuthor), nil
+}
+
+// DeleteAuthor deletes the authenticated author
+func (s *Service) DeleteAuthor(ctx context.Context, in *recipesbookv1.DeleteAuthorRequest) (*emptypb.Empty, error) {
+	req := authorModel.DeleteRequest{Username: in.GetUsername()}
+	if err := binding.Validator.ValidateStruct(&req); err != nil {
+		return nil, errorMiddleware.TranslateBind(err)
+	}
+
+	payload, err := authInterceptor.Payload(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.authors.Delete(ctx, payload.Username, req.Username); err != nil {
+		return nil, err
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func toAuthor(author db.Author) *recipesbookv1.Author {
+	return &recipesbookv1.Author{
+		Username:  author.Username,
+		Email:     author.Email,
+		CreatedAt: timestamppb.New(author.CreatedAt),
+		UpdatedAt: timestamppb.New(author.UpdatedAt),
+		Version:   author.Version,
+	}
+}
@@ -0,0 +1,73 @@
+package authInterceptor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	authMiddleware "github.com/gmaschi/go-recipes-book/internal/controllers/middlewares/auth"
+	"github.com/gmaschi/go-recipes-book/pkg/auth/tokenAuth"
+	"github.com/gmaschi/go-recipes-book/pkg/tools/problem"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"net/http"
+	"strings"
+)
+
+type payloadKey struct{}
+
+var errInvalidAuthorization = problem.New(http.StatusUnauthorized, problem.CodeInvalidAuthorization, "invalid authorization metadata format")
+
+// AuthInterceptor verifies the access token sent in the authorization metadata, in the same
+// "bearer <token>" format as the HTTP header, checks that its author can still use it, and
+// stores its payload in the context. The methods listed in public, by full method name, are
+// served without a token.
+func AuthInterceptor(tokenMaker tokenAuth.Maker, authors authMiddleware.Authors, public ...string) grpc.UnaryServerInterceptor {
+	publicMethods := make(map[string]bool, len(public))
+	for _, method := range public {
+		publicMethods[method] = true
+	}
+
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if publicMethods[info.FullMethod] {
+			return handler(ctx, req)
+		}
+
+		md, _ := metadata.FromIncomingContext(ctx)
+		values := md.Get(authMiddleware.AuthorizationHeaderKey)
+		if len(values) == 0 || values[0] == "" {
+			return nil, authMiddleware.ErrMissingAuthorization
+		}
+
+		fields := strings.Fields(values[0])
+		if len(fields) < 2 {
+			return nil, errInvalidAuthorization
+		}
+
+		authorizationType := strings.ToLower(fields[0])
+		if authorizationType != authMiddleware.AuthorizationTypeBearer {
+			return nil, problem.New(http.StatusUnauthorized, problem.CodeUnsupportedAuthorization, fmt.Sprintf("unsupported authorization format %s", authorizationType))
+		}
+
+		payload, err := tokenMaker.VerifyToken(fields[1])
+		if err != nil {
+			if errors.Is(err, tokenAuth.ErrExpiredToken) {
+				return nil, authMiddleware.ErrExpiredToken
+			}
+			return nil, authMiddleware.ErrInvalidToken.WithCause(err)
+		}
+		if err := authMiddleware.CheckAuthor(ctx, authors, payload.Username); err != nil {
+			return nil, err
+		}
+
+		return handler(context.WithValue(ctx, payloadKey{}, payload), req)
+	}
+}
+
+// Payload returns the payload of the access token verified by AuthInterceptor
+func Payload(ctx context.Context) (*tokenAuth.Payload, error) {
+	payload, ok := ctx.Value(payloadKey{}).(*tokenAuth.Payload)
+	if !ok {
+		return nil, authMiddleware.ErrMissingAuthorization
+	}
+	return payload, nil
+}
@@ -0,0 +1,134 @@
+package errorInterceptor
+
+import (
+	"context"
+	"fmt"
+	errorMiddleware "github.com/gmaschi/go-recipes-book/internal/controllers/middlewares/errors"
+	"github.com/gmaschi/go-recipes-book/pkg/logger"
+	"github.com/gmaschi/go-recipes-book/pkg/tools/problem"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/runtime/protoiface"
+	"net/http"
+	"strings"
+)
+
+// Domain is the domain of the google.rpc.ErrorInfo details, whose reason is the problem code
+// also reported by the REST API
+const Domain = "recipes-book"
+
+var (
+	// statusCodes maps the HTTP status of the problems to gRPC codes
+	statusCodes = map[int]codes.Code{
+		http.StatusBadRequest:          codes.InvalidArgument,
+		http.StatusUnauthorized:        codes.Unauthenticated,
+		http.StatusForbidden:           codes.PermissionDenied,
+		http.StatusNotFound:            codes.NotFound,
+		http.StatusConflict:            codes.Aborted,
+		http.StatusPreconditionFailed:  codes.FailedPrecondition,
+		http.StatusUnprocessableEntity: codes.InvalidArgument,
+		http.StatusTooManyRequests:     codes.ResourceExhausted,
+		http.StatusInternalServerError: codes.Internal,
+		http.StatusServiceUnavailable:  codes.Unavailable,
+	}
+
+	// problemCodes overrides the gRPC code of the problems whose HTTP status is kept for the
+	// compatibility of the REST API
+	problemCodes = map[string]codes.Code{
+		problem.CodeAuthorNotAuthenticated: codes.PermissionDenied,
+		problem.CodeRecipeNotOwned:         codes.PermissionDenied,
+		problem.CodeAuthorUsernameTaken:    codes.AlreadyExists,
+		problem.CodeAuthorEmailTaken:       codes.AlreadyExists,
+		problem.CodeConflict:               codes.AlreadyExists,
+		problem.CodeAuthorHasRecipes:       codes.FailedPrecondition,
+		problem.CodeRecipeAuthorNotFound:   codes.FailedPrecondition,
+		problem.CodeReferenceViolation:     codes.FailedPrecondition,
+	}
+)
+
+// ErrorInterceptor reports the errors returned by the handlers, and their panics, as gRPC
+// statuses. resources maps the full service names to the resource named in their not found
+// errors.
+func ErrorInterceptor(resources map[string]string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (res interface{}, err error) {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				logger.FromContext(ctx).Error("panic recovered", "panic", recovered)
+				res, err = nil, Status(fmt.Errorf("panic: %v", recovered), "")
+			}
+		}()
+
+		res, err = handler(ctx, req)
+		if err == nil {
+			return res, nil
+		}
+
+		st := Status(err, resources[serviceName(info.FullMethod)])
+		if code := status.Code(st); code == codes.Internal || code == codes.Unknown {
+			// the status does not carry the cause, which is only logged
+			logger.FromContext(ctx).Error("rpc failed", "error", err.Error())
+		}
+		return nil, st
+	}
+}
+
+// Status converts err to a gRPC status error carrying the problem code in a
+// google.rpc.ErrorInfo and the invalid fields in a google.rpc.BadRequest. Errors that are
+// already statuses are returned unchanged.
+func Status(err error, resource string) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	p := errorMiddleware.Translate(err, resource)
+	code, ok := problemCodes[p.Code]
+	if !ok {
+		code, ok = statusCodes[p.Status]
+	}
+	if !ok {
+		code = codes.Unknown
+	}
+
+	st := status.New(code, p.Detail)
+	details := []protoiface.MessageV1{
+		&errdetails.ErrorInfo{Reason: p.Code, Domain: Domain},
+	}
+	if len(p.Fields) > 0 {
+		badRequest := &errdetails.BadRequest{}
+		for _, field := range p.Fields {
+			badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       field.Field,
+				Description: field.Message,
+			})
+		}
+		details = append(details, badRequest)
+	}
+
+	if withDetails, err := st.WithDetails(details...); err == nil {
+		st = withDetails
+	}
+	return st.Err()
+}
+
+// Code returns the problem code of a status returned by Status, or an empty string
+func Code(err error) string {
+	st, ok := status.FromError(err)
+	if !ok {
+		return ""
+	}
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok && info.Domain == Domain {
+			return info.Reason
+		}
+	}
+	return ""
+}
+
+// serviceName returns the service of a full method name, e.g. recipesbook.v1.AuthorService
+// for /recipesbook.v1.AuthorService/Login
+func serviceName(fullMethod string) string {
+	service, _, _ := strings.Cut(strings.TrimPrefix(fullMethod, "/"), "/")
+	return service
+}
@@ -0,0 +1,69 @@
+package loggingInterceptor
+
+import (
+	"context"
+	loggingMiddleware "github.com/gmaschi/go-recipes-book/internal/controllers/middlewares/logging"
+	"github.com/gmaschi/go-recipes-book/pkg/logger"
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// RequestIDMetadataKey is the metadata key of the request ID, sent back in the header
+var RequestIDMetadataKey = strings.ToLower(loggingMiddleware.RequestIDHeaderKey)
+
+// LoggingInterceptor propagates the request ID metadata of a call, or generates one, echoes
+// it in the response header, attaches a logger carrying it to the context and logs every
+// call once it has been handled
+func LoggingInterceptor(log *slog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+
+		var requestID string
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if values := md.Get(RequestIDMetadataKey); len(values) > 0 {
+				requestID = values[0]
+			}
+		}
+		if !loggingMiddleware.ValidRequestID(requestID) {
+			requestID = uuid.New().String()
+		}
+		_ = grpc.SetHeader(ctx, metadata.Pairs(RequestIDMetadataKey, requestID))
+
+		log := log.With(loggingMiddleware.RequestIDKey, requestID)
+		ctx = logger.WithContext(ctx, log)
+
+		res, err := handler(ctx, req)
+
+		code := status.Code(err)
+		level := slog.LevelInfo
+		switch code {
+		case codes.OK:
+		case codes.Internal, codes.Unknown, codes.Unavailable, codes.DataLoss:
+			level = slog.LevelError
+		default:
+			level = slog.LevelWarn
+		}
+
+		attrs := []slog.Attr{
+			slog.String("method", info.FullMethod),
+			slog.String("code", code.String()),
+			slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+		}
+		if p, ok := peer.FromContext(ctx); ok {
+			attrs = append(attrs, slog.String("client_ip", p.Addr.String()))
+		}
+		if err != nil {
+			attrs = append(attrs, slog.String("error", status.Convert(err).Message()))
+		}
+
+		log.LogAttrs(ctx, level, "rpc handled", attrs...)
+		return res, err
+	}
+}
@@ -0,0 +1,72 @@
+package rateLimitInterceptor
+
+import (
+	"context"
+	rateLimitMiddleware "github.com/gmaschi/go-recipes-book/internal/controllers/middlewares/ratelimit"
+	authInterceptor "github.com/gmaschi/go-recipes-book/internal/rpc/interceptors/auth"
+	"github.com/gmaschi/go-recipes-book/internal/services/ratelimit"
+	"github.com/gmaschi/go-recipes-book/pkg/logger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// RateLimitInterceptor limits the calls each client makes to the methods of routes, which
+// maps full method names to the key of the REST route they mirror, with the limits of these
+// routes. Clients are identified as by the REST API, so they share their buckets across
+// both: by username once authenticated, which requires it to come after AuthInterceptor,
+// and by IP address otherwise. Calls are let through when the backend fails.
+func RateLimitInterceptor(backend ratelimit.Backend, limits map[string]ratelimit.Limit, routes map[string]string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		route := routes[info.FullMethod]
+		limit, ok := limits[route]
+		if !ok {
+			return handler(ctx, req)
+		}
+
+		res, err := backend.Take(ctx, route+" "+client(ctx), limit)
+		if err != nil {
+			logger.FromContext(ctx).Error("cannot apply rate limit", "route", route, "error", err)
+			return handler(ctx, req)
+		}
+
+		md := metadata.Pairs(
+			header(rateLimitMiddleware.LimitHeaderKey), strconv.Itoa(res.Limit),
+			header(rateLimitMiddleware.RemainingHeaderKey), strconv.Itoa(res.Remaining),
+			header(rateLimitMiddleware.ResetHeaderKey), rateLimitMiddleware.Seconds(res.Reset),
+			header(rateLimitMiddleware.PolicyHeaderKey), limit.Policy(),
+		)
+		if !res.Allowed {
+			md.Set(header(rateLimitMiddleware.RetryAfterHeaderKey), rateLimitMiddleware.Seconds(res.RetryAfter))
+			_ = grpc.SetHeader(ctx, md)
+			return nil, rateLimitMiddleware.Exceeded(res)
+		}
+		_ = grpc.SetHeader(ctx, md)
+		return handler(ctx, req)
+	}
+}
+
+// client identifies the client of the call by username, or by the IP address of the peer
+// when it is not authenticated
+func client(ctx context.Context) string {
+	if payload, err := authInterceptor.Payload(ctx); err == nil {
+		return rateLimitMiddleware.Client(payload.Username, "")
+	}
+
+	var ip string
+	if p, ok := peer.FromContext(ctx); ok {
+		ip = p.Addr.String()
+		if host, _, err := net.SplitHostPort(ip); err == nil {
+			ip = host
+		}
+	}
+	return rateLimitMiddleware.Client("", ip)
+}
+
+// header is the metadata key of an HTTP header
+func header(key string) string {
+	return strings.ToLower(key)
+}
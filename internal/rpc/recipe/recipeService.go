@@ -0,0 +1,165 @@
+package recipeService
+
+import (
+	"context"
+	"github.com/gin-gonic/gin/binding"
+	errorMiddleware "github.com/gmaschi/go-recipes-book/internal/controllers/middlewares/errors"
+	recipeDomain "github.com/gmaschi/go-recipes-book/internal/domain/recipe"
+	recipeModel "github.com/gmaschi/go-recipes-book/internal/models/recipe"
+	authInterceptor "github.com/gmaschi/go-recipes-book/internal/rpc/interceptors/auth"
+	db "github.com/gmaschi/go-recipes-book/internal/services/datastore/postgresql/recipes/sqlc"
+	recipesbookv1 "github.com/gmaschi/go-recipes-book/pkg/pb/recipesbook/v1"
+	"github.com/gmaschi/go-recipes-book/pkg/tools/etag"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Service implements the RecipeService of the gRPC API with the same rules as the recipe
+// controller of the REST API. Every method requires an authenticated author.
+type Service struct {
+	recipesbookv1.UnimplementedRecipeServiceServer
+
+	store   db.Store
+	recipes *recipeDomain.Service
+}
+
+// New creates a pointer to a Service applying the rules of recipes, and listing the recipes
+// of store
+func New(store db.Store, recipes *recipeDomain.Service) *Service {
+	return &Service{
+		store:   store,
+		recipes: recipes,
+	}
+}
+
+// CreateRecipe creates a recipe of the authenticated author
+func (s *Service) CreateRecipe(ctx context.Context, in *recipesbookv1.CreateRecipeRequest) (*recipesbookv1.Recipe, error) {
+	payload, err := authInterceptor.Payload(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	recipe, err := s.recipes.Create(ctx, payload.Username, recipeModel.CreateRequest{
+		Ingredients: in.GetIngredients(),
+		Steps:       in.GetSteps(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toRecipe(recipe), nil
+}
+
+// GetRecipe returns a recipe of the authenticated author by ID
+func (s *Service) GetRecipe(ctx context.Context, in *recipesbookv1.GetRecipeRequest) (*recipesbookv1.Recipe, error) {
+	if err := validateID(in.GetId()); err != nil {
+		return nil, err
+	}
+
+	payload, err := authInterceptor.Payload(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	recipe, err := s.recipes.Get(ctx, payload.Username, in.GetId())
+	if err != nil {
+		return nil, err
+	}
+	return toRecipe(recipe), nil
+}
+
+// ListRecipes returns a page of the recipes of the authenticated author
+func (s *Service) ListRecipes(ctx context.Context, in *recipesbookv1.ListRecipesRequest) (*recipesbookv1.ListRecipesResponse, error) {
+	req := recipeModel.ListRequest{PageID: in.GetPageId(), PageSize: in.GetPageSize()}
+	if err := binding.Validator.ValidateStruct(&req); err != nil {
+		return nil, errorMiddleware.TranslateBind(err)
+	}
+
+	payload, err := authInterceptor.Payload(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	recipes, err := s.store.ListRecipes(ctx, db.ListRecipesParams{
+		Author: payload.Username,
+		Limit:  req.PageSize,
+		Offset: req.PageSize * (req.PageID - 1),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	res := &recipesbookv1.ListRecipesResponse{Recipes: make([]*recipesbookv1.Recipe, 0, len(recipes))}
+	for _, recipe := range recipes {
+		res.Recipes = append(res.Recipes, toRecipe(recipe))
+	}
+	return res, nil
+}
+
+// UpdateRecipe replaces the non-empty ingredients and/or steps of a recipe of the
+// authenticated author, if it still has the version the update is made against and, when
+// the if-match metadata is sent, the ETag it holds
+func (s *Service) UpdateRecipe(ctx context.Context, in *recipesbookv1.UpdateRecipeRequest) (*recipesbookv1.Recipe, error) {
+	req := recipeModel.UpdateRequest{ID: in.GetId(), Version: in.GetVersion(), Ingredients: in.GetIngredients(), Steps: in.GetSteps()}
+	if err := binding.Validator.ValidateStruct(&req); err != nil {
+		return nil, errorMiddleware.TranslateBind(err)
+	}
+	if err := validateID(req.ID); err != nil {
+		return nil, err
+	}
+
+	payload, err := authInterceptor.Payload(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var precondition recipeDomain.Precondition
+	md, _ := metadata.FromIncomingContext(ctx)
+	if values := md.Get(etag.IfMatchHeader); len(values) > 0 {
+		precondition.IfMatch = values[0]
+	}
+
+	updatedRecipe, err := s.recipes.Update(ctx, payload.Username, req, precondition)
+	if err != nil {
+		return nil, err
+	}
+	return toRecipe(updatedRecipe), nil
+}
+
+// DeleteRecipe deletes a recipe of the authenticated author
+func (s *Service) DeleteRecipe(ctx context.Context, in *recipesbookv1.DeleteRecipeRequest) (*emptypb.Empty, error) {
+	if err := validateID(in.GetId()); err != nil {
+		return nil, err
+	}
+
+	payload, err := authInterceptor.Payload(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.recipes.Delete(ctx, payload.Username, in.GetId()); err != nil {
+		return nil, err
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// validateID validates the ID of a recipe as the REST routes do
+func validateID(id int64) error {
+	req := recipeModel.GetRequest{ID: id}
+	if err := binding.Validator.ValidateStruct(&req); err != nil {
+		return errorMiddleware.TranslateBind(err)
+	}
+	return nil
+}
+
+func toRecipe(recipe db.Recipe) *recipesbookv1.Recipe {
+	return &recipesbookv1.Recipe{
+		Id:          recipe.ID,
+		Author:      recipe.Author,
+		Ingredients: recipe.Ingredients,
+		Steps:       recipe.Steps,
+		CreatedAt:   timestamppb.New(recipe.CreatedAt),
+		UpdatedAt:   timestamppb.New(recipe.UpdatedAt),
+		Version:     recipe.Version,
+	}
+}
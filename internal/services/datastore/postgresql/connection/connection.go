@@ -0,0 +1,58 @@
+package connection
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/gmaschi/go-recipes-book/pkg/config/env"
+	"github.com/gmaschi/go-recipes-book/pkg/tools/backoff"
+	"log/slog"
+)
+
+// Open opens the database described by config, configures its connection pool and waits
+// until it answers a ping. Failed pings are retried with an exponential backoff for at most
+// the configured connect timeout, so that a database that is still booting is waited for.
+func Open(ctx context.Context, config env.DatabaseConfig) (*sql.DB, error) {
+	conn, err := sql.Open(config.Driver, config.Source)
+	if err != nil {
+		return nil, err
+	}
+	Configure(conn, config)
+
+	if config.ConnectTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, config.ConnectTimeout)
+		defer cancel()
+	}
+
+	policy := backoff.Policy{Initial: config.ConnectBackoff, Max: config.ConnectMaxBackoff}
+	attempt := 0
+	var pingErr error
+	err = backoff.Retry(ctx, policy, func(ctx context.Context) error {
+		attempt++
+		err := conn.PingContext(ctx)
+		if err != nil {
+			slog.WarnContext(ctx, "database not ready", "attempt", attempt, "error", err)
+			// the last attempt may only fail because the timeout expired during it,
+			// which says less than the error of the attempts before it
+			if ctx.Err() == nil || pingErr == nil {
+				pingErr = err
+			}
+		}
+		return err
+	})
+	if err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("database unreachable after %d attempts: %w", attempt, pingErr)
+	}
+
+	return conn, nil
+}
+
+// Configure applies the pool settings of config to conn
+func Configure(conn *sql.DB, config env.DatabaseConfig) {
+	conn.SetMaxOpenConns(config.MaxOpenConns)
+	conn.SetMaxIdleConns(config.MaxIdleConns)
+	conn.SetConnMaxLifetime(config.ConnMaxLifetime)
+	conn.SetConnMaxIdleTime(config.ConnMaxIdleTime)
+}
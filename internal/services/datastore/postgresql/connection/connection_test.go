@@ -0,0 +1,119 @@
+package connection
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"github.com/gmaschi/go-recipes-book/pkg/config/env"
+	"github.com/stretchr/testify/require"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var errNotReady = errors.New("the database system is starting up")
+
+// flakyDriverName is the name of the flakyDrivers registered once for the whole package,
+// since database/sql panics when a name is registered twice, as with -count
+const flakyDriverName = "flaky"
+
+func init() {
+	sql.Register(flakyDriverName, flakyDrivers{})
+}
+
+// flakyDrivers dispatches the connections to the flakyDriver registered for their source
+type flakyDrivers struct{}
+
+var (
+	flakyDriversMu       sync.Mutex
+	flakyDriversBySource = make(map[string]*flakyDriver)
+)
+
+func (flakyDrivers) Open(source string) (driver.Conn, error) {
+	flakyDriversMu.Lock()
+	d, ok := flakyDriversBySource[source]
+	flakyDriversMu.Unlock()
+	if !ok {
+		return nil, errors.New("unknown source " + source)
+	}
+	return d.Open(source)
+}
+
+// useFlakyDriver makes d answer the connections to source for the duration of the test
+func useFlakyDriver(t *testing.T, source string, d *flakyDriver) {
+	flakyDriversMu.Lock()
+	flakyDriversBySource[source] = d
+	flakyDriversMu.Unlock()
+
+	t.Cleanup(func() {
+		flakyDriversMu.Lock()
+		delete(flakyDriversBySource, source)
+		flakyDriversMu.Unlock()
+	})
+}
+
+// flakyDriver is a driver whose pings fail until failures pings were attempted
+type flakyDriver struct {
+	failures int32
+	pings    int32
+}
+
+type flakyConn struct {
+	driver *flakyDriver
+}
+
+func (d *flakyDriver) Open(string) (driver.Conn, error) {
+	return flakyConn{driver: d}, nil
+}
+
+func (c flakyConn) Ping(context.Context) error {
+	if atomic.AddInt32(&c.driver.pings, 1) <= c.driver.failures {
+		return errNotReady
+	}
+	return nil
+}
+
+func (c flakyConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not implemented") }
+func (c flakyConn) Close() error                        { return nil }
+func (c flakyConn) Begin() (driver.Tx, error)           { return nil, errors.New("not implemented") }
+
+func testConfig(source string) env.DatabaseConfig {
+	return env.DatabaseConfig{
+		Driver:            flakyDriverName,
+		Source:            source,
+		MaxOpenConns:      4,
+		MaxIdleConns:      2,
+		ConnMaxLifetime:   time.Minute,
+		ConnectTimeout:    time.Second,
+		ConnectBackoff:    time.Millisecond,
+		ConnectMaxBackoff: 5 * time.Millisecond,
+	}
+}
+
+func TestOpen(t *testing.T) {
+	t.Run("Retries until the database answers", func(t *testing.T) {
+		d := &flakyDriver{failures: 3}
+		useFlakyDriver(t, "flaky-ok", d)
+
+		conn, err := Open(context.Background(), testConfig("flaky-ok"))
+		require.NoError(t, err)
+		defer conn.Close()
+
+		require.Equal(t, int32(4), atomic.LoadInt32(&d.pings))
+		require.Equal(t, 4, conn.Stats().MaxOpenConnections)
+	})
+
+	t.Run("Gives up after the connect timeout", func(t *testing.T) {
+		d := &flakyDriver{failures: 1 << 30}
+		useFlakyDriver(t, "flaky-down", d)
+
+		config := testConfig("flaky-down")
+		config.ConnectTimeout = 30 * time.Millisecond
+
+		_, err := Open(context.Background(), config)
+		require.ErrorIs(t, err, errNotReady)
+		require.Greater(t, atomic.LoadInt32(&d.pings), int32(1))
+	})
+}
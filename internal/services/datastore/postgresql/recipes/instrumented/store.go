@@ -0,0 +1,318 @@
+package instrumentedStore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	db "github.com/gmaschi/go-recipes-book/internal/services/datastore/postgresql/recipes/sqlc"
+	"github.com/gmaschi/go-recipes-book/internal/services/metrics"
+	"github.com/gmaschi/go-recipes-book/internal/services/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+	"time"
+)
+
+const (
+	// QueryKey is the span attribute holding the sqlc query name
+	QueryKey = attribute.Key("db.sqlc.query")
+
+	// RowsKey is the span attribute holding the number of rows returned by the query
+	RowsKey = attribute.Key("db.rows")
+)
+
+// unknownRows is reported by the :exec queries, whose row count sqlc does not return
+const unknownRows = -1
+
+// Store decorates a db.Store, recording the duration of every sqlc query under its name
+// and wrapping it in a client span
+type Store struct {
+	next    db.Store
+	metrics *metrics.Metrics
+	tracer  trace.Tracer
+}
+
+// call is a query in progress
+type call struct {
+	store *Store
+	query string
+	start time.Time
+	span  trace.Span
+}
+
+var _ db.Store = (*Store)(nil)
+
+// New creates a pointer to a Store recording the queries of next in m and tracing them
+// with provider
+func New(next db.Store, m *metrics.Metrics, provider trace.TracerProvider) *Store {
+	return &Store{
+		next:    next,
+		metrics: m,
+		tracer:  provider.Tracer(tracing.InstrumentationName),
+	}
+}
+
+func (s *Store) begin(ctx context.Context, query string) (context.Context, *call) {
+	ctx, span := s.tracer.Start(ctx, query,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.DBSystemPostgreSQL, QueryKey.String(query)),
+	)
+	return ctx, &call{
+		store: s,
+		query: query,
+		start: time.Now(),
+		span:  span,
+	}
+}
+
+// end records the outcome of the query. A missing row is not an error of the query.
+func (c *call) end(rows int, err error) {
+	c.store.metrics.ObserveQuery(c.query, time.Since(c.start), err)
+
+	if rows != unknownRows {
+		c.span.SetAttributes(RowsKey.Int(rows))
+	}
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		c.span.RecordError(err)
+		c.span.SetStatus(codes.Error, err.Error())
+	}
+	c.span.End()
+}
+
+// oneRow is the row count of a :one query
+func oneRow(err error) int {
+	if err != nil {
+		return 0
+	}
+	return 1
+}
+
+func (s *Store) CreateAuthor(ctx context.Context, arg db.CreateAuthorParams) (db.Author, error) {
+	ctx, c := s.begin(ctx, "CreateAuthor")
+	res, err := s.next.CreateAuthor(ctx, arg)
+	c.end(oneRow(err), err)
+	return res, err
+}
+
+func (s *Store) CreateRecipe(ctx context.Context, arg db.CreateRecipeParams) (db.Recipe, error) {
+	ctx, c := s.begin(ctx, "CreateRecipe")
+	res, err := s.next.CreateRecipe(ctx, arg)
+	c.end(oneRow(err), err)
+	return res, err
+}
+
+func (s *Store) DeleteAuthor(ctx context.Context, username string) error {
+	ctx, c := s.begin(ctx, "DeleteAuthor")
+	err := s.next.DeleteAuthor(ctx, username)
+	c.end(unknownRows, err)
+	return err
+}
+
+func (s *Store) DeleteRecipe(ctx context.Context, id int64) error {
+	ctx, c := s.begin(ctx, "DeleteRecipe")
+	err := s.next.DeleteRecipe(ctx, id)
+	c.end(unknownRows, err)
+	return err
+}
+
+func (s *Store) GetAuthor(ctx context.Context, username string) (db.Author, error) {
+	ctx, c := s.begin(ctx, "GetAuthor")
+	res, err := s.next.GetAuthor(ctx, username)
+	c.end(oneRow(err), err)
+	return res, err
+}
+
+func (s *Store) GetRecipe(ctx context.Context, id int64) (db.Recipe, error) {
+	ctx, c := s.begin(ctx, "GetRecipe")
+	res, err := s.next.GetRecipe(ctx, id)
+	c.end(oneRow(err), err)
+	return res, err
+}
+
+func (s *Store) ListAuthors(ctx context.Context, arg db.ListAuthorsParams) ([]db.Author, error) {
+	ctx, c := s.begin(ctx, "ListAuthors")
+	res, err := s.next.ListAuthors(ctx, arg)
+	c.end(len(res), err)
+	return res, err
+}
+
+func (s *Store) ListAuthorsAfter(ctx context.Context, arg db.ListAuthorsAfterParams) ([]db.Author, error) {
+	ctx, c := s.begin(ctx, "ListAuthorsAfter")
+	res, err := s.next.ListAuthorsAfter(ctx, arg)
+	c.end(len(res), err)
+	return res, err
+}
+
+func (s *Store) ListAuthorsByUsernames(ctx context.Context, usernames []string) ([]db.Author, error) {
+	ctx, c := s.begin(ctx, "ListAuthorsByUsernames")
+	res, err := s.next.ListAuthorsByUsernames(ctx, usernames)
+	c.end(len(res), err)
+	return res, err
+}
+
+func (s *Store) ListRecipes(ctx context.Context, arg db.ListRecipesParams) ([]db.Recipe, error) {
+	ctx, c := s.begin(ctx, "ListRecipes")
+	res, err := s.next.ListRecipes(ctx, arg)
+	c.end(len(res), err)
+	return res, err
+}
+
+func (s *Store) ListRecipesAfter(ctx context.Context, arg db.ListRecipesAfterParams) ([]db.Recipe, error) {
+	ctx, c := s.begin(ctx, "ListRecipesAfter")
+	res, err := s.next.ListRecipesAfter(ctx, arg)
+	c.end(len(res), err)
+	return res, err
+}
+
+func (s *Store) UpdateAuthor(ctx context.Context, arg db.UpdateAuthorParams) (db.Author, error) {
+	ctx, c := s.begin(ctx, "UpdateAuthor")
+	res, err := s.next.UpdateAuthor(ctx, arg)
+	c.end(oneRow(err), err)
+	return res, err
+}
+
+func (s *Store) UpdateRecipe(ctx context.Context, arg db.UpdateRecipeParams) (db.Recipe, error) {
+	ctx, c := s.begin(ctx, "UpdateRecipe")
+	res, err := s.next.UpdateRecipe(ctx, arg)
+	c.end(oneRow(err), err)
+	return res, err
+}
+
+func (s *Store) DisableAuthor(ctx context.Context, username string) (db.Author, error) {
+	ctx, c := s.begin(ctx, "DisableAuthor")
+	res, err := s.next.DisableAuthor(ctx, username)
+	c.end(oneRow(err), err)
+	return res, err
+}
+
+func (s *Store) EnableAuthor(ctx context.Context, username string) (db.Author, error) {
+	ctx, c := s.begin(ctx, "EnableAuthor")
+	res, err := s.next.EnableAuthor(ctx, username)
+	c.end(oneRow(err), err)
+	return res, err
+}
+
+func (s *Store) GetAuthorDisabledAt(ctx context.Context, username string) (sql.NullTime, error) {
+	ctx, c := s.begin(ctx, "GetAuthorDisabledAt")
+	res, err := s.next.GetAuthorDisabledAt(ctx, username)
+	c.end(oneRow(err), err)
+	return res, err
+}
+
+func (s *Store) UpdateAuthorRole(ctx context.Context, arg db.UpdateAuthorRoleParams) (db.Author, error) {
+	ctx, c := s.begin(ctx, "UpdateAuthorRole")
+	res, err := s.next.UpdateAuthorRole(ctx, arg)
+	c.end(oneRow(err), err)
+	return res, err
+}
+
+func (s *Store) ReassignRecipes(ctx context.Context, arg db.ReassignRecipesParams) (int64, error) {
+	ctx, c := s.begin(ctx, "ReassignRecipes")
+	res, err := s.next.ReassignRecipes(ctx, arg)
+	c.end(int(res), err)
+	return res, err
+}
+
+func (s *Store) PurgeDeletedRecipes(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	ctx, c := s.begin(ctx, "PurgeDeletedRecipes")
+	res, err := s.next.PurgeDeletedRecipes(ctx, deletedBefore)
+	c.end(int(res), err)
+	return res, err
+}
+
+func (s *Store) CreateIdempotencyKey(ctx context.Context, arg db.CreateIdempotencyKeyParams) (db.IdempotencyKey, error) {
+	ctx, c := s.begin(ctx, "CreateIdempotencyKey")
+	res, err := s.next.CreateIdempotencyKey(ctx, arg)
+	c.end(oneRow(err), err)
+	return res, err
+}
+
+func (s *Store) GetIdempotencyKey(ctx context.Context, arg db.GetIdempotencyKeyParams) (db.IdempotencyKey, error) {
+	ctx, c := s.begin(ctx, "GetIdempotencyKey")
+	res, err := s.next.GetIdempotencyKey(ctx, arg)
+	c.end(oneRow(err), err)
+	return res, err
+}
+
+func (s *Store) UpdateIdempotencyKey(ctx context.Context, arg db.UpdateIdempotencyKeyParams) error {
+	ctx, c := s.begin(ctx, "UpdateIdempotencyKey")
+	err := s.next.UpdateIdempotencyKey(ctx, arg)
+	c.end(unknownRows, err)
+	return err
+}
+
+func (s *Store) DeleteIdempotencyKey(ctx context.Context, arg db.DeleteIdempotencyKeyParams) error {
+	ctx, c := s.begin(ctx, "DeleteIdempotencyKey")
+	err := s.next.DeleteIdempotencyKey(ctx, arg)
+	c.end(unknownRows, err)
+	return err
+}
+
+func (s *Store) PurgeIdempotencyKeys(ctx context.Context, createdBefore time.Time) (int64, error) {
+	ctx, c := s.begin(ctx, "PurgeIdempotencyKeys")
+	res, err := s.next.PurgeIdempotencyKeys(ctx, createdBefore)
+	c.end(int(res), err)
+	return res, err
+}
+
+func (s *Store) GetStats(ctx context.Context) (db.GetStatsRow, error) {
+	ctx, c := s.begin(ctx, "GetStats")
+	res, err := s.next.GetStats(ctx)
+	c.end(oneRow(err), err)
+	return res, err
+}
+
+func (s *Store) CountAuthors(ctx context.Context) (int64, error) {
+	ctx, c := s.begin(ctx, "CountAuthors")
+	res, err := s.next.CountAuthors(ctx)
+	c.end(oneRow(err), err)
+	return res, err
+}
+
+func (s *Store) CountRecipes(ctx context.Context, author string) (int64, error) {
+	ctx, c := s.begin(ctx, "CountRecipes")
+	res, err := s.next.CountRecipes(ctx, author)
+	c.end(oneRow(err), err)
+	return res, err
+}
+
+func (s *Store) CountSearchAuthors(ctx context.Context, arg db.SearchAuthorsParams) (int64, error) {
+	ctx, c := s.begin(ctx, "CountSearchAuthors")
+	res, err := s.next.CountSearchAuthors(ctx, arg)
+	c.end(oneRow(err), err)
+	return res, err
+}
+
+func (s *Store) CountSearchRecipes(ctx context.Context, arg db.SearchRecipesParams) (int64, error) {
+	ctx, c := s.begin(ctx, "CountSearchRecipes")
+	res, err := s.next.CountSearchRecipes(ctx, arg)
+	c.end(oneRow(err), err)
+	return res, err
+}
+
+func (s *Store) SearchAuthors(ctx context.Context, arg db.SearchAuthorsParams) ([]db.Author, error) {
+	ctx, c := s.begin(ctx, "SearchAuthors")
+	res, err := s.next.SearchAuthors(ctx, arg)
+	c.end(len(res), err)
+	return res, err
+}
+
+func (s *Store) SearchRecipes(ctx context.Context, arg db.SearchRecipesParams) ([]db.Recipe, error) {
+	ctx, c := s.begin(ctx, "SearchRecipes")
+	res, err := s.next.SearchRecipes(ctx, arg)
+	c.end(len(res), err)
+	return res, err
+}
+
+func (s *Store) Ping(ctx context.Context) error {
+	return s.next.Ping(ctx)
+}
+
+func (s *Store) MigrationVersion(ctx context.Context) (int64, bool, error) {
+	return s.next.MigrationVersion(ctx)
+}
+
+func (s *Store) Stats() sql.DBStats {
+	return s.next.Stats()
+}
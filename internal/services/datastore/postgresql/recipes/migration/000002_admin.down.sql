@@ -0,0 +1,6 @@
+DELETE FROM "recipes" WHERE "deleted_at" IS NOT NULL;
+ALTER TABLE "recipes" DROP COLUMN IF EXISTS "deleted_at";
+
+ALTER TABLE "authors" DROP COLUMN IF EXISTS "disabled_at";
+ALTER TABLE "authors" DROP CONSTRAINT IF EXISTS "authors_role_check";
+ALTER TABLE "authors" DROP COLUMN IF EXISTS "role";
@@ -0,0 +1,7 @@
+ALTER TABLE "authors" ADD COLUMN "role" varchar NOT NULL DEFAULT 'author';
+ALTER TABLE "authors" ADD CONSTRAINT "authors_role_check" CHECK ("role" IN ('author', 'admin'));
+ALTER TABLE "authors" ADD COLUMN "disabled_at" timestamptz;
+
+ALTER TABLE "recipes" ADD COLUMN "deleted_at" timestamptz;
+
+CREATE INDEX ON "recipes" ("deleted_at") WHERE "deleted_at" IS NOT NULL;
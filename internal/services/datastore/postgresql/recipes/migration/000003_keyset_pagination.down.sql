@@ -0,0 +1 @@
+DROP INDEX IF EXISTS "recipes_author_created_at_id_idx";
@@ -0,0 +1 @@
+CREATE INDEX "recipes_author_created_at_id_idx" ON "recipes" ("author", "created_at", "id") WHERE "deleted_at" IS NULL;
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS "recipes_ingredients_idx";
@@ -0,0 +1 @@
+CREATE INDEX "recipes_ingredients_idx" ON "recipes" USING GIN ("ingredients");
@@ -0,0 +1,3 @@
+ALTER TABLE "recipes" DROP COLUMN IF EXISTS "version";
+
+ALTER TABLE "authors" DROP COLUMN IF EXISTS "version";
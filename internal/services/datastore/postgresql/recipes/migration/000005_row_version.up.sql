@@ -0,0 +1,3 @@
+ALTER TABLE "authors" ADD COLUMN "version" integer NOT NULL DEFAULT 1;
+
+ALTER TABLE "recipes" ADD COLUMN "version" integer NOT NULL DEFAULT 1;
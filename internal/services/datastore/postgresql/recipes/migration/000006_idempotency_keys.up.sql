@@ -0,0 +1,12 @@
+CREATE TABLE "idempotency_keys" (
+                           "author" varchar NOT NULL REFERENCES "authors" ("username") ON DELETE CASCADE,
+                           "key" varchar NOT NULL,
+                           "request_hash" varchar NOT NULL,
+                           "response_status" integer NOT NULL DEFAULT 0,
+                           "response_headers" jsonb NOT NULL DEFAULT '{}',
+                           "response_body" bytea NOT NULL DEFAULT '',
+                           "created_at" timestamptz NOT NULL DEFAULT (now()),
+                           PRIMARY KEY ("author", "key")
+);
+
+CREATE INDEX ON "idempotency_keys" ("created_at");
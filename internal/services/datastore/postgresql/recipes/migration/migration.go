@@ -0,0 +1,177 @@
+// Package migration embeds the database migrations and applies them with golang-migrate,
+// using the same schema_migrations table as the migrate CLI.
+package migration
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"github.com/golang-migrate/migrate/v4"
+	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"io/fs"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// lockTimeout bounds the time spent waiting for another instance to finish migrating
+const lockTimeout = time.Minute
+
+//go:embed *.sql
+var files embed.FS
+
+var upFileRegex = regexp.MustCompile(`^(\d+)_.+\.up\.sql$`)
+
+var (
+	// ErrSchemaAhead is returned when the database was migrated by a newer binary
+	ErrSchemaAhead = errors.New("database schema is ahead of this binary")
+
+	// ErrSchemaBehind is returned when embedded migrations are not applied to the database
+	ErrSchemaBehind = errors.New("database schema is behind this binary")
+
+	// ErrSchemaDirty is returned when the last migration applied to the database failed,
+	// leaving its schema partly migrated until it is fixed by hand and forced
+	ErrSchemaDirty = errors.New("database schema is dirty")
+)
+
+type (
+	// Migrator applies the embedded migrations to a database.
+	//
+	// The postgres driver holds a session-level advisory lock while migrating, so that
+	// replicas starting at the same time apply the migrations one after the other.
+	Migrator struct {
+		m *migrate.Migrate
+	}
+
+	// Status describes the migration state of a database
+	Status struct {
+		Version uint
+		Dirty   bool
+		Latest  uint
+	}
+)
+
+// New creates a Migrator for the database at databaseURL. The Migrator opens its own
+// connection, which is released by Close.
+func New(databaseURL string) (*Migrator, error) {
+	source, err := iofs.New(files, ".")
+	if err != nil {
+		return nil, fmt.Errorf("cannot read embedded migrations: %w", err)
+	}
+
+	m, err := migrate.NewWithSourceInstance("iofs", source, databaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open migration database: %w", err)
+	}
+	m.LockTimeout = lockTimeout
+
+	return &Migrator{m: m}, nil
+}
+
+// Up applies all the pending migrations, or only the next steps ones when steps is positive
+func (mg *Migrator) Up(steps int) error {
+	var err error
+	if steps > 0 {
+		err = mg.m.Steps(steps)
+	} else {
+		err = mg.m.Up()
+	}
+	if errors.Is(err, migrate.ErrNoChange) {
+		return nil
+	}
+	return err
+}
+
+// Down rolls back the last steps migrations
+func (mg *Migrator) Down(steps int) error {
+	if steps <= 0 {
+		return fmt.Errorf("steps must be positive, got %d", steps)
+	}
+	return mg.m.Steps(-steps)
+}
+
+// Force sets the migration version without running any migration and clears the dirty flag
+func (mg *Migrator) Force(version int) error {
+	return mg.m.Force(version)
+}
+
+// Status returns the current and latest migration versions
+func (mg *Migrator) Status() (Status, error) {
+	latest, err := LatestVersion()
+	if err != nil {
+		return Status{}, err
+	}
+
+	version, dirty, err := mg.m.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		return Status{Latest: latest}, nil
+	}
+	if err != nil {
+		return Status{}, err
+	}
+
+	return Status{Version: version, Dirty: dirty, Latest: latest}, nil
+}
+
+// CheckCompatible returns ErrSchemaDirty when the last migration failed, and ErrSchemaAhead
+// when the database is at a version unknown to this binary, which would run queries against
+// a schema it was not written for. Pending migrations are not an error, since they can
+// still be applied.
+func (mg *Migrator) CheckCompatible() error {
+	status, err := mg.Status()
+	if err != nil {
+		return err
+	}
+	if err := status.Check(); !errors.Is(err, ErrSchemaBehind) {
+		return err
+	}
+	return nil
+}
+
+// Check returns ErrSchemaDirty when the last migration failed, and otherwise ErrSchemaBehind
+// or ErrSchemaAhead when the database is not at the latest embedded migration
+func (s Status) Check() error {
+	switch {
+	case s.Dirty:
+		return fmt.Errorf("%w: migration %d failed, fix it and force its version", ErrSchemaDirty, s.Version)
+	case s.Version < s.Latest:
+		return fmt.Errorf("%w: database is at version %d, latest embedded migration is %d", ErrSchemaBehind, s.Version, s.Latest)
+	case s.Version > s.Latest:
+		return fmt.Errorf("%w: database is at version %d, latest embedded migration is %d", ErrSchemaAhead, s.Version, s.Latest)
+	}
+	return nil
+}
+
+// Close releases the migration database connection
+func (mg *Migrator) Close() error {
+	sourceErr, dbErr := mg.m.Close()
+	if sourceErr != nil {
+		return sourceErr
+	}
+	return dbErr
+}
+
+// LatestVersion returns the version of the last embedded migration
+func LatestVersion() (uint, error) {
+	entries, err := fs.ReadDir(files, ".")
+	if err != nil {
+		return 0, err
+	}
+
+	var latest uint
+	for _, entry := range entries {
+		match := upFileRegex.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+		version, err := strconv.ParseUint(match[1], 10, 64)
+		if err != nil {
+			return 0, err
+		}
+		if uint(version) > latest {
+			latest = uint(version)
+		}
+	}
+	return latest, nil
+}
@@ -0,0 +1,52 @@
+package migration
+
+import (
+	"github.com/stretchr/testify/require"
+	"io/fs"
+	"testing"
+)
+
+func TestStatusCheck(t *testing.T) {
+	testCases := []struct {
+		name   string
+		status Status
+		err    error
+	}{
+		{name: "Latest", status: Status{Version: 3, Latest: 3}},
+		{name: "Behind", status: Status{Version: 2, Latest: 3}, err: ErrSchemaBehind},
+		{name: "Ahead", status: Status{Version: 4, Latest: 3}, err: ErrSchemaAhead},
+		{name: "Dirty", status: Status{Version: 3, Dirty: true, Latest: 3}, err: ErrSchemaDirty},
+		{name: "DirtyAhead", status: Status{Version: 4, Dirty: true, Latest: 3}, err: ErrSchemaDirty},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.status.Check()
+			if tc.err == nil {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, tc.err)
+		})
+	}
+}
+
+func TestLatestVersion(t *testing.T) {
+	latest, err := LatestVersion()
+	require.NoError(t, err)
+	require.NotZero(t, latest)
+
+	entries, err := fs.ReadDir(files, ".")
+	require.NoError(t, err)
+
+	var ups, downs int
+	for _, entry := range entries {
+		if upFileRegex.MatchString(entry.Name()) {
+			ups++
+		} else {
+			downs++
+		}
+	}
+	require.Equal(t, int(latest), ups)
+	require.Equal(t, ups, downs)
+}
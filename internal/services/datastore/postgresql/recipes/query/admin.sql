@@ -0,0 +1,34 @@
+-- name: DisableAuthor :one
+UPDATE authors SET (disabled_at, version) = (now(), version + 1)
+WHERE username = $1
+RETURNING *;
+
+-- name: EnableAuthor :one
+UPDATE authors SET (disabled_at, version) = (NULL, version + 1)
+WHERE username = $1
+RETURNING *;
+
+-- name: GetAuthorDisabledAt :one
+SELECT disabled_at FROM authors
+WHERE username = $1;
+
+-- name: UpdateAuthorRole :one
+UPDATE authors SET (role, updated_at, version) = ($2, $3, version + 1)
+WHERE username = $1
+RETURNING *;
+
+-- name: ReassignRecipes :execrows
+UPDATE recipes SET (author, updated_at, version) = (sqlc.arg(to_author), now(), version + 1)
+WHERE author = sqlc.arg(from_author) AND deleted_at IS NULL;
+
+-- name: PurgeDeletedRecipes :execrows
+DELETE FROM recipes
+WHERE deleted_at IS NOT NULL AND deleted_at < sqlc.arg(deleted_before);
+
+-- name: GetStats :one
+SELECT
+    (SELECT count(*) FROM authors) AS authors,
+    (SELECT count(*) FROM authors WHERE disabled_at IS NOT NULL) AS disabled_authors,
+    (SELECT count(*) FROM authors WHERE role = 'admin') AS admins,
+    (SELECT count(*) FROM recipes WHERE deleted_at IS NULL) AS recipes,
+    (SELECT count(*) FROM recipes WHERE deleted_at IS NOT NULL) AS deleted_recipes;
@@ -16,11 +16,29 @@ ORDER BY username
 LIMIT $1
 OFFSET $2;
 
+-- name: ListAuthorsAfter :many
+SELECT * FROM authors
+WHERE username > $1
+ORDER BY username
+LIMIT $2;
+
+-- name: CountAuthors :one
+SELECT count(*) FROM authors;
+
+-- name: ListAuthorsByUsernames :many
+SELECT * FROM authors
+WHERE username = ANY(@usernames::varchar[])
+ORDER BY username;
+
 -- name: UpdateAuthor :one
-UPDATE authors SET (email, hashed_password, updated_at) = ($2, $3, $4)
-WHERE username = $1
+UPDATE authors SET (email, hashed_password, updated_at, version) = ($2, $3, $4, version + 1)
+WHERE username = $1 AND version = $5
 RETURNING *;
 
 -- name: DeleteAuthor :exec
+WITH purged AS (
+    DELETE FROM recipes
+    WHERE author = $1 AND deleted_at IS NOT NULL
+)
 DELETE FROM authors
 WHERE username = $1;
\ No newline at end of file
@@ -0,0 +1,28 @@
+-- name: CreateIdempotencyKey :one
+INSERT INTO idempotency_keys (
+    author, key, request_hash
+) VALUES (
+             @author, @key, @request_hash
+         )
+ON CONFLICT (author, key) DO UPDATE
+    SET (request_hash, response_status, response_headers, response_body, created_at) =
+            (EXCLUDED.request_hash, 0, '{}', '', now())
+    WHERE idempotency_keys.created_at < @expired_before
+       OR (idempotency_keys.response_status = 0 AND idempotency_keys.created_at < @lease_expired_before)
+RETURNING *;
+
+-- name: GetIdempotencyKey :one
+SELECT * FROM idempotency_keys
+WHERE author = $1 AND key = $2 LIMIT 1;
+
+-- name: UpdateIdempotencyKey :exec
+UPDATE idempotency_keys SET (response_status, response_headers, response_body) = ($3, $4, $5)
+WHERE author = $1 AND key = $2;
+
+-- name: DeleteIdempotencyKey :exec
+DELETE FROM idempotency_keys
+WHERE author = $1 AND key = $2;
+
+-- name: PurgeIdempotencyKeys :execrows
+DELETE FROM idempotency_keys
+WHERE created_at < sqlc.arg(created_before);
@@ -8,20 +8,31 @@ RETURNING *;
 
 -- name: GetRecipe :one
 SELECT * FROM recipes
-WHERE id = $1 LIMIT 1;
+WHERE id = $1 AND deleted_at IS NULL LIMIT 1;
 
 -- name: ListRecipes :many
 SELECT * FROM recipes
-WHERE author = $1
+WHERE author = $1 AND deleted_at IS NULL
 ORDER BY id
 LIMIT $2
     OFFSET $3;
 
+-- name: ListRecipesAfter :many
+SELECT * FROM recipes
+WHERE author = @author AND deleted_at IS NULL
+  AND (created_at, id) > (@created_at::timestamptz, @id::bigint)
+ORDER BY created_at, id
+LIMIT sqlc.arg('limit');
+
+-- name: CountRecipes :one
+SELECT count(*) FROM recipes
+WHERE author = $1 AND deleted_at IS NULL;
+
 -- name: UpdateRecipe :one
-UPDATE recipes SET (ingredients, steps, updated_at) = ($2, $3, $4)
-WHERE id = $1
+UPDATE recipes SET (ingredients, steps, updated_at, version) = ($2, $3, $4, version + 1)
+WHERE id = $1 AND version = $5 AND deleted_at IS NULL
 RETURNING *;
 
 -- name: DeleteRecipe :exec
-DELETE FROM recipes
-WHERE id = $1;
\ No newline at end of file
+UPDATE recipes SET deleted_at = now()
+WHERE id = $1 AND deleted_at IS NULL;
\ No newline at end of file
@@ -0,0 +1,224 @@
+// Package search lists the authors and recipes with the optional filters and sort order of
+// the list routes. The statements are built at run time, which sqlc cannot generate.
+package search
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	db "github.com/gmaschi/go-recipes-book/internal/services/datastore/postgresql/recipes/sqlc"
+	"github.com/lib/pq"
+	"strconv"
+	"strings"
+)
+
+// Queries runs the searches on a database connection or transaction
+type Queries struct {
+	db db.DBTX
+}
+
+var _ db.Searcher = (*Queries)(nil)
+
+// New creates a pointer to Queries running the searches on conn
+func New(conn db.DBTX) *Queries {
+	return &Queries{db: conn}
+}
+
+// WithTx returns Queries running the searches in tx
+func (q *Queries) WithTx(tx *sql.Tx) *Queries {
+	return &Queries{db: tx}
+}
+
+// search builds the WHERE and ORDER BY clauses of a search, numbering its arguments
+type search struct {
+	conditions []string
+	args       []interface{}
+}
+
+// where adds the condition cond, in which ? stands for arg
+func (s *search) where(cond string, arg interface{}) {
+	s.args = append(s.args, arg)
+	s.conditions = append(s.conditions, strings.Replace(cond, "?", "$"+strconv.Itoa(len(s.args)), 1))
+}
+
+func (s *search) whereTime(cond string, arg sql.NullTime) {
+	if arg.Valid {
+		s.where(cond, arg.Time)
+	}
+}
+
+// count completes the count statement with the conditions
+func (s *search) count(countFrom string) string {
+	var b strings.Builder
+	s.writeWhere(&b, countFrom)
+	return b.String()
+}
+
+// query completes the select statement with the conditions, ordered by sort and then by
+// the unique column key so that pages are stable
+func (s *search) query(selectFrom string, sort db.Sort, fields []string, key string, limit, offset int32) (string, error) {
+	column, err := sortColumn(sort, fields)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	s.writeWhere(&b, selectFrom)
+	direction := "ASC"
+	if sort.Desc {
+		direction = "DESC"
+	}
+	fmt.Fprintf(&b, "\nORDER BY %s %s", column, direction)
+	if column != key {
+		fmt.Fprintf(&b, ", %s %s", key, direction)
+	}
+
+	s.args = append(s.args, limit, offset)
+	fmt.Fprintf(&b, "\nLIMIT $%d\nOFFSET $%d", len(s.args)-1, len(s.args))
+	return b.String(), nil
+}
+
+func (s *search) writeWhere(b *strings.Builder, statement string) {
+	b.WriteString(statement)
+	if len(s.conditions) > 0 {
+		b.WriteString("\nWHERE ")
+		b.WriteString(strings.Join(s.conditions, " AND "))
+	}
+}
+
+// sortColumn returns the column of the sort field, which must be one of fields
+func sortColumn(sort db.Sort, fields []string) (string, error) {
+	for _, field := range fields {
+		if sort.Field == field {
+			return field, nil
+		}
+	}
+	return "", fmt.Errorf("search: cannot sort by %q", sort.Field)
+}
+
+const (
+	countSearchAuthors = `SELECT count(*) FROM authors`
+	searchAuthors      = `SELECT username, hashed_password, email, created_at, updated_at, role, disabled_at, version FROM authors`
+)
+
+func authorFilters(arg db.SearchAuthorsParams) *search {
+	var s search
+	s.whereTime("created_at > ?", arg.CreatedAfter)
+	s.whereTime("created_at < ?", arg.CreatedBefore)
+	return &s
+}
+
+// CountSearchAuthors counts the authors SearchAuthors lists over all pages
+func (q *Queries) CountSearchAuthors(ctx context.Context, arg db.SearchAuthorsParams) (int64, error) {
+	s := authorFilters(arg)
+	row := q.db.QueryRowContext(ctx, s.count(countSearchAuthors), s.args...)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+// SearchAuthors lists the authors matching the filters of arg that are set
+func (q *Queries) SearchAuthors(ctx context.Context, arg db.SearchAuthorsParams) ([]db.Author, error) {
+	s := authorFilters(arg)
+	query, err := s.query(searchAuthors, arg.Sort, db.AuthorSortFields, "username", arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := q.db.QueryContext(ctx, query, s.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []db.Author{}
+	for rows.Next() {
+		var i db.Author
+		if err := rows.Scan(
+			&i.Username,
+			&i.HashedPassword,
+			&i.Email,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Role,
+			&i.DisabledAt,
+			&i.Version,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const (
+	countSearchRecipes = `SELECT count(*) FROM recipes`
+	searchRecipes      = `SELECT id, author, ingredients, steps, created_at, updated_at, deleted_at, version FROM recipes`
+)
+
+func recipeFilters(arg db.SearchRecipesParams) *search {
+	var s search
+	s.where("author = ?", arg.Author)
+	s.conditions = append(s.conditions, "deleted_at IS NULL")
+	s.whereTime("created_at > ?", arg.CreatedAfter)
+	s.whereTime("created_at < ?", arg.CreatedBefore)
+	s.whereTime("updated_at >= ?", arg.UpdatedSince)
+	if arg.Ingredient.Valid {
+		s.where("ingredients @> ARRAY[?]::varchar[]", arg.Ingredient.String)
+	}
+	return &s
+}
+
+// CountSearchRecipes counts the recipes SearchRecipes lists over all pages
+func (q *Queries) CountSearchRecipes(ctx context.Context, arg db.SearchRecipesParams) (int64, error) {
+	s := recipeFilters(arg)
+	row := q.db.QueryRowContext(ctx, s.count(countSearchRecipes), s.args...)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+// SearchRecipes lists the recipes of arg.Author that are not deleted and match the filters
+// of arg that are set
+func (q *Queries) SearchRecipes(ctx context.Context, arg db.SearchRecipesParams) ([]db.Recipe, error) {
+	s := recipeFilters(arg)
+	query, err := s.query(searchRecipes, arg.Sort, db.RecipeSortFields, "id", arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := q.db.QueryContext(ctx, query, s.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []db.Recipe{}
+	for rows.Next() {
+		var i db.Recipe
+		if err := rows.Scan(
+			&i.ID,
+			&i.Author,
+			pq.Array(&i.Ingredients),
+			pq.Array(&i.Steps),
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+			&i.Version,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,123 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestCreateAuthorRole(t *testing.T) {
+	author := createRandomAuthor(t)
+	require.Equal(t, "author", author.Role)
+	require.False(t, author.DisabledAt.Valid)
+}
+
+func TestDisableAuthor(t *testing.T) {
+	author := createRandomAuthor(t)
+
+	disabledAuthor, err := testQueries.DisableAuthor(context.Background(), author.Username)
+	require.NoError(t, err)
+	require.True(t, disabledAuthor.DisabledAt.Valid)
+	require.WithinDuration(t, time.Now(), disabledAuthor.DisabledAt.Time, time.Second)
+
+	disabledAt, err := testQueries.GetAuthorDisabledAt(context.Background(), author.Username)
+	require.NoError(t, err)
+	require.Equal(t, disabledAuthor.DisabledAt.Valid, disabledAt.Valid)
+
+	enabledAuthor, err := testQueries.EnableAuthor(context.Background(), author.Username)
+	require.NoError(t, err)
+	require.False(t, enabledAuthor.DisabledAt.Valid)
+
+	disabledAt, err = testQueries.GetAuthorDisabledAt(context.Background(), author.Username)
+	require.NoError(t, err)
+	require.False(t, disabledAt.Valid)
+
+	_, err = testQueries.GetAuthorDisabledAt(context.Background(), "unknown")
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+
+	_, err = testQueries.DisableAuthor(context.Background(), "unknown")
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+}
+
+func TestUpdateAuthorRole(t *testing.T) {
+	author := createRandomAuthor(t)
+
+	arg := UpdateAuthorRoleParams{
+		Username:  author.Username,
+		Role:      "admin",
+		UpdatedAt: time.Now(),
+	}
+	updatedAuthor, err := testQueries.UpdateAuthorRole(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, "admin", updatedAuthor.Role)
+	require.WithinDuration(t, arg.UpdatedAt, updatedAuthor.UpdatedAt, time.Second)
+
+	arg.Role = "owner"
+	_, err = testQueries.UpdateAuthorRole(context.Background(), arg)
+	require.Error(t, err)
+}
+
+func TestReassignRecipes(t *testing.T) {
+	recipe := createRandomRecipe(t)
+	deleted, err := testQueries.CreateRecipe(context.Background(), CreateRecipeParams{
+		Author:      recipe.Author,
+		Ingredients: recipe.Ingredients,
+		Steps:       recipe.Steps,
+	})
+	require.NoError(t, err)
+	require.NoError(t, testQueries.DeleteRecipe(context.Background(), deleted.ID))
+	author := createRandomAuthor(t)
+
+	n, err := testQueries.ReassignRecipes(context.Background(), ReassignRecipesParams{
+		FromAuthor: recipe.Author,
+		ToAuthor:   author.Username,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), n)
+
+	gotRecipe, err := testQueries.GetRecipe(context.Background(), recipe.ID)
+	require.NoError(t, err)
+	require.Equal(t, author.Username, gotRecipe.Author)
+	require.Equal(t, recipe.Version+1, gotRecipe.Version)
+	require.WithinDuration(t, time.Now(), gotRecipe.UpdatedAt, time.Second)
+}
+
+func TestPurgeDeletedRecipes(t *testing.T) {
+	recipe := createRandomRecipe(t)
+	require.NoError(t, testQueries.DeleteRecipe(context.Background(), recipe.ID))
+
+	stats, err := testQueries.GetStats(context.Background())
+	require.NoError(t, err)
+	require.Positive(t, stats.DeletedRecipes)
+
+	n, err := testQueries.PurgeDeletedRecipes(context.Background(), time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	require.Positive(t, n)
+
+	stats, err = testQueries.GetStats(context.Background())
+	require.NoError(t, err)
+	require.Zero(t, stats.DeletedRecipes)
+}
+
+func TestDeleteAuthorWithDeletedRecipes(t *testing.T) {
+	recipe := createRandomRecipe(t)
+
+	err := testQueries.DeleteAuthor(context.Background(), recipe.Author)
+	require.Error(t, err)
+
+	require.NoError(t, testQueries.DeleteRecipe(context.Background(), recipe.ID))
+	err = testQueries.DeleteAuthor(context.Background(), recipe.Author)
+	require.NoError(t, err)
+}
+
+func TestGetStats(t *testing.T) {
+	createRandomRecipe(t)
+
+	stats, err := testQueries.GetStats(context.Background())
+	require.NoError(t, err)
+	require.Positive(t, stats.Authors)
+	require.Positive(t, stats.Recipes)
+	require.LessOrEqual(t, stats.DisabledAuthors, stats.Authors)
+}
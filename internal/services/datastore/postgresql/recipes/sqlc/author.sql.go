@@ -6,15 +6,28 @@ package db
 import (
 	"context"
 	"time"
+
+	"github.com/lib/pq"
 )
 
+const countAuthors = `-- name: CountAuthors :one
+SELECT count(*) FROM authors
+`
+
+func (q *Queries) CountAuthors(ctx context.Context) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countAuthors)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createAuthor = `-- name: CreateAuthor :one
 INSERT INTO authors (
     username, hashed_password, email
 ) VALUES (
              $1, $2, $3
          )
-RETURNING username, hashed_password, email, created_at, updated_at
+RETURNING username, hashed_password, email, created_at, updated_at, role, disabled_at, version
 `
 
 type CreateAuthorParams struct {
@@ -32,11 +45,18 @@ func (q *Queries) CreateAuthor(ctx context.Context, arg CreateAuthorParams) (Aut
 		&i.Email,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Role,
+		&i.DisabledAt,
+		&i.Version,
 	)
 	return i, err
 }
 
 const deleteAuthor = `-- name: DeleteAuthor :exec
+WITH purged AS (
+    DELETE FROM recipes
+    WHERE author = $1 AND deleted_at IS NOT NULL
+)
 DELETE FROM authors
 WHERE username = $1
 `
@@ -47,7 +67,7 @@ func (q *Queries) DeleteAuthor(ctx context.Context, username string) error {
 }
 
 const getAuthor = `-- name: GetAuthor :one
-SELECT username, hashed_password, email, created_at, updated_at FROM authors
+SELECT username, hashed_password, email, created_at, updated_at, role, disabled_at, version FROM authors
 WHERE username = $1 LIMIT 1
 `
 
@@ -60,12 +80,15 @@ func (q *Queries) GetAuthor(ctx context.Context, username string) (Author, error
 		&i.Email,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Role,
+		&i.DisabledAt,
+		&i.Version,
 	)
 	return i, err
 }
 
 const listAuthors = `-- name: ListAuthors :many
-SELECT username, hashed_password, email, created_at, updated_at FROM authors
+SELECT username, hashed_password, email, created_at, updated_at, role, disabled_at, version FROM authors
 ORDER BY username
 LIMIT $1
 OFFSET $2
@@ -91,6 +114,91 @@ func (q *Queries) ListAuthors(ctx context.Context, arg ListAuthorsParams) ([]Aut
 			&i.Email,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Role,
+			&i.DisabledAt,
+			&i.Version,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAuthorsAfter = `-- name: ListAuthorsAfter :many
+SELECT username, hashed_password, email, created_at, updated_at, role, disabled_at, version FROM authors
+WHERE username > $1
+ORDER BY username
+LIMIT $2
+`
+
+type ListAuthorsAfterParams struct {
+	Username string `json:"username"`
+	Limit    int32  `json:"limit"`
+}
+
+func (q *Queries) ListAuthorsAfter(ctx context.Context, arg ListAuthorsAfterParams) ([]Author, error) {
+	rows, err := q.db.QueryContext(ctx, listAuthorsAfter, arg.Username, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Author{}
+	for rows.Next() {
+		var i Author
+		if err := rows.Scan(
+			&i.Username,
+			&i.HashedPassword,
+			&i.Email,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Role,
+			&i.DisabledAt,
+			&i.Version,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAuthorsByUsernames = `-- name: ListAuthorsByUsernames :many
+SELECT username, hashed_password, email, created_at, updated_at, role, disabled_at, version FROM authors
+WHERE username = ANY($1::varchar[])
+ORDER BY username
+`
+
+func (q *Queries) ListAuthorsByUsernames(ctx context.Context, usernames []string) ([]Author, error) {
+	rows, err := q.db.QueryContext(ctx, listAuthorsByUsernames, pq.Array(usernames))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Author{}
+	for rows.Next() {
+		var i Author
+		if err := rows.Scan(
+			&i.Username,
+			&i.HashedPassword,
+			&i.Email,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Role,
+			&i.DisabledAt,
+			&i.Version,
 		); err != nil {
 			return nil, err
 		}
@@ -106,9 +214,9 @@ func (q *Queries) ListAuthors(ctx context.Context, arg ListAuthorsParams) ([]Aut
 }
 
 const updateAuthor = `-- name: UpdateAuthor :one
-UPDATE authors SET (email, hashed_password, updated_at) = ($2, $3, $4)
-WHERE username = $1
-RETURNING username, hashed_password, email, created_at, updated_at
+UPDATE authors SET (email, hashed_password, updated_at, version) = ($2, $3, $4, version + 1)
+WHERE username = $1 AND version = $5
+RETURNING username, hashed_password, email, created_at, updated_at, role, disabled_at, version
 `
 
 type UpdateAuthorParams struct {
@@ -116,6 +224,7 @@ type UpdateAuthorParams struct {
 	Email          string    `json:"email"`
 	HashedPassword string    `json:"hashed_password"`
 	UpdatedAt      time.Time `json:"updated_at"`
+	Version        int32     `json:"version"`
 }
 
 func (q *Queries) UpdateAuthor(ctx context.Context, arg UpdateAuthorParams) (Author, error) {
@@ -124,6 +233,7 @@ func (q *Queries) UpdateAuthor(ctx context.Context, arg UpdateAuthorParams) (Aut
 		arg.Email,
 		arg.HashedPassword,
 		arg.UpdatedAt,
+		arg.Version,
 	)
 	var i Author
 	err := row.Scan(
@@ -132,6 +242,9 @@ func (q *Queries) UpdateAuthor(ctx context.Context, arg UpdateAuthorParams) (Aut
 		&i.Email,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Role,
+		&i.DisabledAt,
+		&i.Version,
 	)
 	return i, err
 }
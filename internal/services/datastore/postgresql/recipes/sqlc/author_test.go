@@ -28,6 +28,7 @@ func createRandomAuthor(t *testing.T) Author {
 	require.Equal(t, arg.Username, author.Username)
 	require.Equal(t, arg.Email, author.Email)
 	require.Equal(t, arg.HashedPassword, author.HashedPassword)
+	require.EqualValues(t, 1, author.Version)
 	return author
 }
 
@@ -61,6 +62,7 @@ func TestUpdateAuthor(t *testing.T) {
 		Email:          random.Email(),
 		HashedPassword: hashedPassword,
 		UpdatedAt:      time.Now().UTC(),
+		Version:        author.Version,
 	}
 
 	updatedAuthor, err := testQueries.UpdateAuthor(context.Background(), updateArgs)
@@ -72,6 +74,12 @@ func TestUpdateAuthor(t *testing.T) {
 	require.Equal(t, updateArgs.HashedPassword, updatedAuthor.HashedPassword)
 	require.Equal(t, author.CreatedAt, updatedAuthor.CreatedAt)
 	require.WithinDuration(t, updateArgs.UpdatedAt, updatedAuthor.UpdatedAt, time.Second)
+	require.Equal(t, author.Version+1, updatedAuthor.Version)
+
+	updateArgs.Email = random.Email()
+	staleAuthor, err := testQueries.UpdateAuthor(context.Background(), updateArgs)
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+	require.Empty(t, staleAuthor)
 }
 
 func TestDeleteAuthor(t *testing.T) {
@@ -110,3 +118,42 @@ func TestListAuthors(t *testing.T) {
 		require.NotEmpty(t, author)
 	}
 }
+
+func TestListAuthorsByUsernames(t *testing.T) {
+	author1 := createRandomAuthor(t)
+	author2 := createRandomAuthor(t)
+
+	authors, err := testQueries.ListAuthorsByUsernames(context.Background(), []string{author1.Username, author2.Username, random.String(10)})
+	require.NoError(t, err)
+	require.Len(t, authors, 2)
+
+	usernames := []string{authors[0].Username, authors[1].Username}
+	require.ElementsMatch(t, []string{author1.Username, author2.Username}, usernames)
+	require.Less(t, usernames[0], usernames[1])
+}
+
+func TestListAuthorsAfter(t *testing.T) {
+	for i := 0; i < 5; i++ {
+		createRandomAuthor(t)
+	}
+
+	first, err := testQueries.ListAuthorsAfter(context.Background(), ListAuthorsAfterParams{Limit: 3})
+	require.NoError(t, err)
+	require.Len(t, first, 3)
+
+	next, err := testQueries.ListAuthorsAfter(context.Background(), ListAuthorsAfterParams{
+		Username: first[len(first)-1].Username,
+		Limit:    3,
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, next)
+	require.Less(t, first[len(first)-1].Username, next[0].Username)
+}
+
+func TestCountAuthors(t *testing.T) {
+	createRandomAuthor(t)
+
+	count, err := testQueries.CountAuthors(context.Background())
+	require.NoError(t, err)
+	require.NotZero(t, count)
+}
@@ -0,0 +1,107 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"github.com/gmaschi/go-recipes-book/pkg/tools/random"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func createRandomIdempotencyKey(t *testing.T) IdempotencyKey {
+	author := createRandomAuthor(t)
+
+	arg := CreateIdempotencyKeyParams{
+		Author:        author.Username,
+		Key:           random.String(16),
+		RequestHash:   random.String(64),
+		ExpiredBefore: time.Now().Add(-time.Hour),
+	}
+	key, err := testQueries.CreateIdempotencyKey(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Author, key.Author)
+	require.Equal(t, arg.Key, key.Key)
+	require.Equal(t, arg.RequestHash, key.RequestHash)
+	require.Zero(t, key.ResponseStatus)
+	require.WithinDuration(t, time.Now(), key.CreatedAt, time.Second)
+	return key
+}
+
+func TestCreateIdempotencyKey(t *testing.T) {
+	key := createRandomIdempotencyKey(t)
+
+	arg := CreateIdempotencyKeyParams{
+		Author:        key.Author,
+		Key:           key.Key,
+		RequestHash:   random.String(64),
+		ExpiredBefore: time.Now().Add(-time.Hour),
+	}
+	_, err := testQueries.CreateIdempotencyKey(context.Background(), arg)
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+
+	// the request holding the key did not store its response within the lease
+	arg.LeaseExpiredBefore = time.Now().Add(time.Minute)
+	renewedKey, err := testQueries.CreateIdempotencyKey(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.RequestHash, renewedKey.RequestHash)
+
+	// a stored response is kept until the key expires, whatever the lease
+	err = testQueries.UpdateIdempotencyKey(context.Background(), UpdateIdempotencyKeyParams{
+		Author:          key.Author,
+		Key:             key.Key,
+		ResponseStatus:  http.StatusCreated,
+		ResponseHeaders: json.RawMessage(`{}`),
+		ResponseBody:    []byte(`{}`),
+	})
+	require.NoError(t, err)
+	_, err = testQueries.CreateIdempotencyKey(context.Background(), arg)
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+
+	arg.ExpiredBefore = time.Now().Add(time.Minute)
+	renewedKey, err = testQueries.CreateIdempotencyKey(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.RequestHash, renewedKey.RequestHash)
+}
+
+func TestUpdateIdempotencyKey(t *testing.T) {
+	key := createRandomIdempotencyKey(t)
+
+	arg := UpdateIdempotencyKeyParams{
+		Author:          key.Author,
+		Key:             key.Key,
+		ResponseStatus:  http.StatusCreated,
+		ResponseHeaders: json.RawMessage(`{"Content-Type":"application/json"}`),
+		ResponseBody:    []byte(`{"id":1}`),
+	}
+	require.NoError(t, testQueries.UpdateIdempotencyKey(context.Background(), arg))
+
+	gotKey, err := testQueries.GetIdempotencyKey(context.Background(), GetIdempotencyKeyParams{Author: key.Author, Key: key.Key})
+	require.NoError(t, err)
+	require.Equal(t, arg.ResponseStatus, gotKey.ResponseStatus)
+	require.JSONEq(t, string(arg.ResponseHeaders), string(gotKey.ResponseHeaders))
+	require.Equal(t, arg.ResponseBody, gotKey.ResponseBody)
+}
+
+func TestDeleteIdempotencyKey(t *testing.T) {
+	key := createRandomIdempotencyKey(t)
+
+	err := testQueries.DeleteIdempotencyKey(context.Background(), DeleteIdempotencyKeyParams{Author: key.Author, Key: key.Key})
+	require.NoError(t, err)
+
+	_, err = testQueries.GetIdempotencyKey(context.Background(), GetIdempotencyKeyParams{Author: key.Author, Key: key.Key})
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+}
+
+func TestPurgeIdempotencyKeys(t *testing.T) {
+	key := createRandomIdempotencyKey(t)
+
+	n, err := testQueries.PurgeIdempotencyKeys(context.Background(), time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	require.Positive(t, n)
+
+	_, err = testQueries.GetIdempotencyKey(context.Background(), GetIdempotencyKeyParams{Author: key.Author, Key: key.Key})
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+}
@@ -3,22 +3,39 @@
 package db
 
 import (
+	"database/sql"
+	"encoding/json"
 	"time"
 )
 
 type Author struct {
-	Username       string    `json:"username"`
-	HashedPassword string    `json:"hashed_password"`
-	Email          string    `json:"email"`
-	CreatedAt      time.Time `json:"created_at"`
-	UpdatedAt      time.Time `json:"updated_at"`
+	Username       string       `json:"username"`
+	HashedPassword string       `json:"hashed_password"`
+	Email          string       `json:"email"`
+	CreatedAt      time.Time    `json:"created_at"`
+	UpdatedAt      time.Time    `json:"updated_at"`
+	Role           string       `json:"role"`
+	DisabledAt     sql.NullTime `json:"disabled_at"`
+	Version        int32        `json:"version"`
+}
+
+type IdempotencyKey struct {
+	Author          string          `json:"author"`
+	Key             string          `json:"key"`
+	RequestHash     string          `json:"request_hash"`
+	ResponseStatus  int32           `json:"response_status"`
+	ResponseHeaders json.RawMessage `json:"response_headers"`
+	ResponseBody    []byte          `json:"response_body"`
+	CreatedAt       time.Time       `json:"created_at"`
 }
 
 type Recipe struct {
-	ID          int64     `json:"id"`
-	Author      string    `json:"author"`
-	Ingredients []string  `json:"ingredients"`
-	Steps       []string  `json:"steps"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID          int64        `json:"id"`
+	Author      string       `json:"author"`
+	Ingredients []string     `json:"ingredients"`
+	Steps       []string     `json:"steps"`
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+	DeletedAt   sql.NullTime `json:"deleted_at"`
+	Version     int32        `json:"version"`
 }
@@ -4,18 +4,37 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"time"
 )
 
 type Querier interface {
+	CountAuthors(ctx context.Context) (int64, error)
+	CountRecipes(ctx context.Context, author string) (int64, error)
 	CreateAuthor(ctx context.Context, arg CreateAuthorParams) (Author, error)
+	CreateIdempotencyKey(ctx context.Context, arg CreateIdempotencyKeyParams) (IdempotencyKey, error)
 	CreateRecipe(ctx context.Context, arg CreateRecipeParams) (Recipe, error)
 	DeleteAuthor(ctx context.Context, username string) error
+	DeleteIdempotencyKey(ctx context.Context, arg DeleteIdempotencyKeyParams) error
 	DeleteRecipe(ctx context.Context, id int64) error
+	DisableAuthor(ctx context.Context, username string) (Author, error)
+	EnableAuthor(ctx context.Context, username string) (Author, error)
 	GetAuthor(ctx context.Context, username string) (Author, error)
+	GetAuthorDisabledAt(ctx context.Context, username string) (sql.NullTime, error)
+	GetIdempotencyKey(ctx context.Context, arg GetIdempotencyKeyParams) (IdempotencyKey, error)
 	GetRecipe(ctx context.Context, id int64) (Recipe, error)
+	GetStats(ctx context.Context) (GetStatsRow, error)
 	ListAuthors(ctx context.Context, arg ListAuthorsParams) ([]Author, error)
+	ListAuthorsAfter(ctx context.Context, arg ListAuthorsAfterParams) ([]Author, error)
+	ListAuthorsByUsernames(ctx context.Context, usernames []string) ([]Author, error)
 	ListRecipes(ctx context.Context, arg ListRecipesParams) ([]Recipe, error)
+	ListRecipesAfter(ctx context.Context, arg ListRecipesAfterParams) ([]Recipe, error)
+	PurgeDeletedRecipes(ctx context.Context, deletedBefore time.Time) (int64, error)
+	PurgeIdempotencyKeys(ctx context.Context, createdBefore time.Time) (int64, error)
+	ReassignRecipes(ctx context.Context, arg ReassignRecipesParams) (int64, error)
 	UpdateAuthor(ctx context.Context, arg UpdateAuthorParams) (Author, error)
+	UpdateAuthorRole(ctx context.Context, arg UpdateAuthorRoleParams) (Author, error)
+	UpdateIdempotencyKey(ctx context.Context, arg UpdateIdempotencyKeyParams) error
 	UpdateRecipe(ctx context.Context, arg UpdateRecipeParams) (Recipe, error)
 }
 
@@ -10,13 +10,25 @@ import (
 	"github.com/lib/pq"
 )
 
+const countRecipes = `-- name: CountRecipes :one
+SELECT count(*) FROM recipes
+WHERE author = $1 AND deleted_at IS NULL
+`
+
+func (q *Queries) CountRecipes(ctx context.Context, author string) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countRecipes, author)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createRecipe = `-- name: CreateRecipe :one
 INSERT INTO recipes (
     author, ingredients, steps
 ) VALUES (
              $1, $2, $3
          )
-RETURNING id, author, ingredients, steps, created_at, updated_at
+RETURNING id, author, ingredients, steps, created_at, updated_at, deleted_at, version
 `
 
 type CreateRecipeParams struct {
@@ -35,13 +47,15 @@ func (q *Queries) CreateRecipe(ctx context.Context, arg CreateRecipeParams) (Rec
 		pq.Array(&i.Steps),
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.Version,
 	)
 	return i, err
 }
 
 const deleteRecipe = `-- name: DeleteRecipe :exec
-DELETE FROM recipes
-WHERE id = $1
+UPDATE recipes SET deleted_at = now()
+WHERE id = $1 AND deleted_at IS NULL
 `
 
 func (q *Queries) DeleteRecipe(ctx context.Context, id int64) error {
@@ -50,8 +64,8 @@ func (q *Queries) DeleteRecipe(ctx context.Context, id int64) error {
 }
 
 const getRecipe = `-- name: GetRecipe :one
-SELECT id, author, ingredients, steps, created_at, updated_at FROM recipes
-WHERE id = $1 LIMIT 1
+SELECT id, author, ingredients, steps, created_at, updated_at, deleted_at, version FROM recipes
+WHERE id = $1 AND deleted_at IS NULL LIMIT 1
 `
 
 func (q *Queries) GetRecipe(ctx context.Context, id int64) (Recipe, error) {
@@ -64,13 +78,15 @@ func (q *Queries) GetRecipe(ctx context.Context, id int64) (Recipe, error) {
 		pq.Array(&i.Steps),
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.Version,
 	)
 	return i, err
 }
 
 const listRecipes = `-- name: ListRecipes :many
-SELECT id, author, ingredients, steps, created_at, updated_at FROM recipes
-WHERE author = $1
+SELECT id, author, ingredients, steps, created_at, updated_at, deleted_at, version FROM recipes
+WHERE author = $1 AND deleted_at IS NULL
 ORDER BY id
 LIMIT $2
     OFFSET $3
@@ -98,6 +114,60 @@ func (q *Queries) ListRecipes(ctx context.Context, arg ListRecipesParams) ([]Rec
 			pq.Array(&i.Steps),
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.DeletedAt,
+			&i.Version,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRecipesAfter = `-- name: ListRecipesAfter :many
+SELECT id, author, ingredients, steps, created_at, updated_at, deleted_at, version FROM recipes
+WHERE author = $1 AND deleted_at IS NULL
+  AND (created_at, id) > ($2::timestamptz, $3::bigint)
+ORDER BY created_at, id
+LIMIT $4
+`
+
+type ListRecipesAfterParams struct {
+	Author    string    `json:"author"`
+	CreatedAt time.Time `json:"created_at"`
+	ID        int64     `json:"id"`
+	Limit     int32     `json:"limit"`
+}
+
+func (q *Queries) ListRecipesAfter(ctx context.Context, arg ListRecipesAfterParams) ([]Recipe, error) {
+	rows, err := q.db.QueryContext(ctx, listRecipesAfter,
+		arg.Author,
+		arg.CreatedAt,
+		arg.ID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Recipe{}
+	for rows.Next() {
+		var i Recipe
+		if err := rows.Scan(
+			&i.ID,
+			&i.Author,
+			pq.Array(&i.Ingredients),
+			pq.Array(&i.Steps),
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+			&i.Version,
 		); err != nil {
 			return nil, err
 		}
@@ -113,9 +183,9 @@ func (q *Queries) ListRecipes(ctx context.Context, arg ListRecipesParams) ([]Rec
 }
 
 const updateRecipe = `-- name: UpdateRecipe :one
-UPDATE recipes SET (ingredients, steps, updated_at) = ($2, $3, $4)
-WHERE id = $1
-RETURNING id, author, ingredients, steps, created_at, updated_at
+UPDATE recipes SET (ingredients, steps, updated_at, version) = ($2, $3, $4, version + 1)
+WHERE id = $1 AND version = $5 AND deleted_at IS NULL
+RETURNING id, author, ingredients, steps, created_at, updated_at, deleted_at, version
 `
 
 type UpdateRecipeParams struct {
@@ -123,6 +193,7 @@ type UpdateRecipeParams struct {
 	Ingredients []string  `json:"ingredients"`
 	Steps       []string  `json:"steps"`
 	UpdatedAt   time.Time `json:"updated_at"`
+	Version     int32     `json:"version"`
 }
 
 func (q *Queries) UpdateRecipe(ctx context.Context, arg UpdateRecipeParams) (Recipe, error) {
@@ -131,6 +202,7 @@ func (q *Queries) UpdateRecipe(ctx context.Context, arg UpdateRecipeParams) (Rec
 		pq.Array(arg.Ingredients),
 		pq.Array(arg.Steps),
 		arg.UpdatedAt,
+		arg.Version,
 	)
 	var i Recipe
 	err := row.Scan(
@@ -140,6 +212,8 @@ func (q *Queries) UpdateRecipe(ctx context.Context, arg UpdateRecipeParams) (Rec
 		pq.Array(&i.Steps),
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.Version,
 	)
 	return i, err
 }
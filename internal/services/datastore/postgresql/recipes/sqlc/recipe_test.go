@@ -59,6 +59,7 @@ func TestUpdateRecipe(t *testing.T) {
 		Ingredients: random.StringSlice(4),
 		Steps:       random.StringSlice(5),
 		UpdatedAt:   time.Now().UTC(),
+		Version:     recipe.Version,
 	}
 
 	updatedRecipe, err := testQueries.UpdateRecipe(context.Background(), updateArgs)
@@ -70,6 +71,11 @@ func TestUpdateRecipe(t *testing.T) {
 	require.Equal(t, updateArgs.Steps, updatedRecipe.Steps)
 	require.Equal(t, recipe.CreatedAt, updatedRecipe.CreatedAt)
 	require.WithinDuration(t, updateArgs.UpdatedAt, updatedRecipe.UpdatedAt, time.Second)
+	require.Equal(t, recipe.Version+1, updatedRecipe.Version)
+
+	staleRecipe, err := testQueries.UpdateRecipe(context.Background(), updateArgs)
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+	require.Empty(t, staleRecipe)
 }
 
 func TestDeleteRecipe(t *testing.T) {
@@ -109,3 +115,49 @@ func TestListRecipes(t *testing.T) {
 		require.Equal(t, lastRecipe.Author, recipe.Author)
 	}
 }
+
+func TestListRecipesAfter(t *testing.T) {
+	recipe := createRandomRecipe(t)
+	for i := 0; i < 4; i++ {
+		_, err := testQueries.CreateRecipe(context.Background(), CreateRecipeParams{
+			Author:      recipe.Author,
+			Ingredients: random.StringSlice(6),
+			Steps:       random.StringSlice(4),
+		})
+		require.NoError(t, err)
+	}
+
+	first, err := testQueries.ListRecipesAfter(context.Background(), ListRecipesAfterParams{
+		Author: recipe.Author,
+		Limit:  3,
+	})
+	require.NoError(t, err)
+	require.Len(t, first, 3)
+	require.Equal(t, recipe.ID, first[0].ID)
+
+	last := first[len(first)-1]
+	next, err := testQueries.ListRecipesAfter(context.Background(), ListRecipesAfterParams{
+		Author:    recipe.Author,
+		CreatedAt: last.CreatedAt,
+		ID:        last.ID,
+		Limit:     3,
+	})
+	require.NoError(t, err)
+	require.Len(t, next, 2)
+	for _, r := range next {
+		require.Greater(t, r.ID, last.ID)
+	}
+}
+
+func TestCountRecipes(t *testing.T) {
+	recipe := createRandomRecipe(t)
+
+	count, err := testQueries.CountRecipes(context.Background(), recipe.Author)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), count)
+
+	require.NoError(t, testQueries.DeleteRecipe(context.Background(), recipe.ID))
+	count, err = testQueries.CountRecipes(context.Background(), recipe.Author)
+	require.NoError(t, err)
+	require.Zero(t, count)
+}
@@ -1,19 +1,114 @@
 package db
 
-import "database/sql"
+import (
+	"context"
+	"database/sql"
+)
+
+// Roles of an author, enforced by the authors_role_check constraint
+const (
+	RoleAuthor = "author"
+	RoleAdmin  = "admin"
+)
+
+// The columns the searches can be sorted by. Sort fields are written into the query, so
+// only the names listed here ever reach the SQL.
+var (
+	AuthorSortFields = []string{"username", "created_at", "updated_at"}
+	RecipeSortFields = []string{"id", "created_at", "updated_at"}
+)
+
+// Sort is the order of a search, by one of its sort fields
+type Sort struct {
+	Field string
+	Desc  bool
+}
+
+type SearchAuthorsParams struct {
+	CreatedAfter  sql.NullTime
+	CreatedBefore sql.NullTime
+	Sort          Sort
+	Limit         int32
+	Offset        int32
+}
+
+type SearchRecipesParams struct {
+	Author        string
+	CreatedAfter  sql.NullTime
+	CreatedBefore sql.NullTime
+	UpdatedSince  sql.NullTime
+	Ingredient    sql.NullString
+	Sort          Sort
+	Limit         int32
+	Offset        int32
+}
+
+// Searcher lists the authors and recipes with optional filters and a sort order. It is
+// implemented by the search package, since sqlc cannot generate its statements.
+type Searcher interface {
+	// CountSearchAuthors counts the authors matching the filters of a search
+	CountSearchAuthors(ctx context.Context, arg SearchAuthorsParams) (int64, error)
+
+	// CountSearchRecipes counts the recipes matching the filters of a search
+	CountSearchRecipes(ctx context.Context, arg SearchRecipesParams) (int64, error)
+
+	// SearchAuthors lists the authors with optional filters and a sort order
+	SearchAuthors(ctx context.Context, arg SearchAuthorsParams) ([]Author, error)
+
+	// SearchRecipes lists the recipes of an author with optional filters and a sort order
+	SearchRecipes(ctx context.Context, arg SearchRecipesParams) ([]Recipe, error)
+}
 
 type Store interface {
 	Querier
+	Searcher
+
+	// Ping verifies that the database is reachable
+	Ping(ctx context.Context) error
+
+	// MigrationVersion returns the currently applied migration version and whether the last
+	// migration failed halfway
+	MigrationVersion(ctx context.Context) (version int64, dirty bool, err error)
+
+	// Stats returns the connection pool statistics
+	Stats() sql.DBStats
 }
 
 type PostgresqlStore struct {
 	db *sql.DB
 	*Queries
+	Searcher
 }
 
-func NewStore(db *sql.DB) PostgresqlStore {
+// NewStore creates a PostgresqlStore running the queries on db, and the searches through
+// searcher, usually search.New(db)
+func NewStore(db *sql.DB, searcher Searcher) PostgresqlStore {
 	return PostgresqlStore{
-		db:      db,
-		Queries: New(db),
+		db:       db,
+		Queries:  New(db),
+		Searcher: searcher,
 	}
 }
+
+// Ping verifies that the database is reachable
+func (s PostgresqlStore) Ping(ctx context.Context) error {
+	return s.db.PingContext(ctx)
+}
+
+// MigrationVersion reads the version recorded by the migrate tool in schema_migrations
+func (s PostgresqlStore) MigrationVersion(ctx context.Context) (int64, bool, error) {
+	var (
+		version int64
+		dirty   bool
+	)
+	err := s.db.QueryRowContext(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&version, &dirty)
+	if err == sql.ErrNoRows {
+		return 0, false, nil
+	}
+	return version, dirty, err
+}
+
+// Stats returns the connection pool statistics
+func (s PostgresqlStore) Stats() sql.DBStats {
+	return s.db.Stats()
+}
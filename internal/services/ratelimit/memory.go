@@ -0,0 +1,62 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// sweepInterval is the minimum interval between two removals of the full buckets
+const sweepInterval = time.Minute
+
+// MemoryBackend keeps the buckets in the process memory, so that every instance of the
+// server limits its clients on its own
+type MemoryBackend struct {
+	mu        sync.Mutex
+	buckets   map[string]memoryBucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// memoryBucket is a bucket along with the time it is full again, after which it can be
+// forgotten
+type memoryBucket struct {
+	bucket
+	full time.Time
+}
+
+var _ Backend = (*MemoryBackend)(nil)
+
+// NewMemoryBackend creates a pointer to an empty MemoryBackend
+func NewMemoryBackend() *MemoryBackend {
+	return &MemoryBackend{
+		buckets: make(map[string]memoryBucket),
+		now:     time.Now,
+	}
+}
+
+func (m *MemoryBackend) Take(_ context.Context, key string, limit Limit) (Result, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	m.sweep(now)
+
+	b, res := m.buckets[key].bucket.take(now, limit)
+	m.buckets[key] = memoryBucket{bucket: b, full: now.Add(res.Reset)}
+	return res, nil
+}
+
+// sweep removes the buckets that are full again, which behave as new ones
+func (m *MemoryBackend) sweep(now time.Time) {
+	if now.Sub(m.lastSweep) < sweepInterval {
+		return
+	}
+	m.lastSweep = now
+
+	for key, b := range m.buckets {
+		if !now.Before(b.full) {
+			delete(m.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package ratelimit
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestMemoryBackend(t *testing.T) {
+	now := time.Now()
+	backend := NewMemoryBackend()
+	backend.now = func() time.Time { return now }
+	limit := Limit{Requests: 3, Period: time.Minute}
+
+	for i := 2; i >= 0; i-- {
+		res, err := backend.Take(context.Background(), "alice", limit)
+		require.NoError(t, err)
+		require.True(t, res.Allowed)
+		require.Equal(t, i, res.Remaining)
+	}
+	res, err := backend.Take(context.Background(), "alice", limit)
+	require.NoError(t, err)
+	require.False(t, res.Allowed)
+	require.Equal(t, 20*time.Second, res.RetryAfter)
+
+	res, err = backend.Take(context.Background(), "bob", limit)
+	require.NoError(t, err)
+	require.True(t, res.Allowed)
+
+	now = now.Add(20 * time.Second)
+	res, err = backend.Take(context.Background(), "alice", limit)
+	require.NoError(t, err)
+	require.True(t, res.Allowed)
+
+	// the buckets full again are swept
+	now = now.Add(time.Hour)
+	_, err = backend.Take(context.Background(), "carol", limit)
+	require.NoError(t, err)
+	require.Len(t, backend.buckets, 1)
+}
@@ -0,0 +1,151 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type (
+	// Limit is a token bucket holding Requests tokens, refilled evenly over Period. A
+	// client may send Requests requests at once, then one every Period / Requests.
+	Limit struct {
+		Requests int
+		Period   time.Duration
+	}
+
+	// Result is the state of a bucket after a token was requested from it
+	Result struct {
+		Allowed   bool
+		Limit     int
+		Remaining int
+		// RetryAfter is the time until a token is available, zero when Allowed
+		RetryAfter time.Duration
+		// Reset is the time until the bucket is full again
+		Reset time.Duration
+	}
+
+	// Backend stores the buckets, keyed by route and client
+	Backend interface {
+		// Take takes a token from the bucket of key, created full the first time
+		Take(ctx context.Context, key string, limit Limit) (Result, error)
+	}
+
+	// bucket is the state of a token bucket, full when updated is zero
+	bucket struct {
+		tokens  float64
+		updated time.Time
+	}
+)
+
+// periodUnits are the periods that can be written without a number, e.g. 10/m
+var periodUnits = map[string]time.Duration{
+	"s": time.Second,
+	"m": time.Minute,
+	"h": time.Hour,
+}
+
+// String formats the limit as ParseLimit reads it
+func (l Limit) String() string {
+	return fmt.Sprintf("%d/%s", l.Requests, l.Period)
+}
+
+// Policy formats the limit as the value of the RateLimit-Policy header
+func (l Limit) Policy() string {
+	return fmt.Sprintf("%d;w=%d", l.Requests, int64(math.Ceil(l.Period.Seconds())))
+}
+
+// ParseLimit parses a limit written REQUESTS/PERIOD, where the period is a duration such
+// as 30s or a unit among s, m and h, e.g. 10/m
+func ParseLimit(s string) (Limit, error) {
+	requests, period, ok := strings.Cut(strings.TrimSpace(s), "/")
+	if !ok {
+		return Limit{}, fmt.Errorf("invalid limit %q, expected REQUESTS/PERIOD", s)
+	}
+
+	n, err := strconv.Atoi(requests)
+	if err != nil || n <= 0 {
+		return Limit{}, fmt.Errorf("invalid limit %q, requests must be a positive integer", s)
+	}
+
+	d, ok := periodUnits[period]
+	if !ok {
+		d, err = time.ParseDuration(period)
+		if err != nil || d <= 0 {
+			return Limit{}, fmt.Errorf("invalid limit %q, period must be a positive duration", s)
+		}
+	}
+	return Limit{Requests: n, Period: d}, nil
+}
+
+// ParseRoutes parses comma separated route limits written METHOD /path=LIMIT, where the
+// path is a route template such as /recipes/:id, e.g. POST /authors/login=10/m. The limits
+// are keyed by RouteKey.
+func ParseRoutes(s string) (map[string]Limit, error) {
+	limits := make(map[string]Limit)
+	for _, entry := range strings.Split(s, ",") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+
+		route, limit, ok := strings.Cut(entry, "=")
+		fields := strings.Fields(route)
+		if !ok || len(fields) != 2 || !strings.HasPrefix(fields[1], "/") {
+			return nil, fmt.Errorf("invalid route limit %q, expected METHOD /path=REQUESTS/PERIOD", strings.TrimSpace(entry))
+		}
+
+		l, err := ParseLimit(limit)
+		if err != nil {
+			return nil, err
+		}
+		key := RouteKey(fields[0], fields[1])
+		if _, ok := limits[key]; ok {
+			return nil, fmt.Errorf("duplicate route limit for %s", key)
+		}
+		limits[key] = l
+	}
+	return limits, nil
+}
+
+// RouteKey identifies a route by its method and path template
+func RouteKey(method, path string) string {
+	return strings.ToUpper(method) + " " + path
+}
+
+// rate is the number of tokens added to the bucket per second
+func (l Limit) rate() float64 {
+	return float64(l.Requests) / l.Period.Seconds()
+}
+
+// take refills b up to now and takes a token from it when one is available
+func (b bucket) take(now time.Time, limit Limit) (bucket, Result) {
+	burst := float64(limit.Requests)
+	rate := limit.rate()
+
+	if b.updated.IsZero() {
+		b = bucket{tokens: burst, updated: now}
+	}
+	if elapsed := now.Sub(b.updated); elapsed > 0 {
+		b.tokens = math.Min(burst, b.tokens+elapsed.Seconds()*rate)
+		b.updated = now
+	}
+
+	res := Result{Limit: limit.Requests}
+	if b.tokens >= 1 {
+		b.tokens--
+		res.Allowed = true
+	} else {
+		res.RetryAfter = seconds((1 - b.tokens) / rate)
+	}
+	res.Remaining = int(b.tokens)
+	res.Reset = seconds((burst - b.tokens) / rate)
+	return b, res
+}
+
+// seconds converts a number of seconds to a duration
+func seconds(s float64) time.Duration {
+	return time.Duration(math.Ceil(s * float64(time.Second)))
+}
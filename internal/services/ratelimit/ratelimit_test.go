@@ -0,0 +1,84 @@
+package ratelimit
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestParseLimit(t *testing.T) {
+	testCases := []struct {
+		input string
+		limit Limit
+		err   bool
+	}{
+		{input: "10/m", limit: Limit{Requests: 10, Period: time.Minute}},
+		{input: " 5/30s ", limit: Limit{Requests: 5, Period: 30 * time.Second}},
+		{input: "100/h", limit: Limit{Requests: 100, Period: time.Hour}},
+		{input: "10", err: true},
+		{input: "0/m", err: true},
+		{input: "ten/m", err: true},
+		{input: "10/week", err: true},
+		{input: "10/-1s", err: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			limit, err := ParseLimit(tc.input)
+			if tc.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.limit, limit)
+		})
+	}
+}
+
+func TestParseRoutes(t *testing.T) {
+	limits, err := ParseRoutes("POST /authors/login=10/m, get /recipes/:id=100/1m,")
+	require.NoError(t, err)
+	require.Equal(t, map[string]Limit{
+		"POST /authors/login": {Requests: 10, Period: time.Minute},
+		"GET /recipes/:id":    {Requests: 100, Period: time.Minute},
+	}, limits)
+
+	limits, err = ParseRoutes("")
+	require.NoError(t, err)
+	require.Empty(t, limits)
+
+	for _, input := range []string{"/recipes=10/m", "POST recipes=10/m", "POST /recipes", "POST /recipes=10/m,POST /recipes=5/m"} {
+		_, err = ParseRoutes(input)
+		require.Error(t, err, input)
+	}
+}
+
+func TestBucketTake(t *testing.T) {
+	limit := Limit{Requests: 2, Period: 2 * time.Second}
+	now := time.Now()
+
+	var b bucket
+	var res Result
+	b, res = b.take(now, limit)
+	require.Equal(t, Result{Allowed: true, Limit: 2, Remaining: 1, Reset: time.Second}, res)
+	b, res = b.take(now, limit)
+	require.Equal(t, Result{Allowed: true, Limit: 2, Remaining: 0, Reset: 2 * time.Second}, res)
+
+	b, res = b.take(now.Add(500*time.Millisecond), limit)
+	require.False(t, res.Allowed)
+	require.Equal(t, 500*time.Millisecond, res.RetryAfter)
+	require.Equal(t, 1500*time.Millisecond, res.Reset)
+
+	b, res = b.take(now.Add(time.Second), limit)
+	require.True(t, res.Allowed)
+	require.Zero(t, res.Remaining)
+
+	_, res = b.take(now.Add(time.Hour), limit)
+	require.True(t, res.Allowed)
+	require.Equal(t, 1, res.Remaining)
+}
+
+func TestLimitPolicy(t *testing.T) {
+	require.Equal(t, "10;w=60", Limit{Requests: 10, Period: time.Minute}.Policy())
+	require.Equal(t, "10/1m0s", Limit{Requests: 10, Period: time.Minute}.String())
+}
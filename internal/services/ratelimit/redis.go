@@ -0,0 +1,97 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// takeScript refills the bucket hash of KEYS[1] and takes a token from it, atomically.
+// ARGV holds the burst, the period and the current time, in milliseconds. It returns
+// whether a token was taken, the remaining tokens, the milliseconds until a token is
+// available and the milliseconds until the bucket is full again. The hash expires once
+// full, when it behaves as a missing one.
+const takeScript = `
+local burst = tonumber(ARGV[1])
+local rate = burst / tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'updated')
+local tokens = tonumber(state[1]) or burst
+local updated = tonumber(state[2]) or now
+if now > updated then
+  tokens = math.min(burst, tokens + (now - updated) * rate)
+  updated = now
+end
+
+local allowed = 0
+local retry = 0
+if tokens >= 1 then
+  tokens = tokens - 1
+  allowed = 1
+else
+  retry = math.ceil((1 - tokens) / rate)
+end
+local reset = math.ceil((burst - tokens) / rate)
+
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'updated', tostring(updated))
+redis.call('PEXPIRE', KEYS[1], reset + 1)
+return {allowed, math.floor(tokens), retry, reset}
+`
+
+// RedisClient is the subset of a Redis client used by RedisBackend. It is implemented by
+// RESPClient, and by thin adapters over the usual Redis client libraries. Replies are
+// decoded as int64, string, nil, error or []interface{} values.
+type RedisClient interface {
+	Eval(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error)
+}
+
+// RedisBackend keeps the buckets in a Redis compatible server, so that the instances of
+// the server sharing it enforce the limits together
+type RedisBackend struct {
+	client RedisClient
+	prefix string
+	now    func() time.Time
+}
+
+var _ Backend = (*RedisBackend)(nil)
+
+// NewRedisBackend creates a pointer to a RedisBackend storing the buckets through client,
+// under keys starting with prefix
+func NewRedisBackend(client RedisClient, prefix string) *RedisBackend {
+	return &RedisBackend{
+		client: client,
+		prefix: prefix,
+		now:    time.Now,
+	}
+}
+
+func (r *RedisBackend) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	reply, err := r.client.Eval(ctx, takeScript, []string{r.prefix + key},
+		limit.Requests,
+		limit.Period.Milliseconds(),
+		r.now().UnixMilli(),
+	)
+	if err != nil {
+		return Result{}, fmt.Errorf("cannot take a token: %w", err)
+	}
+
+	values, ok := reply.([]interface{})
+	if !ok || len(values) != 4 {
+		return Result{}, fmt.Errorf("unexpected reply %v", reply)
+	}
+	var n [4]int64
+	for i, value := range values {
+		if n[i], ok = value.(int64); !ok {
+			return Result{}, fmt.Errorf("unexpected reply %v", reply)
+		}
+	}
+
+	return Result{
+		Allowed:    n[0] == 1,
+		Limit:      limit.Requests,
+		Remaining:  int(n[1]),
+		RetryAfter: time.Duration(n[2]) * time.Millisecond,
+		Reset:      time.Duration(n[3]) * time.Millisecond,
+	}, nil
+}
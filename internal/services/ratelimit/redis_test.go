@@ -0,0 +1,246 @@
+package ratelimit
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"github.com/stretchr/testify/require"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeRedis is a local Redis server answering AUTH, and EVAL and EVALSHA of takeScript by
+// applying the bucket logic in Go
+type fakeRedis struct {
+	password string
+
+	mu       sync.Mutex
+	buckets  map[string]bucket
+	commands [][]string
+	// loaded is whether takeScript is in the script cache
+	loaded bool
+	// conns and maxConns are the current and maximum number of open connections
+	conns    int
+	maxConns int
+}
+
+func newFakeRedis(t *testing.T, password string) (*fakeRedis, string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	f := &fakeRedis{password: password, buckets: make(map[string]bucket)}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go f.serve(conn)
+		}
+	}()
+	return f, listener.Addr().String()
+}
+
+func (f *fakeRedis) serve(conn net.Conn) {
+	f.mu.Lock()
+	f.conns++
+	if f.conns > f.maxConns {
+		f.maxConns = f.conns
+	}
+	f.mu.Unlock()
+	defer func() {
+		f.mu.Lock()
+		f.conns--
+		f.mu.Unlock()
+		_ = conn.Close()
+	}()
+
+	r := bufio.NewReader(conn)
+	authenticated := f.password == ""
+
+	for {
+		reply, err := readReply(r)
+		if err != nil {
+			return
+		}
+		var args []string
+		for _, arg := range reply.([]interface{}) {
+			args = append(args, arg.(string))
+		}
+		f.mu.Lock()
+		f.commands = append(f.commands, args)
+		loaded := f.loaded
+		if args[0] == "EVAL" && args[1] == takeScript {
+			f.loaded = true
+		}
+		f.mu.Unlock()
+
+		switch {
+		case args[0] == "AUTH" && args[1] == f.password:
+			authenticated = true
+			fmt.Fprint(conn, "+OK\r\n")
+		case args[0] == "AUTH":
+			fmt.Fprint(conn, "-WRONGPASS invalid password\r\n")
+		case !authenticated:
+			fmt.Fprint(conn, "-NOAUTH Authentication required.\r\n")
+		case args[0] == "EVAL" && args[1] == takeScript,
+			args[0] == "EVALSHA" && args[1] == takeScriptSHA && loaded:
+			fmt.Fprint(conn, f.take(args[3], args[4:]))
+		case args[0] == "EVALSHA":
+			fmt.Fprint(conn, "-NOSCRIPT No matching script. Please use EVAL.\r\n")
+		default:
+			fmt.Fprintf(conn, "-ERR unknown command '%s'\r\n", args[0])
+		}
+	}
+}
+
+// takeScriptSHA is the digest takeScript is referred to by in EVALSHA
+var takeScriptSHA = func() string {
+	digest := sha1.Sum([]byte(takeScript))
+	return hex.EncodeToString(digest[:])
+}()
+
+// take runs takeScript on key with the arguments burst, period and now in milliseconds
+func (f *fakeRedis) take(key string, args []string) string {
+	var n [3]int64
+	for i, arg := range args {
+		n[i], _ = strconv.ParseInt(arg, 10, 64)
+	}
+	limit := Limit{Requests: int(n[0]), Period: time.Duration(n[1]) * time.Millisecond}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	b, res := f.buckets[key].take(time.UnixMilli(n[2]), limit)
+	f.buckets[key] = b
+
+	allowed := 0
+	if res.Allowed {
+		allowed = 1
+	}
+	return fmt.Sprintf("*4\r\n:%d\r\n:%d\r\n:%d\r\n:%d\r\n", allowed, res.Remaining, res.RetryAfter.Milliseconds(), res.Reset.Milliseconds())
+}
+
+func TestRedisBackend(t *testing.T) {
+	fake, address := newFakeRedis(t, "secret")
+	client := NewRESPClient(address, "secret", 1, time.Second)
+	t.Cleanup(func() { _ = client.Close() })
+
+	now := time.Now()
+	backend := NewRedisBackend(client, "ratelimit:")
+	backend.now = func() time.Time { return now }
+	limit := Limit{Requests: 2, Period: time.Minute}
+
+	for i := 1; i >= 0; i-- {
+		res, err := backend.Take(context.Background(), "alice", limit)
+		require.NoError(t, err)
+		require.True(t, res.Allowed)
+		require.Equal(t, i, res.Remaining)
+		require.Equal(t, 2, res.Limit)
+	}
+	res, err := backend.Take(context.Background(), "alice", limit)
+	require.NoError(t, err)
+	require.False(t, res.Allowed)
+	require.Equal(t, 30*time.Second, res.RetryAfter)
+	require.Equal(t, time.Minute, res.Reset)
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	// the script is sent once, when missing from the script cache
+	var names []string
+	for _, cmd := range fake.commands {
+		names = append(names, cmd[0])
+	}
+	require.Equal(t, []string{"AUTH", "EVALSHA", "EVAL", "EVALSHA", "EVALSHA"}, names)
+	require.Equal(t, "secret", fake.commands[0][1])
+	for _, eval := range fake.commands[1:] {
+		require.Equal(t, []string{"1", "ratelimit:alice", "2", "60000", strconv.FormatInt(now.UnixMilli(), 10)}, eval[2:])
+	}
+	require.Equal(t, takeScriptSHA, fake.commands[1][1])
+}
+
+func TestRESPClient(t *testing.T) {
+	_, address := newFakeRedis(t, "secret")
+
+	t.Run("WrongPassword", func(t *testing.T) {
+		client := NewRESPClient(address, "wrong", 1, time.Second)
+		_, err := client.Do(context.Background(), "PING")
+		require.ErrorContains(t, err, "WRONGPASS")
+		require.Empty(t, client.idle)
+		require.Empty(t, client.slots)
+	})
+
+	t.Run("ErrorReply", func(t *testing.T) {
+		client := NewRESPClient(address, "secret", 1, time.Second)
+		t.Cleanup(func() { _ = client.Close() })
+
+		_, err := client.Do(context.Background(), "PING")
+		require.Equal(t, RedisError("ERR unknown command 'PING'"), err)
+		// error replies keep the connection
+		require.Len(t, client.idle, 1)
+	})
+
+	t.Run("Pool", func(t *testing.T) {
+		fake, address := newFakeRedis(t, "")
+		client := NewRESPClient(address, "", 2, time.Second)
+		backend := NewRedisBackend(client, "")
+		limit := Limit{Requests: 100, Period: time.Second}
+
+		var wg sync.WaitGroup
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := backend.Take(context.Background(), "alice", limit)
+				require.NoError(t, err)
+			}()
+		}
+		wg.Wait()
+		// every connection is back in the pool
+		require.Len(t, client.idle, len(client.slots))
+
+		require.NoError(t, client.Close())
+		require.Empty(t, client.slots)
+		_, err := client.Do(context.Background(), "PING")
+		require.ErrorIs(t, err, errClientClosed)
+
+		fake.mu.Lock()
+		defer fake.mu.Unlock()
+		require.LessOrEqual(t, fake.maxConns, 2)
+	})
+
+	t.Run("Unreachable", func(t *testing.T) {
+		client := NewRESPClient("127.0.0.1:1", "", 1, time.Second)
+		_, err := NewRedisBackend(client, "").Take(context.Background(), "alice", Limit{Requests: 1, Period: time.Second})
+		require.Error(t, err)
+	})
+}
+
+func TestReadReply(t *testing.T) {
+	testCases := []struct {
+		input string
+		reply interface{}
+	}{
+		{input: "+OK\r\n", reply: "OK"},
+		{input: ":-12\r\n", reply: int64(-12)},
+		{input: "$5\r\nhello\r\n", reply: "hello"},
+		{input: "$-1\r\n", reply: nil},
+		{input: "*2\r\n:1\r\n$1\r\na\r\n", reply: []interface{}{int64(1), "a"}},
+		{input: "*1\r\n-ERR nested\r\n", reply: []interface{}{RedisError("ERR nested")}},
+	}
+
+	for _, tc := range testCases {
+		reply, err := readReply(bufio.NewReader(strings.NewReader(tc.input)))
+		require.NoError(t, err, tc.input)
+		require.Equal(t, tc.reply, reply, tc.input)
+	}
+
+	_, err := readReply(bufio.NewReader(strings.NewReader("?\r\n")))
+	require.Error(t, err)
+}
@@ -0,0 +1,280 @@
+package ratelimit
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RedisError is an error reply of the Redis server
+type RedisError string
+
+func (e RedisError) Error() string {
+	return "redis: " + string(e)
+}
+
+// errClientClosed is returned by the calls made after the client is closed
+var errClientClosed = errors.New("redis client is closed")
+
+// RESPClient is a minimal RedisClient speaking the RESP protocol over a pool of connections.
+// Connections are opened on demand, up to the size of the pool, kept open between commands
+// and dropped after a network error. Calls wait for a free connection once they are all in
+// use.
+type RESPClient struct {
+	address  string
+	password string
+	timeout  time.Duration
+
+	// slots holds a value per open connection, and idle the connections not in use
+	slots chan struct{}
+	idle  chan *respConn
+
+	mu     sync.Mutex
+	closed bool
+}
+
+// respConn is a connection of the pool
+type respConn struct {
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+var _ RedisClient = (*RESPClient)(nil)
+
+// NewRESPClient creates a pointer to a RESPClient for the server at address, authenticated
+// with password when it is not empty, opening up to poolSize connections. Calls time out
+// after timeout unless their context has an earlier deadline.
+func NewRESPClient(address, password string, poolSize int, timeout time.Duration) *RESPClient {
+	if poolSize < 1 {
+		poolSize = 1
+	}
+	return &RESPClient{
+		address:  address,
+		password: password,
+		timeout:  timeout,
+		slots:    make(chan struct{}, poolSize),
+		idle:     make(chan *respConn, poolSize),
+	}
+}
+
+// Eval runs a Lua script on the server. The script is referred to by its SHA1 digest, and
+// only sent when the server does not have it in its script cache yet, which EVAL fills.
+func (c *RESPClient) Eval(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error) {
+	digest := sha1.Sum([]byte(script))
+	cmd := make([]interface{}, 0, 3+len(keys)+len(args))
+	cmd = append(cmd, "EVALSHA", hex.EncodeToString(digest[:]), len(keys))
+	for _, key := range keys {
+		cmd = append(cmd, key)
+	}
+	cmd = append(cmd, args...)
+
+	reply, err := c.Do(ctx, cmd...)
+	var redisErr RedisError
+	if errors.As(err, &redisErr) && strings.HasPrefix(string(redisErr), "NOSCRIPT") {
+		cmd[0], cmd[1] = "EVAL", script
+		return c.Do(ctx, cmd...)
+	}
+	return reply, err
+}
+
+// Do sends a command and returns its reply. Error replies are returned as a RedisError.
+func (c *RESPClient) Do(ctx context.Context, args ...interface{}) (interface{}, error) {
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	conn, err := c.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	reply, err := conn.roundTrip(ctx, args)
+	var redisErr RedisError
+	// the connection is in an unknown state after a network error
+	c.put(conn, err == nil || errors.As(err, &redisErr))
+	return reply, err
+}
+
+// Close closes the connections to the server. The connections in use are closed once their
+// command completes.
+func (c *RESPClient) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.closed = true
+	var err error
+	for {
+		select {
+		case conn := <-c.idle:
+			if closeErr := conn.conn.Close(); err == nil {
+				err = closeErr
+			}
+			<-c.slots
+		default:
+			return err
+		}
+	}
+}
+
+// get takes an idle connection, or opens one when none is idle and the pool is not full,
+// and waits for one otherwise
+func (c *RESPClient) get(ctx context.Context) (*respConn, error) {
+	select {
+	case conn := <-c.idle:
+		return conn, nil
+	case c.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, fmt.Errorf("cannot connect to redis: %w", ctx.Err())
+	}
+
+	// a connection may have been released while the slot was taken
+	select {
+	case conn := <-c.idle:
+		<-c.slots
+		return conn, nil
+	default:
+	}
+
+	c.mu.Lock()
+	closed := c.closed
+	c.mu.Unlock()
+	if closed {
+		<-c.slots
+		return nil, errClientClosed
+	}
+
+	conn, err := c.connect(ctx)
+	if err != nil {
+		<-c.slots
+		return nil, err
+	}
+	return conn, nil
+}
+
+// put gives a connection back to the pool when it is healthy, and closes it otherwise
+func (c *RESPClient) put(conn *respConn, healthy bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !healthy || c.closed {
+		_ = conn.conn.Close()
+		<-c.slots
+		return
+	}
+	c.idle <- conn
+}
+
+func (c *RESPClient) connect(ctx context.Context) (*respConn, error) {
+	var dialer net.Dialer
+	netConn, err := dialer.DialContext(ctx, "tcp", c.address)
+	if err != nil {
+		return nil, fmt.Errorf("cannot connect to redis: %w", err)
+	}
+	conn := &respConn{conn: netConn, reader: bufio.NewReader(netConn)}
+
+	if c.password != "" {
+		if _, err := conn.roundTrip(ctx, []interface{}{"AUTH", c.password}); err != nil {
+			_ = netConn.Close()
+			return nil, fmt.Errorf("cannot authenticate to redis: %w", err)
+		}
+	}
+	return conn, nil
+}
+
+func (c *respConn) roundTrip(ctx context.Context, args []interface{}) (interface{}, error) {
+	deadline, _ := ctx.Deadline()
+	if err := c.conn.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+
+	if _, err := c.conn.Write(encodeCommand(args)); err != nil {
+		return nil, err
+	}
+	return readReply(c.reader)
+}
+
+// encodeCommand encodes a command as an array of bulk strings
+func encodeCommand(args []interface{}) []byte {
+	buf := []byte("*" + strconv.Itoa(len(args)) + "\r\n")
+	for _, arg := range args {
+		var s string
+		switch v := arg.(type) {
+		case string:
+			s = v
+		case []byte:
+			s = string(v)
+		case int:
+			s = strconv.Itoa(v)
+		case int64:
+			s = strconv.FormatInt(v, 10)
+		case float64:
+			s = strconv.FormatFloat(v, 'f', -1, 64)
+		default:
+			s = fmt.Sprint(v)
+		}
+		buf = append(buf, "$"+strconv.Itoa(len(s))+"\r\n"+s+"\r\n"...)
+	}
+	return buf
+}
+
+// readReply decodes a RESP2 reply. Error replies nested in an array are returned as
+// RedisError values.
+func readReply(r *bufio.Reader) (interface{}, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 || line[len(line)-2] != '\r' {
+		return nil, fmt.Errorf("malformed redis reply %q", line)
+	}
+	kind, payload := line[0], line[1:len(line)-2]
+
+	switch kind {
+	case '+':
+		return payload, nil
+	case '-':
+		return nil, RedisError(payload)
+	case ':':
+		return strconv.ParseInt(payload, 10, 64)
+	case '$':
+		n, err := strconv.Atoi(payload)
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		return string(buf[:n]), nil
+	case '*':
+		n, err := strconv.Atoi(payload)
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		values := make([]interface{}, n)
+		for i := range values {
+			values[i], err = readReply(r)
+			var redisErr RedisError
+			if errors.As(err, &redisErr) {
+				values[i], err = redisErr, nil
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+		return values, nil
+	default:
+		return nil, fmt.Errorf("malformed redis reply %q", line)
+	}
+}
@@ -0,0 +1,76 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"github.com/gmaschi/go-recipes-book/pkg/config/env"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"io"
+)
+
+// InstrumentationName names the tracers of the application
+const InstrumentationName = "github.com/gmaschi/go-recipes-book"
+
+// New creates a tracer provider exporting the spans as selected by config. Spans are still
+// created, and trace contexts propagated, when the exporter is none. The stdout exporter
+// writes to w.
+func New(ctx context.Context, config env.TracingConfig, w io.Writer) (*sdktrace.TracerProvider, error) {
+	switch config.Exporter {
+	case "", "none":
+		return newProvider(config), nil
+	case "stdout":
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(w))
+		if err != nil {
+			return nil, fmt.Errorf("cannot create stdout exporter: %w", err)
+		}
+		return NewWithExporter(config, exporter), nil
+	case "otlp":
+		opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(config.OTLPEndpoint)}
+		if config.OTLPInsecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		exporter, err := otlptracehttp.New(ctx, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("cannot create OTLP exporter: %w", err)
+		}
+		return newProvider(config, sdktrace.WithBatcher(exporter)), nil
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter %q", config.Exporter)
+	}
+}
+
+// NewWithExporter creates a tracer provider exporting every span to exporter as soon as it
+// ends, e.g. to an in-memory exporter in tests
+func NewWithExporter(config env.TracingConfig, exporter sdktrace.SpanExporter) *sdktrace.TracerProvider {
+	return newProvider(config, sdktrace.WithSyncer(exporter))
+}
+
+func newProvider(config env.TracingConfig, opts ...sdktrace.TracerProviderOption) *sdktrace.TracerProvider {
+	serviceName := config.ServiceName
+	if serviceName == "" {
+		serviceName = "recipes-book"
+	}
+
+	opts = append(opts,
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(serviceName))),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(config.SampleRatio))),
+	)
+	return sdktrace.NewTracerProvider(opts...)
+}
+
+// Propagator returns the W3C trace context and baggage propagator
+func Propagator() propagation.TextMapPropagator {
+	return propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+}
+
+// SetGlobal installs provider and the W3C propagator as the global OpenTelemetry defaults
+func SetGlobal(provider *sdktrace.TracerProvider) {
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(Propagator())
+}
@@ -0,0 +1,97 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// Login authenticates an author and stores the issued access token
+func (c *Client) Login(ctx context.Context, username, password string) (Session, error) {
+	var session Session
+	err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/authors/login",
+		body: map[string]string{
+			"username": username,
+			"password": password,
+		},
+	}, &session)
+	if err != nil {
+		return Session{}, err
+	}
+
+	err = c.tokens.Save(Token{AccessToken: session.AccessToken, Username: session.Username})
+	return session, err
+}
+
+// Logout forgets the stored access token
+func (c *Client) Logout() error {
+	return c.tokens.Clear()
+}
+
+// CreateAuthor creates an author. The email of the created author is not returned.
+func (c *Client) CreateAuthor(ctx context.Context, req CreateAuthorRequest) (Author, error) {
+	var author Author
+	err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/authors",
+		body:   req,
+	}, &author)
+	return author, err
+}
+
+// GetAuthor returns the author with the given username
+func (c *Client) GetAuthor(ctx context.Context, username string) (Author, error) {
+	var author Author
+	err := c.do(ctx, request{
+		method: http.MethodGet,
+		path:   "/authors/" + url.PathEscape(username),
+	}, &author)
+	return author, err
+}
+
+// ListAuthors returns a page of the authors ordered by username
+func (c *Client) ListAuthors(ctx context.Context, opts ListOptions) ([]Author, error) {
+	var authors []Author
+	opts.Ingredient = ""
+	err := c.do(ctx, request{
+		method: http.MethodGet,
+		path:   "/authors",
+		query:  opts.values(),
+	}, &authors)
+	return authors, err
+}
+
+// Authors iterates over every author, fetching them pageSize at a time
+func (c *Client) Authors(pageSize int32) *Iterator[Author] {
+	return newIterator(ListOptions{PageSize: pageSize}, c.ListAuthors)
+}
+
+// UpdateAuthor updates the authenticated author. The username defaults to the one of the
+// stored access token.
+func (c *Client) UpdateAuthor(ctx context.Context, req UpdateAuthorRequest) (Author, error) {
+	if req.Username == "" {
+		if token, err := c.tokens.Load(); err == nil {
+			req.Username = token.Username
+		}
+	}
+
+	var author Author
+	err := c.do(ctx, request{
+		method:        http.MethodPatch,
+		path:          "/authors",
+		body:          req,
+		authenticated: true,
+	}, &author)
+	return author, err
+}
+
+// DeleteAuthor deletes the authenticated author, who must not have recipes left
+func (c *Client) DeleteAuthor(ctx context.Context, username string) error {
+	return c.do(ctx, request{
+		method:        http.MethodDelete,
+		path:          "/authors/" + url.PathEscape(username),
+		authenticated: true,
+	}, nil)
+}
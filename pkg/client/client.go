@@ -0,0 +1,211 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+const (
+	userAgent = "recipes-book-go-client"
+
+	// maxErrorBody bounds the body read from error responses that are not problem details
+	maxErrorBody = 1 << 12
+)
+
+type (
+	// Client calls the recipes book REST API. It is safe for concurrent use.
+	Client struct {
+		baseURL    *url.URL
+		httpClient *http.Client
+		tokens     TokenStore
+
+		// loginMu serializes the logins refreshing an expired token
+		loginMu     sync.Mutex
+		credentials *credentials
+	}
+
+	credentials struct {
+		username string
+		password string
+	}
+
+	// Option configures a Client
+	Option func(*Client)
+)
+
+// WithHTTPClient sets the HTTP client used for the requests, http.DefaultClient by default
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithTokenStore sets where the access token is kept, in memory by default
+func WithTokenStore(tokens TokenStore) Option {
+	return func(c *Client) {
+		c.tokens = tokens
+	}
+}
+
+// WithCredentials makes the client log in with username and password whenever it has no
+// valid access token, including when the stored one expired
+func WithCredentials(username, password string) Option {
+	return func(c *Client) {
+		c.credentials = &credentials{username: username, password: password}
+	}
+}
+
+// New creates a pointer to a Client calling the API at baseURL, e.g. http://localhost:8080
+func New(baseURL string, opts ...Option) (*Client, error) {
+	u, err := url.Parse(strings.TrimSuffix(baseURL, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid base URL: %w", err)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid base URL %q: scheme and host are required", baseURL)
+	}
+
+	c := &Client{
+		baseURL:    u,
+		httpClient: http.DefaultClient,
+		tokens:     NewMemoryTokenStore(),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
+
+// BaseURL returns the URL of the API the client calls
+func (c *Client) BaseURL() string {
+	return c.baseURL.String()
+}
+
+// request describes a call to the API
+type request struct {
+	method        string
+	path          string
+	query         url.Values
+	header        http.Header
+	body          interface{}
+	authenticated bool
+
+	// etag receives the ETag of the response when set
+	etag *string
+}
+
+// do sends req and decodes the response body into out, which may be nil. Authenticated
+// requests rejected because of the token are retried once after logging in again when the
+// client has credentials.
+func (c *Client) do(ctx context.Context, req request, out interface{}) error {
+	var body []byte
+	if req.body != nil {
+		var err error
+		body, err = json.Marshal(req.body)
+		if err != nil {
+			return fmt.Errorf("cannot encode request: %w", err)
+		}
+	}
+
+	token, err := c.token(ctx, req.authenticated)
+	if err != nil {
+		return err
+	}
+
+	err = c.send(ctx, req, body, token, out)
+	if req.authenticated && c.credentials != nil && isTokenError(err) {
+		token, err = c.refresh(ctx, token)
+		if err != nil {
+			return err
+		}
+		err = c.send(ctx, req, body, token, out)
+	}
+	return err
+}
+
+func (c *Client) send(ctx context.Context, req request, body []byte, token string, out interface{}) error {
+	u := *c.baseURL
+	u.Path += req.path
+	u.RawQuery = req.query.Encode()
+
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, req.method, u.String(), reader)
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Accept", "application/json")
+	httpReq.Header.Set("User-Agent", userAgent)
+	if body != nil {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+token)
+	}
+	for key, values := range req.header {
+		httpReq.Header[key] = values
+	}
+
+	res, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return decodeError(res)
+	}
+	if req.etag != nil {
+		*req.etag = res.Header.Get("ETag")
+	}
+	if out == nil {
+		_, _ = io.Copy(io.Discard, res.Body)
+		return nil
+	}
+	if err := json.NewDecoder(res.Body).Decode(out); err != nil {
+		return fmt.Errorf("cannot decode response: %w", err)
+	}
+	return nil
+}
+
+// token returns the stored access token, logging in first when there is none
+func (c *Client) token(ctx context.Context, authenticated bool) (string, error) {
+	if !authenticated {
+		return "", nil
+	}
+
+	token, err := c.tokens.Load()
+	if err != nil && !errors.Is(err, ErrNoToken) {
+		return "", err
+	}
+	if token.AccessToken != "" || c.credentials == nil {
+		return token.AccessToken, nil
+	}
+	return c.refresh(ctx, "")
+}
+
+// refresh logs in again unless another request already replaced the rejected token
+func (c *Client) refresh(ctx context.Context, rejected string) (string, error) {
+	c.loginMu.Lock()
+	defer c.loginMu.Unlock()
+
+	if token, err := c.tokens.Load(); err == nil && token.AccessToken != "" && token.AccessToken != rejected {
+		return token.AccessToken, nil
+	}
+
+	session, err := c.Login(ctx, c.credentials.username, c.credentials.password)
+	if err != nil {
+		return "", err
+	}
+	return session.AccessToken, nil
+}
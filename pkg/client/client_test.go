@@ -0,0 +1,245 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	bookRecipeFactory "github.com/gmaschi/go-recipes-book/internal/factories/book-recipe-factory"
+	memoryStore "github.com/gmaschi/go-recipes-book/internal/mocks/datastore/memory"
+	"github.com/gmaschi/go-recipes-book/pkg/client"
+	"github.com/gmaschi/go-recipes-book/pkg/config/env"
+	"github.com/gmaschi/go-recipes-book/pkg/tools/problem"
+	"github.com/gmaschi/go-recipes-book/pkg/tools/random"
+	"github.com/stretchr/testify/require"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func newTestServer(t *testing.T) *httptest.Server {
+	config := env.Config{
+		Auth: env.AuthConfig{
+			TokenSymmetricKey: random.String(32),
+			TokenDuration:     time.Minute,
+		},
+		Idempotency: env.IdempotencyConfig{KeyTTL: time.Hour},
+	}
+	server, err := bookRecipeFactory.New(config, memoryStore.New())
+	require.NoError(t, err)
+
+	httpServer := httptest.NewServer(server.Router)
+	t.Cleanup(httpServer.Close)
+	return httpServer
+}
+
+func newAuthor(t *testing.T, c *client.Client) client.CreateAuthorRequest {
+	req := client.CreateAuthorRequest{
+		Username: random.String(10),
+		Password: random.String(8),
+		Email:    random.Email(),
+	}
+	author, err := c.CreateAuthor(context.Background(), req)
+	require.NoError(t, err)
+	require.Equal(t, req.Username, author.Username)
+	return req
+}
+
+func TestClient(t *testing.T) {
+	ctx := context.Background()
+	server := newTestServer(t)
+
+	c, err := client.New(server.URL)
+	require.NoError(t, err)
+	author := newAuthor(t, c)
+
+	t.Run("Authors", func(t *testing.T) {
+		got, err := c.GetAuthor(ctx, author.Username)
+		require.NoError(t, err)
+		require.Equal(t, author.Email, got.Email)
+
+		_, err = c.GetAuthor(ctx, "unknown")
+		require.True(t, client.IsNotFound(err))
+		require.Equal(t, problem.CodeAuthorNotFound, client.ErrorCode(err))
+
+		_, err = c.CreateAuthor(ctx, author)
+		require.Equal(t, problem.CodeAuthorUsernameTaken, client.ErrorCode(err))
+
+		_, err = c.CreateAuthor(ctx, client.CreateAuthorRequest{Username: "not valid!", Password: "abc"})
+		var apiErr *client.Error
+		require.True(t, errors.As(err, &apiErr))
+		require.Equal(t, problem.CodeInvalidRequest, apiErr.Code)
+		require.Len(t, apiErr.Errors, 3)
+	})
+
+	t.Run("Unauthenticated", func(t *testing.T) {
+		_, err := c.ListRecipes(ctx, client.ListOptions{})
+		require.Equal(t, problem.CodeMissingAuthorization, client.ErrorCode(err))
+
+		_, err = c.Login(ctx, author.Username, "wrong password")
+		require.Equal(t, problem.CodeInvalidCredentials, client.ErrorCode(err))
+	})
+
+	t.Run("Recipes", func(t *testing.T) {
+		session, err := c.Login(ctx, author.Username, author.Password)
+		require.NoError(t, err)
+		require.NotEmpty(t, session.AccessToken)
+		require.Equal(t, author.Username, session.Username)
+
+		var created []client.Recipe
+		for i := 0; i < 12; i++ {
+			recipe, err := c.CreateRecipe(ctx, client.CreateRecipeRequest{
+				Ingredients: []string{"ingredient " + strconv.Itoa(i)},
+				Steps:       []string{"step " + strconv.Itoa(i)},
+			})
+			require.NoError(t, err)
+			require.NotZero(t, recipe.ID)
+			require.Equal(t, author.Username, recipe.Author)
+			created = append(created, recipe)
+		}
+
+		page, err := c.ListRecipes(ctx, client.ListOptions{PageID: 2, PageSize: 5})
+		require.NoError(t, err)
+		require.Len(t, page, 5)
+		require.Equal(t, created[5].ID, page[0].ID)
+
+		var iterated []int64
+		it := c.Recipes(5)
+		for it.Next(ctx) {
+			iterated = append(iterated, it.Value().ID)
+		}
+		require.NoError(t, it.Err())
+		require.Len(t, iterated, len(created))
+		for i, recipe := range created {
+			require.Equal(t, recipe.ID, iterated[i])
+		}
+
+		withIngredient := c.RecipesWithIngredient("ingredient 7", 5)
+		require.True(t, withIngredient.Next(ctx))
+		require.Equal(t, created[7].ID, withIngredient.Value().ID)
+		require.False(t, withIngredient.Next(ctx))
+		require.NoError(t, withIngredient.Err())
+
+		require.NotEmpty(t, created[0].ETag)
+		updated, err := c.UpdateRecipe(ctx, client.UpdateRecipeRequest{ID: created[0].ID, Version: created[0].Version, Steps: []string{"new step"}, ETag: created[0].ETag})
+		require.NoError(t, err)
+		require.Equal(t, []string{"new step"}, updated.Steps)
+		require.Equal(t, created[0].Ingredients, updated.Ingredients)
+		require.NotEqual(t, created[0].ETag, updated.ETag)
+		require.Equal(t, created[0].Version+1, updated.Version)
+
+		got, err := c.GetRecipe(ctx, created[0].ID)
+		require.NoError(t, err)
+		require.Equal(t, updated.Steps, got.Steps)
+		require.Equal(t, updated.ETag, got.ETag)
+
+		// a stale client sends the ETag and version it read before the update
+		_, err = c.UpdateRecipe(ctx, client.UpdateRecipeRequest{ID: created[0].ID, Version: created[0].Version, Steps: []string{"lost update"}, ETag: created[0].ETag})
+		require.Equal(t, problem.CodePreconditionFailed, client.ErrorCode(err))
+		_, err = c.UpdateRecipe(ctx, client.UpdateRecipeRequest{ID: created[0].ID, Version: created[0].Version, Steps: []string{"lost update"}, ETag: updated.ETag})
+		require.Equal(t, problem.CodeVersionConflict, client.ErrorCode(err))
+		var conflict *client.Error
+		require.ErrorAs(t, err, &conflict)
+		require.Equal(t, float64(updated.Version), conflict.Current.(map[string]interface{})["version"])
+		_, err = c.UpdateRecipe(ctx, client.UpdateRecipeRequest{ID: created[0].ID, Version: updated.Version, Steps: []string{"blind update"}})
+		require.Equal(t, problem.CodeMissingPrecondition, client.ErrorCode(err))
+		_, err = c.UpdateRecipe(ctx, client.UpdateRecipeRequest{ID: created[0].ID, Version: created[0].Version, Steps: []string{"stale update"}, ETag: "*"})
+		require.Equal(t, problem.CodeVersionConflict, client.ErrorCode(err))
+
+		err = c.DeleteAuthor(ctx, author.Username)
+		require.Equal(t, problem.CodeAuthorHasRecipes, client.ErrorCode(err))
+
+		for _, recipe := range created {
+			require.NoError(t, c.DeleteRecipe(ctx, recipe.ID))
+		}
+		_, err = c.GetRecipe(ctx, created[0].ID)
+		require.Equal(t, problem.CodeRecipeNotFound, client.ErrorCode(err))
+	})
+
+	t.Run("Other author", func(t *testing.T) {
+		other := newAuthor(t, c)
+		otherClient, err := client.New(server.URL, client.WithCredentials(other.Username, other.Password))
+		require.NoError(t, err)
+
+		recipe, err := otherClient.CreateRecipe(ctx, client.CreateRecipeRequest{Ingredients: []string{"salt"}, Steps: []string{"mix"}})
+		require.NoError(t, err)
+
+		_, err = c.GetRecipe(ctx, recipe.ID)
+		require.Equal(t, problem.CodeRecipeNotOwned, client.ErrorCode(err))
+
+		req := client.CreateRecipeRequest{Ingredients: []string{"pepper"}, Steps: []string{"grind"}, IdempotencyKey: random.String(16)}
+		first, err := otherClient.CreateRecipe(ctx, req)
+		require.NoError(t, err)
+		retried, err := otherClient.CreateRecipe(ctx, req)
+		require.NoError(t, err)
+		require.Equal(t, first, retried)
+
+		req.Steps = []string{"crush"}
+		_, err = otherClient.CreateRecipe(ctx, req)
+		require.Equal(t, problem.CodeIdempotencyKeyReused, client.ErrorCode(err))
+
+		_, err = c.UpdateAuthor(ctx, client.UpdateAuthorRequest{Username: other.Username, Version: 1, Email: random.Email()})
+		require.Equal(t, problem.CodeAuthorNotAuthenticated, client.ErrorCode(err))
+	})
+
+	t.Run("Update and delete author", func(t *testing.T) {
+		current, err := c.GetAuthor(ctx, author.Username)
+		require.NoError(t, err)
+
+		email := random.Email()
+		updated, err := c.UpdateAuthor(ctx, client.UpdateAuthorRequest{Version: current.Version, Email: email})
+		require.NoError(t, err)
+		require.Equal(t, email, updated.Email)
+		require.Equal(t, current.Version+1, updated.Version)
+
+		_, err = c.UpdateAuthor(ctx, client.UpdateAuthorRequest{Version: current.Version, Email: random.Email()})
+		require.Equal(t, problem.CodeVersionConflict, client.ErrorCode(err))
+
+		require.NoError(t, c.DeleteAuthor(ctx, author.Username))
+		_, err = c.GetAuthor(ctx, author.Username)
+		require.True(t, client.IsNotFound(err))
+	})
+}
+
+func TestTokenRefresh(t *testing.T) {
+	ctx := context.Background()
+	server := newTestServer(t)
+
+	anonymous, err := client.New(server.URL)
+	require.NoError(t, err)
+	author := newAuthor(t, anonymous)
+
+	tokens := client.NewFileTokenStore(filepath.Join(t.TempDir(), "config", "token.json"))
+	require.NoError(t, tokens.Save(client.Token{AccessToken: "expired", Username: author.Username}))
+
+	c, err := client.New(server.URL,
+		client.WithTokenStore(tokens),
+		client.WithCredentials(author.Username, author.Password),
+	)
+	require.NoError(t, err)
+
+	recipes, err := c.ListRecipes(ctx, client.ListOptions{})
+	require.NoError(t, err)
+	require.Empty(t, recipes)
+
+	token, err := tokens.Load()
+	require.NoError(t, err)
+	require.NotEqual(t, "expired", token.AccessToken)
+	require.Equal(t, author.Username, token.Username)
+
+	require.NoError(t, c.Logout())
+	_, err = tokens.Load()
+	require.ErrorIs(t, err, client.ErrNoToken)
+
+	// logs in again when no token is stored
+	_, err = c.ListRecipes(ctx, client.ListOptions{})
+	require.NoError(t, err)
+}
+
+func TestNew(t *testing.T) {
+	_, err := client.New("localhost:8080")
+	require.Error(t, err)
+
+	_, err = client.New("http://localhost:8080/")
+	require.NoError(t, err)
+}
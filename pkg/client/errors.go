@@ -0,0 +1,79 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gmaschi/go-recipes-book/pkg/tools/problem"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// Error is an error response of the API. Its code is one of the problem.Code constants.
+type Error struct {
+	problem.Problem
+}
+
+func (e *Error) Error() string {
+	msg := fmt.Sprintf("%d %s", e.Status, http.StatusText(e.Status))
+	if e.Code != "" {
+		msg += ": " + e.Code
+	}
+	if e.Detail != "" {
+		msg += ": " + e.Detail
+	}
+	for _, field := range e.Errors {
+		msg += fmt.Sprintf("; %s %s", field.Field, field.Message)
+	}
+	return msg
+}
+
+// ErrorCode returns the code of the API error wrapped by err, or an empty string
+func ErrorCode(err error) string {
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		return apiErr.Code
+	}
+	return ""
+}
+
+// IsNotFound reports whether err is the API reporting a missing resource
+func IsNotFound(err error) bool {
+	var apiErr *Error
+	return errors.As(err, &apiErr) && apiErr.Status == http.StatusNotFound
+}
+
+// isTokenError reports whether err is the API rejecting the access token
+func isTokenError(err error) bool {
+	switch ErrorCode(err) {
+	case problem.CodeTokenExpired, problem.CodeTokenInvalid, problem.CodeMissingAuthorization:
+		return true
+	default:
+		return false
+	}
+}
+
+func decodeError(res *http.Response) error {
+	body, err := io.ReadAll(io.LimitReader(res.Body, maxErrorBody))
+	if err != nil {
+		return fmt.Errorf("cannot read error response: %w", err)
+	}
+
+	apiErr := &Error{}
+	mediaType, _, _ := mime.ParseMediaType(res.Header.Get("Content-Type"))
+	if mediaType == problem.ContentType && json.Unmarshal(body, &apiErr.Problem) == nil {
+		if apiErr.Status == 0 {
+			apiErr.Status = res.StatusCode
+		}
+		return apiErr
+	}
+
+	apiErr.Problem = problem.Problem{
+		Status: res.StatusCode,
+		Title:  http.StatusText(res.StatusCode),
+		Detail: strings.TrimSpace(string(body)),
+	}
+	return apiErr
+}
@@ -0,0 +1,95 @@
+package client
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+)
+
+// minPageSize is the smallest page the list routes accept
+const minPageSize = 1
+
+// Iterator walks a list page by page:
+//
+//	it := c.Recipes(client.MaxPageSize)
+//	for it.Next(ctx) {
+//		recipe := it.Value()
+//	}
+//	if err := it.Err(); err != nil {
+//		return err
+//	}
+type Iterator[T any] struct {
+	fetch   func(ctx context.Context, opts ListOptions) ([]T, error)
+	opts    ListOptions
+	items   []T
+	current T
+	last    bool
+	err     error
+}
+
+// newIterator creates a pointer to an Iterator fetching the pages of the list selected by
+// opts, starting from the first one
+func newIterator[T any](opts ListOptions, fetch func(ctx context.Context, opts ListOptions) ([]T, error)) *Iterator[T] {
+	if opts.PageSize < minPageSize || opts.PageSize > MaxPageSize {
+		opts.PageSize = MaxPageSize
+	}
+	opts.PageID = 0
+	return &Iterator[T]{
+		fetch: fetch,
+		opts:  opts,
+	}
+}
+
+// Next advances to the next item, fetching the next page when needed. It returns false at
+// the end of the list or on error.
+func (it *Iterator[T]) Next(ctx context.Context) bool {
+	if it.err != nil {
+		return false
+	}
+
+	if len(it.items) == 0 {
+		if it.last {
+			return false
+		}
+		it.opts.PageID++
+		it.items, it.err = it.fetch(ctx, it.opts)
+		if it.err != nil {
+			return false
+		}
+		it.last = int32(len(it.items)) < it.opts.PageSize
+		if len(it.items) == 0 {
+			return false
+		}
+	}
+
+	it.current, it.items = it.items[0], it.items[1:]
+	return true
+}
+
+// Value returns the current item
+func (it *Iterator[T]) Value() T {
+	return it.current
+}
+
+// Err returns the error that stopped the iteration, if any
+func (it *Iterator[T]) Err() error {
+	return it.err
+}
+
+func (opts ListOptions) values() url.Values {
+	pageID, pageSize := opts.PageID, opts.PageSize
+	if pageID < 1 {
+		pageID = 1
+	}
+	if pageSize == 0 {
+		pageSize = MaxPageSize
+	}
+
+	values := url.Values{}
+	values.Set("page_id", strconv.Itoa(int(pageID)))
+	values.Set("page_size", strconv.Itoa(int(pageSize)))
+	if opts.Ingredient != "" {
+		values.Set("has_ingredient", opts.Ingredient)
+	}
+	return values
+}
@@ -0,0 +1,89 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+)
+
+// CreateRecipe creates a recipe of the authenticated author. It fails with the
+// problem.CodeIdempotencyKeyReused code when req.IdempotencyKey was sent with other fields.
+func (c *Client) CreateRecipe(ctx context.Context, req CreateRecipeRequest) (Recipe, error) {
+	var header http.Header
+	if req.IdempotencyKey != "" {
+		header = http.Header{"Idempotency-Key": {req.IdempotencyKey}}
+	}
+
+	var recipe Recipe
+	err := c.do(ctx, request{
+		method:        http.MethodPost,
+		path:          "/recipes",
+		header:        header,
+		body:          req,
+		authenticated: true,
+		etag:          &recipe.ETag,
+	}, &recipe)
+	return recipe, err
+}
+
+// GetRecipe returns a recipe of the authenticated author
+func (c *Client) GetRecipe(ctx context.Context, id int64) (Recipe, error) {
+	var recipe Recipe
+	err := c.do(ctx, request{
+		method:        http.MethodGet,
+		path:          "/recipes/" + strconv.FormatInt(id, 10),
+		authenticated: true,
+		etag:          &recipe.ETag,
+	}, &recipe)
+	return recipe, err
+}
+
+// ListRecipes returns a page of the recipes of the authenticated author ordered by ID
+func (c *Client) ListRecipes(ctx context.Context, opts ListOptions) ([]Recipe, error) {
+	var recipes []Recipe
+	err := c.do(ctx, request{
+		method:        http.MethodGet,
+		path:          "/recipes",
+		query:         opts.values(),
+		authenticated: true,
+	}, &recipes)
+	return recipes, err
+}
+
+// Recipes iterates over every recipe of the authenticated author, fetching them pageSize
+// at a time
+func (c *Client) Recipes(pageSize int32) *Iterator[Recipe] {
+	return newIterator(ListOptions{PageSize: pageSize}, c.ListRecipes)
+}
+
+// RecipesWithIngredient iterates over the recipes of the authenticated author having the
+// given ingredient, which the server filters, fetching them pageSize at a time
+func (c *Client) RecipesWithIngredient(ingredient string, pageSize int32) *Iterator[Recipe] {
+	return newIterator(ListOptions{PageSize: pageSize, Ingredient: ingredient}, c.ListRecipes)
+}
+
+// UpdateRecipe updates a recipe of the authenticated author. It fails with the
+// problem.CodePreconditionFailed code when the recipe changed since req.ETag was read, and
+// with the problem.CodeVersionConflict code, the error holding the current recipe, when it
+// changed since req.Version was read.
+func (c *Client) UpdateRecipe(ctx context.Context, req UpdateRecipeRequest) (Recipe, error) {
+	var recipe Recipe
+	err := c.do(ctx, request{
+		method:        http.MethodPatch,
+		path:          "/recipes",
+		header:        http.Header{"If-Match": {req.ETag}},
+		body:          req,
+		authenticated: true,
+		etag:          &recipe.ETag,
+	}, &recipe)
+	return recipe, err
+}
+
+// DeleteRecipe deletes a recipe of the authenticated author
+func (c *Client) DeleteRecipe(ctx context.Context, id int64) error {
+	return c.do(ctx, request{
+		method:        http.MethodDelete,
+		path:          "/recipes/" + strconv.FormatInt(id, 10),
+		authenticated: true,
+	}, nil)
+}
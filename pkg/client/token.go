@@ -0,0 +1,113 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// ErrNoToken is returned by the token stores holding no access token
+var ErrNoToken = errors.New("no access token stored")
+
+type (
+	// Token is an access token and the author it was issued to
+	Token struct {
+		AccessToken string `json:"access_token"`
+		Username    string `json:"username"`
+	}
+
+	// TokenStore keeps the access token between requests
+	TokenStore interface {
+		Load() (Token, error)
+		Save(token Token) error
+		Clear() error
+	}
+
+	// MemoryTokenStore keeps the access token in memory
+	MemoryTokenStore struct {
+		mu    sync.Mutex
+		token Token
+	}
+
+	// FileTokenStore keeps the access token in a JSON file readable by its owner only, so
+	// that it survives between processes
+	FileTokenStore struct {
+		path string
+	}
+)
+
+// NewMemoryTokenStore creates a pointer to an empty MemoryTokenStore
+func NewMemoryTokenStore() *MemoryTokenStore {
+	return &MemoryTokenStore{}
+}
+
+func (s *MemoryTokenStore) Load() (Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token.AccessToken == "" {
+		return Token{}, ErrNoToken
+	}
+	return s.token, nil
+}
+
+func (s *MemoryTokenStore) Save(token Token) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.token = token
+	return nil
+}
+
+func (s *MemoryTokenStore) Clear() error {
+	return s.Save(Token{})
+}
+
+// NewFileTokenStore creates a pointer to a FileTokenStore writing to path
+func NewFileTokenStore(path string) *FileTokenStore {
+	return &FileTokenStore{path: path}
+}
+
+func (s *FileTokenStore) Load() (Token, error) {
+	content, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return Token{}, ErrNoToken
+	}
+	if err != nil {
+		return Token{}, fmt.Errorf("cannot read token file: %w", err)
+	}
+
+	var token Token
+	if err := json.Unmarshal(content, &token); err != nil {
+		return Token{}, fmt.Errorf("%s: %w", s.path, err)
+	}
+	if token.AccessToken == "" {
+		return Token{}, ErrNoToken
+	}
+	return token, nil
+}
+
+func (s *FileTokenStore) Save(token Token) error {
+	content, err := json.MarshalIndent(token, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return fmt.Errorf("cannot create token directory: %w", err)
+	}
+	if err := os.WriteFile(s.path, content, 0o600); err != nil {
+		return fmt.Errorf("cannot write token file: %w", err)
+	}
+	return nil
+}
+
+func (s *FileTokenStore) Clear() error {
+	err := os.Remove(s.path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("cannot remove token file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package client
+
+import "time"
+
+// MaxPageSize is the largest page the list routes return
+const MaxPageSize = 100
+
+type (
+	// Author is an author account
+	Author struct {
+		Username  string    `json:"username"`
+		Email     string    `json:"email,omitempty"`
+		CreatedAt time.Time `json:"created_at"`
+		UpdatedAt time.Time `json:"updated_at,omitempty"`
+		Version   int32     `json:"version"`
+	}
+
+	// Recipe is a recipe of an author
+	Recipe struct {
+		ID          int64     `json:"id"`
+		Author      string    `json:"author"`
+		Ingredients []string  `json:"ingredients"`
+		Steps       []string  `json:"steps"`
+		CreatedAt   time.Time `json:"created_at"`
+		UpdatedAt   time.Time `json:"updated_at,omitempty"`
+		Version     int32     `json:"version"`
+
+		// ETag identifies the version of the recipe returned by the API, to send back when
+		// updating it
+		ETag string `json:"-"`
+	}
+
+	// Session is the result of a login
+	Session struct {
+		AccessToken string `json:"access_token"`
+		Author
+	}
+
+	// CreateAuthorRequest holds the fields of a new author
+	CreateAuthorRequest struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+		Email    string `json:"email"`
+	}
+
+	// UpdateAuthorRequest holds the new email and/or password of the authenticated author.
+	// Empty fields are left unchanged. Version is the Author.Version being updated.
+	UpdateAuthorRequest struct {
+		Username string `json:"username"`
+		Version  int32  `json:"version"`
+		Email    string `json:"email,omitempty"`
+		Password string `json:"password,omitempty"`
+	}
+
+	// CreateRecipeRequest holds the fields of a new recipe. When IdempotencyKey is set,
+	// retrying the request with the same key returns the recipe it created instead of
+	// creating another one.
+	CreateRecipeRequest struct {
+		Ingredients    []string `json:"ingredients"`
+		Steps          []string `json:"steps"`
+		IdempotencyKey string   `json:"-"`
+	}
+
+	// UpdateRecipeRequest holds the new ingredients and/or steps of a recipe. Empty fields
+	// are left unchanged. ETag and Version are the Recipe.ETag and Recipe.Version being
+	// updated, ETag being * to skip the ETag check.
+	UpdateRecipeRequest struct {
+		ID          int64    `json:"id"`
+		Version     int32    `json:"version"`
+		Ingredients []string `json:"ingredients,omitempty"`
+		Steps       []string `json:"steps,omitempty"`
+		ETag        string   `json:"-"`
+	}
+
+	// ListOptions selects a page of a list
+	ListOptions struct {
+		PageID   int32
+		PageSize int32
+		// Ingredient keeps the recipes having this exact ingredient. It is ignored by the
+		// author lists.
+		Ingredient string
+	}
+)
@@ -0,0 +1,102 @@
+package env
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// findEnvFile walks up from dir looking for EnvFileName and returns its path, or an empty
+// string when no env file exists in dir or any of its parents
+func findEnvFile(dir string) string {
+	for {
+		path := filepath.Join(dir, EnvFileName)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// parseDotEnv parses the content of an env file.
+//
+// Blank lines and lines starting with # are ignored, an optional "export " prefix is
+// accepted, values may be wrapped in single or double quotes and unquoted values may be
+// followed by an inline # comment. Double-quoted values support the \n, \t, \" and \\ escapes.
+func parseDotEnv(content string) (map[string]string, error) {
+	values := make(map[string]string)
+
+	for i, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		split := strings.SplitN(line, "=", 2)
+		if len(split) != 2 {
+			return nil, fmt.Errorf("line %d: expected KEY=VALUE", i+1)
+		}
+
+		key := strings.TrimSpace(split[0])
+		if key == "" || strings.ContainsAny(key, " \t") {
+			return nil, fmt.Errorf("line %d: invalid key %q", i+1, key)
+		}
+
+		value, err := parseDotEnvValue(strings.TrimSpace(split[1]))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		values[key] = value
+	}
+
+	return values, nil
+}
+
+func parseDotEnvValue(raw string) (string, error) {
+	if raw == "" {
+		return "", nil
+	}
+
+	quote := raw[0]
+	if quote != '"' && quote != '\'' {
+		if idx := strings.Index(raw, " #"); idx >= 0 {
+			raw = raw[:idx]
+		}
+		return strings.TrimSpace(raw), nil
+	}
+
+	end := -1
+	for i := 1; i < len(raw); i++ {
+		if quote == '"' && raw[i] == '\\' {
+			i++
+			continue
+		}
+		if raw[i] == quote {
+			end = i
+			break
+		}
+	}
+	if end < 0 {
+		return "", fmt.Errorf("unterminated quoted value")
+	}
+
+	rest := strings.TrimSpace(raw[end+1:])
+	if rest != "" && !strings.HasPrefix(rest, "#") {
+		return "", fmt.Errorf("unexpected characters after quoted value")
+	}
+
+	value := raw[1:end]
+	if quote == '\'' {
+		return value, nil
+	}
+
+	replacer := strings.NewReplacer(`\n`, "\n", `\t`, "\t", `\"`, `"`, `\\`, `\`)
+	return replacer.Replace(value), nil
+}
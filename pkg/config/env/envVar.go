@@ -1,134 +1,223 @@
 package env
 
 import (
-	"encoding/json"
+	"flag"
 	"fmt"
 	"os"
-	"strings"
+	"time"
 )
 
-type Config struct {
-	DbDriver          string `json:"DB_DRIVER"`
-	DbSource          string `json:"DB_SOURCE"`
-	ServerAddress     string `json:"SERVER_ADDRESS"`
-	TokenSymmetricKey string `json:"TOKEN_SYMMETRIC_KEY"`
-	TokenDuration     int    `json:"TOKEN_DURATION,string"`
-}
+const (
+	// EnvFileName is the name of the env file looked up from the working directory upwards
+	EnvFileName = "app.env"
 
-func NewConfig() (Config, error) {
-	pwd, err := currDir()
-	if err != nil {
-		return Config{}, err
-	}
-	dirSeparator := findDirSeparator(pwd)
+	// EnvFileVar is the environment variable that points to an explicit env file
+	EnvFileVar = "ENV_FILE"
 
-	dirWithEnv, err := findDirWithEnv(pwd, dirSeparator)
-	if err != nil {
-		return Config{}, err
+	// ConfigFileVar is the environment variable that points to a YAML or TOML config file
+	ConfigFileVar = "CONFIG_FILE"
+
+	// ProfileVar is the environment variable that selects a profile of the config file
+	ProfileVar = "APP_PROFILE"
+)
+
+type (
+	// Config holds the application configuration.
+	//
+	// Each field is resolved, from lowest to highest precedence, from its default value,
+	// the config file (and its selected profile), the env file, the process environment and
+	// finally the command-line flags. The config tags give the path of a field in the
+	// config file, the env tags its variable name everywhere else.
+	Config struct {
+		Database    DatabaseConfig    `config:"database"`
+		HTTP        HTTPConfig        `config:"http"`
+		GRPC        GRPCConfig        `config:"grpc"`
+		Auth        AuthConfig        `config:"auth"`
+		Idempotency IdempotencyConfig `config:"idempotency"`
+		RateLimit   RateLimitConfig   `config:"rate_limit"`
+		Mail        MailConfig        `config:"mail"`
+		Logging     LoggingConfig     `config:"logging"`
+		Tracing     TracingConfig     `config:"tracing"`
 	}
 
-	envStruct, err := readEnvContent(dirWithEnv)
-	if err != nil {
-		return Config{}, err
+	// DatabaseConfig holds the database connection and pool settings
+	DatabaseConfig struct {
+		Driver            string        `config:"driver" env:"DB_DRIVER" flag:"db-driver" default:"postgres" required:"true" usage:"database driver name"`
+		Source            string        `config:"source" env:"DB_SOURCE" flag:"db-source" required:"true" secret:"true" usage:"database connection string"`
+		MaxOpenConns      int           `config:"max_open_conns" env:"DB_MAX_OPEN_CONNS" flag:"db-max-open-conns" default:"25" usage:"maximum number of open connections"`
+		MaxIdleConns      int           `config:"max_idle_conns" env:"DB_MAX_IDLE_CONNS" flag:"db-max-idle-conns" default:"25" usage:"maximum number of idle connections"`
+		ConnMaxLifetime   time.Duration `config:"conn_max_lifetime" env:"DB_CONN_MAX_LIFETIME" flag:"db-conn-max-lifetime" default:"30m" usage:"maximum lifetime of a connection"`
+		ConnMaxIdleTime   time.Duration `config:"conn_max_idle_time" env:"DB_CONN_MAX_IDLE_TIME" flag:"db-conn-max-idle-time" default:"5m" usage:"maximum idle time of a connection"`
+		ConnectTimeout    time.Duration `config:"connect_timeout" env:"DB_CONNECT_TIMEOUT" flag:"db-connect-timeout" default:"60s" usage:"maximum duration to wait for the database on startup"`
+		ConnectBackoff    time.Duration `config:"connect_backoff" env:"DB_CONNECT_BACKOFF" flag:"db-connect-backoff" default:"500ms" usage:"initial delay between startup connection attempts"`
+		ConnectMaxBackoff time.Duration `config:"connect_max_backoff" env:"DB_CONNECT_MAX_BACKOFF" flag:"db-connect-max-backoff" default:"10s" usage:"maximum delay between startup connection attempts"`
+		AutoMigrate       bool          `config:"auto_migrate" env:"AUTO_MIGRATE" flag:"auto-migrate" default:"false" usage:"apply pending migrations on startup"`
 	}
 
-	return envStruct, nil
-}
+	// HTTPConfig holds the HTTP server settings
+	HTTPConfig struct {
+		Address           string        `config:"address" env:"SERVER_ADDRESS" flag:"server-address" default:"0.0.0.0:8080" required:"true" usage:"address the HTTP server listens on"`
+		ReadTimeout       time.Duration `config:"read_timeout" env:"HTTP_READ_TIMEOUT" flag:"http-read-timeout" default:"15s" usage:"maximum duration for reading a request"`
+		ReadHeaderTimeout time.Duration `config:"read_header_timeout" env:"HTTP_READ_HEADER_TIMEOUT" flag:"http-read-header-timeout" default:"5s" usage:"maximum duration for reading request headers"`
+		WriteTimeout      time.Duration `config:"write_timeout" env:"HTTP_WRITE_TIMEOUT" flag:"http-write-timeout" default:"15s" usage:"maximum duration before timing out writes of a response"`
+		IdleTimeout       time.Duration `config:"idle_timeout" env:"HTTP_IDLE_TIMEOUT" flag:"http-idle-timeout" default:"60s" usage:"maximum keep-alive idle time"`
+		ShutdownDelay     time.Duration `config:"shutdown_delay" env:"HTTP_SHUTDOWN_DELAY" flag:"http-shutdown-delay" default:"0s" usage:"duration to keep serving with a failing readiness probe before draining"`
+		ShutdownTimeout   time.Duration `config:"shutdown_timeout" env:"HTTP_SHUTDOWN_TIMEOUT" flag:"http-shutdown-timeout" default:"20s" usage:"maximum duration to drain connections on shutdown"`
+		TrustedProxies    string        `config:"trusted_proxies" env:"HTTP_TRUSTED_PROXIES" flag:"http-trusted-proxies" usage:"comma separated IP addresses or CIDR ranges of the proxies trusted to set X-Forwarded-For, none by default"`
+	}
 
-func currDir() (string, error) {
-	wd, err := os.Getwd()
-	if err != nil {
-		return "", err
+	// GRPCConfig holds the gRPC server settings. The server shares the HTTP shutdown settings.
+	GRPCConfig struct {
+		Enabled bool   `config:"enabled" env:"GRPC_ENABLED" flag:"grpc-enabled" default:"false" usage:"serve the gRPC API"`
+		Address string `config:"address" env:"GRPC_ADDRESS" flag:"grpc-address" default:"0.0.0.0:9090" usage:"address the gRPC server listens on"`
 	}
-	return wd, nil
-}
 
-func findDirSeparator(dir string) string {
-	var separator string
+	// AuthConfig holds the access token settings
+	AuthConfig struct {
+		TokenSymmetricKey string        `config:"token_symmetric_key" env:"TOKEN_SYMMETRIC_KEY" flag:"token-symmetric-key" required:"true" secret:"true" usage:"32 characters key used to sign access tokens"`
+		TokenDuration     time.Duration `config:"token_duration" env:"TOKEN_DURATION" flag:"token-duration" default:"15m" required:"true" bareUnit:"m" usage:"access token lifetime, e.g. 15m"`
+	}
 
-	if strings.Contains(dir, "/") {
-		separator = "/"
+	// IdempotencyConfig holds the settings of the Idempotency-Key header
+	IdempotencyConfig struct {
+		KeyTTL        time.Duration `config:"key_ttl" env:"IDEMPOTENCY_KEY_TTL" flag:"idempotency-key-ttl" default:"24h" usage:"duration an idempotency key replays its response before it can be reused"`
+		PurgeInterval time.Duration `config:"purge_interval" env:"IDEMPOTENCY_PURGE_INTERVAL" flag:"idempotency-purge-interval" default:"1h" usage:"interval between deletions of the expired idempotency keys, 0 disables them"`
 	}
-	if strings.Contains(dir, "\\") {
-		separator = "\\"
+
+	// RateLimitConfig holds the per-route limits of the requests of each client, identified
+	// by username on the authenticated routes and by IP address on the others
+	RateLimitConfig struct {
+		Routes        string        `config:"routes" env:"RATE_LIMIT_ROUTES" flag:"rate-limit-routes" default:"POST /authors/login=10/m,POST /authors=20/h,POST /recipes=60/m,POST /graphql=120/m" allowEmpty:"true" usage:"comma separated route limits as METHOD /path=REQUESTS/PERIOD, e.g. POST /recipes=60/m, shared by the gRPC methods and GraphQL mutations mirroring the routes, set empty to disable rate limiting"`
+		Backend       string        `config:"backend" env:"RATE_LIMIT_BACKEND" flag:"rate-limit-backend" default:"memory" usage:"store of the rate limits: memory, per instance, or redis, shared by the instances"`
+		RedisAddress  string        `config:"redis_address" env:"RATE_LIMIT_REDIS_ADDRESS" flag:"rate-limit-redis-address" default:"localhost:6379" usage:"host:port of the Redis compatible server of the redis backend"`
+		RedisPassword string        `config:"redis_password" env:"RATE_LIMIT_REDIS_PASSWORD" flag:"rate-limit-redis-password" secret:"true" usage:"password of the Redis compatible server"`
+		RedisPoolSize int           `config:"redis_pool_size" env:"RATE_LIMIT_REDIS_POOL_SIZE" flag:"rate-limit-redis-pool-size" default:"10" usage:"maximum number of connections to the Redis compatible server"`
+		RedisTimeout  time.Duration `config:"redis_timeout" env:"RATE_LIMIT_REDIS_TIMEOUT" flag:"rate-limit-redis-timeout" default:"500ms" usage:"maximum duration of a call to the Redis compatible server"`
 	}
 
-	return separator
-}
+	// MailConfig holds the outgoing mail settings
+	MailConfig struct {
+		Host     string `config:"host" env:"MAIL_HOST" flag:"mail-host" usage:"SMTP server host"`
+		Port     int    `config:"port" env:"MAIL_PORT" flag:"mail-port" default:"587" usage:"SMTP server port"`
+		Username string `config:"username" env:"MAIL_USERNAME" flag:"mail-username" usage:"SMTP username"`
+		Password string `config:"password" env:"MAIL_PASSWORD" flag:"mail-password" secret:"true" usage:"SMTP password"`
+		From     string `config:"from" env:"MAIL_FROM" flag:"mail-from" usage:"sender address of outgoing mails"`
+	}
 
-func searchEnvFile(dir string) (string, error) {
-	de, err := os.ReadDir(dir)
-	if err != nil {
-		return "", err
+	// LoggingConfig holds the logger settings
+	LoggingConfig struct {
+		Level  string `config:"level" env:"LOG_LEVEL" flag:"log-level" default:"info" usage:"minimum log level: debug, info, warn or error"`
+		Format string `config:"format" env:"LOG_FORMAT" flag:"log-format" default:"json" usage:"log format: json or text"`
 	}
 
-	for _, e := range de {
-		if strings.HasSuffix(e.Name(), ".env") {
-			return e.Name(), nil
-		}
+	// TracingConfig holds the OpenTelemetry tracing settings
+	TracingConfig struct {
+		Exporter     string  `config:"exporter" env:"TRACING_EXPORTER" flag:"tracing-exporter" default:"none" usage:"span exporter: none, stdout (written to stderr) or otlp"`
+		OTLPEndpoint string  `config:"otlp_endpoint" env:"TRACING_OTLP_ENDPOINT" flag:"tracing-otlp-endpoint" default:"localhost:4318" usage:"host:port of the OTLP/HTTP collector"`
+		OTLPInsecure bool    `config:"otlp_insecure" env:"TRACING_OTLP_INSECURE" flag:"tracing-otlp-insecure" default:"false" usage:"send spans to the collector over plain HTTP"`
+		ServiceName  string  `config:"service_name" env:"TRACING_SERVICE_NAME" flag:"tracing-service-name" default:"recipes-book" usage:"service name reported with the spans"`
+		SampleRatio  float64 `config:"sample_ratio" env:"TRACING_SAMPLE_RATIO" flag:"tracing-sample-ratio" default:"1" usage:"ratio of the root spans sampled, between 0 and 1"`
 	}
+)
 
-	return "", nil
+// NewConfig loads the configuration from the config file, the env file and the process environment
+func NewConfig() (Config, error) {
+	return Load(nil)
 }
 
-func findDirWithEnv(dir, separator string) (string, error) {
-	var (
-		currDir, env   string
-		separatorIndex int
-		err            error
-	)
+// Load loads the configuration from the config file, the env file, the process environment
+// and the given command-line arguments, in increasing order of precedence
+func Load(args []string) (Config, error) {
+	fs := flag.NewFlagSet("config", flag.ContinueOnError)
+	return LoadFlagSet(fs, args)
+}
 
-	currDir = dir
-	numberOfSeparators := strings.Count(dir, separator)
+// LoadFlagSet is like Load but registers the configuration flags on fs, so that callers can
+// add flags of their own before the arguments are parsed
+func LoadFlagSet(fs *flag.FlagSet, args []string) (Config, error) {
+	configFile := fs.String("config", "", "path to a YAML or TOML config file (defaults to $"+ConfigFileVar+")")
+	profile := fs.String("profile", "", "config file profile to use (defaults to $"+ProfileVar+")")
+	envFile := fs.String("env-file", "", "path to the env file (defaults to $"+EnvFileVar+" or the nearest "+EnvFileName+")")
+	flagValues := registerFlags(fs)
 
-	for i := 0; i < numberOfSeparators; i++ {
-		env, err = searchEnvFile(currDir)
-		if err != nil {
-			return "", err
-		}
-		if env != "" {
-			return currDir, nil
+	if err := fs.Parse(args); err != nil {
+		return Config{}, err
+	}
+
+	if *profile == "" {
+		*profile = os.Getenv(ProfileVar)
+	}
+	values, err := readConfigFile(*configFile, *profile)
+	if err != nil {
+		return Config{}, err
+	}
+
+	envValues, err := readEnvFile(*envFile)
+	if err != nil {
+		return Config{}, err
+	}
+	for k, v := range envValues {
+		values[k] = v
+	}
+
+	for _, f := range configFields() {
+		if v, ok := os.LookupEnv(f.env); ok {
+			values[f.env] = v
 		}
-		separatorIndex = strings.LastIndex(currDir, separator)
-		currDir = currDir[:separatorIndex]
 	}
 
-	err = fmt.Errorf("could not find .env file")
-	return "", err
+	fs.Visit(func(fl *flag.Flag) {
+		if v, ok := flagValues[fl.Name]; ok {
+			values[v.env] = v.value.value
+		}
+	})
+
+	return FromValues(values)
 }
 
-func readEnvContent(dwe string) (Config, error) {
-	var content []byte
-	envStruct := Config{}
+// FromValues builds a Config from a map of variable names to raw values, applying defaults
+// and validating the result
+func FromValues(values map[string]string) (Config, error) {
+	var config Config
+	if err := decode(&config, values); err != nil {
+		return Config{}, err
+	}
+	if err := config.Validate(); err != nil {
+		return Config{}, err
+	}
+	return config, nil
+}
 
-	dirEntries, err := os.ReadDir(dwe)
-	if err != nil {
-		return envStruct, err
+// String implements fmt.Stringer and never prints secret values
+func (c Config) String() string {
+	type plain Config
+	return fmt.Sprintf("%+v", plain(c.Redacted()))
+}
+
+func readEnvFile(path string) (map[string]string, error) {
+	if path == "" {
+		path = os.Getenv(EnvFileVar)
 	}
 
-	for _, entry := range dirEntries {
-		if strings.Contains(entry.Name(), ".env") {
-			content, err = os.ReadFile(dwe + "/" + entry.Name())
-			if err != nil {
-				return envStruct, err
-			}
+	if path == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return nil, err
 		}
-	}
-	ss := strings.Fields(string(content))
-	transformedSlice := make([]string, len(ss))
-	for i, s := range ss {
-		split := strings.SplitN(s, "=", 2)
-		if len(split) != 2 {
-			return envStruct, err
+		path = findEnvFile(wd)
+		if path == "" {
+			return map[string]string{}, nil
 		}
-		transformedSlice[i] = fmt.Sprintf(`"%v":"%v"`,split[0], split[1])
 	}
-	transformedString := fmt.Sprintf(`{%v}`, strings.Join(transformedSlice, ","))
 
-	err = json.Unmarshal([]byte(transformedString), &envStruct)
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read env file: %w", err)
+	}
+
+	values, err := parseDotEnv(string(content))
 	if err != nil {
-		return envStruct, err
+		return nil, fmt.Errorf("%s: %w", path, err)
 	}
-	fmt.Println(envStruct)
-	return envStruct, nil
+	return values, nil
 }
@@ -0,0 +1,253 @@
+package env
+
+import (
+	"flag"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const redactedValue = "[REDACTED]"
+
+// maskedValue replaces the passwords of the connection URLs
+const maskedValue = "xxxxx"
+
+// secretParams are the query parameters of connection URLs holding secrets, such as the
+// password and sslpassword parameters of lib/pq
+var secretParams = []string{"password", "sslpassword", "passwd", "pwd", "secret", "token"}
+
+type (
+	// field describes a Config field through its struct tags
+	field struct {
+		index    []int
+		path     string
+		kind     reflect.Kind
+		env      string
+		flag     string
+		def      string
+		usage    string
+		required bool
+		secret   bool
+		// allowEmpty keeps a value explicitly set empty, which otherwise falls back to the
+		// default like a missing one
+		allowEmpty bool
+		// bareUnit is the unit of the unit-less values of a duration field, which are
+		// rejected when it is empty
+		bareUnit string
+	}
+
+	flagValue struct {
+		env   string
+		value *stringFlag
+	}
+
+	// stringFlag is a flag.Value keeping the raw argument, which is decoded with the other
+	// sources. Boolean fields accept the -flag form without a value.
+	stringFlag struct {
+		value  string
+		isBool bool
+	}
+
+	// MissingError is returned when required configuration variables are not set
+	MissingError struct {
+		Vars []string
+	}
+)
+
+func (e *MissingError) Error() string {
+	return fmt.Sprintf("missing required configuration: %s", strings.Join(e.Vars, ", "))
+}
+
+// configFields lists the fields of Config that can be loaded
+func configFields() []field {
+	return structFields(reflect.TypeOf(Config{}), nil, "")
+}
+
+func structFields(t reflect.Type, index []int, prefix string) []field {
+	var fields []field
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		idx := append(append([]int{}, index...), i)
+		path := prefix + sf.Tag.Get("config")
+
+		if sf.Type.Kind() == reflect.Struct {
+			fields = append(fields, structFields(sf.Type, idx, path+".")...)
+			continue
+		}
+
+		name, ok := sf.Tag.Lookup("env")
+		if !ok {
+			continue
+		}
+		fields = append(fields, field{
+			index:      idx,
+			path:       path,
+			kind:       sf.Type.Kind(),
+			env:        name,
+			flag:       sf.Tag.Get("flag"),
+			def:        sf.Tag.Get("default"),
+			usage:      sf.Tag.Get("usage"),
+			required:   sf.Tag.Get("required") == "true",
+			secret:     sf.Tag.Get("secret") == "true",
+			allowEmpty: sf.Tag.Get("allowEmpty") == "true",
+			bareUnit:   sf.Tag.Get("bareUnit"),
+		})
+	}
+	return fields
+}
+
+func (s *stringFlag) String() string {
+	if s == nil {
+		return ""
+	}
+	return s.value
+}
+
+func (s *stringFlag) Set(value string) error {
+	s.value = value
+	return nil
+}
+
+func (s *stringFlag) IsBoolFlag() bool {
+	return s.isBool
+}
+
+func registerFlags(fs *flag.FlagSet) map[string]flagValue {
+	values := make(map[string]flagValue)
+	for _, f := range configFields() {
+		if f.flag == "" {
+			continue
+		}
+		value := &stringFlag{isBool: f.kind == reflect.Bool}
+		fs.Var(value, f.flag, fmt.Sprintf("%s (env %s)", f.usage, f.env))
+		values[f.flag] = flagValue{
+			env:   f.env,
+			value: value,
+		}
+	}
+	return values
+}
+
+// decode sets every field of config from values, falling back to the field default when a
+// value is missing, or empty and the field does not allow it
+func decode(config *Config, values map[string]string) error {
+	var missing []string
+	rv := reflect.ValueOf(config).Elem()
+
+	for _, f := range configFields() {
+		raw, ok := values[f.env]
+		if !ok || (raw == "" && !f.allowEmpty) {
+			raw = f.def
+		}
+		if raw == "" {
+			if f.required {
+				missing = append(missing, f.env)
+			}
+			continue
+		}
+
+		if err := setValue(rv.FieldByIndex(f.index), raw, f.bareUnit); err != nil {
+			return fmt.Errorf("invalid value for %s: %w", f.env, err)
+		}
+	}
+
+	if len(missing) > 0 {
+		return &MissingError{Vars: missing}
+	}
+	return nil
+}
+
+func setValue(v reflect.Value, raw, bareUnit string) error {
+	switch v.Interface().(type) {
+	case time.Duration:
+		d, err := parseDuration(raw, bareUnit)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+// parseDuration parses a Go duration. A bare integer is read in bareUnit, which keeps the
+// env files written before durations were typed working, and is rejected when bareUnit is
+// empty since its unit would be a guess.
+func parseDuration(raw, bareUnit string) (time.Duration, error) {
+	if _, err := strconv.Atoi(raw); err == nil {
+		if bareUnit == "" {
+			return 0, fmt.Errorf("missing unit in duration %q", raw)
+		}
+		raw += bareUnit
+	}
+	return time.ParseDuration(raw)
+}
+
+// Redacted returns a copy of the config with every secret value masked
+func (c Config) Redacted() Config {
+	rv := reflect.ValueOf(&c).Elem()
+	for _, f := range configFields() {
+		if !f.secret {
+			continue
+		}
+		v := rv.FieldByIndex(f.index)
+		if v.Kind() == reflect.String && v.String() != "" {
+			v.SetString(redact(v.String()))
+		}
+	}
+	return c
+}
+
+// redact masks a secret value, keeping the non-sensitive parts of connection URLs readable
+func redact(value string) string {
+	u, err := url.Parse(value)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return redactedValue
+	}
+	if _, ok := u.User.Password(); ok {
+		u.User = url.UserPassword(u.User.Username(), maskedValue)
+	}
+
+	query := u.Query()
+	masked := false
+	for key := range query {
+		for _, param := range secretParams {
+			if strings.EqualFold(key, param) {
+				query.Set(key, maskedValue)
+				masked = true
+			}
+		}
+	}
+	if masked {
+		u.RawQuery = query.Encode()
+	}
+	return u.String()
+}
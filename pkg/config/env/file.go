@@ -0,0 +1,128 @@
+package env
+
+import (
+	"fmt"
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// profilesKey is the top-level config file section holding the named profiles
+const profilesKey = "profiles"
+
+// readConfigFile reads a YAML or TOML config file and returns its values keyed by
+// environment variable name. The top-level sections are the base configuration and the
+// selected profile, if any, is merged on top of them.
+func readConfigFile(path, profile string) (map[string]string, error) {
+	if path == "" {
+		path = os.Getenv(ConfigFileVar)
+	}
+	if path == "" {
+		if profile != "" {
+			return nil, fmt.Errorf("profile %q selected but no config file given", profile)
+		}
+		return map[string]string{}, nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read config file: %w", err)
+	}
+
+	doc := make(map[string]interface{})
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, &doc)
+	case ".toml":
+		err = toml.Unmarshal(content, &doc)
+	default:
+		return nil, fmt.Errorf("unsupported config file extension %q", ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	profiles, _ := doc[profilesKey].(map[string]interface{})
+	delete(doc, profilesKey)
+
+	flat := make(map[string]string)
+	if err := flatten(flat, "", doc); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	if profile != "" {
+		overlay, ok := profiles[profile].(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%s: unknown profile %q", path, profile)
+		}
+		if err := flatten(flat, "", overlay); err != nil {
+			return nil, fmt.Errorf("%s: profile %s: %w", path, profile, err)
+		}
+	}
+
+	envNames := make(map[string]string)
+	for _, f := range configFields() {
+		envNames[f.path] = f.env
+	}
+
+	values := make(map[string]string, len(flat))
+	for key, value := range flat {
+		name, ok := envNames[key]
+		if !ok {
+			return nil, fmt.Errorf("%s: unknown key %q", path, key)
+		}
+		values[name] = value
+	}
+	return values, nil
+}
+
+func flatten(dst map[string]string, prefix string, src map[string]interface{}) error {
+	for key, value := range src {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			if err := flatten(dst, prefix+key+".", v); err != nil {
+				return err
+			}
+		case []interface{}:
+			return fmt.Errorf("key %q: lists are not supported", prefix+key)
+		default:
+			dst[prefix+key] = fmt.Sprint(v)
+		}
+	}
+	return nil
+}
+
+// Map returns the config as nested sections keyed like the config file
+func (c Config) Map() map[string]interface{} {
+	rv := reflect.ValueOf(c)
+	out := make(map[string]interface{})
+
+	for _, f := range configFields() {
+		parts := strings.Split(f.path, ".")
+		section := out
+		for _, part := range parts[:len(parts)-1] {
+			next, ok := section[part].(map[string]interface{})
+			if !ok {
+				next = make(map[string]interface{})
+				section[part] = next
+			}
+			section = next
+		}
+
+		value := rv.FieldByIndex(f.index).Interface()
+		if d, ok := value.(time.Duration); ok {
+			value = d.String()
+		}
+		section[parts[len(parts)-1]] = value
+	}
+	return out
+}
+
+// YAML encodes the config as a YAML document
+func (c Config) YAML() ([]byte, error) {
+	return yaml.Marshal(c.Map())
+}
@@ -0,0 +1,82 @@
+package env
+
+import (
+	"fmt"
+	"strings"
+)
+
+// tokenSymmetricKeySize is the key size required by the PASETO v2 local tokens
+const tokenSymmetricKeySize = 32
+
+// ValidationError lists every invalid value found in a Config
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid configuration: %s", strings.Join(e.Problems, "; "))
+}
+
+// Validate checks the consistency of the configuration values
+func (c Config) Validate() error {
+	var problems []string
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			problems = append(problems, fmt.Sprintf(format, args...))
+		}
+	}
+
+	check(c.Database.MaxOpenConns >= 0, "DB_MAX_OPEN_CONNS must not be negative")
+	check(c.Database.MaxIdleConns >= 0, "DB_MAX_IDLE_CONNS must not be negative")
+	check(c.Database.ConnMaxLifetime >= 0, "DB_CONN_MAX_LIFETIME must not be negative")
+	check(c.Database.ConnMaxIdleTime >= 0, "DB_CONN_MAX_IDLE_TIME must not be negative")
+	check(c.Database.ConnectTimeout >= 0, "DB_CONNECT_TIMEOUT must not be negative")
+	check(c.Database.ConnectBackoff > 0, "DB_CONNECT_BACKOFF must be positive")
+	check(c.Database.ConnectMaxBackoff >= c.Database.ConnectBackoff, "DB_CONNECT_MAX_BACKOFF must not be lower than DB_CONNECT_BACKOFF")
+
+	check(c.HTTP.ReadTimeout >= 0, "HTTP_READ_TIMEOUT must not be negative")
+	check(c.HTTP.ReadHeaderTimeout >= 0, "HTTP_READ_HEADER_TIMEOUT must not be negative")
+	check(c.HTTP.WriteTimeout >= 0, "HTTP_WRITE_TIMEOUT must not be negative")
+	check(c.HTTP.IdleTimeout >= 0, "HTTP_IDLE_TIMEOUT must not be negative")
+	check(c.HTTP.ShutdownDelay >= 0, "HTTP_SHUTDOWN_DELAY must not be negative")
+	check(c.HTTP.ShutdownTimeout >= 0, "HTTP_SHUTDOWN_TIMEOUT must not be negative")
+
+	check(len(c.Auth.TokenSymmetricKey) == tokenSymmetricKeySize, "TOKEN_SYMMETRIC_KEY must be exactly %d characters", tokenSymmetricKeySize)
+	check(c.Auth.TokenDuration > 0, "TOKEN_DURATION must be positive")
+
+	check(c.Idempotency.KeyTTL > 0, "IDEMPOTENCY_KEY_TTL must be positive")
+	check(c.Idempotency.PurgeInterval >= 0, "IDEMPOTENCY_PURGE_INTERVAL must not be negative")
+
+	switch c.RateLimit.Backend {
+	case "", "memory", "redis":
+	default:
+		check(false, "RATE_LIMIT_BACKEND must be memory or redis")
+	}
+	check(c.RateLimit.RedisPoolSize > 0, "RATE_LIMIT_REDIS_POOL_SIZE must be positive")
+	check(c.RateLimit.RedisTimeout >= 0, "RATE_LIMIT_REDIS_TIMEOUT must not be negative")
+
+	check(c.Mail.Port >= 0 && c.Mail.Port <= 65535, "MAIL_PORT must be a valid port number")
+
+	switch c.Logging.Level {
+	case "", "debug", "info", "warn", "error":
+	default:
+		check(false, "LOG_LEVEL must be one of debug, info, warn or error")
+	}
+	switch c.Logging.Format {
+	case "", "json", "text":
+	default:
+		check(false, "LOG_FORMAT must be json or text")
+	}
+
+	switch c.Tracing.Exporter {
+	case "", "none", "stdout", "otlp":
+	default:
+		check(false, "TRACING_EXPORTER must be none, stdout or otlp")
+	}
+	check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "TRACING_SAMPLE_RATIO must be between 0 and 1")
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/gmaschi/go-recipes-book/pkg/config/env"
+	"github.com/stretchr/testify/require"
+	"log/slog"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	t.Run("JSON with level", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger, err := New(env.LoggingConfig{Level: "warn", Format: "json"}, &buf)
+		require.NoError(t, err)
+
+		logger.Info("ignored")
+		logger.Warn("kept", "key", "value")
+
+		var line map[string]interface{}
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+		require.Equal(t, "WARN", line["level"])
+		require.Equal(t, "kept", line["msg"])
+		require.Equal(t, "value", line["key"])
+	})
+
+	t.Run("Text", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger, err := New(env.LoggingConfig{Level: "debug", Format: "text"}, &buf)
+		require.NoError(t, err)
+
+		logger.Debug("message", "key", "value")
+		require.Contains(t, buf.String(), "level=DEBUG")
+		require.Contains(t, buf.String(), "key=value")
+	})
+
+	t.Run("Invalid level", func(t *testing.T) {
+		_, err := New(env.LoggingConfig{Level: "verbose"}, &bytes.Buffer{})
+		require.Error(t, err)
+	})
+
+	t.Run("Invalid format", func(t *testing.T) {
+		_, err := New(env.LoggingConfig{Format: "xml"}, &bytes.Buffer{})
+		require.Error(t, err)
+	})
+}
+
+func TestContext(t *testing.T) {
+	require.Equal(t, slog.Default(), FromContext(context.Background()))
+
+	logger := slog.New(slog.NewJSONHandler(&bytes.Buffer{}, nil))
+	ctx := WithContext(context.Background(), logger)
+	require.Equal(t, logger, FromContext(ctx))
+}
@@ -0,0 +1,185 @@
+package openapi
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Version is the version of the OpenAPI specification the documents follow
+const Version = "3.0.3"
+
+type (
+	// Document is an OpenAPI 3 document
+	Document struct {
+		OpenAPI    string               `json:"openapi"`
+		Info       Info                 `json:"info"`
+		Paths      map[string]*PathItem `json:"paths"`
+		Components Components           `json:"components"`
+
+		schemas *Schemas
+	}
+
+	// Info holds the metadata of the API
+	Info struct {
+		Title       string `json:"title"`
+		Description string `json:"description,omitempty"`
+		Version     string `json:"version"`
+	}
+
+	// PathItem holds the operations of a path keyed by lowercase HTTP method
+	PathItem map[string]*Operation
+
+	// Operation describes a single route
+	Operation struct {
+		OperationID string                `json:"operationId"`
+		Summary     string                `json:"summary,omitempty"`
+		Tags        []string              `json:"tags,omitempty"`
+		Parameters  []Parameter           `json:"parameters,omitempty"`
+		RequestBody *RequestBody          `json:"requestBody,omitempty"`
+		Responses   map[string]Response   `json:"responses"`
+		Security    []map[string][]string `json:"security,omitempty"`
+	}
+
+	// Parameter describes a path or query parameter
+	Parameter struct {
+		Name     string  `json:"name"`
+		In       string  `json:"in"`
+		Required bool    `json:"required,omitempty"`
+		Schema   *Schema `json:"schema"`
+	}
+
+	// RequestBody describes the body of an operation
+	RequestBody struct {
+		Required bool                 `json:"required,omitempty"`
+		Content  map[string]MediaType `json:"content"`
+	}
+
+	// Response describes a response of an operation
+	Response struct {
+		Description string               `json:"description"`
+		Content     map[string]MediaType `json:"content,omitempty"`
+	}
+
+	// MediaType holds the schema of a body
+	MediaType struct {
+		Schema *Schema `json:"schema"`
+	}
+
+	// Components holds the reusable schemas and security schemes
+	Components struct {
+		Schemas         map[string]*Schema        `json:"schemas,omitempty"`
+		SecuritySchemes map[string]SecurityScheme `json:"securitySchemes,omitempty"`
+	}
+
+	// SecurityScheme describes an authentication method
+	SecurityScheme struct {
+		Type         string `json:"type"`
+		Scheme       string `json:"scheme,omitempty"`
+		BearerFormat string `json:"bearerFormat,omitempty"`
+	}
+)
+
+// ginParam matches the :name parameters of the gin route templates
+var ginParam = regexp.MustCompile(`:([A-Za-z0-9_]+)`)
+
+// New creates a pointer to an empty Document
+func New(info Info) *Document {
+	schemas := NewSchemas()
+	return &Document{
+		OpenAPI: Version,
+		Info:    info,
+		Paths:   make(map[string]*PathItem),
+		Components: Components{
+			Schemas:         schemas.Components(),
+			SecuritySchemes: make(map[string]SecurityScheme),
+		},
+		schemas: schemas,
+	}
+}
+
+// Path converts a gin route template to an OpenAPI path, e.g. /recipes/:id to /recipes/{id}
+func Path(route string) string {
+	return ginParam.ReplaceAllString(route, "{$1}")
+}
+
+// Add documents the operation of the gin route with the given method
+func (d *Document) Add(method, route string, op Operation) {
+	path := Path(route)
+	item, ok := d.Paths[path]
+	if !ok {
+		item = &PathItem{}
+		d.Paths[path] = item
+	}
+	(*item)[strings.ToLower(method)] = &op
+}
+
+// Operation returns the operation of the gin route with the given method, or nil
+func (d *Document) Operation(method, route string) *Operation {
+	item, ok := d.Paths[Path(route)]
+	if !ok {
+		return nil
+	}
+	return (*item)[strings.ToLower(method)]
+}
+
+// Schema returns a reference to the schema of the type of v, registering it in the
+// components when needed
+func (d *Document) Schema(v interface{}) *Schema {
+	return d.schemas.Of(v)
+}
+
+// Parameters returns the parameters of the uri and form fields of the request struct v
+func (d *Document) Parameters(v interface{}) []Parameter {
+	return d.schemas.Parameters(v)
+}
+
+// JSONBody returns a required JSON request body of the type of v
+func (d *Document) JSONBody(v interface{}) *RequestBody {
+	return &RequestBody{
+		Required: true,
+		Content:  map[string]MediaType{"application/json": {Schema: d.Schema(v)}},
+	}
+}
+
+// JSONResponse returns a response whose body is the type of v encoded with contentType
+func (d *Document) JSONResponse(description, contentType string, v interface{}) Response {
+	return Response{
+		Description: description,
+		Content:     map[string]MediaType{contentType: {Schema: d.Schema(v)}},
+	}
+}
+
+// JSONResponseOneOf returns a response whose body is one of the types of vs encoded with
+// contentType
+func (d *Document) JSONResponseOneOf(description, contentType string, vs ...interface{}) Response {
+	schema := &Schema{}
+	for _, v := range vs {
+		schema.OneOf = append(schema.OneOf, d.Schema(v))
+	}
+	return Response{
+		Description: description,
+		Content:     map[string]MediaType{contentType: {Schema: schema}},
+	}
+}
+
+// Validate checks that every operation has an ID and a response and that the IDs are unique
+func (d *Document) Validate() error {
+	ids := make(map[string]string)
+	for path, item := range d.Paths {
+		for method, op := range *item {
+			where := fmt.Sprintf("%s %s", strings.ToUpper(method), path)
+			if op.OperationID == "" {
+				return fmt.Errorf("%s: missing operation ID", where)
+			}
+			if other, ok := ids[op.OperationID]; ok {
+				return fmt.Errorf("%s: operation ID %s already used by %s", where, op.OperationID, other)
+			}
+			ids[op.OperationID] = where
+			if len(op.Responses) == 0 {
+				return fmt.Errorf("%s: no response documented", where)
+			}
+		}
+	}
+	return nil
+}
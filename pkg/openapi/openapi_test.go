@@ -0,0 +1,79 @@
+package openapi
+
+import (
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"testing"
+	"time"
+)
+
+type testItem struct {
+	Name     string    `json:"name" binding:"required,min=2,max=20"`
+	Tags     []string  `json:"tags" binding:"required,min=1"`
+	Count    int32     `json:"count" binding:"min=1"`
+	Secret   string    `json:"-"`
+	Created  time.Time `json:"created_at"`
+	Children []testItem
+}
+
+type testQuery struct {
+	ID       int64 `uri:"id" binding:"required,min=1"`
+	PageSize int32 `form:"page_size" binding:"max=10"`
+}
+
+func TestPath(t *testing.T) {
+	require.Equal(t, "/recipes/{id}", Path("/recipes/:id"))
+	require.Equal(t, "/authors/{username}/recipes/{id}", Path("/authors/:username/recipes/:id"))
+	require.Equal(t, "/authors", Path("/authors"))
+}
+
+func TestSchemas(t *testing.T) {
+	doc := New(Info{Title: "test", Version: "1"})
+
+	ref := doc.Schema([]testItem{})
+	require.Equal(t, "array", ref.Type)
+	require.Equal(t, "#/components/schemas/openapi.testItem", ref.Items.Ref)
+
+	item := doc.Components.Schemas["openapi.testItem"]
+	require.NotNil(t, item)
+	require.Equal(t, []string{"name", "tags"}, item.Required)
+	require.EqualValues(t, 2, *item.Properties["name"].MinLength)
+	require.EqualValues(t, 20, *item.Properties["name"].MaxLength)
+	require.EqualValues(t, 1, *item.Properties["tags"].MinItems)
+	require.EqualValues(t, 1, *item.Properties["count"].Minimum)
+	require.Equal(t, "date-time", item.Properties["created_at"].Format)
+	require.NotContains(t, item.Properties, "Secret")
+	require.Equal(t, "#/components/schemas/openapi.testItem", item.Properties["Children"].Items.Ref)
+
+	params := doc.Parameters(testQuery{})
+	require.Equal(t, []Parameter{
+		{Name: "id", In: "path", Required: true, Schema: &Schema{Type: "integer", Format: "int64", Minimum: float(1)}},
+		{Name: "page_size", In: "query", Schema: &Schema{Type: "integer", Format: "int32", Maximum: float(10)}},
+	}, params)
+
+	oneOf := doc.JSONResponseOneOf("OK", "application/json", []testItem{}, testItem{})
+	schema := oneOf.Content["application/json"].Schema
+	require.Len(t, schema.OneOf, 2)
+	require.Equal(t, "array", schema.OneOf[0].Type)
+	require.Equal(t, "#/components/schemas/openapi.testItem", schema.OneOf[1].Ref)
+}
+
+func TestValidate(t *testing.T) {
+	doc := New(Info{Title: "test", Version: "1"})
+	ok := map[string]Response{"200": {Description: "OK"}}
+
+	doc.Add(http.MethodGet, "/items", Operation{OperationID: "listItems", Responses: ok})
+	require.NoError(t, doc.Validate())
+	require.NotNil(t, doc.Operation(http.MethodGet, "/items"))
+	require.Nil(t, doc.Operation(http.MethodPost, "/items"))
+
+	doc.Add(http.MethodPost, "/items", Operation{OperationID: "listItems", Responses: ok})
+	require.Error(t, doc.Validate())
+
+	doc.Add(http.MethodPost, "/items", Operation{OperationID: "createItem"})
+	require.Error(t, doc.Validate())
+}
+
+func float(f float64) *float64 {
+	return &f
+}
@@ -0,0 +1,232 @@
+package openapi
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schema is an OpenAPI 3 schema object
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	OneOf                []*Schema          `json:"oneOf,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	MinLength            *int64             `json:"minLength,omitempty"`
+	MaxLength            *int64             `json:"maxLength,omitempty"`
+	MinItems             *int64             `json:"minItems,omitempty"`
+	MaxItems             *int64             `json:"maxItems,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+}
+
+// Schemas derives the schemas of Go types from their json, uri and form tags and the
+// validation rules of their binding tags
+type Schemas struct {
+	components map[string]*Schema
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// NewSchemas creates a pointer to an empty Schemas
+func NewSchemas() *Schemas {
+	return &Schemas{components: make(map[string]*Schema)}
+}
+
+// Components returns the named schemas registered so far
+func (s *Schemas) Components() map[string]*Schema {
+	return s.components
+}
+
+// Of returns the schema of the type of v. Named struct types are registered as components
+// named after their package and type, e.g. authorModel.CreateRequest, and referenced.
+func (s *Schemas) Of(v interface{}) *Schema {
+	return s.typeSchema(reflect.TypeOf(v))
+}
+
+// Parameters returns the path parameters of the uri fields and the query parameters of the
+// form fields of the struct v
+func (s *Schemas) Parameters(v interface{}) []Parameter {
+	t := reflect.TypeOf(v)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	var params []Parameter
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		for _, loc := range []struct{ tag, in string }{{"uri", "path"}, {"form", "query"}} {
+			name := tagName(sf, loc.tag)
+			if name == "" {
+				continue
+			}
+			schema, required := s.fieldSchema(sf)
+			params = append(params, Parameter{
+				Name:     name,
+				In:       loc.in,
+				Required: required || loc.in == "path",
+				Schema:   schema,
+			})
+		}
+	}
+	return params
+}
+
+func (s *Schemas) typeSchema(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t.Kind() == reflect.Struct && t.Name() != "":
+		name := componentName(t)
+		if _, ok := s.components[name]; !ok {
+			// registered before the fields are walked so that recursive types terminate
+			s.components[name] = &Schema{}
+			*s.components[name] = *s.structSchema(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + name}
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		return s.structSchema(t)
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: s.typeSchema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: s.typeSchema(t.Elem())}
+	default:
+		return &Schema{}
+	}
+}
+
+func (s *Schemas) structSchema(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		name := tagName(sf, "json")
+		if name == "" {
+			continue
+		}
+
+		field, required := s.fieldSchema(sf)
+		schema.Properties[name] = field
+		if required {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+	return schema
+}
+
+// fieldSchema returns the schema of a struct field with its binding rules applied and
+// whether the field is required
+func (s *Schemas) fieldSchema(sf reflect.StructField) (*Schema, bool) {
+	schema := s.typeSchema(sf.Type)
+	if schema.Ref != "" {
+		return schema, hasRule(sf, "required")
+	}
+
+	required := false
+	for _, rule := range strings.Split(sf.Tag.Get("binding"), ",") {
+		name, param := rule, ""
+		if idx := strings.Index(rule, "="); idx >= 0 {
+			name, param = rule[:idx], rule[idx+1:]
+		}
+
+		switch name {
+		case "required":
+			required = true
+		case "email":
+			schema.Format = "email"
+		case "alphanum":
+			schema.Pattern = "^[a-zA-Z0-9]+$"
+		case "min", "max":
+			n, err := strconv.ParseInt(param, 10, 64)
+			if err != nil {
+				continue
+			}
+			applyBound(schema, name == "min", n)
+		}
+	}
+	return schema, required
+}
+
+func applyBound(schema *Schema, min bool, n int64) {
+	switch schema.Type {
+	case "string":
+		if min {
+			schema.MinLength = &n
+		} else {
+			schema.MaxLength = &n
+		}
+	case "array":
+		if min {
+			schema.MinItems = &n
+		} else {
+			schema.MaxItems = &n
+		}
+	case "integer", "number":
+		f := float64(n)
+		if min {
+			schema.Minimum = &f
+		} else {
+			schema.Maximum = &f
+		}
+	}
+}
+
+func hasRule(sf reflect.StructField, rule string) bool {
+	for _, r := range strings.Split(sf.Tag.Get("binding"), ",") {
+		if r == rule {
+			return true
+		}
+	}
+	return false
+}
+
+// tagName returns the name given to a field by tag, or an empty string when the field is
+// skipped or has no such tag. Untagged fields keep their Go name in JSON.
+func tagName(sf reflect.StructField, tag string) string {
+	value, ok := sf.Tag.Lookup(tag)
+	if !ok {
+		if tag == "json" && sf.Tag.Get("uri") == "" && sf.Tag.Get("form") == "" {
+			return sf.Name
+		}
+		return ""
+	}
+	name := strings.Split(value, ",")[0]
+	if name == "-" {
+		return ""
+	}
+	return name
+}
+
+// componentName names a type after its package name rather than its import path, which
+// differ in this module, e.g. authorModel.CreateRequest
+func componentName(t reflect.Type) string {
+	return t.String()
+}
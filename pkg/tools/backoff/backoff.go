@@ -0,0 +1,44 @@
+package backoff
+
+import (
+	"context"
+	"time"
+)
+
+// Policy describes an exponential backoff: the delay starts at Initial and doubles after
+// every failed attempt, up to Max
+type Policy struct {
+	Initial time.Duration
+	Max     time.Duration
+}
+
+// Delay returns the delay to wait after the given failed attempt, counting from zero
+func (p Policy) Delay(attempt int) time.Duration {
+	delay := p.Initial
+	for i := 0; i < attempt; i++ {
+		delay *= 2
+		if p.Max > 0 && delay >= p.Max {
+			return p.Max
+		}
+	}
+	return delay
+}
+
+// Retry calls fn until it succeeds or ctx is done, waiting between attempts as described
+// by the policy. When ctx is done, the last error of fn is returned.
+func Retry(ctx context.Context, policy Policy, fn func(ctx context.Context) error) error {
+	for attempt := 0; ; attempt++ {
+		err := fn(ctx)
+		if err == nil {
+			return nil
+		}
+
+		timer := time.NewTimer(policy.Delay(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
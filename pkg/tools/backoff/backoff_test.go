@@ -0,0 +1,47 @@
+package backoff
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestDelay(t *testing.T) {
+	policy := Policy{Initial: 100 * time.Millisecond, Max: time.Second}
+
+	require.Equal(t, 100*time.Millisecond, policy.Delay(0))
+	require.Equal(t, 200*time.Millisecond, policy.Delay(1))
+	require.Equal(t, 800*time.Millisecond, policy.Delay(3))
+	require.Equal(t, time.Second, policy.Delay(4))
+	require.Equal(t, time.Second, policy.Delay(100))
+}
+
+func TestRetry(t *testing.T) {
+	policy := Policy{Initial: time.Millisecond, Max: 5 * time.Millisecond}
+
+	t.Run("Succeeds after failures", func(t *testing.T) {
+		attempts := 0
+		err := Retry(context.Background(), policy, func(ctx context.Context) error {
+			attempts++
+			if attempts < 3 {
+				return errors.New("not yet")
+			}
+			return nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, 3, attempts)
+	})
+
+	t.Run("Gives up when context is done", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		lastErr := errors.New("still failing")
+		err := Retry(ctx, policy, func(ctx context.Context) error {
+			return lastErr
+		})
+		require.ErrorIs(t, err, lastErr)
+	})
+}
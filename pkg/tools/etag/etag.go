@@ -0,0 +1,63 @@
+package etag
+
+import (
+	"encoding/json"
+	"hash/fnv"
+	"strconv"
+	"strings"
+)
+
+// The headers of the conditional requests
+const (
+	Header            = "ETag"
+	IfMatchHeader     = "If-Match"
+	IfNoneMatchHeader = "If-None-Match"
+)
+
+// weakPrefix marks the weak entity tags, which only tell that two representations are
+// equivalent
+const weakPrefix = "W/"
+
+// Version returns the strong entity tag of the full representation of a resource at
+// version. Every change of a resource bumps its version, so the tag identifies its state.
+func Version(version int32) string {
+	return `"v` + strconv.FormatInt(int64(version), 10) + `"`
+}
+
+// Weak returns the weak entity tag of a partial representation v of a resource, such as a
+// sparse fieldset or one embedding other resources, whose version does not identify it.
+// The tag is a hash of the JSON encoding of v, so it is only valid in If-None-Match.
+func Weak(v interface{}) (string, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	h := fnv.New64a()
+	h.Write(b)
+	return weakPrefix + `"` + strconv.FormatUint(h.Sum64(), 36) + `"`, nil
+}
+
+// WeakMatch tells whether the If-None-Match header lists tag or is *, ignoring whether the
+// tags are weak
+func WeakMatch(header, tag string) bool {
+	return match(header, func(t string) bool {
+		return strings.TrimPrefix(t, weakPrefix) == strings.TrimPrefix(tag, weakPrefix)
+	})
+}
+
+// StrongMatch tells whether the If-Match header lists tag or is *. Weak tags never match.
+func StrongMatch(header, tag string) bool {
+	return match(header, func(t string) bool {
+		return !strings.HasPrefix(t, weakPrefix) && !strings.HasPrefix(tag, weakPrefix) && t == tag
+	})
+}
+
+func match(header string, equal func(t string) bool) bool {
+	for _, t := range strings.Split(header, ",") {
+		t = strings.TrimSpace(t)
+		if t == "*" || (t != "" && equal(t)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,51 @@
+package etag
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestVersion(t *testing.T) {
+	tag := Version(3)
+	require.Equal(t, `"v3"`, tag)
+	require.Equal(t, tag, Version(3))
+	require.NotEqual(t, tag, Version(4))
+	require.True(t, StrongMatch(tag, Version(3)))
+}
+
+func TestWeak(t *testing.T) {
+	tag, err := Weak(map[string]interface{}{"steps": []string{"mix"}})
+	require.NoError(t, err)
+	require.True(t, WeakMatch(tag, tag))
+	require.False(t, StrongMatch(tag, tag))
+
+	same, err := Weak(map[string]interface{}{"steps": []string{"mix"}})
+	require.NoError(t, err)
+	require.Equal(t, tag, same)
+
+	other, err := Weak(map[string]interface{}{"steps": []string{"stir"}})
+	require.NoError(t, err)
+	require.NotEqual(t, tag, other)
+}
+
+func TestMatch(t *testing.T) {
+	testCases := []struct {
+		header string
+		tag    string
+		weak   bool
+		strong bool
+	}{
+		{header: "", tag: `"a"`},
+		{header: `"a"`, tag: `"a"`, weak: true, strong: true},
+		{header: `"b", "a"`, tag: `"a"`, weak: true, strong: true},
+		{header: `"b"`, tag: `"a"`},
+		{header: "*", tag: `"a"`, weak: true, strong: true},
+		{header: `W/"a"`, tag: `"a"`, weak: true},
+		{header: `"a"`, tag: `W/"a"`, weak: true},
+	}
+
+	for _, tc := range testCases {
+		require.Equal(t, tc.weak, WeakMatch(tc.header, tc.tag), tc.header)
+		require.Equal(t, tc.strong, StrongMatch(tc.header, tc.tag), tc.header)
+	}
+}
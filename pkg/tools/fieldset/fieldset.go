@@ -0,0 +1,167 @@
+package fieldset
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/gmaschi/go-recipes-book/pkg/tools/problem"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// The query parameters selecting the fields of a response and the relations it embeds
+const (
+	FieldsKey  = "fields"
+	IncludeKey = "include"
+)
+
+// IncludedKey is the member of a response holding its embedded relations, so that they
+// never collide with its fields
+const IncludedKey = "included"
+
+// Relation loads a relation of resource to embed in its response
+type Relation[T any] func(ctx context.Context, resource T) (interface{}, error)
+
+// Selection is the part of a response requested with the fields and include parameters.
+// The zero Selection requests the full response without relations.
+type Selection struct {
+	Fields  []string
+	Include []string
+}
+
+// Partial tells whether the selection requests less or more than the full response
+func (s Selection) Partial() bool {
+	return len(s.Fields) > 0 || len(s.Include) > 0
+}
+
+// Parse reads the comma separated fields and include parameters of query. The fields must
+// be JSON fields of the response v, a struct, and the includes keys of relations.
+func Parse[T any](query url.Values, v interface{}, relations map[string]Relation[T]) (Selection, error) {
+	var (
+		s       Selection
+		invalid []problem.FieldError
+	)
+
+	fields := Fields(v)
+	for _, field := range split(query.Get(FieldsKey)) {
+		if !contains(fields, field) {
+			invalid = append(invalid, problem.FieldError{Field: FieldsKey, Code: "oneof", Message: fmt.Sprintf("unknown field %s, must be among %s", field, strings.Join(fields, " "))})
+			continue
+		}
+		s.Fields = append(s.Fields, field)
+	}
+
+	names := make([]string, 0, len(relations))
+	for name := range relations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range split(query.Get(IncludeKey)) {
+		if _, ok := relations[name]; !ok {
+			message := fmt.Sprintf("unknown relation %s, must be among %s", name, strings.Join(names, " "))
+			if len(names) == 0 {
+				message = "the resource has no relation to include"
+			}
+			invalid = append(invalid, problem.FieldError{Field: IncludeKey, Code: "oneof", Message: message})
+			continue
+		}
+		s.Include = append(s.Include, name)
+	}
+
+	if len(invalid) > 0 {
+		return Selection{}, problem.Invalid(invalid...)
+	}
+	return s, nil
+}
+
+// Fields returns the names of the JSON fields of the struct v, in declaration order
+func Fields(v interface{}) []string {
+	t := reflect.TypeOf(v)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	var fields []string
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		name := strings.Split(sf.Tag.Get("json"), ",")[0]
+		switch name {
+		case "-":
+			continue
+		case "":
+			name = sf.Name
+		}
+		fields = append(fields, name)
+	}
+	return fields
+}
+
+// Load loads the relations of resource included in the selection
+func Load[T any](ctx context.Context, s Selection, resource T, relations map[string]Relation[T]) (map[string]interface{}, error) {
+	if len(s.Include) == 0 {
+		return nil, nil
+	}
+
+	included := make(map[string]interface{}, len(s.Include))
+	for _, name := range s.Include {
+		relation, err := relations[name](ctx, resource)
+		if err != nil {
+			return nil, err
+		}
+		included[name] = relation
+	}
+	return included, nil
+}
+
+// Apply returns the response v restricted to the selected fields, with the included
+// relations under IncludedKey. v is returned as is when the full response was requested.
+func (s Selection) Apply(v interface{}, included map[string]interface{}) (interface{}, error) {
+	if len(s.Fields) == 0 && len(included) == 0 {
+		return v, nil
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(b, &all); err != nil {
+		return nil, err
+	}
+
+	res := make(map[string]interface{}, len(all)+1)
+	for field, value := range all {
+		if len(s.Fields) == 0 || contains(s.Fields, field) {
+			res[field] = value
+		}
+	}
+	if len(included) > 0 {
+		res[IncludedKey] = included
+	}
+	return res, nil
+}
+
+// split splits a comma separated parameter, dropping the empty values
+func split(value string) []string {
+	var values []string
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,85 @@
+package fieldset
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/gmaschi/go-recipes-book/pkg/tools/problem"
+	"github.com/stretchr/testify/require"
+	"net/url"
+	"testing"
+)
+
+type response struct {
+	ID       int64    `json:"id"`
+	Name     string   `json:"name,omitempty"`
+	Tags     []string `json:"tags"`
+	Password string   `json:"-"`
+	Untagged bool
+	hidden   bool
+}
+
+var relations = map[string]Relation[response]{
+	"owner": func(ctx context.Context, r response) (interface{}, error) {
+		return map[string]string{"name": r.Name}, nil
+	},
+	"broken": func(ctx context.Context, r response) (interface{}, error) {
+		return nil, errors.New("broken")
+	},
+}
+
+func TestFields(t *testing.T) {
+	require.Equal(t, []string{"id", "name", "tags", "Untagged"}, Fields(&response{}))
+}
+
+func TestParse(t *testing.T) {
+	t.Run("Empty", func(t *testing.T) {
+		s, err := Parse(url.Values{}, response{}, relations)
+		require.NoError(t, err)
+		require.Equal(t, Selection{}, s)
+	})
+
+	t.Run("Valid", func(t *testing.T) {
+		s, err := Parse(url.Values{"fields": {"id, tags,"}, "include": {"owner"}}, response{}, relations)
+		require.NoError(t, err)
+		require.Equal(t, Selection{Fields: []string{"id", "tags"}, Include: []string{"owner"}}, s)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		_, err := Parse(url.Values{"fields": {"id,Password,hidden"}, "include": {"tags"}}, response{}, relations)
+		var p *problem.Error
+		require.ErrorAs(t, err, &p)
+		require.Equal(t, problem.CodeInvalidRequest, p.Code)
+		require.Len(t, p.Fields, 3)
+		require.Equal(t, FieldsKey, p.Fields[0].Field)
+		require.Equal(t, FieldsKey, p.Fields[1].Field)
+		require.Equal(t, IncludeKey, p.Fields[2].Field)
+	})
+}
+
+func TestApply(t *testing.T) {
+	r := response{ID: 7, Name: "pie", Tags: []string{"sweet"}, Password: "secret"}
+
+	t.Run("Full", func(t *testing.T) {
+		res, err := Selection{}.Apply(r, nil)
+		require.NoError(t, err)
+		require.Equal(t, r, res)
+	})
+
+	t.Run("Fields and relations", func(t *testing.T) {
+		s := Selection{Fields: []string{"id", "tags"}, Include: []string{"owner"}}
+		included, err := Load(context.Background(), s, r, relations)
+		require.NoError(t, err)
+
+		res, err := s.Apply(r, included)
+		require.NoError(t, err)
+		b, err := json.Marshal(res)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"id":7,"tags":["sweet"],"included":{"owner":{"name":"pie"}}}`, string(b))
+	})
+
+	t.Run("Relation error", func(t *testing.T) {
+		_, err := Load(context.Background(), Selection{Include: []string{"broken"}}, r, relations)
+		require.EqualError(t, err, "broken")
+	})
+}
@@ -0,0 +1,94 @@
+package filter
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/gmaschi/go-recipes-book/pkg/tools/problem"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// SortKey is the query parameter selecting the order of a list
+const SortKey = "sort"
+
+// Sort is the order of a list, by a single field
+type Sort struct {
+	Field string
+	Desc  bool
+}
+
+// Parser reads the sort and filter parameters of a list query, collecting the parameters
+// that are invalid so they can be reported together by Err
+type Parser struct {
+	query  url.Values
+	fields []problem.FieldError
+}
+
+// New creates a pointer to a Parser of query
+func New(query url.Values) *Parser {
+	return &Parser{
+		query: query,
+	}
+}
+
+// Sort parses the sort parameter, a field name prefixed with - for a descending order.
+// Only the given fields are accepted, and def is used when the parameter is absent.
+func (p *Parser) Sort(def Sort, fields ...string) Sort {
+	value := p.query.Get(SortKey)
+	if value == "" {
+		return def
+	}
+
+	s := Sort{Field: strings.TrimPrefix(value, "-"), Desc: strings.HasPrefix(value, "-")}
+	for _, field := range fields {
+		if s.Field == field {
+			return s
+		}
+	}
+
+	p.invalid(SortKey, "oneof", fmt.Sprintf("must be one of %s, optionally prefixed with -", strings.Join(fields, " ")))
+	return def
+}
+
+// Time parses the parameter key as an RFC 3339 timestamp, which is not valid when absent
+func (p *Parser) Time(key string) sql.NullTime {
+	value := p.query.Get(key)
+	if value == "" {
+		return sql.NullTime{}
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		p.invalid(key, "datetime", "must be an RFC 3339 timestamp")
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: t, Valid: true}
+}
+
+// String returns the parameter key, which is not valid when absent or empty
+func (p *Parser) String(key string) sql.NullString {
+	value := p.query.Get(key)
+	return sql.NullString{String: value, Valid: value != ""}
+}
+
+// Reject reports the given parameters as unsupported when they are present
+func (p *Parser) Reject(reason string, keys ...string) {
+	for _, key := range keys {
+		if _, ok := p.query[key]; ok {
+			p.invalid(key, "unsupported", reason)
+		}
+	}
+}
+
+// Err returns the invalid parameters as a problem.Invalid, or nil when all were valid
+func (p *Parser) Err() error {
+	if len(p.fields) == 0 {
+		return nil
+	}
+	return problem.Invalid(p.fields...)
+}
+
+func (p *Parser) invalid(key, code, message string) {
+	p.fields = append(p.fields, problem.FieldError{Field: key, Code: code, Message: message})
+}
@@ -0,0 +1,76 @@
+package filter
+
+import (
+	"github.com/gmaschi/go-recipes-book/pkg/tools/problem"
+	"github.com/stretchr/testify/require"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestSort(t *testing.T) {
+	def := Sort{Field: "id"}
+	testCases := []struct {
+		query string
+		want  Sort
+		valid bool
+	}{
+		{query: "", want: def, valid: true},
+		{query: "sort=title", want: Sort{Field: "title"}, valid: true},
+		{query: "sort=-title", want: Sort{Field: "title", Desc: true}, valid: true},
+		{query: "sort=password", want: def},
+		{query: "sort=-", want: def},
+	}
+
+	for _, tc := range testCases {
+		query, err := url.ParseQuery(tc.query)
+		require.NoError(t, err)
+
+		p := New(query)
+		require.Equal(t, tc.want, p.Sort(def, "id", "title"), tc.query)
+		if tc.valid {
+			require.NoError(t, p.Err(), tc.query)
+		} else {
+			requireInvalid(t, p.Err(), SortKey)
+		}
+	}
+}
+
+func TestTime(t *testing.T) {
+	query := url.Values{"after": {"2022-01-02T03:04:05Z"}, "before": {"yesterday"}}
+	p := New(query)
+
+	after := p.Time("after")
+	require.True(t, after.Valid)
+	require.True(t, after.Time.Equal(time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)))
+	require.False(t, p.Time("since").Valid)
+	require.NoError(t, p.Err())
+
+	require.False(t, p.Time("before").Valid)
+	requireInvalid(t, p.Err(), "before")
+}
+
+func TestString(t *testing.T) {
+	p := New(url.Values{"author": {"john"}, "empty": {""}})
+	require.Equal(t, "john", p.String("author").String)
+	require.True(t, p.String("author").Valid)
+	require.False(t, p.String("empty").Valid)
+	require.False(t, p.String("missing").Valid)
+	require.NoError(t, p.Err())
+}
+
+func TestReject(t *testing.T) {
+	p := New(url.Values{"sort": {"id"}, "cursor": {"x"}})
+	p.Reject("not supported", "sort", "author")
+	requireInvalid(t, p.Err(), "sort")
+}
+
+func requireInvalid(t *testing.T, err error, fields ...string) {
+	var p *problem.Error
+	require.ErrorAs(t, err, &p)
+	require.Equal(t, problem.CodeInvalidRequest, p.Code)
+	require.Len(t, p.Fields, len(fields))
+	for i, field := range fields {
+		require.Equal(t, field, p.Fields[i].Field)
+	}
+}
@@ -0,0 +1,102 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/gmaschi/go-recipes-book/pkg/tools/problem"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidCursor is reported when a cursor was not issued by EncodeCursor
+var ErrInvalidCursor = problem.Invalid(problem.FieldError{Field: "cursor", Code: "cursor", Message: "must be a cursor returned by a previous page"})
+
+// Link is a link of a Link header to the same route, with its own query
+type Link struct {
+	Rel   string
+	Query url.Values
+}
+
+// EncodeCursor returns the opaque cursor of a list resuming after the row whose sort key is
+// key. Clients must not rely on its format.
+func EncodeCursor(key interface{}) (string, error) {
+	b, err := json.Marshal(key)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// DecodeCursor decodes a cursor returned by EncodeCursor into key
+func DecodeCursor(cursor string, key interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return ErrInvalidCursor.WithCause(err)
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(string(b)))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(key); err != nil {
+		return ErrInvalidCursor.WithCause(err)
+	}
+	return nil
+}
+
+// LinkHeader formats links to the path of u as an RFC 8288 Link header
+func LinkHeader(u *url.URL, links ...Link) string {
+	values := make([]string, 0, len(links))
+	for _, link := range links {
+		target := url.URL{Path: u.Path, RawQuery: link.Query.Encode()}
+		values = append(values, fmt.Sprintf("<%s>; rel=%q", target.String(), link.Rel))
+	}
+	return strings.Join(values, ", ")
+}
+
+// WithQuery returns a copy of query with the given parameters replaced, and removed when
+// their value is empty
+func WithQuery(query url.Values, params map[string]string) url.Values {
+	c := make(url.Values, len(query))
+	for key, value := range query {
+		c[key] = append([]string(nil), value...)
+	}
+	for key, value := range params {
+		if value == "" {
+			c.Del(key)
+			continue
+		}
+		c.Set(key, value)
+	}
+	return c
+}
+
+// IsCursorQuery reports whether query selects a page with a cursor rather than a page ID
+func IsCursorQuery(query url.Values) bool {
+	_, hasCursor := query["cursor"]
+	_, hasLimit := query["limit"]
+	return hasCursor || hasLimit
+}
+
+// PageLinks returns the links to the previous and next pages of the page pageID selected by
+// query. The next page is only linked when hasNext.
+func PageLinks(query url.Values, pageID int32, hasNext bool) []Link {
+	var links []Link
+	if pageID > 1 {
+		links = append(links, Link{Rel: "prev", Query: WithQuery(query, map[string]string{"page_id": strconv.Itoa(int(pageID) - 1)})})
+	}
+	if hasNext {
+		links = append(links, Link{Rel: "next", Query: WithQuery(query, map[string]string{"page_id": strconv.Itoa(int(pageID) + 1)})})
+	}
+	return links
+}
+
+// CursorLinks returns the links to the first page and, unless nextCursor is empty, to the
+// next page of the list selected by query
+func CursorLinks(query url.Values, nextCursor string) []Link {
+	links := []Link{{Rel: "first", Query: WithQuery(query, map[string]string{"cursor": ""})}}
+	if nextCursor != "" {
+		links = append(links, Link{Rel: "next", Query: WithQuery(query, map[string]string{"cursor": nextCursor})})
+	}
+	return links
+}
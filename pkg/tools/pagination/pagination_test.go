@@ -0,0 +1,53 @@
+package pagination
+
+import (
+	"github.com/gmaschi/go-recipes-book/pkg/tools/problem"
+	"github.com/stretchr/testify/require"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestCursor(t *testing.T) {
+	type key struct {
+		CreatedAt time.Time `json:"c"`
+		ID        int64     `json:"i"`
+	}
+
+	t.Run("Round trip", func(t *testing.T) {
+		want := key{CreatedAt: time.Now().UTC(), ID: 42}
+		cursor, err := EncodeCursor(want)
+		require.NoError(t, err)
+		require.NotContains(t, cursor, "=")
+
+		var got key
+		require.NoError(t, DecodeCursor(cursor, &got))
+		require.True(t, want.CreatedAt.Equal(got.CreatedAt))
+		require.Equal(t, want.ID, got.ID)
+	})
+
+	t.Run("Invalid cursor", func(t *testing.T) {
+		for _, cursor := range []string{"%%%", "bm90IGpzb24", "eyJ4IjoxfQ"} {
+			var got key
+			err := DecodeCursor(cursor, &got)
+			var p *problem.Error
+			require.ErrorAs(t, err, &p, cursor)
+			require.Equal(t, problem.CodeInvalidRequest, p.Code)
+			require.Equal(t, "cursor", p.Fields[0].Field)
+		}
+	})
+}
+
+func TestLinkHeader(t *testing.T) {
+	u, err := url.Parse("http://localhost:8080/recipes?limit=5&cursor=abc")
+	require.NoError(t, err)
+
+	next := WithQuery(u.Query(), map[string]string{"cursor": "def"})
+	require.Equal(t, "abc", u.Query().Get("cursor"))
+
+	header := LinkHeader(u,
+		Link{Rel: "next", Query: next},
+		Link{Rel: "first", Query: WithQuery(u.Query(), map[string]string{"cursor": ""})},
+	)
+	require.Equal(t, `</recipes?cursor=def&limit=5>; rel="next", </recipes?limit=5>; rel="first"`, header)
+}
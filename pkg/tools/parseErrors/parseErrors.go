@@ -1,5 +0,0 @@
-package parseErrors
-
-func ErrorResponse(err error) map[string]interface{} {
-	return map[string]interface{}{"error": err.Error()}
-}
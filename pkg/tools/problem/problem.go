@@ -0,0 +1,161 @@
+package problem
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// ContentType is the media type of the RFC 7807 problem details
+const ContentType = "application/problem+json"
+
+// Generic codes, used when no resource specific code applies
+const (
+	CodeInternal            = "internal_error"
+	CodeMalformedRequest    = "request.malformed"
+	CodeInvalidRequest      = "request.invalid"
+	CodeRouteNotFound       = "route.not_found"
+	CodeNotFound            = "resource.not_found"
+	CodeConflict            = "resource.conflict"
+	CodeReferenceViolation  = "resource.reference_violation"
+	CodeUnsupportedVersion  = "request.unsupported_version"
+	CodeMissingPrecondition = "request.missing_precondition"
+	CodePreconditionFailed  = "resource.precondition_failed"
+	CodeVersionConflict     = "resource.version_conflict"
+	CodeRateLimited         = "request.rate_limited"
+	CodeRequestTooLarge     = "request.too_large"
+)
+
+// Codes of the Idempotency-Key errors
+const (
+	CodeIdempotencyKeyInvalid    = "idempotency_key.invalid"
+	CodeIdempotencyKeyReused     = "idempotency_key.reused"
+	CodeIdempotencyKeyInProgress = "idempotency_key.in_progress"
+)
+
+// Codes of the author, recipe and authentication errors
+const (
+	CodeAuthorNotFound           = "author.not_found"
+	CodeAuthorUsernameTaken      = "author.username_taken"
+	CodeAuthorEmailTaken         = "author.email_taken"
+	CodeAuthorHasRecipes         = "author.has_recipes"
+	CodeAuthorNotAuthenticated   = "author.not_authenticated_author"
+	CodeAuthorDisabled           = "author.disabled"
+	CodeRecipeNotFound           = "recipe.not_found"
+	CodeRecipeNotOwned           = "recipe.not_owned"
+	CodeRecipeAuthorNotFound     = "recipe.author_not_found"
+	CodeInvalidCredentials       = "auth.invalid_credentials"
+	CodeMissingAuthorization     = "auth.missing_authorization"
+	CodeInvalidAuthorization     = "auth.invalid_authorization"
+	CodeUnsupportedAuthorization = "auth.unsupported_authorization"
+	CodeTokenExpired             = "auth.token_expired"
+	CodeTokenInvalid             = "auth.token_invalid"
+)
+
+// defaultType is the problem type of every problem, whose meaning is given by its code
+const defaultType = "about:blank"
+
+type (
+	// Error is an error with the status, stable code and client-safe detail it is reported
+	// with. The wrapped cause is logged but never sent to the client. Current is the state
+	// of the resource a conflicting request was made against.
+	Error struct {
+		Status  int
+		Code    string
+		Detail  string
+		Fields  []FieldError
+		Current interface{}
+		Err     error
+	}
+
+	// FieldError describes why a single request field is invalid
+	FieldError struct {
+		Field   string `json:"field"`
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	}
+
+	// Problem is the application/problem+json response body
+	Problem struct {
+		Type      string       `json:"type"`
+		Title     string       `json:"title"`
+		Status    int          `json:"status"`
+		Detail    string       `json:"detail,omitempty"`
+		Instance  string       `json:"instance,omitempty"`
+		Code      string       `json:"code"`
+		RequestID string       `json:"request_id,omitempty"`
+		Errors    []FieldError `json:"errors,omitempty"`
+		Current   interface{}  `json:"current,omitempty"`
+	}
+)
+
+// New creates a pointer to an Error reported with status, code and detail
+func New(status int, code, detail string) *Error {
+	return &Error{
+		Status: status,
+		Code:   code,
+		Detail: detail,
+	}
+}
+
+// Internal wraps err in an internal server error that does not disclose it
+func Internal(err error) *Error {
+	return New(http.StatusInternalServerError, CodeInternal, "internal server error").WithCause(err)
+}
+
+// Invalid creates an invalid request error listing fields
+func Invalid(fields ...FieldError) *Error {
+	return New(http.StatusBadRequest, CodeInvalidRequest, "request has invalid fields").WithFields(fields...)
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %s", e.Detail, e.Err)
+	}
+	return e.Detail
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// WithCause returns a copy of e wrapping err
+func (e *Error) WithCause(err error) *Error {
+	c := *e
+	c.Err = err
+	return &c
+}
+
+// WithFields returns a copy of e listing fields
+func (e *Error) WithFields(fields ...FieldError) *Error {
+	c := *e
+	c.Fields = fields
+	return &c
+}
+
+// WithCurrent returns a copy of e reporting the current state of the resource
+func (e *Error) WithCurrent(current interface{}) *Error {
+	c := *e
+	c.Current = current
+	return &c
+}
+
+// Problem returns the response body describing e for the request at instance
+func (e *Error) Problem(instance string) Problem {
+	return Problem{
+		Type:     defaultType,
+		Title:    http.StatusText(e.Status),
+		Status:   e.Status,
+		Detail:   e.Detail,
+		Instance: instance,
+		Code:     e.Code,
+		Errors:   e.Fields,
+		Current:  e.Current,
+	}
+}
+
+func (p Problem) Error() string {
+	if p.Detail != "" {
+		return fmt.Sprintf("%s: %s", p.Code, p.Detail)
+	}
+	return p.Code
+}